@@ -15,6 +15,18 @@ type Config struct {
 	LiveKitURL       string
 	LiveKitAPIKey    string
 	LiveKitAPISecret string
+	// InvoiceFont is the path of a TrueType font for invoice PDFs; without one
+	// they are set in Helvetica, which has no Cyrillic.
+	InvoiceFont string
+	// AppURL is where the frontend is served; mailed links point there.
+	AppURL string
+	// SMTPHost is the server mail is sent through; without one, mail is only
+	// logged.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
 }
 
 func Load(log *slog.Logger) Config {
@@ -33,6 +45,22 @@ func Load(log *slog.Logger) Config {
 		LiveKitURL:       os.Getenv("LIVEKIT_URL"),
 		LiveKitAPIKey:    os.Getenv("LIVEKIT_API_KEY"),
 		LiveKitAPISecret: os.Getenv("LIVEKIT_API_SECRET"),
+		InvoiceFont:      os.Getenv("INVOICE_FONT"),
+		AppURL:           os.Getenv("APP_URL"),
+		SMTPHost:         os.Getenv("SMTP_HOST"),
+		SMTPPort:         os.Getenv("SMTP_PORT"),
+		SMTPUsername:     os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:     os.Getenv("SMTP_PASSWORD"),
+		MailFrom:         os.Getenv("MAIL_FROM"),
+	}
+	if cfg.AppURL == "" {
+		cfg.AppURL = "http://localhost:3000"
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "587"
+	}
+	if cfg.MailFrom == "" {
+		cfg.MailFrom = "TutorGo <noreply@tutorgo.app>"
 	}
 
 	if cfg.DBUrl == "" {
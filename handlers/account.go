@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AccountHandler struct {
+	service service.AccountService
+	log     *slog.Logger
+}
+
+func NewAccountHandler(svc service.AccountService, log *slog.Logger) *AccountHandler {
+	return &AccountHandler{service: svc, log: log}
+}
+
+// SendVerification mails the tutor a new link to verify their email.
+func (h *AccountHandler) SendVerification(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	if err := h.service.SendVerification(c.Request.Context(), tutorID); err != nil {
+		h.log.Error("Failed to send verification email", slog.String("tutor_id", tutorID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Verification email sent", slog.String("tutor_id", tutorID))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		h.log.Warn("Failed to verify email", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Email verified")
+	c.Status(http.StatusNoContent)
+}
+
+// forgotPasswordTimeout bounds mailing a reset link once the request has been
+// answered.
+const forgotPasswordTimeout = time.Minute
+
+// ForgotPassword answers the same, and as fast, whether or not the email has an
+// account: the link is made and mailed in the background, and a failure to send
+// it is only logged.
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, forgotPasswordTimeout)
+		defer cancel()
+		if err := h.service.ForgotPassword(ctx, req.Email); err != nil {
+			h.log.Error("Failed to send password reset email", slog.String("error", err.Error()))
+		}
+	}()
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		h.log.Error("Failed to hash password", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, string(hash)); err != nil {
+		h.log.Warn("Failed to reset password", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Password reset")
+	c.Status(http.StatusNoContent)
+}
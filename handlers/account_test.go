@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newAccountRouter(svc *mockAccountService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewAccountHandler(svc, slog.Default())
+	r.POST("/auth/verify-email", h.VerifyEmail)
+	r.POST("/auth/forgot-password", h.ForgotPassword)
+	r.POST("/auth/reset-password", h.ResetPassword)
+	r.POST("/auth/verify-email/send", withTutorID(tutorID), h.SendVerification)
+	return r
+}
+
+func TestAccountSendVerification_AlreadyVerified(t *testing.T) {
+	svc := new(mockAccountService)
+	r := newAccountRouter(svc, testTutorID)
+
+	svc.On("SendVerification", mock.Anything, testTutorID).
+		Return(fmt.Errorf("the email is already verified: %w", service.ErrConflict))
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/verify-email/send", nil)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAccountSendVerification_Unauthorized(t *testing.T) {
+	r := newAccountRouter(new(mockAccountService), "")
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/verify-email/send", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAccountVerifyEmail_InvalidLink(t *testing.T) {
+	svc := new(mockAccountService)
+	r := newAccountRouter(svc, "")
+
+	svc.On("VerifyEmail", mock.Anything, "stale").
+		Return(fmt.Errorf("the link is invalid or has expired: %w", service.ErrBadRequest))
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/verify-email", models.VerifyEmailRequest{Token: "stale"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAccountForgotPassword_NoContent(t *testing.T) {
+	svc := new(mockAccountService)
+	r := newAccountRouter(svc, "")
+
+	sent := make(chan struct{})
+	svc.On("ForgotPassword", mock.Anything, "anna@example.com").
+		Run(func(mock.Arguments) { close(sent) }).
+		Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/forgot-password", models.ForgotPasswordRequest{Email: "anna@example.com"})
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	<-sent
+	svc.AssertExpectations(t)
+}
+
+func TestAccountForgotPassword_SendFailureIsNotTold(t *testing.T) {
+	svc := new(mockAccountService)
+	r := newAccountRouter(svc, "")
+
+	sent := make(chan struct{})
+	svc.On("ForgotPassword", mock.Anything, "anna@example.com").
+		Run(func(mock.Arguments) { close(sent) }).
+		Return(errors.New("smtp: connection refused"))
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/forgot-password", models.ForgotPasswordRequest{Email: "anna@example.com"})
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	<-sent
+}
+
+func TestAccountResetPassword_HashesPassword(t *testing.T) {
+	svc := new(mockAccountService)
+	r := newAccountRouter(svc, "")
+
+	svc.On("ResetPassword", mock.Anything, "token", mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")) == nil
+	})).Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/reset-password",
+		models.ResetPasswordRequest{Token: "token", Password: "new-password"})
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestAccountResetPassword_ShortPassword(t *testing.T) {
+	svc := new(mockAccountService)
+	r := newAccountRouter(svc, "")
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/reset-password",
+		models.ResetPasswordRequest{Token: "token", Password: "123"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+}
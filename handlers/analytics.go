@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	service service.AnalyticsService
+	log     *slog.Logger
+}
+
+func NewAnalyticsHandler(svc service.AnalyticsService, log *slog.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{service: svc, log: log}
+}
+
+// GetIncome reports income between ?from= and ?to= (dates, both included),
+// bucketed by ?bucket=day, week or month.
+func (h *AnalyticsHandler) GetIncome(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	income, err := h.service.GetIncome(c.Request.Context(), tutorID, from, to, c.Query("bucket"))
+	if err != nil {
+		h.log.Error("Failed to get income analytics", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, income)
+}
+
+// GetWorkload reports the lessons between ?from= and ?to= (dates, both
+// included) against the period of the same length before.
+func (h *AnalyticsHandler) GetWorkload(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	workload, err := h.service.GetWorkload(c.Request.Context(), tutorID, from, to)
+	if err != nil {
+		h.log.Error("Failed to get workload report", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, workload)
+}
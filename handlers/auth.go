@@ -17,12 +17,30 @@ import (
 
 type AuthHandler struct {
 	service   service.TutorService
+	sessions  service.SessionService
+	accounts  service.AccountService
+	twoFactor service.TwoFactorService
 	log       *slog.Logger
 	jwtSecret string
 }
 
-func NewAuthHandler(svc service.TutorService, log *slog.Logger, jwtSecret string) *AuthHandler {
-	return &AuthHandler{service: svc, log: log, jwtSecret: jwtSecret}
+func NewAuthHandler(svc service.TutorService, sessions service.SessionService, accounts service.AccountService, twoFactor service.TwoFactorService, log *slog.Logger, jwtSecret string) *AuthHandler {
+	return &AuthHandler{service: svc, sessions: sessions, accounts: accounts, twoFactor: twoFactor, log: log, jwtSecret: jwtSecret}
+}
+
+// accessTTL is how long an access token works. It stays at a day until the web
+// client refreshes tokens on its own; revoking the session still ends it early.
+const accessTTL = 24 * time.Hour
+
+// challengeTTL is how long the code step of a two-factor sign-in may take.
+const challengeTTL = 5 * time.Minute
+
+// challengePurpose marks challenge tokens. They name no session, so the auth
+// middleware refuses them as access tokens.
+const challengePurpose = "2fa"
+
+func sessionMeta(c *gin.Context) models.SessionMeta {
+	return models.SessionMeta{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -43,6 +61,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Phone:     req.Phone,
+		Timezone:  req.Timezone,
 	}
 	tutor, err := h.service.Create(c.Request.Context(), createReq, string(passwordHash))
 	if err != nil {
@@ -56,6 +75,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// The account works before the email is verified, so a lost email is only
+	// worth a warning; the tutor can ask for another.
+	if err := h.accounts.SendVerification(c.Request.Context(), tutor.ID); err != nil {
+		h.log.Warn("Failed to send verification email", slog.String("id", tutor.ID), slog.String("error", err.Error()))
+	}
 	h.log.Info("Tutor registered", slog.String("id", tutor.ID), slog.String("email", tutor.Email))
 	c.JSON(http.StatusCreated, tutor)
 }
@@ -83,18 +107,124 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	twoFactor, err := h.twoFactor.IsEnabled(c.Request.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to check two-factor authentication", slog.String("id", id), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	if twoFactor {
+		h.respondChallenge(c, id)
+		return
+	}
+	h.signIn(c, id)
+}
+
+// LoginTwoFactor is the second step of signing in with two-factor
+// authentication: the challenge token from Login and a code from the
+// authenticator app or a recovery code.
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(req.ChallengeToken, claims, func(*jwt.Token) (any, error) {
+		return []byte(h.jwtSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	id, _ := claims["id"].(string)
+	if err != nil || !token.Valid || claims["purpose"] != challengePurpose || id == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+	if err := h.twoFactor.Verify(c.Request.Context(), id, req.Code); err != nil {
+		h.log.Warn("Two-factor code rejected", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.signIn(c, id)
+}
+
+// signIn opens a session for the tutor and responds with its tokens.
+func (h *AuthHandler) signIn(c *gin.Context, id string) {
+	session, refreshToken, err := h.sessions.Create(c.Request.Context(), id, sessionMeta(c))
+	if err != nil {
+		h.log.Error("Failed to create session", slog.String("id", id), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	h.log.Info("Tutor logged in", slog.String("id", id), slog.String("session", session.ID))
+	h.respondTokens(c, session, refreshToken)
+}
+
+// respondChallenge answers a correct password when a code is needed as well.
+func (h *AuthHandler) respondChallenge(c *gin.Context, id string) {
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  id,
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
+		"id":      id,
+		"purpose": challengePurpose,
+		"iat":     now.Unix(),
+		"exp":     now.Add(challengeTTL).Unix(),
 	})
-
 	tokenString, err := token.SignedString([]byte(h.jwtSecret))
 	if err != nil {
 		h.log.Error("Failed to sign token", slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
+	c.JSON(http.StatusOK, models.TwoFactorChallenge{TwoFactorRequired: true, ChallengeToken: tokenString})
+}
+
+// Refresh trades a refresh token for a new access token and the next refresh
+// token.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	session, refreshToken, err := h.sessions.Refresh(c.Request.Context(), req.RefreshToken, sessionMeta(c))
+	if err != nil {
+		h.log.Warn("Failed to refresh session", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.respondTokens(c, session, refreshToken)
+}
 
-	h.log.Info("Tutor logged in", slog.String("id", id))
-	c.JSON(http.StatusOK, models.LoginResponse{Token: tokenString})
+// Logout signs the current session out.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	sessionID := c.GetString("sessionID")
+	if err := h.sessions.Revoke(c.Request.Context(), sessionID, tutorID); err != nil {
+		h.log.Error("Failed to log out", slog.String("session", sessionID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Tutor logged out", slog.String("id", tutorID), slog.String("session", sessionID))
+	c.Status(http.StatusNoContent)
+}
+
+// respondTokens signs an access token for the session.
+func (h *AuthHandler) respondTokens(c *gin.Context, session models.Session, refreshToken string) {
+	now := time.Now()
+	expiresAt := now.Add(accessTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":   session.TutorID,
+		"sid":  session.ID,
+		"role": models.RoleTutor,
+		"iat":  now.Unix(),
+		"exp":  expiresAt.Unix(),
+	})
+
+	tokenString, err := token.SignedString([]byte(h.jwtSecret))
+	if err != nil {
+		h.log.Error("Failed to sign token", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, models.LoginResponse{Token: tokenString, ExpiresAt: expiresAt, RefreshToken: refreshToken})
 }
@@ -2,31 +2,71 @@ package handlers_test
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 	"tutorgo/handlers"
 	"tutorgo/models"
+	"tutorgo/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 	"log/slog"
 )
 
+const testSessionID = "88888888-8888-8888-8888-888888888888"
+
 func newAuthRouter(svc *mockTutorService) *gin.Engine {
+	return newAuthRouterWith(svc, signedIn(), mailed(), singleFactor())
+}
+
+func newAuthRouterWith(svc *mockTutorService, sessions *mockSessionService, accounts *mockAccountService, twoFactor *mockTwoFactorService) *gin.Engine {
 	r := gin.New()
-	h := handlers.NewAuthHandler(svc, slog.Default(), "test-secret")
+	h := handlers.NewAuthHandler(svc, sessions, accounts, twoFactor, slog.Default(), "test-secret")
 	r.POST("/auth/register", h.Register)
 	r.POST("/auth/login", h.Login)
+	r.POST("/auth/login/2fa", h.LoginTwoFactor)
+	r.POST("/auth/refresh", h.Refresh)
+	r.POST("/auth/logout", func(c *gin.Context) {
+		c.Set("tutorID", testTutorID)
+		c.Set("sessionID", testSessionID)
+	}, h.Logout)
 	return r
 }
 
+// signedIn lets any login open a session.
+func signedIn() *mockSessionService {
+	m := new(mockSessionService)
+	m.On("Create", mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Session{ID: testSessionID, TutorID: testTutorID}, "refresh-token", nil).Maybe()
+	return m
+}
+
+// mailed lets any registration send its verification email.
+func mailed() *mockAccountService {
+	m := new(mockAccountService)
+	m.On("SendVerification", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return m
+}
+
+// singleFactor has two-factor authentication off for everyone.
+func singleFactor() *mockTwoFactorService {
+	m := new(mockTwoFactorService)
+	m.On("IsEnabled", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	return m
+}
+
 // Register
 
 func TestAuthRegister_Success(t *testing.T) {
 	svc := new(mockTutorService)
-	r := newAuthRouter(svc)
+	accounts := new(mockAccountService)
+	r := newAuthRouterWith(svc, signedIn(), accounts, singleFactor())
 
 	req := models.RegisterRequest{
 		Email:     "tutor@example.com",
@@ -39,11 +79,31 @@ func TestAuthRegister_Success(t *testing.T) {
 	svc.On("Create", mock.Anything, mock.MatchedBy(func(cr models.CreateTutorRequest) bool {
 		return cr.Email == req.Email && cr.FirstName == req.FirstName
 	}), mock.AnythingOfType("string")).Return(testTutor, nil)
+	accounts.On("SendVerification", mock.Anything, testTutor.ID).Return(nil)
 
 	w := makeRequest(t, r, http.MethodPost, "/auth/register", req)
 
 	assert.Equal(t, http.StatusCreated, w.Code)
 	svc.AssertExpectations(t)
+	accounts.AssertExpectations(t)
+}
+
+func TestAuthRegister_MailFailureStillRegisters(t *testing.T) {
+	svc := new(mockTutorService)
+	accounts := new(mockAccountService)
+	r := newAuthRouterWith(svc, signedIn(), accounts, singleFactor())
+
+	svc.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("string")).Return(testTutor, nil)
+	accounts.On("SendVerification", mock.Anything, testTutor.ID).Return(errors.New("smtp down"))
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/register", models.RegisterRequest{
+		Email:     "tutor@example.com",
+		Password:  "password123",
+		FirstName: "Amir",
+		LastName:  "Bekov",
+	})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
 func TestAuthRegister_ValidationError(t *testing.T) {
@@ -101,6 +161,7 @@ func TestAuthLogin_Success(t *testing.T) {
 	var got models.LoginResponse
 	decodeJSON(t, w, &got)
 	assert.NotEmpty(t, got.Token)
+	assert.Equal(t, "refresh-token", got.RefreshToken)
 	svc.AssertExpectations(t)
 }
 
@@ -184,3 +245,132 @@ func TestAuthLogin_ValidationError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	svc.AssertNotCalled(t, "GetByEmail")
 }
+
+// Refresh and logout
+
+func TestAuthRefresh_Success(t *testing.T) {
+	sessions := new(mockSessionService)
+	r := newAuthRouterWith(new(mockTutorService), sessions, mailed(), singleFactor())
+
+	sessions.On("Refresh", mock.Anything, "old-token", mock.Anything).
+		Return(models.Session{ID: testSessionID, TutorID: testTutorID}, "new-token", nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/refresh", models.RefreshRequest{RefreshToken: "old-token"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.LoginResponse
+	decodeJSON(t, w, &got)
+	assert.Equal(t, "new-token", got.RefreshToken)
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(got.Token, claims, func(*jwt.Token) (any, error) { return []byte("test-secret"), nil })
+	assert.NoError(t, err)
+	assert.Equal(t, testTutorID, claims["id"])
+	assert.Equal(t, testSessionID, claims["sid"])
+}
+
+func TestAuthRefresh_Rejected(t *testing.T) {
+	sessions := new(mockSessionService)
+	r := newAuthRouterWith(new(mockTutorService), sessions, mailed(), singleFactor())
+
+	sessions.On("Refresh", mock.Anything, "reused-token", mock.Anything).
+		Return(models.Session{}, "", fmt.Errorf("refresh token reused: %w", service.ErrUnauthorized))
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/refresh", models.RefreshRequest{RefreshToken: "reused-token"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthLogout_RevokesCurrentSession(t *testing.T) {
+	sessions := new(mockSessionService)
+	r := newAuthRouterWith(new(mockTutorService), sessions, mailed(), singleFactor())
+
+	sessions.On("Revoke", mock.Anything, testSessionID, testTutorID).Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/logout", nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	sessions.AssertExpectations(t)
+}
+
+// Two-factor login
+
+// challengeFor signs in with a password as the tutor with two-factor
+// authentication on and returns the challenge.
+func challengeFor(t *testing.T, r *gin.Engine, svc *mockTutorService) models.TwoFactorChallenge {
+	t.Helper()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	svc.On("GetByEmail", mock.Anything, "tutor@example.com").Return(testTutorID, string(hash), nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/login", models.LoginRequest{Email: "tutor@example.com", Password: "password123"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var got models.TwoFactorChallenge
+	decodeJSON(t, w, &got)
+	require.True(t, got.TwoFactorRequired)
+	return got
+}
+
+func TestAuthLogin_TwoFactorIssuesChallengeOnly(t *testing.T) {
+	svc := new(mockTutorService)
+	sessions := new(mockSessionService)
+	twoFactor := new(mockTwoFactorService)
+	r := newAuthRouterWith(svc, sessions, mailed(), twoFactor)
+
+	twoFactor.On("IsEnabled", mock.Anything, testTutorID).Return(true, nil)
+
+	challenge := challengeFor(t, r, svc)
+
+	assert.NotEmpty(t, challenge.ChallengeToken)
+	sessions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthLoginTwoFactor_Success(t *testing.T) {
+	svc := new(mockTutorService)
+	twoFactor := new(mockTwoFactorService)
+	r := newAuthRouterWith(svc, signedIn(), mailed(), twoFactor)
+
+	twoFactor.On("IsEnabled", mock.Anything, testTutorID).Return(true, nil)
+	twoFactor.On("Verify", mock.Anything, testTutorID, "123456").Return(nil)
+	challenge := challengeFor(t, r, svc)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/login/2fa",
+		models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "123456"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.LoginResponse
+	decodeJSON(t, w, &got)
+	assert.Equal(t, "refresh-token", got.RefreshToken)
+}
+
+func TestAuthLoginTwoFactor_WrongCode(t *testing.T) {
+	svc := new(mockTutorService)
+	sessions := new(mockSessionService)
+	twoFactor := new(mockTwoFactorService)
+	r := newAuthRouterWith(svc, sessions, mailed(), twoFactor)
+
+	twoFactor.On("IsEnabled", mock.Anything, testTutorID).Return(true, nil)
+	twoFactor.On("Verify", mock.Anything, testTutorID, "000000").Return(fmt.Errorf("wrong code: %w", service.ErrUnauthorized))
+	challenge := challengeFor(t, r, svc)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/login/2fa",
+		models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "000000"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	sessions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthLoginTwoFactor_AccessTokenIsNoChallenge(t *testing.T) {
+	twoFactor := new(mockTwoFactorService)
+	r := newAuthRouterWith(new(mockTutorService), signedIn(), mailed(), twoFactor)
+
+	access := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id": testTutorID, "sid": testSessionID, "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token, err := access.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/login/2fa", models.TwoFactorLoginRequest{ChallengeToken: token, Code: "123456"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	twoFactor.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything, mock.Anything)
+}
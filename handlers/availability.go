@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	service service.AvailabilityService
+	log     *slog.Logger
+}
+
+func NewAvailabilityHandler(svc service.AvailabilityService, log *slog.Logger) *AvailabilityHandler {
+	return &AvailabilityHandler{service: svc, log: log}
+}
+
+func (h *AvailabilityHandler) GetFreeSlots(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	slots, err := h.service.GetFreeSlots(c.Request.Context(), tutorID, from, to)
+	if err != nil {
+		h.log.Error("Failed to get free slots", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+func (h *AvailabilityHandler) GetWorkingHours(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	hours, err := h.service.GetWorkingHours(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get working hours", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hours)
+}
+
+func (h *AvailabilityHandler) SetWorkingHours(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.SetWorkingHoursRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	hours, err := h.service.SetWorkingHours(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to set working hours", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Working hours updated", slog.Int("count", len(hours)))
+	c.JSON(http.StatusOK, hours)
+}
+
+func (h *AvailabilityHandler) GetOverrides(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	overrides, err := h.service.GetOverrides(c.Request.Context(), tutorID, from, to)
+	if err != nil {
+		h.log.Error("Failed to get availability overrides", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, overrides)
+}
+
+func (h *AvailabilityHandler) CreateOverride(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateOverrideRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	override, err := h.service.CreateOverride(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to create availability override", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Availability override created", slog.String("id", override.ID))
+	c.JSON(http.StatusCreated, override)
+}
+
+func (h *AvailabilityHandler) DeleteOverride(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.DeleteOverride(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to delete availability override", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Availability override deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AvailabilityHandler) GetBlackouts(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	blackouts, err := h.service.GetBlackouts(c.Request.Context(), tutorID, from, to)
+	if err != nil {
+		h.log.Error("Failed to get blackouts", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, blackouts)
+}
+
+func (h *AvailabilityHandler) CreateBlackout(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateBlackoutRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	blackout, err := h.service.CreateBlackout(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to create blackout", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Blackout created", slog.String("id", blackout.ID))
+	c.JSON(http.StatusCreated, blackout)
+}
+
+func (h *AvailabilityHandler) DeleteBlackout(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.DeleteBlackout(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to delete blackout", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Blackout deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
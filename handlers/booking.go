@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookingHandler struct {
+	service service.BookingService
+	log     *slog.Logger
+}
+
+func NewBookingHandler(svc service.BookingService, log *slog.Logger) *BookingHandler {
+	return &BookingHandler{service: svc, log: log}
+}
+
+func (h *BookingHandler) CreateLink(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateBookingLinkRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	link, err := h.service.CreateLink(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to create booking link", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Booking link created", slog.String("id", link.ID))
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *BookingHandler) GetLinks(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	links, err := h.service.GetLinks(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get booking links", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+func (h *BookingHandler) DeleteLink(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.DeleteLink(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to delete booking link", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Booking link deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BookingHandler) GetRequests(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	requests, err := h.service.GetRequests(c.Request.Context(), tutorID, c.Query("status"))
+	if err != nil {
+		h.log.Error("Failed to get booking requests", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+func (h *BookingHandler) Confirm(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Confirm(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to confirm booking", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Booking confirmed", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BookingHandler) Decline(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Decline(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to decline booking", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Booking declined", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// GET /public/booking/:token — публичный, страница записи
+func (h *BookingHandler) GetPublicLink(c *gin.Context) {
+	link, err := h.service.GetPublicLink(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, link)
+}
+
+// GET /public/booking/:token/slots — публичный, свободные слоты
+func (h *BookingHandler) GetPublicSlots(c *gin.Context) {
+	slots, err := h.service.GetPublicSlots(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.log.Error("Failed to get booking slots", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+// POST /public/booking/:token — публичный, заявка на урок
+func (h *BookingHandler) Book(c *gin.Context) {
+	var req models.CreateBookingRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	booking, err := h.service.Book(c.Request.Context(), c.Param("token"), req)
+	if err != nil {
+		h.log.Error("Failed to book slot", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Booking requested", slog.String("id", booking.ID))
+	c.JSON(http.StatusCreated, gin.H{
+		"id":               booking.ID,
+		"status":           booking.Status,
+		"scheduled_at":     booking.ScheduledAt,
+		"duration_minutes": booking.DurationMinutes,
+	})
+}
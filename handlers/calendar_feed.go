@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarFeedHandler struct {
+	service service.CalendarFeedService
+	log     *slog.Logger
+}
+
+func NewCalendarFeedHandler(svc service.CalendarFeedService, log *slog.Logger) *CalendarFeedHandler {
+	return &CalendarFeedHandler{service: svc, log: log}
+}
+
+func (h *CalendarFeedHandler) Get(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	id := c.Param("id")
+	if id != tutorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+	feed, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to get calendar feed", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+func (h *CalendarFeedHandler) Rotate(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	id := c.Param("id")
+	if id != tutorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+	feed, err := h.service.Rotate(c.Request.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to rotate calendar feed token", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Calendar feed token rotated", slog.String("id", id))
+	c.JSON(http.StatusOK, feed)
+}
+
+func (h *CalendarFeedHandler) Revoke(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	id := c.Param("id")
+	if id != tutorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+	if err := h.service.Revoke(c.Request.Context(), id); err != nil {
+		h.log.Error("Failed to revoke calendar feed", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Calendar feed revoked", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// GET /public/calendar/:token — публичный, для подписки из Google/Apple Calendar.
+// Токен принимается как с суффиксом .ics, так и без него.
+func (h *CalendarFeedHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	body, err := h.service.Render(c.Request.Context(), token)
+	if err != nil {
+		h.log.Error("Failed to render calendar feed", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
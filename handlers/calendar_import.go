@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarImportHandler struct {
+	service service.CalendarImportService
+	log     *slog.Logger
+}
+
+func NewCalendarImportHandler(svc service.CalendarImportService, log *slog.Logger) *CalendarImportHandler {
+	return &CalendarImportHandler{service: svc, log: log}
+}
+
+// Preview accepts the .ics file either as the multipart field "file" or as the
+// raw request body.
+func (h *CalendarImportHandler) Preview(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var file io.Reader = c.Request.Body
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		defer f.Close()
+		file = f
+	}
+	imp, err := h.service.Preview(c.Request.Context(), tutorID, file)
+	if err != nil {
+		h.log.Error("Failed to preview calendar import", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Calendar import previewed", slog.String("id", imp.ID), slog.Int("events", len(imp.Events)))
+	c.JSON(http.StatusCreated, imp)
+}
+
+func (h *CalendarImportHandler) Commit(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CommitImportRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	opts := scheduleOptions(c)
+	result, err := h.service.Commit(c.Request.Context(), id, tutorID, req, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to commit calendar import", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Calendar import committed", slog.String("id", id),
+		slog.Int("lessons", result.LessonsCreated), slog.Int("series", result.SeriesCreated))
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CancellationPolicyHandler struct {
+	service service.CancellationPolicyService
+	log     *slog.Logger
+}
+
+func NewCancellationPolicyHandler(svc service.CancellationPolicyService, log *slog.Logger) *CancellationPolicyHandler {
+	return &CancellationPolicyHandler{service: svc, log: log}
+}
+
+func (h *CancellationPolicyHandler) GetForTutor(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	policy, err := h.service.GetForTutor(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get cancellation policy", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *CancellationPolicyHandler) SetForTutor(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.SetCancellationPolicyRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	policy, err := h.service.SetForTutor(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to set cancellation policy", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Cancellation policy updated", slog.String("tutor_id", tutorID))
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *CancellationPolicyHandler) GetForCourse(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	policy, err := h.service.GetForCourse(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get course cancellation policy", slog.String("course_id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *CancellationPolicyHandler) SetForCourse(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.SetCancellationPolicyRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	policy, err := h.service.SetForCourse(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to set course cancellation policy", slog.String("course_id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Course cancellation policy updated", slog.String("course_id", id))
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *CancellationPolicyHandler) ClearForCourse(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.ClearForCourse(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to clear course cancellation policy", slog.String("course_id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Course cancellation policy cleared", slog.String("course_id", id))
+	c.Status(http.StatusNoContent)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 	"tutorgo/handlers"
 	"tutorgo/models"
+	"tutorgo/money"
 	"tutorgo/service"
 
 	"github.com/gin-gonic/gin"
@@ -33,7 +34,7 @@ var (
 	testCreateCourseReq  = models.CreateCourseRequest{
 		StudentID:      testStudentIDPtr,
 		Subject:        "Mathematics",
-		PricePerLesson: 5000,
+		PricePerLesson: money.FromUnits(5000),
 		StartedAt:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 		EndedAt:        testEndedAt,
 	}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateHandler struct {
+	service service.ExchangeRateService
+	log     *slog.Logger
+}
+
+func NewExchangeRateHandler(svc service.ExchangeRateService, log *slog.Logger) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: svc, log: log}
+}
+
+func (h *ExchangeRateHandler) GetAll(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	rates, err := h.service.GetAll(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get exchange rates", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// PUT /exchange-rates/:currency
+func (h *ExchangeRateHandler) Set(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.SetExchangeRateRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	currency := c.Param("currency")
+	rate, err := h.service.Set(c.Request.Context(), tutorID, currency, req)
+	if err != nil {
+		h.log.Error("Failed to set exchange rate", slog.String("currency", currency), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Exchange rate updated", slog.String("currency", rate.Currency), slog.String("rate", rate.Rate.String()))
+	c.JSON(http.StatusOK, rate)
+}
+
+func (h *ExchangeRateHandler) Delete(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	currency := c.Param("currency")
+	if err := h.service.Delete(c.Request.Context(), tutorID, currency); err != nil {
+		h.log.Error("Failed to delete exchange rate", slog.String("currency", currency), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Exchange rate deleted", slog.String("currency", currency))
+	c.Status(http.StatusNoContent)
+}
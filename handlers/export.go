@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	service service.ExportService
+	log     *slog.Logger
+}
+
+func NewExportHandler(svc service.ExportService, log *slog.Logger) *ExportHandler {
+	return &ExportHandler{service: svc, log: log}
+}
+
+var exportContentTypes = map[string]string{
+	models.ExportCSV:  "text/csv; charset=utf-8",
+	models.ExportXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportOptions reads ?format= (csv by default) and the language, ?lang= or
+// else the first one in Accept-Language.
+func exportOptions(c *gin.Context) models.ExportOptions {
+	opts := models.ExportOptions{Format: c.DefaultQuery("format", models.ExportCSV), Lang: c.Query("lang")}
+	if opts.Lang == "" {
+		opts.Lang = c.GetHeader("Accept-Language")
+	}
+	opts.Lang = strings.ToLower(opts.Lang)
+	if len(opts.Lang) > 2 {
+		opts.Lang = opts.Lang[:2]
+	}
+	return opts
+}
+
+// exportWriter sends the download headers with the first bytes of the file, so
+// an export that fails before it starts can still answer with a JSON error.
+type exportWriter struct {
+	c    *gin.Context
+	opts models.ExportOptions
+	name string
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.c.Writer.Written() {
+		w.c.Header("Content-Type", exportContentTypes[w.opts.Format])
+		w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, w.name, w.opts.Format))
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
+
+// export runs one export as a download named name. Once the file has started
+// an error can only cut it short.
+func (h *ExportHandler) export(c *gin.Context, name string, run func(opts models.ExportOptions, w *exportWriter) error) {
+	opts := exportOptions(c)
+	if err := run(opts, &exportWriter{c: c, opts: opts, name: name}); err != nil {
+		h.log.Error("Failed to export "+name, slog.String("error", err.Error()))
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Exported "+name, slog.String("format", opts.Format))
+}
+
+// Students exports the students matching ?search=.
+func (h *ExportHandler) Students(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	h.export(c, "students", func(opts models.ExportOptions, w *exportWriter) error {
+		return h.service.Students(c.Request.Context(), tutorID, c.Query("search"), opts, w)
+	})
+}
+
+// Courses exports the courses matching ?search=.
+func (h *ExportHandler) Courses(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	h.export(c, "courses", func(opts models.ExportOptions, w *exportWriter) error {
+		return h.service.Courses(c.Request.Context(), tutorID, c.Query("search"), opts, w)
+	})
+}
+
+// Lessons exports the lessons between ?from= and ?to= (dates, both included),
+// optionally only those with ?status= or of ?course_id=.
+func (h *ExportHandler) Lessons(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	filter := models.LessonExportFilter{From: from, To: to, Status: c.Query("status")}
+	if id := c.Query("course_id"); id != "" {
+		filter.CourseID = &id
+	}
+	h.export(c, "lessons", func(opts models.ExportOptions, w *exportWriter) error {
+		return h.service.Lessons(c.Request.Context(), tutorID, filter, opts, w)
+	})
+}
+
+// Payments exports all the tutor's payments.
+func (h *ExportHandler) Payments(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	h.export(c, "payments", func(opts models.ExportOptions, w *exportWriter) error {
+		return h.service.Payments(c.Request.Context(), tutorID, opts, w)
+	})
+}
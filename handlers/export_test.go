@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newExportRouter(svc *mockExportService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewExportHandler(svc, slog.Default())
+	r.Use(withTutorID(tutorID))
+	r.GET("/exports/students", h.Students)
+	r.GET("/exports/lessons", h.Lessons)
+	r.GET("/exports/payments", h.Payments)
+	return r
+}
+
+func TestExportStudents_XLSXDownload(t *testing.T) {
+	svc := new(mockExportService)
+	r := newExportRouter(svc, testTutorID)
+
+	opts := models.ExportOptions{Format: models.ExportXLSX, Lang: "ru"}
+	svc.On("Students", mock.Anything, testTutorID, "iv", opts).Return("PK...", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/exports/students?format=xlsx&search=iv", nil)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="students.xlsx"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "PK...", w.Body.String())
+}
+
+func TestExportLessons_Filter(t *testing.T) {
+	svc := new(mockExportService)
+	r := newExportRouter(svc, testTutorID)
+
+	courseID := testCourseID
+	filter := models.LessonExportFilter{From: "2026-03-01", To: "2026-03-31", Status: "completed", CourseID: &courseID}
+	opts := models.ExportOptions{Format: models.ExportCSV, Lang: "en"}
+	svc.On("Lessons", mock.Anything, testTutorID, filter, opts).Return("a,b\n", nil)
+
+	w := makeRequest(t, r, http.MethodGet,
+		"/exports/lessons?from=2026-03-01&to=2026-03-31&status=completed&course_id="+testCourseID+"&lang=en", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="lessons.csv"`, w.Header().Get("Content-Disposition"))
+	svc.AssertExpectations(t)
+}
+
+func TestExportLessons_MissingDates(t *testing.T) {
+	svc := new(mockExportService)
+	r := newExportRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodGet, "/exports/lessons?from=2026-03-01", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Lessons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportPayments_ErrorBeforeWriting(t *testing.T) {
+	svc := new(mockExportService)
+	r := newExportRouter(svc, testTutorID)
+
+	svc.On("Payments", mock.Anything, testTutorID, mock.Anything).
+		Return("", fmt.Errorf("format must be csv or xlsx: %w", service.ErrBadRequest))
+
+	w := makeRequest(t, r, http.MethodGet, "/exports/payments?format=ods", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportPayments_ErrorMidway(t *testing.T) {
+	svc := new(mockExportService)
+	r := newExportRouter(svc, testTutorID)
+
+	svc.On("Payments", mock.Anything, testTutorID, mock.Anything).Return("Paid at\n", errors.New("connection reset"))
+
+	w := makeRequest(t, r, http.MethodGet, "/exports/payments", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Paid at\n", w.Body.String())
+}
+
+func TestExport_Unauthorized(t *testing.T) {
+	svc := new(mockExportService)
+	r := newExportRouter(svc, "")
+
+	w := makeRequest(t, r, http.MethodGet, "/exports/students", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
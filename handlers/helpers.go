@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"tutorgo/models"
 	"tutorgo/service"
 	"tutorgo/validator"
 
@@ -22,8 +23,42 @@ func bindAndValidate(c *gin.Context, req any) bool {
 	return true
 }
 
+// scheduleOptions reads the ?force and ?dry_run flags of calendar writes.
+func scheduleOptions(c *gin.Context) models.ScheduleOptions {
+	var opts models.ScheduleOptions
+	_ = c.ShouldBindQuery(&opts)
+	return opts
+}
+
+// respondDryRun answers a dry run with the conflicts found, an empty list when the
+// requested slots are free, plus any non-blocking warnings. It reports false for
+// errors that are not about the schedule.
+func respondDryRun(c *gin.Context, err error, warnings []string) bool {
+	var conflictErr *service.ConflictError
+	if warnings == nil {
+		warnings = []string{}
+	}
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"conflicts": []models.OccurrenceConflicts{}, "warnings": warnings})
+	case errors.As(err, &conflictErr):
+		c.JSON(http.StatusOK, gin.H{"conflicts": conflictErr.Occurrences, "warnings": warnings})
+	default:
+		return false
+	}
+	return true
+}
+
 func handleServiceError(c *gin.Context, err error) {
+	var conflictErr *service.ConflictError
+	var tooLarge *http.MaxBytesError
 	switch {
+	case errors.As(err, &conflictErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "schedule conflict", "conflicts": conflictErr.Occurrences})
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 	case errors.Is(err, service.ErrNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 	case errors.Is(err, service.ErrForbidden):
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	service service.InvoiceService
+	log     *slog.Logger
+}
+
+func NewInvoiceHandler(svc service.InvoiceService, log *slog.Logger) *InvoiceHandler {
+	return &InvoiceHandler{service: svc, log: log}
+}
+
+// GetAll lists invoices, optionally by ?status= (draft, sent, paid, overdue) and
+// ?course_id=.
+func (h *InvoiceHandler) GetAll(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var p models.Pagination
+	_ = c.ShouldBindQuery(&p)
+	p.Normalize()
+	var f models.InvoiceFilter
+	_ = c.ShouldBindQuery(&f)
+
+	invoices, total, err := h.service.GetAll(c.Request.Context(), tutorID, f, p)
+	if err != nil {
+		h.log.Error("Failed to get invoices", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.PagedResponse[models.Invoice]{
+		Data: invoices, Total: total, Page: p.Page, Limit: p.Limit,
+	})
+}
+
+func (h *InvoiceHandler) Create(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateInvoiceRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	invoice, err := h.service.Create(c.Request.Context(), req, tutorID)
+	if err != nil {
+		h.log.Error("Failed to create invoice", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Invoice created", slog.String("id", invoice.ID), slog.String("total", invoice.Total.String()))
+	c.JSON(http.StatusCreated, invoice)
+}
+
+func (h *InvoiceHandler) GetByID(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	invoice, err := h.service.GetByID(c.Request.Context(), id, tutorID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *InvoiceHandler) Delete(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to delete invoice", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Invoice deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// Send numbers the draft and marks it sent.
+func (h *InvoiceHandler) Send(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	invoice, err := h.service.Send(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to send invoice", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Invoice sent", slog.String("id", id), slog.Int("number", *invoice.Number))
+	c.JSON(http.StatusOK, invoice)
+}
+
+// Pay marks the invoice paid; the body may be empty.
+func (h *InvoiceHandler) Pay(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.PayInvoiceRequest
+	if c.Request.ContentLength != 0 && !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	invoice, err := h.service.Pay(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to mark invoice paid", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Invoice paid", slog.String("id", id), slog.String("payment_id", *invoice.PaymentID))
+	c.JSON(http.StatusOK, invoice)
+}
+
+// PDF downloads the invoice as a PDF.
+func (h *InvoiceHandler) PDF(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	body, name, err := h.service.RenderPDF(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to render invoice", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Data(http.StatusOK, "application/pdf", body)
+}
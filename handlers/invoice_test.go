@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testInvoiceID = "66666666-6666-6666-6666-666666666666"
+
+func newInvoiceRouter(svc *mockInvoiceService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewInvoiceHandler(svc, slog.Default())
+	r.Use(withTutorID(tutorID))
+	r.GET("/invoices", h.GetAll)
+	r.POST("/invoices", h.Create)
+	r.POST("/invoices/:id/pay", h.Pay)
+	r.GET("/invoices/:id/pdf", h.PDF)
+	return r
+}
+
+var testInvoice = models.Invoice{
+	ID:        testInvoiceID,
+	Status:    models.InvoiceDraft,
+	CourseID:  testCourseIDPtr,
+	StudentID: testStudentIDPtr,
+	Currency:  "KZT",
+	IssueDate: "2026-04-01",
+	DueDate:   "2026-04-15",
+	Total:     money.FromUnits(5000),
+}
+
+func TestInvoiceGetAll_ByStatus(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, testTutorID)
+
+	p := models.Pagination{Page: 1, Limit: 20}
+	svc.On("GetAll", mock.Anything, testTutorID, models.InvoiceFilter{Status: "overdue"}, p).Return([]models.Invoice{testInvoice}, 1, nil)
+
+	w := makeRequest(t, r, http.MethodGet, "/invoices?status=overdue&page=1&limit=20", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.PagedResponse[models.Invoice]
+	decodeJSON(t, w, &got)
+	assert.Equal(t, 1, got.Total)
+	svc.AssertExpectations(t)
+}
+
+func TestInvoiceCreate_Success(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, testTutorID)
+
+	req := models.CreateInvoiceRequest{Source: models.InvoiceFromLessons, CourseID: testCourseID, From: "2026-03-01", To: "2026-03-31"}
+	svc.On("Create", mock.Anything, req, testTutorID).Return(testInvoice, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/invoices", req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestInvoiceCreate_LessonsNeedPeriod(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodPost, "/invoices", map[string]any{"source": "lessons", "course_id": testCourseID})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Create")
+}
+
+func TestInvoicePay_EmptyBody(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, testTutorID)
+
+	paid := testInvoice
+	paid.Status = models.InvoicePaid
+	paid.PaymentID = func() *string { s := testPaymentID; return &s }()
+	svc.On("Pay", mock.Anything, testInvoiceID, testTutorID, models.PayInvoiceRequest{}).Return(paid, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/invoices/"+testInvoiceID+"/pay", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestInvoicePay_Draft(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, testTutorID)
+
+	svc.On("Pay", mock.Anything, testInvoiceID, testTutorID, models.PayInvoiceRequest{}).
+		Return(models.Invoice{}, service.ErrConflict)
+
+	w := makeRequest(t, r, http.MethodPost, "/invoices/"+testInvoiceID+"/pay", nil)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestInvoicePDF(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, testTutorID)
+
+	svc.On("RenderPDF", mock.Anything, testInvoiceID, testTutorID).Return([]byte("%PDF-1.4"), "invoice-7.pdf", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/invoices/"+testInvoiceID+"/pdf", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="invoice-7.pdf"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "%PDF-1.4", w.Body.String())
+}
+
+func TestInvoicePDF_Unauthorized(t *testing.T) {
+	svc := new(mockInvoiceService)
+	r := newInvoiceRouter(svc, "")
+
+	w := makeRequest(t, r, http.MethodGet, "/invoices/"+testInvoiceID+"/pdf", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
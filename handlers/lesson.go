@@ -50,7 +50,11 @@ func (h *LessonHandler) CreateBulk(c *gin.Context) {
 	if !bindAndValidate(c, &req) {
 		return
 	}
-	lessons, err := h.service.CreateBulk(c.Request.Context(), req, tutorID)
+	opts := scheduleOptions(c)
+	lessons, err := h.service.CreateBulk(c.Request.Context(), req, tutorID, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to create lessons", slog.String("error", err.Error()))
 		handleServiceError(c, err)
@@ -70,7 +74,11 @@ func (h *LessonHandler) Create(c *gin.Context) {
 	if !bindAndValidate(c, &req) {
 		return
 	}
-	lesson, err := h.service.Create(c.Request.Context(), req, tutorID)
+	opts := scheduleOptions(c)
+	lesson, err := h.service.Create(c.Request.Context(), req, tutorID, opts)
+	if opts.DryRun && respondDryRun(c, err, lesson.Warnings) {
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to create lesson", slog.String("error", err.Error()))
 		handleServiceError(c, err)
@@ -107,7 +115,11 @@ func (h *LessonHandler) Update(c *gin.Context) {
 	if !bindAndValidate(c, &req) {
 		return
 	}
-	lesson, err := h.service.Update(c.Request.Context(), id, req, tutorID)
+	opts := scheduleOptions(c)
+	lesson, err := h.service.Update(c.Request.Context(), id, req, tutorID, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to update lesson", slog.String("id", id), slog.String("error", err.Error()))
 		handleServiceError(c, err)
@@ -117,6 +129,24 @@ func (h *LessonHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, lesson)
 }
 
+// GetStatusEvents returns the lesson's status timeline: who changed it, when,
+// from which status to which, and why.
+func (h *LessonHandler) GetStatusEvents(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	events, err := h.service.GetStatusEvents(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get lesson status events", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
 func (h *LessonHandler) Delete(c *gin.Context) {
 	tutorID := c.GetString("tutorID")
 	if tutorID == "" {
@@ -153,47 +183,6 @@ func (h *LessonHandler) DeleteByCourse(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-func (h *LessonHandler) DeleteSeries(c *gin.Context) {
-	tutorID := c.GetString("tutorID")
-	if tutorID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	seriesID := c.Param("seriesId")
-	fromDate := c.Query("from")
-	var fromDatePtr *string
-	if fromDate != "" {
-		fromDatePtr = &fromDate
-	}
-	if err := h.service.DeleteSeries(c.Request.Context(), seriesID, tutorID, fromDatePtr); err != nil {
-		h.log.Error("Failed to delete series", slog.String("seriesId", seriesID), slog.String("error", err.Error()))
-		handleServiceError(c, err)
-		return
-	}
-	h.log.Info("Series deleted", slog.String("seriesId", seriesID))
-	c.Status(http.StatusNoContent)
-}
-
-func (h *LessonHandler) UpdateSeries(c *gin.Context) {
-	tutorID := c.GetString("tutorID")
-	if tutorID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	seriesID := c.Param("seriesId")
-	var req models.UpdateSeriesRequest
-	if !bindAndValidate(c, &req) {
-		return
-	}
-	if err := h.service.UpdateSeries(c.Request.Context(), seriesID, tutorID, req); err != nil {
-		h.log.Error("Failed to update series", slog.String("seriesId", seriesID), slog.String("error", err.Error()))
-		handleServiceError(c, err)
-		return
-	}
-	h.log.Info("Series updated", slog.String("seriesId", seriesID))
-	c.Status(http.StatusNoContent)
-}
-
 func (h *LessonHandler) GetCalendar(c *gin.Context) {
 	tutorID := c.GetString("tutorID")
 	if tutorID == "" {
@@ -24,6 +24,7 @@ func newLessonRouter(svc *mockLessonService, tutorID string) *gin.Engine {
 	r.GET("/lessons/:id", h.GetByID)
 	r.PUT("/lessons/:id", h.Update)
 	r.DELETE("/lessons/:id", h.Delete)
+	r.GET("/lessons/:id/status-events", h.GetStatusEvents)
 	return r
 }
 
@@ -81,7 +82,7 @@ func TestLessonCreate_Success(t *testing.T) {
 	svc := new(mockLessonService)
 	r := newLessonRouter(svc, testTutorID)
 
-	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID).Return(testLesson, nil)
+	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID, models.ScheduleOptions{}).Return(testLesson, nil)
 
 	w := makeRequest(t, r, http.MethodPost, "/lessons", testCreateLessonReq)
 
@@ -104,7 +105,7 @@ func TestLessonCreate_ServiceError(t *testing.T) {
 	svc := new(mockLessonService)
 	r := newLessonRouter(svc, testTutorID)
 
-	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID).Return(models.Lesson{}, fmt.Errorf("course: %w", service.ErrNotFound))
+	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID, models.ScheduleOptions{}).Return(models.Lesson{}, fmt.Errorf("course: %w", service.ErrNotFound))
 
 	w := makeRequest(t, r, http.MethodPost, "/lessons", testCreateLessonReq)
 
@@ -112,6 +113,54 @@ func TestLessonCreate_ServiceError(t *testing.T) {
 	svc.AssertExpectations(t)
 }
 
+func TestLessonCreate_Conflict(t *testing.T) {
+	svc := new(mockLessonService)
+	r := newLessonRouter(svc, testTutorID)
+
+	conflicts := []models.OccurrenceConflicts{{
+		ScheduledAt:     testCreateLessonReq.ScheduledAt,
+		DurationMinutes: 60,
+		Conflicts:       []models.ScheduleConflict{{Kind: "lesson", ID: testLessonID, Title: "Mathematics"}},
+	}}
+	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID, models.ScheduleOptions{}).
+		Return(models.Lesson{}, &service.ConflictError{Occurrences: conflicts})
+
+	w := makeRequest(t, r, http.MethodPost, "/lessons", testCreateLessonReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var body struct {
+		Conflicts []models.OccurrenceConflicts `json:"conflicts"`
+	}
+	decodeJSON(t, w, &body)
+	assert.Len(t, body.Conflicts, 1)
+	assert.Equal(t, testLessonID, body.Conflicts[0].Conflicts[0].ID)
+}
+
+func TestLessonCreate_Force(t *testing.T) {
+	svc := new(mockLessonService)
+	r := newLessonRouter(svc, testTutorID)
+
+	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID, models.ScheduleOptions{Force: true}).Return(testLesson, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/lessons?force=true", testCreateLessonReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestLessonCreate_DryRunFree(t *testing.T) {
+	svc := new(mockLessonService)
+	r := newLessonRouter(svc, testTutorID)
+
+	svc.On("Create", mock.Anything, testCreateLessonReq, testTutorID, models.ScheduleOptions{DryRun: true}).
+		Return(models.Lesson{Warnings: []string{models.WarningOutsideWorkingHours}}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/lessons?dry_run=true", testCreateLessonReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"conflicts":[],"warnings":["outside_working_hours"]}`, w.Body.String())
+}
+
 // GetByID
 
 func TestLessonGetByID_Success(t *testing.T) {
@@ -144,7 +193,7 @@ func TestLessonUpdate_Success(t *testing.T) {
 	svc := new(mockLessonService)
 	r := newLessonRouter(svc, testTutorID)
 
-	svc.On("Update", mock.Anything, testLessonID, testUpdateLessonReq, testTutorID).Return(testLesson, nil)
+	svc.On("Update", mock.Anything, testLessonID, testUpdateLessonReq, testTutorID, models.ScheduleOptions{}).Return(testLesson, nil)
 
 	w := makeRequest(t, r, http.MethodPut, "/lessons/"+testLessonID, testUpdateLessonReq)
 
@@ -156,7 +205,7 @@ func TestLessonUpdate_NotFound(t *testing.T) {
 	svc := new(mockLessonService)
 	r := newLessonRouter(svc, testTutorID)
 
-	svc.On("Update", mock.Anything, testLessonID, testUpdateLessonReq, testTutorID).Return(models.Lesson{}, fmt.Errorf("lesson: %w", service.ErrNotFound))
+	svc.On("Update", mock.Anything, testLessonID, testUpdateLessonReq, testTutorID, models.ScheduleOptions{}).Return(models.Lesson{}, fmt.Errorf("lesson: %w", service.ErrNotFound))
 
 	w := makeRequest(t, r, http.MethodPut, "/lessons/"+testLessonID, testUpdateLessonReq)
 
@@ -189,3 +238,31 @@ func TestLessonDelete_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	svc.AssertExpectations(t)
 }
+
+// GetStatusEvents
+
+func TestLessonGetStatusEvents_Success(t *testing.T) {
+	svc := new(mockLessonService)
+	r := newLessonRouter(svc, testTutorID)
+
+	events := []models.LessonStatusEvent{{ID: "event-1", LessonID: testLessonID, ToStatus: "completed", ActorType: models.ActorSystem}}
+	svc.On("GetStatusEvents", mock.Anything, testLessonID, testTutorID).Return(events, nil)
+
+	w := makeRequest(t, r, http.MethodGet, "/lessons/"+testLessonID+"/status-events", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"actor_type":"system"`)
+	svc.AssertExpectations(t)
+}
+
+func TestLessonGetStatusEvents_NotFound(t *testing.T) {
+	svc := new(mockLessonService)
+	r := newLessonRouter(svc, testTutorID)
+
+	svc.On("GetStatusEvents", mock.Anything, testLessonID, testTutorID).Return([]models.LessonStatusEvent(nil), fmt.Errorf("lesson: %w", service.ErrNotFound))
+
+	w := makeRequest(t, r, http.MethodGet, "/lessons/"+testLessonID+"/status-events", nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MakeupHandler struct {
+	service service.MakeupService
+	log     *slog.Logger
+}
+
+func NewMakeupHandler(svc service.MakeupService, log *slog.Logger) *MakeupHandler {
+	return &MakeupHandler{service: svc, log: log}
+}
+
+func (h *MakeupHandler) GetForCourse(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	credits, err := h.service.GetForCourse(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get makeup credits", slog.String("course_id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, credits)
+}
+
+func (h *MakeupHandler) GetForStudent(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	credits, err := h.service.GetForStudent(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get makeup credits", slog.String("student_id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, credits)
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http/httptest"
 	"testing"
 	"time"
 	"tutorgo/models"
+	"tutorgo/money"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
@@ -43,13 +45,14 @@ var (
 	}
 
 	testStudentIDPtr = func() *string { s := testStudentID; return &s }()
+	testCourseIDPtr  = func() *string { s := testCourseID; return &s }()
 
 	testCourse = models.Course{
 		ID:             testCourseID,
 		TutorID:        testTutorID,
 		StudentID:      testStudentIDPtr,
 		Subject:        "Mathematics",
-		PricePerLesson: 5000,
+		PricePerLesson: money.FromUnits(5000),
 		StartedAt:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 		EndedAt:        func() *time.Time { t := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 	}
@@ -64,8 +67,8 @@ var (
 
 	testPayment = models.Payment{
 		ID:           testPaymentID,
-		CourseID:     testCourseID,
-		Amount:       5000,
+		CourseID:     testCourseIDPtr,
+		Amount:       money.FromUnits(5000),
 		LessonsCount: 10,
 		PaidAt:       time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 	}
@@ -221,21 +224,42 @@ func (m *mockPaymentService) GetBalance(ctx context.Context, courseID string, tu
 	return args.Get(0).(models.CourseBalance), args.Error(1)
 }
 
-func (m *mockPaymentService) GetMonthlyIncome(ctx context.Context, tutorID string) (float64, error) {
+func (m *mockPaymentService) GetMonthlyIncome(ctx context.Context, tutorID string) (models.MonthlyIncome, error) {
 	args := m.Called(ctx, tutorID)
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(models.MonthlyIncome), args.Error(1)
+}
+
+func (m *mockPaymentService) GetStudentBalances(ctx context.Context, courseID string, tutorID string) ([]models.StudentBalance, error) {
+	args := m.Called(ctx, courseID, tutorID)
+	return args.Get(0).([]models.StudentBalance), args.Error(1)
+}
+func (m *mockPaymentService) Update(ctx context.Context, id string, tutorID string, req models.UpdatePaymentRequest) (models.Payment, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+func (m *mockPaymentService) Void(ctx context.Context, id string, tutorID string, req models.VoidPaymentRequest) (models.Payment, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+func (m *mockPaymentService) Refund(ctx context.Context, id string, tutorID string, req models.RefundPaymentRequest) (models.Payment, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+func (m *mockPaymentService) GetEntries(ctx context.Context, id string, tutorID string) ([]models.PaymentEntry, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).([]models.PaymentEntry), args.Error(1)
 }
 
 // --- Mock: LessonService ---
 
 type mockLessonService struct{ mock.Mock }
 
-func (m *mockLessonService) Create(ctx context.Context, req models.CreateLessonRequest, tutorID string) (models.Lesson, error) {
-	args := m.Called(ctx, req, tutorID)
+func (m *mockLessonService) Create(ctx context.Context, req models.CreateLessonRequest, tutorID string, opts models.ScheduleOptions) (models.Lesson, error) {
+	args := m.Called(ctx, req, tutorID, opts)
 	return args.Get(0).(models.Lesson), args.Error(1)
 }
-func (m *mockLessonService) CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest, tutorID string) ([]models.Lesson, error) {
-	args := m.Called(ctx, req, tutorID)
+func (m *mockLessonService) CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest, tutorID string, opts models.ScheduleOptions) ([]models.Lesson, error) {
+	args := m.Called(ctx, req, tutorID, opts)
 	return args.Get(0).([]models.Lesson), args.Error(1)
 }
 func (m *mockLessonService) GetByCourse(ctx context.Context, courseID string, tutorID string) ([]models.Lesson, error) {
@@ -246,8 +270,8 @@ func (m *mockLessonService) GetByID(ctx context.Context, id string, tutorID stri
 	args := m.Called(ctx, id, tutorID)
 	return args.Get(0).(models.Lesson), args.Error(1)
 }
-func (m *mockLessonService) Update(ctx context.Context, id string, req models.UpdateLessonRequest, tutorID string) (models.Lesson, error) {
-	args := m.Called(ctx, id, req, tutorID)
+func (m *mockLessonService) Update(ctx context.Context, id string, req models.UpdateLessonRequest, tutorID string, opts models.ScheduleOptions) (models.Lesson, error) {
+	args := m.Called(ctx, id, req, tutorID, opts)
 	return args.Get(0).(models.Lesson), args.Error(1)
 }
 func (m *mockLessonService) Delete(ctx context.Context, id string, tutorID string) error {
@@ -263,14 +287,323 @@ func (m *mockLessonService) DeleteByCourse(ctx context.Context, courseID string,
 	return m.Called(ctx, courseID, tutorID).Error(0)
 }
 
-func (m *mockLessonService) DeleteSeries(ctx context.Context, seriesID string, tutorID string, fromDate *string) error {
-	return m.Called(ctx, seriesID, tutorID, fromDate).Error(0)
+func (m *mockLessonService) ExistsPublic(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
 }
 
-func (m *mockLessonService) UpdateSeries(ctx context.Context, seriesID string, tutorID string, req models.UpdateSeriesRequest) error {
-	return m.Called(ctx, seriesID, tutorID, req).Error(0)
+func (m *mockLessonService) GetStatusEvents(ctx context.Context, id string, tutorID string) ([]models.LessonStatusEvent, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).([]models.LessonStatusEvent), args.Error(1)
 }
 
-func (m *mockLessonService) ExistsPublic(ctx context.Context, id string) error {
-	return m.Called(ctx, id).Error(0)
+// --- Mock: InvoiceService ---
+
+type mockInvoiceService struct{ mock.Mock }
+
+func (m *mockInvoiceService) Create(ctx context.Context, req models.CreateInvoiceRequest, tutorID string) (models.Invoice, error) {
+	args := m.Called(ctx, req, tutorID)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceService) GetByID(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceService) GetAll(ctx context.Context, tutorID string, f models.InvoiceFilter, p models.Pagination) ([]models.Invoice, int, error) {
+	args := m.Called(ctx, tutorID, f, p)
+	return args.Get(0).([]models.Invoice), args.Int(1), args.Error(2)
+}
+
+func (m *mockInvoiceService) Delete(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockInvoiceService) Send(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceService) Pay(ctx context.Context, id string, tutorID string, req models.PayInvoiceRequest) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceService) RenderPDF(ctx context.Context, id string, tutorID string) ([]byte, string, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+// --- Mock: PackageService ---
+
+type mockPackageService struct{ mock.Mock }
+
+func (m *mockPackageService) Create(ctx context.Context, tutorID string, req models.CreatePackageRequest) (models.Package, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.Package), args.Error(1)
+}
+
+func (m *mockPackageService) GetAll(ctx context.Context, tutorID string) ([]models.Package, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.Package), args.Error(1)
+}
+
+func (m *mockPackageService) Update(ctx context.Context, id string, tutorID string, req models.UpdatePackageRequest) (models.Package, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Package), args.Error(1)
+}
+
+func (m *mockPackageService) Delete(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockPackageService) Sell(ctx context.Context, id string, tutorID string, req models.SellPackageRequest) (models.PackageSale, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.PackageSale), args.Error(1)
+}
+
+// --- Mock: ExportService ---
+
+// mockExportService writes its first return value to w before returning its
+// error, like an export failing partway.
+type mockExportService struct{ mock.Mock }
+
+func writeExport(args mock.Arguments, w io.Writer) error {
+	if body := args.String(0); body != "" {
+		if _, err := io.WriteString(w, body); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockExportService) Students(ctx context.Context, tutorID string, search string, opts models.ExportOptions, w io.Writer) error {
+	return writeExport(m.Called(ctx, tutorID, search, opts), w)
+}
+
+func (m *mockExportService) Courses(ctx context.Context, tutorID string, search string, opts models.ExportOptions, w io.Writer) error {
+	return writeExport(m.Called(ctx, tutorID, search, opts), w)
+}
+
+func (m *mockExportService) Lessons(ctx context.Context, tutorID string, filter models.LessonExportFilter, opts models.ExportOptions, w io.Writer) error {
+	return writeExport(m.Called(ctx, tutorID, filter, opts), w)
+}
+
+func (m *mockExportService) Payments(ctx context.Context, tutorID string, opts models.ExportOptions, w io.Writer) error {
+	return writeExport(m.Called(ctx, tutorID, opts), w)
+}
+
+// --- Mock: StudentImportService ---
+
+type mockStudentImportService struct{ mock.Mock }
+
+func (m *mockStudentImportService) Import(ctx context.Context, tutorID string, file io.Reader, dryRun bool) (models.StudentImport, error) {
+	body, _ := io.ReadAll(file)
+	args := m.Called(ctx, tutorID, string(body), dryRun)
+	return args.Get(0).(models.StudentImport), args.Error(1)
+}
+
+// --- Mock: SessionService ---
+
+type mockSessionService struct{ mock.Mock }
+
+func (m *mockSessionService) Create(ctx context.Context, tutorID string, meta models.SessionMeta) (models.Session, string, error) {
+	args := m.Called(ctx, tutorID, meta)
+	return args.Get(0).(models.Session), args.String(1), args.Error(2)
+}
+
+func (m *mockSessionService) Refresh(ctx context.Context, refreshToken string, meta models.SessionMeta) (models.Session, string, error) {
+	args := m.Called(ctx, refreshToken, meta)
+	return args.Get(0).(models.Session), args.String(1), args.Error(2)
+}
+
+func (m *mockSessionService) GetAll(ctx context.Context, tutorID string, currentID string) ([]models.Session, error) {
+	args := m.Called(ctx, tutorID, currentID)
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *mockSessionService) Revoke(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockSessionService) RevokeOthers(ctx context.Context, tutorID string, currentID string) error {
+	return m.Called(ctx, tutorID, currentID).Error(0)
+}
+
+func (m *mockSessionService) IsActive(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// --- Mock: AccountService ---
+
+type mockAccountService struct{ mock.Mock }
+
+func (m *mockAccountService) SendVerification(ctx context.Context, tutorID string) error {
+	return m.Called(ctx, tutorID).Error(0)
+}
+
+func (m *mockAccountService) VerifyEmail(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+func (m *mockAccountService) ForgotPassword(ctx context.Context, email string) error {
+	return m.Called(ctx, email).Error(0)
+}
+
+func (m *mockAccountService) ResetPassword(ctx context.Context, token string, passwordHash string) error {
+	return m.Called(ctx, token, passwordHash).Error(0)
+}
+
+// --- Mock: TwoFactorService ---
+
+type mockTwoFactorService struct{ mock.Mock }
+
+func (m *mockTwoFactorService) GetStatus(ctx context.Context, tutorID string) (models.TwoFactorStatus, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).(models.TwoFactorStatus), args.Error(1)
+}
+
+func (m *mockTwoFactorService) Setup(ctx context.Context, tutorID string) (models.TwoFactorSetup, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).(models.TwoFactorSetup), args.Error(1)
+}
+
+func (m *mockTwoFactorService) Enable(ctx context.Context, tutorID string, code string) (models.RecoveryCodes, error) {
+	args := m.Called(ctx, tutorID, code)
+	return args.Get(0).(models.RecoveryCodes), args.Error(1)
+}
+
+func (m *mockTwoFactorService) Disable(ctx context.Context, tutorID string, code string) error {
+	return m.Called(ctx, tutorID, code).Error(0)
+}
+
+func (m *mockTwoFactorService) IsEnabled(ctx context.Context, tutorID string) (bool, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockTwoFactorService) Verify(ctx context.Context, tutorID string, code string) error {
+	return m.Called(ctx, tutorID, code).Error(0)
+}
+
+// --- Mock: PortalService ---
+
+type mockPortalService struct{ mock.Mock }
+
+func (m *mockPortalService) Invite(ctx context.Context, tutorID string, studentID string, req models.InvitePortalRequest) (models.PortalAccount, error) {
+	args := m.Called(ctx, tutorID, studentID, req)
+	return args.Get(0).(models.PortalAccount), args.Error(1)
+}
+
+func (m *mockPortalService) GetAccounts(ctx context.Context, tutorID string, studentID string) ([]models.PortalAccount, error) {
+	args := m.Called(ctx, tutorID, studentID)
+	return args.Get(0).([]models.PortalAccount), args.Error(1)
+}
+
+func (m *mockPortalService) DeleteAccount(ctx context.Context, tutorID string, studentID string, id string) error {
+	return m.Called(ctx, tutorID, studentID, id).Error(0)
+}
+
+func (m *mockPortalService) AcceptInvite(ctx context.Context, token string, passwordHash string) error {
+	return m.Called(ctx, token, passwordHash).Error(0)
+}
+
+func (m *mockPortalService) GetLogins(ctx context.Context, email string) ([]models.PortalLogin, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).([]models.PortalLogin), args.Error(1)
+}
+
+func (m *mockPortalService) IsActive(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPortalService) GetProfile(ctx context.Context, id string) (models.PortalProfile, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.PortalProfile), args.Error(1)
+}
+
+func (m *mockPortalService) GetLessons(ctx context.Context, studentID string, from string, to string) ([]models.PortalLesson, error) {
+	args := m.Called(ctx, studentID, from, to)
+	return args.Get(0).([]models.PortalLesson), args.Error(1)
+}
+
+func (m *mockPortalService) GetBalances(ctx context.Context, studentID string) ([]models.PortalBalance, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]models.PortalBalance), args.Error(1)
+}
+
+func (m *mockPortalService) GetHomework(ctx context.Context, studentID string) ([]models.PortalLesson, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]models.PortalLesson), args.Error(1)
+}
+
+// --- Mock: OrganizationService ---
+
+type mockOrganizationService struct{ mock.Mock }
+
+func (m *mockOrganizationService) Create(ctx context.Context, tutorID string, req models.CreateOrganizationRequest) (models.Organization, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationService) GetAll(ctx context.Context, tutorID string) ([]models.Organization, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationService) GetByID(ctx context.Context, id string, tutorID string) (models.Organization, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationService) Update(ctx context.Context, id string, tutorID string, req models.UpdateOrganizationRequest) (models.Organization, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationService) Delete(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockOrganizationService) GetMembers(ctx context.Context, id string, tutorID string) ([]models.OrganizationMember, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).([]models.OrganizationMember), args.Error(1)
+}
+
+func (m *mockOrganizationService) InviteMember(ctx context.Context, id string, tutorID string, req models.InviteMemberRequest) (models.OrganizationMember, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.OrganizationMember), args.Error(1)
+}
+
+func (m *mockOrganizationService) UpdateMember(ctx context.Context, id string, tutorID string, memberID string, req models.UpdateMemberRequest) (models.OrganizationMember, error) {
+	args := m.Called(ctx, id, tutorID, memberID, req)
+	return args.Get(0).(models.OrganizationMember), args.Error(1)
+}
+
+func (m *mockOrganizationService) RemoveMember(ctx context.Context, id string, tutorID string, memberID string) error {
+	return m.Called(ctx, id, tutorID, memberID).Error(0)
+}
+
+func (m *mockOrganizationService) Join(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockOrganizationService) GetCalendar(ctx context.Context, id string, tutorID string, from string, to string) ([]models.OrganizationLesson, error) {
+	args := m.Called(ctx, id, tutorID, from, to)
+	return args.Get(0).([]models.OrganizationLesson), args.Error(1)
+}
+
+func (m *mockOrganizationService) GetIncome(ctx context.Context, id string, tutorID string) (models.OrganizationIncome, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.OrganizationIncome), args.Error(1)
+}
+
+func (m *mockOrganizationService) AssignStudent(ctx context.Context, id string, tutorID string, studentID string, req models.AssignTutorRequest) error {
+	return m.Called(ctx, id, tutorID, studentID, req).Error(0)
+}
+
+func (m *mockOrganizationService) AssignCourse(ctx context.Context, id string, tutorID string, courseID string, req models.AssignTutorRequest) error {
+	return m.Called(ctx, id, tutorID, courseID, req).Error(0)
 }
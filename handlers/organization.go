@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	service service.OrganizationService
+	log     *slog.Logger
+}
+
+func NewOrganizationHandler(svc service.OrganizationService, log *slog.Logger) *OrganizationHandler {
+	return &OrganizationHandler{service: svc, log: log}
+}
+
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateOrganizationRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	org, err := h.service.Create(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to create organization", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Organization created", slog.String("id", org.ID))
+	c.JSON(http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) GetAll(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgs, err := h.service.GetAll(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get organizations", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) GetByID(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	org, err := h.service.GetByID(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get organization", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.UpdateOrganizationRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	org, err := h.service.Update(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to update organization", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *OrganizationHandler) Delete(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to delete organization", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Organization deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	members, err := h.service.GetMembers(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get organization members", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.InviteMemberRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	member, err := h.service.InviteMember(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to invite organization member", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Organization member invited", slog.String("id", id), slog.String("tutor_id", member.TutorID), slog.String("role", member.Role))
+	c.JSON(http.StatusCreated, member)
+}
+
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.UpdateMemberRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id, memberID := c.Param("id"), c.Param("tutorId")
+	member, err := h.service.UpdateMember(c.Request.Context(), id, tutorID, memberID, req)
+	if err != nil {
+		h.log.Error("Failed to update organization member", slog.String("id", id), slog.String("tutor_id", memberID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Organization member updated", slog.String("id", id), slog.String("tutor_id", memberID), slog.String("role", member.Role))
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes another member, or the caller themselves: leaving the
+// organization, or declining its invitation.
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, memberID := c.Param("id"), c.Param("tutorId")
+	if err := h.service.RemoveMember(c.Request.Context(), id, tutorID, memberID); err != nil {
+		h.log.Error("Failed to remove organization member", slog.String("id", id), slog.String("tutor_id", memberID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Organization member removed", slog.String("id", id), slog.String("tutor_id", memberID))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) Join(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Join(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to join organization", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Organization joined", slog.String("id", id), slog.String("tutor_id", tutorID))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) GetCalendar(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	id := c.Param("id")
+	lessons, err := h.service.GetCalendar(c.Request.Context(), id, tutorID, from, to)
+	if err != nil {
+		h.log.Error("Failed to get organization calendar", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lessons)
+}
+
+func (h *OrganizationHandler) GetIncome(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	income, err := h.service.GetIncome(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get organization income", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, income)
+}
+
+func (h *OrganizationHandler) AssignStudent(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.AssignTutorRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id, studentID := c.Param("id"), c.Param("studentId")
+	if err := h.service.AssignStudent(c.Request.Context(), id, tutorID, studentID, req); err != nil {
+		h.log.Error("Failed to assign student", slog.String("id", id), slog.String("student_id", studentID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Student assigned", slog.String("id", id), slog.String("student_id", studentID), slog.String("tutor_id", req.TutorID))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) AssignCourse(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.AssignTutorRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id, courseID := c.Param("id"), c.Param("courseId")
+	if err := h.service.AssignCourse(c.Request.Context(), id, tutorID, courseID, req); err != nil {
+		h.log.Error("Failed to assign course", slog.String("id", id), slog.String("course_id", courseID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Course assigned", slog.String("id", id), slog.String("course_id", courseID), slog.String("tutor_id", req.TutorID))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testOrgID       = "77777777-7777-7777-7777-777777777777"
+	testColleagueID = "88888888-8888-8888-8888-888888888888"
+)
+
+func newOrganizationRouter(svc *mockOrganizationService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewOrganizationHandler(svc, slog.Default())
+	r.Use(withTutorID(tutorID))
+	r.POST("/organizations", h.Create)
+	r.POST("/organizations/:id/join", h.Join)
+	r.POST("/organizations/:id/members", h.InviteMember)
+	r.DELETE("/organizations/:id/members/:tutorId", h.RemoveMember)
+	r.GET("/organizations/:id/calendar", h.GetCalendar)
+	r.GET("/organizations/:id/income", h.GetIncome)
+	r.POST("/organizations/:id/courses/:courseId/assign", h.AssignCourse)
+	return r
+}
+
+func TestOrganizationCreate_Created(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+	req := models.CreateOrganizationRequest{Name: "Bright School"}
+
+	svc.On("Create", mock.Anything, testTutorID, req).
+		Return(models.Organization{ID: testOrgID, Name: req.Name, Role: models.OrgRoleOwner}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations", req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var got models.Organization
+	decodeJSON(t, w, &got)
+	assert.Equal(t, models.OrgRoleOwner, got.Role)
+}
+
+func TestOrganizationCreate_Unauthorized(t *testing.T) {
+	r := newOrganizationRouter(new(mockOrganizationService), "")
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations", models.CreateOrganizationRequest{Name: "Bright School"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestOrganizationInviteMember_InvalidRole(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations/"+testOrgID+"/members",
+		models.InviteMemberRequest{Email: "b@example.com", Role: "principal"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "InviteMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationInviteMember_Forbidden(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+	req := models.InviteMemberRequest{Email: "b@example.com", Role: models.OrgRoleAdmin}
+
+	svc.On("InviteMember", mock.Anything, testOrgID, testTutorID, req).
+		Return(models.OrganizationMember{}, fmt.Errorf("only owners can invite owners and admins: %w", service.ErrForbidden))
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations/"+testOrgID+"/members", req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestOrganizationJoin_NoContent(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	svc.On("Join", mock.Anything, testOrgID, testTutorID).Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations/"+testOrgID+"/join", nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestOrganizationRemoveMember_LastOwner(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	svc.On("RemoveMember", mock.Anything, testOrgID, testTutorID, testTutorID).
+		Return(fmt.Errorf("the organization needs another owner first: %w", service.ErrConflict))
+
+	w := makeRequest(t, r, http.MethodDelete, "/organizations/"+testOrgID+"/members/"+testTutorID, nil)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestOrganizationGetCalendar_MissingRange(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodGet, "/organizations/"+testOrgID+"/calendar?from=2026-05-01", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "GetCalendar", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationGetCalendar_OK(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	svc.On("GetCalendar", mock.Anything, testOrgID, testTutorID, "2026-05-01", "2026-05-08").
+		Return([]models.OrganizationLesson{{CalendarLesson: models.CalendarLesson{ID: testLessonID}, TutorID: testTutorID}}, nil)
+
+	w := makeRequest(t, r, http.MethodGet, "/organizations/"+testOrgID+"/calendar?from=2026-05-01&to=2026-05-08", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []map[string]any
+	decodeJSON(t, w, &got)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, testLessonID, got[0]["id"])
+		assert.Equal(t, testTutorID, got[0]["tutor_id"])
+	}
+}
+
+func TestOrganizationGetIncome_Forbidden(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	svc.On("GetIncome", mock.Anything, testOrgID, testTutorID).
+		Return(models.OrganizationIncome{}, fmt.Errorf("your role in the organization does not allow this: %w", service.ErrForbidden))
+
+	w := makeRequest(t, r, http.MethodGet, "/organizations/"+testOrgID+"/income", nil)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestOrganizationAssignCourse_InvalidTutorID(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations/"+testOrgID+"/courses/"+testCourseID+"/assign",
+		models.AssignTutorRequest{TutorID: "not-a-uuid"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "AssignCourse", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationAssignCourse_NoContent(t *testing.T) {
+	svc := new(mockOrganizationService)
+	r := newOrganizationRouter(svc, testTutorID)
+	req := models.AssignTutorRequest{TutorID: testColleagueID}
+
+	svc.On("AssignCourse", mock.Anything, testOrgID, testTutorID, testCourseID, req).Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/organizations/"+testOrgID+"/courses/"+testCourseID+"/assign", req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PackageHandler struct {
+	service service.PackageService
+	log     *slog.Logger
+}
+
+func NewPackageHandler(svc service.PackageService, log *slog.Logger) *PackageHandler {
+	return &PackageHandler{service: svc, log: log}
+}
+
+func (h *PackageHandler) GetAll(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	packages, err := h.service.GetAll(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get packages", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, packages)
+}
+
+func (h *PackageHandler) Create(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreatePackageRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	pkg, err := h.service.Create(c.Request.Context(), tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to create package", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Package created", slog.String("id", pkg.ID), slog.String("kind", pkg.Kind))
+	c.JSON(http.StatusCreated, pkg)
+}
+
+func (h *PackageHandler) Update(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.UpdatePackageRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	pkg, err := h.service.Update(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to update package", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Package updated", slog.String("id", id))
+	c.JSON(http.StatusOK, pkg)
+}
+
+func (h *PackageHandler) Delete(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to delete package", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Package deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// Sell records the payment for the package on a course and the lessons it gives.
+func (h *PackageHandler) Sell(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.SellPackageRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	sale, err := h.service.Sell(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to sell package", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Package sold", slog.String("id", id), slog.String("payment_id", sale.Payment.ID))
+	c.JSON(http.StatusCreated, sale)
+}
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testPackageID = "77777777-7777-7777-7777-777777777777"
+
+func newPackageRouter(svc *mockPackageService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewPackageHandler(svc, slog.Default())
+	r.Use(withTutorID(tutorID))
+	r.POST("/packages", h.Create)
+	r.DELETE("/packages/:id", h.Delete)
+	r.POST("/packages/:id/sell", h.Sell)
+	return r
+}
+
+func TestPackageCreate_Success(t *testing.T) {
+	svc := new(mockPackageService)
+	r := newPackageRouter(svc, testTutorID)
+
+	days := 60
+	req := models.CreatePackageRequest{Name: "8 lessons", Kind: models.KindPackage, LessonsCount: 8, Price: money.FromUnits(36000), ValidDays: &days}
+	svc.On("Create", mock.Anything, testTutorID, req).Return(models.Package{ID: testPackageID, Kind: models.KindPackage}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/packages", req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestPackageCreate_UnknownKind(t *testing.T) {
+	svc := new(mockPackageService)
+	r := newPackageRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodPost, "/packages", map[string]any{
+		"name": "Yearly", "kind": "annual", "lessons_count": 40, "price": "100000",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Create")
+}
+
+func TestPackageDelete_NotFound(t *testing.T) {
+	svc := new(mockPackageService)
+	r := newPackageRouter(svc, testTutorID)
+
+	svc.On("Delete", mock.Anything, testPackageID, testTutorID).Return(service.ErrNotFound)
+
+	w := makeRequest(t, r, http.MethodDelete, "/packages/"+testPackageID, nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPackageSell_Success(t *testing.T) {
+	svc := new(mockPackageService)
+	r := newPackageRouter(svc, testTutorID)
+
+	req := models.SellPackageRequest{CourseID: testCourseID, PaidAt: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	sale := models.PackageSale{Payment: models.Payment{ID: testPaymentID}}
+	svc.On("Sell", mock.Anything, testPackageID, testTutorID, req).Return(sale, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/packages/"+testPackageID+"/sell", req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var got models.PackageSale
+	decodeJSON(t, w, &got)
+	assert.Equal(t, testPaymentID, got.Payment.ID)
+	svc.AssertExpectations(t)
+}
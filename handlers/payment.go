@@ -3,6 +3,7 @@ package handlers
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"tutorgo/models"
 	"tutorgo/service"
@@ -69,17 +70,22 @@ func (h *PaymentHandler) Create(c *gin.Context) {
 		handleServiceError(c, err)
 		return
 	}
-	h.log.Info("Payment created", slog.String("id", payment.ID), slog.Float64("amount", payment.Amount))
+	h.log.Info("Payment created", slog.String("id", payment.ID), slog.String("amount", payment.Amount.String()))
 	c.JSON(http.StatusCreated, payment)
 }
 
+// GetRecent lists the latest ?limit= payments, 5 by default and 50 at most.
 func (h *PaymentHandler) GetRecent(c *gin.Context) {
 	tutorID := c.GetString("tutorID")
 	if tutorID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	payments, err := h.service.GetAllByTutor(c.Request.Context(), tutorID, 5)
+	limit := 5
+	if n, err := strconv.Atoi(c.Query("limit")); err == nil && n > 0 {
+		limit = min(n, 50)
+	}
+	payments, err := h.service.GetAllByTutor(c.Request.Context(), tutorID, limit)
 	if err != nil {
 		h.log.Error("Failed to get recent payments", slog.String("error", err.Error()))
 		handleServiceError(c, err)
@@ -94,13 +100,13 @@ func (h *PaymentHandler) GetMonthlyIncome(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	total, err := h.service.GetMonthlyIncome(c.Request.Context(), tutorID)
+	income, err := h.service.GetMonthlyIncome(c.Request.Context(), tutorID)
 	if err != nil {
 		h.log.Error("Failed to get monthly income", slog.String("error", err.Error()))
 		handleServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"total": total})
+	c.JSON(http.StatusOK, income)
 }
 
 func (h *PaymentHandler) GetBalance(c *gin.Context) {
@@ -122,3 +128,101 @@ func (h *PaymentHandler) GetBalance(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, balance)
 }
+
+// GetStudentBalances lists the members of a group course with their balances.
+func (h *PaymentHandler) GetStudentBalances(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	courseID := c.Param("id")
+	balances, err := h.service.GetStudentBalances(c.Request.Context(), courseID, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get student balances", slog.String("course_id", courseID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balances)
+}
+
+// Update corrects the payment with compensating ledger entries.
+func (h *PaymentHandler) Update(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.UpdatePaymentRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	payment, err := h.service.Update(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to update payment", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Payment corrected", slog.String("id", id), slog.String("amount", payment.Amount.String()))
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) Void(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.VoidPaymentRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	payment, err := h.service.Void(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to void payment", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Payment voided", slog.String("id", id))
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.RefundPaymentRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	id := c.Param("id")
+	payment, err := h.service.Refund(c.Request.Context(), id, tutorID, req)
+	if err != nil {
+		h.log.Error("Failed to refund payment", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Payment refunded", slog.String("id", id), slog.String("amount", req.Amount.String()))
+	c.JSON(http.StatusOK, payment)
+}
+
+// GetEntries returns the payment's ledger, oldest entry first.
+func (h *PaymentHandler) GetEntries(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	entries, err := h.service.GetEntries(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get payment entries", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
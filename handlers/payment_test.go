@@ -9,6 +9,7 @@ import (
 	"time"
 	"tutorgo/handlers"
 	"tutorgo/models"
+	"tutorgo/money"
 	"tutorgo/service"
 
 	"github.com/gin-gonic/gin"
@@ -23,12 +24,16 @@ func newPaymentRouter(svc *mockPaymentService, tutorID string) *gin.Engine {
 	r.GET("/payments", h.GetAll)
 	r.POST("/payments", h.Create)
 	r.GET("/payments/balance", h.GetBalance)
+	r.PUT("/payments/:id", h.Update)
+	r.POST("/payments/:id/void", h.Void)
+	r.POST("/payments/:id/refunds", h.Refund)
+	r.GET("/payments/:id/entries", h.GetEntries)
 	return r
 }
 
 var testCreatePaymentReq = models.CreatePaymentRequest{
 	CourseID:     testCourseID,
-	Amount:       5000,
+	Amount:       money.FromUnits(5000),
 	LessonsCount: 10,
 	PaidAt:       time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 }
@@ -171,3 +176,115 @@ func TestPaymentGetBalance_ServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	svc.AssertExpectations(t)
 }
+
+// Update, Void, Refund
+
+func TestPaymentUpdate_Success(t *testing.T) {
+	svc := new(mockPaymentService)
+	r := newPaymentRouter(svc, testTutorID)
+
+	req := models.UpdatePaymentRequest{
+		Amount:       money.FromUnits(4500),
+		LessonsCount: 9,
+		PaidAt:       testPayment.PaidAt,
+		Reason:       "typo",
+	}
+	corrected := testPayment
+	corrected.Amount, corrected.LessonsCount = req.Amount, req.LessonsCount
+	svc.On("Update", mock.Anything, testPaymentID, testTutorID, req).Return(corrected, nil)
+
+	w := makeRequest(t, r, http.MethodPut, "/payments/"+testPaymentID, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.Payment
+	decodeJSON(t, w, &got)
+	assert.Equal(t, money.FromUnits(4500), got.Amount)
+	svc.AssertExpectations(t)
+}
+
+func TestPaymentVoid_AlreadyVoided(t *testing.T) {
+	svc := new(mockPaymentService)
+	r := newPaymentRouter(svc, testTutorID)
+
+	svc.On("Void", mock.Anything, testPaymentID, testTutorID, models.VoidPaymentRequest{}).
+		Return(models.Payment{}, fmt.Errorf("payment is already voided: %w", service.ErrConflict))
+
+	w := makeRequest(t, r, http.MethodPost, "/payments/"+testPaymentID+"/void", map[string]any{})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestPaymentRefund_Success(t *testing.T) {
+	svc := new(mockPaymentService)
+	r := newPaymentRouter(svc, testTutorID)
+
+	req := models.RefundPaymentRequest{Amount: money.FromUnits(1000), LessonsCount: 2}
+	refunded := testPayment
+	refunded.Refunded, refunded.LessonsRefunded = req.Amount, req.LessonsCount
+	svc.On("Refund", mock.Anything, testPaymentID, testTutorID, req).Return(refunded, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/payments/"+testPaymentID+"/refunds", req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.Payment
+	decodeJSON(t, w, &got)
+	assert.Equal(t, money.FromUnits(1000), got.Refunded)
+	assert.Equal(t, 2, got.LessonsRefunded)
+	svc.AssertExpectations(t)
+}
+
+func TestPaymentRefund_ValidationError(t *testing.T) {
+	svc := new(mockPaymentService)
+	r := newPaymentRouter(svc, testTutorID)
+
+	w := makeRequest(t, r, http.MethodPost, "/payments/"+testPaymentID+"/refunds", map[string]any{"amount": 0})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Refund")
+}
+
+func TestPaymentGetEntries_Success(t *testing.T) {
+	svc := new(mockPaymentService)
+	r := newPaymentRouter(svc, testTutorID)
+
+	entries := []models.PaymentEntry{
+		{ID: "entry-1", PaymentID: testPaymentID, Kind: models.EntryPayment, Amount: money.FromUnits(5000), LessonsCount: 10},
+		{ID: "entry-2", PaymentID: testPaymentID, Kind: models.EntryRefund, Amount: -money.FromUnits(1000), LessonsCount: -2},
+	}
+	svc.On("GetEntries", mock.Anything, testPaymentID, testTutorID).Return(entries, nil)
+
+	w := makeRequest(t, r, http.MethodGet, "/payments/"+testPaymentID+"/entries", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []models.PaymentEntry
+	decodeJSON(t, w, &got)
+	assert.Equal(t, entries, got)
+	svc.AssertExpectations(t)
+}
+
+func TestPaymentGetRecent_Limit(t *testing.T) {
+	tests := []struct {
+		query string
+		limit int
+	}{
+		{"", 5},
+		{"?limit=20", 20},
+		{"?limit=500", 50},
+		{"?limit=abc", 5},
+	}
+	for _, tt := range tests {
+		svc := new(mockPaymentService)
+		r := gin.New()
+		h := handlers.NewPaymentHandler(svc, slog.Default())
+		r.Use(withTutorID(testTutorID))
+		r.GET("/payments/recent", h.GetRecent)
+
+		svc.On("GetAllByTutor", mock.Anything, testTutorID, tt.limit).Return([]models.Payment{}, nil)
+
+		w := makeRequest(t, r, http.MethodGet, "/payments/recent"+tt.query, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.query)
+		svc.AssertExpectations(t)
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PortalHandler serves both sides of the portal: tutors inviting students and
+// parents, and those signing in to see the student's lessons, balance and
+// homework.
+type PortalHandler struct {
+	service   service.PortalService
+	log       *slog.Logger
+	jwtSecret string
+}
+
+func NewPortalHandler(svc service.PortalService, log *slog.Logger, jwtSecret string) *PortalHandler {
+	return &PortalHandler{service: svc, log: log, jwtSecret: jwtSecret}
+}
+
+// portalTTL is how long a portal token works. There is no refresh: the portal
+// is read-only, and signing in again once a day is no burden.
+const portalTTL = 12 * time.Hour
+
+// GetAccounts lists the portal accounts and pending invitations of a student.
+func (h *PortalHandler) GetAccounts(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	studentID := c.Param("id")
+	accounts, err := h.service.GetAccounts(c.Request.Context(), tutorID, studentID)
+	if err != nil {
+		h.log.Error("Failed to get portal accounts", slog.String("student_id", studentID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *PortalHandler) Invite(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.InvitePortalRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	studentID := c.Param("id")
+	account, err := h.service.Invite(c.Request.Context(), tutorID, studentID, req)
+	if err != nil {
+		h.log.Error("Failed to invite to portal", slog.String("student_id", studentID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	if account.MailFailed {
+		h.log.Warn("Portal invitation not mailed", slog.String("student_id", studentID), slog.String("account_id", account.ID))
+	} else {
+		h.log.Info("Portal invitation sent", slog.String("student_id", studentID), slog.String("account_id", account.ID))
+	}
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *PortalHandler) DeleteAccount(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	studentID, id := c.Param("id"), c.Param("accountId")
+	if err := h.service.DeleteAccount(c.Request.Context(), tutorID, studentID, id); err != nil {
+		h.log.Error("Failed to delete portal account", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Portal account deleted", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvite sets the password of an invited account.
+func (h *PortalHandler) AcceptInvite(c *gin.Context) {
+	var req models.AcceptInviteRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		h.log.Error("Failed to hash password", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
+	if err := h.service.AcceptInvite(c.Request.Context(), req.Token, string(hash)); err != nil {
+		h.log.Warn("Failed to accept portal invitation", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Login signs in for one student. When the email and password open accounts for
+// several, it answers 409 with the students to choose from, and the client signs
+// in again with student_id.
+func (h *PortalHandler) Login(c *gin.Context) {
+	var req models.PortalLoginRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	logins, err := h.service.GetLogins(c.Request.Context(), req.Email)
+	if err != nil {
+		h.log.Error("Failed to get portal accounts", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	// Each of the email's accounts, one per student, has its own password; only
+	// the students the password opens are offered to choose from.
+	var students []models.PortalStudent
+	var account models.PortalAccount
+	for _, l := range logins {
+		if req.StudentID != "" && l.Account.StudentID != req.StudentID {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(l.PasswordHash), []byte(req.Password)) != nil {
+			continue
+		}
+		account = l.Account
+		students = append(students, models.PortalStudent{ID: l.Account.StudentID, FirstName: l.StudentFirstName, LastName: l.StudentLastName})
+	}
+	switch {
+	case len(students) == 0:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	case len(students) > 1:
+		c.JSON(http.StatusConflict, gin.H{"error": "choose a student to sign in for", "students": students})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(portalTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":         account.ID,
+		"role":       account.Role,
+		"student_id": account.StudentID,
+		"iat":        now.Unix(),
+		"exp":        expiresAt.Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(h.jwtSecret))
+	if err != nil {
+		h.log.Error("Failed to sign token", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	h.log.Info("Portal account logged in", slog.String("id", account.ID), slog.String("role", account.Role))
+	c.JSON(http.StatusOK, models.PortalLoginResponse{Token: tokenString, ExpiresAt: expiresAt, Role: account.Role})
+}
+
+func (h *PortalHandler) GetProfile(c *gin.Context) {
+	accountID := c.GetString("portalAccountID")
+	if accountID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	profile, err := h.service.GetProfile(c.Request.Context(), accountID)
+	if err != nil {
+		h.log.Error("Failed to get portal profile", slog.String("id", accountID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *PortalHandler) GetLessons(c *gin.Context) {
+	studentID := c.GetString("studentID")
+	if studentID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query parameters are required"})
+		return
+	}
+	lessons, err := h.service.GetLessons(c.Request.Context(), studentID, from, to)
+	if err != nil {
+		h.log.Error("Failed to get portal lessons", slog.String("student_id", studentID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lessons)
+}
+
+func (h *PortalHandler) GetBalances(c *gin.Context) {
+	studentID := c.GetString("studentID")
+	if studentID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	balances, err := h.service.GetBalances(c.Request.Context(), studentID)
+	if err != nil {
+		h.log.Error("Failed to get portal balances", slog.String("student_id", studentID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balances)
+}
+
+func (h *PortalHandler) GetHomework(c *gin.Context) {
+	studentID := c.GetString("studentID")
+	if studentID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	lessons, err := h.service.GetHomework(c.Request.Context(), studentID)
+	if err != nil {
+		h.log.Error("Failed to get portal homework", slog.String("student_id", studentID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lessons)
+}
@@ -0,0 +1,272 @@
+package handlers_test
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testPortalAccountID = "66666666-6666-6666-6666-666666666666"
+	testOtherStudentID  = "88888888-8888-8888-8888-888888888888"
+)
+
+func newPortalRouter(svc *mockPortalService, tutorID string, studentID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewPortalHandler(svc, slog.Default(), "test-secret")
+	r.POST("/portal/login", h.Login)
+	r.POST("/portal/invites/accept", h.AcceptInvite)
+
+	tutor := r.Group("/", withTutorID(tutorID))
+	tutor.GET("/students/:id/portal-accounts", h.GetAccounts)
+	tutor.POST("/students/:id/portal-accounts", h.Invite)
+	tutor.DELETE("/students/:id/portal-accounts/:accountId", h.DeleteAccount)
+
+	portal := r.Group("/portal", func(c *gin.Context) {
+		if studentID != "" {
+			c.Set("portalAccountID", testPortalAccountID)
+			c.Set("studentID", studentID)
+		}
+		c.Next()
+	})
+	portal.GET("/me", h.GetProfile)
+	portal.GET("/lessons", h.GetLessons)
+	portal.GET("/balance", h.GetBalances)
+	portal.GET("/homework", h.GetHomework)
+	return r
+}
+
+func TestPortalInvite_Created(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, testTutorID, "")
+	req := models.InvitePortalRequest{Email: "parent@example.com", Role: models.RoleParent}
+
+	svc.On("Invite", mock.Anything, testTutorID, testStudentID, req).
+		Return(models.PortalAccount{ID: testPortalAccountID, StudentID: testStudentID, Role: models.RoleParent, Email: req.Email}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/students/"+testStudentID+"/portal-accounts", req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var got models.PortalAccount
+	decodeJSON(t, w, &got)
+	assert.Equal(t, testPortalAccountID, got.ID)
+}
+
+func TestPortalInvite_MailFailedStillCreated(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, testTutorID, "")
+	req := models.InvitePortalRequest{Email: "parent@example.com", Role: models.RoleParent}
+
+	svc.On("Invite", mock.Anything, testTutorID, testStudentID, req).
+		Return(models.PortalAccount{ID: testPortalAccountID, StudentID: testStudentID, Role: models.RoleParent, Email: req.Email, MailFailed: true}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/students/"+testStudentID+"/portal-accounts", req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var got models.PortalAccount
+	decodeJSON(t, w, &got)
+	assert.True(t, got.MailFailed)
+}
+
+func TestPortalInvite_InvalidRole(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, testTutorID, "")
+
+	w := makeRequest(t, r, http.MethodPost, "/students/"+testStudentID+"/portal-accounts",
+		models.InvitePortalRequest{Email: "tutor@example.com", Role: models.RoleTutor})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Invite", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortalInvite_EmailTaken(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, testTutorID, "")
+	req := models.InvitePortalRequest{Email: "taken@example.com", Role: models.RoleStudent}
+
+	svc.On("Invite", mock.Anything, testTutorID, testStudentID, req).
+		Return(models.PortalAccount{}, fmt.Errorf("this email already has a portal account: %w", service.ErrConflict))
+
+	w := makeRequest(t, r, http.MethodPost, "/students/"+testStudentID+"/portal-accounts", req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPortalDeleteAccount_NoContent(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, testTutorID, "")
+
+	svc.On("DeleteAccount", mock.Anything, testTutorID, testStudentID, testPortalAccountID).Return(nil)
+
+	w := makeRequest(t, r, http.MethodDelete, "/students/"+testStudentID+"/portal-accounts/"+testPortalAccountID, nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestPortalGetAccounts_Unauthorized(t *testing.T) {
+	r := newPortalRouter(new(mockPortalService), "", "")
+
+	w := makeRequest(t, r, http.MethodGet, "/students/"+testStudentID+"/portal-accounts", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPortalAcceptInvite_HashesPassword(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", "")
+
+	svc.On("AcceptInvite", mock.Anything, "token", mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("secret-password")) == nil
+	})).Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/portal/invites/accept",
+		models.AcceptInviteRequest{Token: "token", Password: "secret-password"})
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestPortalLogin_SignsRoleToken(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", "")
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	svc.On("GetLogins", mock.Anything, "parent@example.com").
+		Return([]models.PortalLogin{{Account: models.PortalAccount{ID: testPortalAccountID, StudentID: testStudentID, Role: models.RoleParent}, PasswordHash: string(hash)}}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/portal/login",
+		models.PortalLoginRequest{Email: "parent@example.com", Password: "secret-password"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var got models.PortalLoginResponse
+	decodeJSON(t, w, &got)
+	assert.Equal(t, models.RoleParent, got.Role)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(got.Token, claims, func(*jwt.Token) (any, error) { return []byte("test-secret"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, testPortalAccountID, claims["id"])
+	assert.Equal(t, testStudentID, claims["student_id"])
+	assert.Equal(t, models.RoleParent, claims["role"])
+	assert.NotContains(t, claims, "sid")
+}
+
+func TestPortalLogin_WrongPassword(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", "")
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	svc.On("GetLogins", mock.Anything, "parent@example.com").
+		Return([]models.PortalLogin{{Account: models.PortalAccount{ID: testPortalAccountID}, PasswordHash: string(hash)}}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/portal/login",
+		models.PortalLoginRequest{Email: "parent@example.com", Password: "wrong-password"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPortalLogin_UnknownEmail(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", "")
+
+	svc.On("GetLogins", mock.Anything, "nobody@example.com").Return([]models.PortalLogin{}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/portal/login",
+		models.PortalLoginRequest{Email: "nobody@example.com", Password: "secret-password"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// twoChildren is a parent's logins for two students, with the same password.
+func twoChildren(t *testing.T) []models.PortalLogin {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	return []models.PortalLogin{
+		{Account: models.PortalAccount{ID: testPortalAccountID, StudentID: testStudentID, Role: models.RoleParent}, StudentFirstName: "Anna", PasswordHash: string(hash)},
+		{Account: models.PortalAccount{ID: "77777777-7777-7777-7777-777777777777", StudentID: testOtherStudentID, Role: models.RoleParent}, StudentFirstName: "Ben", PasswordHash: string(hash)},
+	}
+}
+
+func TestPortalLogin_SeveralStudentsAsksToChoose(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", "")
+
+	svc.On("GetLogins", mock.Anything, "parent@example.com").Return(twoChildren(t), nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/portal/login",
+		models.PortalLoginRequest{Email: "parent@example.com", Password: "secret-password"})
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var got struct {
+		Token    string                 `json:"token"`
+		Students []models.PortalStudent `json:"students"`
+	}
+	decodeJSON(t, w, &got)
+	assert.Empty(t, got.Token)
+	assert.Equal(t, []models.PortalStudent{{ID: testStudentID, FirstName: "Anna"}, {ID: testOtherStudentID, FirstName: "Ben"}}, got.Students)
+}
+
+func TestPortalLogin_ChosenStudent(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", "")
+
+	svc.On("GetLogins", mock.Anything, "parent@example.com").Return(twoChildren(t), nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/portal/login",
+		models.PortalLoginRequest{Email: "parent@example.com", Password: "secret-password", StudentID: testOtherStudentID})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var got models.PortalLoginResponse
+	decodeJSON(t, w, &got)
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(got.Token, claims, func(*jwt.Token) (any, error) { return []byte("test-secret"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, testOtherStudentID, claims["student_id"])
+}
+
+func TestPortalGetLessons_ScopedToStudent(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", testStudentID)
+
+	svc.On("GetLessons", mock.Anything, testStudentID, "2026-05-01", "2026-06-01").
+		Return([]models.PortalLesson{{ID: testLessonID, Status: "scheduled"}}, nil)
+
+	w := makeRequest(t, r, http.MethodGet, "/portal/lessons?from=2026-05-01&to=2026-06-01", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []models.PortalLesson
+	decodeJSON(t, w, &got)
+	assert.Len(t, got, 1)
+}
+
+func TestPortalGetLessons_MissingRange(t *testing.T) {
+	svc := new(mockPortalService)
+	r := newPortalRouter(svc, "", testStudentID)
+
+	w := makeRequest(t, r, http.MethodGet, "/portal/lessons?from=2026-05-01", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "GetLessons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortalGetBalances_Unauthorized(t *testing.T) {
+	r := newPortalRouter(new(mockPortalService), "", "")
+
+	w := makeRequest(t, r, http.MethodGet, "/portal/balance", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RescheduleHandler struct {
+	service service.RescheduleService
+	log     *slog.Logger
+}
+
+func NewRescheduleHandler(svc service.RescheduleService, log *slog.Logger) *RescheduleHandler {
+	return &RescheduleHandler{service: svc, log: log}
+}
+
+func (h *RescheduleHandler) Reschedule(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	var req models.RescheduleLessonRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	opts := scheduleOptions(c)
+	rs, err := h.service.Reschedule(c.Request.Context(), id, tutorID, req, opts)
+	if opts.DryRun && respondDryRun(c, err, rs.Warnings) {
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to reschedule lesson", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Lesson rescheduled", slog.String("id", id), slog.String("status", rs.Status))
+	c.JSON(http.StatusCreated, rs)
+}
+
+func (h *RescheduleHandler) GetByLesson(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	reschedules, err := h.service.GetByLesson(c.Request.Context(), id, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get reschedules", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reschedules)
+}
+
+func (h *RescheduleHandler) Withdraw(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Withdraw(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to withdraw reschedule", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Reschedule withdrawn", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// GET /public/reschedules/:token — публичный, предложение перенести урок
+func (h *RescheduleHandler) GetPublic(c *gin.Context) {
+	rs, err := h.service.GetPublic(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rs)
+}
+
+// POST /public/reschedules/:token/accept — публичный, согласие на перенос
+func (h *RescheduleHandler) Accept(c *gin.Context) {
+	rs, err := h.service.Accept(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.log.Error("Failed to accept reschedule", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Reschedule accepted", slog.String("id", rs.ID))
+	c.JSON(http.StatusOK, gin.H{
+		"status":       rs.Status,
+		"scheduled_at": rs.ProposedAt,
+	})
+}
+
+// POST /public/reschedules/:token/decline — публичный, отказ от переноса
+func (h *RescheduleHandler) Decline(c *gin.Context) {
+	if err := h.service.Decline(c.Request.Context(), c.Param("token")); err != nil {
+		h.log.Error("Failed to decline reschedule", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Reschedule declined")
+	c.Status(http.StatusNoContent)
+}
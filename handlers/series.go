@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeriesHandler struct {
+	service service.SeriesService
+	log     *slog.Logger
+}
+
+func NewSeriesHandler(svc service.SeriesService, log *slog.Logger) *SeriesHandler {
+	return &SeriesHandler{service: svc, log: log}
+}
+
+func (h *SeriesHandler) Create(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateSeriesRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	opts := scheduleOptions(c)
+	result, err := h.service.Create(c.Request.Context(), req, tutorID, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to create series", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Series created", slog.String("seriesId", result.Series.ID), slog.Int("lessons", len(result.Lessons)))
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *SeriesHandler) GetByID(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	seriesID := c.Param("seriesId")
+	result, err := h.service.GetByID(c.Request.Context(), seriesID, tutorID)
+	if err != nil {
+		h.log.Error("Failed to get series", slog.String("seriesId", seriesID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *SeriesHandler) Extend(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	seriesID := c.Param("seriesId")
+	var req models.ExtendSeriesRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	opts := scheduleOptions(c)
+	lessons, err := h.service.Extend(c.Request.Context(), seriesID, tutorID, req, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to extend series", slog.String("seriesId", seriesID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Series extended", slog.String("seriesId", seriesID), slog.Int("count", len(lessons)))
+	c.JSON(http.StatusOK, lessons)
+}
+
+func (h *SeriesHandler) Update(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	seriesID := c.Param("seriesId")
+	var req models.UpdateSeriesRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	opts := scheduleOptions(c)
+	err := h.service.Update(c.Request.Context(), seriesID, tutorID, req, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to update series", slog.String("seriesId", seriesID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Series updated", slog.String("seriesId", seriesID))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SeriesHandler) Delete(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	seriesID := c.Param("seriesId")
+	fromDate := c.Query("from")
+	var fromDatePtr *string
+	if fromDate != "" {
+		fromDatePtr = &fromDate
+	}
+	if err := h.service.Delete(c.Request.Context(), seriesID, tutorID, fromDatePtr); err != nil {
+		h.log.Error("Failed to delete series", slog.String("seriesId", seriesID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Series deleted", slog.String("seriesId", seriesID))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	service service.SessionService
+	log     *slog.Logger
+}
+
+func NewSessionHandler(svc service.SessionService, log *slog.Logger) *SessionHandler {
+	return &SessionHandler{service: svc, log: log}
+}
+
+func (h *SessionHandler) GetAll(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	sessions, err := h.service.GetAll(c.Request.Context(), tutorID, c.GetString("sessionID"))
+	if err != nil {
+		h.log.Error("Failed to get sessions", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) Delete(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Revoke(c.Request.Context(), id, tutorID); err != nil {
+		h.log.Error("Failed to revoke session", slog.String("id", id), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Session revoked", slog.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteOthers signs out every session but the one making the request.
+func (h *SessionHandler) DeleteOthers(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	if err := h.service.RevokeOthers(c.Request.Context(), tutorID, c.GetString("sessionID")); err != nil {
+		h.log.Error("Failed to revoke sessions", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Other sessions revoked", slog.String("tutor_id", tutorID))
+	c.Status(http.StatusNoContent)
+}
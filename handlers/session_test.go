@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newSessionRouter(svc *mockSessionService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewSessionHandler(svc, slog.Default())
+	r.Use(withTutorID(tutorID), func(c *gin.Context) { c.Set("sessionID", testSessionID) })
+	r.GET("/sessions", h.GetAll)
+	r.DELETE("/sessions", h.DeleteOthers)
+	r.DELETE("/sessions/:id", h.Delete)
+	return r
+}
+
+func TestSessionGetAll_Success(t *testing.T) {
+	svc := new(mockSessionService)
+	r := newSessionRouter(svc, testTutorID)
+
+	svc.On("GetAll", mock.Anything, testTutorID, testSessionID).
+		Return([]models.Session{{ID: testSessionID, Current: true}}, nil)
+
+	w := makeRequest(t, r, http.MethodGet, "/sessions", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []models.Session
+	decodeJSON(t, w, &got)
+	assert.Len(t, got, 1)
+	assert.True(t, got[0].Current)
+}
+
+func TestSessionGetAll_Unauthorized(t *testing.T) {
+	r := newSessionRouter(new(mockSessionService), "")
+
+	w := makeRequest(t, r, http.MethodGet, "/sessions", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSessionDelete_NotFound(t *testing.T) {
+	svc := new(mockSessionService)
+	r := newSessionRouter(svc, testTutorID)
+
+	svc.On("Revoke", mock.Anything, "other", testTutorID).Return(fmt.Errorf("session: %w", service.ErrNotFound))
+
+	w := makeRequest(t, r, http.MethodDelete, "/sessions/other", nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSessionDeleteOthers_KeepsCurrent(t *testing.T) {
+	svc := new(mockSessionService)
+	r := newSessionRouter(svc, testTutorID)
+
+	svc.On("RevokeOthers", mock.Anything, testTutorID, testSessionID).Return(nil)
+
+	w := makeRequest(t, r, http.MethodDelete, "/sessions", nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
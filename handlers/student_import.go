@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StudentImportHandler struct {
+	service service.StudentImportService
+	log     *slog.Logger
+}
+
+func NewStudentImportHandler(svc service.StudentImportService, log *slog.Logger) *StudentImportHandler {
+	return &StudentImportHandler{service: svc, log: log}
+}
+
+// Import accepts the CSV file either as the multipart field "file" or as the raw
+// request body of any other content type. With ?dry_run=true it only reports the errors of each row; a
+// real import with errors is refused with the same report.
+func (h *StudentImportHandler) Import(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	var file io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		defer f.Close()
+		file = f
+	}
+	imp, err := h.service.Import(c.Request.Context(), tutorID, file, dryRun)
+	if err != nil {
+		h.log.Error("Failed to import students", slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, imp)
+	case imp.Invalid > 0:
+		c.JSON(http.StatusBadRequest, imp)
+	default:
+		h.log.Info("Students imported", slog.Int("students", imp.StudentsCreated), slog.Int("courses", imp.CoursesCreated))
+		c.JSON(http.StatusCreated, imp)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"bytes"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const studentsCSV = "first_name,email\nAnna,anna@example.com\n"
+
+func newStudentImportRouter(svc *mockStudentImportService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewStudentImportHandler(svc, slog.Default())
+	r.Use(withTutorID(tutorID))
+	r.POST("/students/import", h.Import)
+	return r
+}
+
+func TestStudentImport_DryRunMultipart(t *testing.T) {
+	svc := new(mockStudentImportService)
+	r := newStudentImportRouter(svc, testTutorID)
+
+	svc.On("Import", mock.Anything, testTutorID, studentsCSV, true).
+		Return(models.StudentImport{DryRun: true, Valid: 1, Rows: []models.StudentImportRow{{Line: 2}}}, nil)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "students.csv")
+	require.NoError(t, err)
+	_, _ = fw.Write([]byte(studentsCSV))
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/students/import?dry_run=true", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestStudentImport_RefusedWithErrors(t *testing.T) {
+	svc := new(mockStudentImportService)
+	r := newStudentImportRouter(svc, testTutorID)
+
+	report := models.StudentImport{Invalid: 1, Rows: []models.StudentImportRow{
+		{Line: 2, Errors: map[string]string{"email": "invalid email format"}},
+	}}
+	svc.On("Import", mock.Anything, testTutorID, studentsCSV, false).Return(report, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/students/import", bytes.NewBufferString(studentsCSV))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var got models.StudentImport
+	decodeJSON(t, w, &got)
+	assert.Equal(t, report.Rows, got.Rows)
+}
+
+func TestStudentImport_Created(t *testing.T) {
+	svc := new(mockStudentImportService)
+	r := newStudentImportRouter(svc, testTutorID)
+
+	svc.On("Import", mock.Anything, testTutorID, studentsCSV, false).
+		Return(models.StudentImport{Valid: 1, StudentsCreated: 1}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/students/import", bytes.NewBufferString(studentsCSV))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+// capped caps request bodies like the router does.
+func capped(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+func multipartBody(t *testing.T, field string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, "students.csv")
+	require.NoError(t, err)
+	_, _ = fw.Write(content)
+	require.NoError(t, mw.Close())
+	return &body, mw.FormDataContentType()
+}
+
+func TestStudentImport_MultipartTooLarge(t *testing.T) {
+	svc := new(mockStudentImportService)
+	r := gin.New()
+	h := handlers.NewStudentImportHandler(svc, slog.Default())
+	r.Use(capped(1024), withTutorID(testTutorID))
+	r.POST("/students/import", h.Import)
+
+	body, contentType := multipartBody(t, "file", bytes.Repeat([]byte("a"), 4096))
+	req := httptest.NewRequest(http.MethodPost, "/students/import", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	svc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStudentImport_MultipartWithoutFile(t *testing.T) {
+	svc := new(mockStudentImportService)
+	r := newStudentImportRouter(svc, testTutorID)
+
+	body, contentType := multipartBody(t, "attachment", []byte(studentsCSV))
+	req := httptest.NewRequest(http.MethodPost, "/students/import", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStudentImport_RawBodyTooLarge(t *testing.T) {
+	svc := new(mockStudentImportService)
+	r := newStudentImportRouter(svc, testTutorID)
+
+	svc.On("Import", mock.Anything, testTutorID, studentsCSV, false).
+		Return(models.StudentImport{}, &http.MaxBytesError{Limit: 1 << 20})
+
+	req := httptest.NewRequest(http.MethodPost, "/students/import", bytes.NewBufferString(studentsCSV))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -53,10 +53,14 @@ func (h *TaskHandler) Create(c *gin.Context) {
 	if !bindAndValidate(c, &req) {
 		return
 	}
-	task, err := h.service.Create(c.Request.Context(), tutorID, req)
+	opts := scheduleOptions(c)
+	task, err := h.service.Create(c.Request.Context(), tutorID, req, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to create task", slog.String("error", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		handleServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, task)
@@ -73,10 +77,14 @@ func (h *TaskHandler) Update(c *gin.Context) {
 	if !bindAndValidate(c, &req) {
 		return
 	}
-	task, err := h.service.Update(c.Request.Context(), id, tutorID, req)
+	opts := scheduleOptions(c)
+	task, err := h.service.Update(c.Request.Context(), id, tutorID, req, opts)
+	if opts.DryRun && respondDryRun(c, err, nil) {
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to update task", slog.String("error", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		handleServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, task)
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type TwoFactorHandler struct {
+	service service.TwoFactorService
+	tutors  service.TutorService
+	log     *slog.Logger
+}
+
+func NewTwoFactorHandler(svc service.TwoFactorService, tutors service.TutorService, log *slog.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{service: svc, tutors: tutors, log: log}
+}
+
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	status, err := h.service.GetStatus(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to get two-factor status", slog.String("tutor_id", tutorID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	setup, err := h.service.Setup(c.Request.Context(), tutorID)
+	if err != nil {
+		h.log.Error("Failed to set up two-factor authentication", slog.String("tutor_id", tutorID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	codes, err := h.service.Enable(c.Request.Context(), tutorID, req.Code)
+	if err != nil {
+		h.log.Warn("Failed to enable two-factor authentication", slog.String("tutor_id", tutorID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Two-factor authentication enabled", slog.String("tutor_id", tutorID))
+	c.JSON(http.StatusOK, codes)
+}
+
+// Disable asks for the password as well as a code.
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	tutorID := c.GetString("tutorID")
+	if tutorID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.DisableTwoFactorRequest
+	if !bindAndValidate(c, &req) {
+		return
+	}
+	hash, err := h.tutors.GetPasswordHash(c.Request.Context(), tutorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tutor not found"})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+	if err := h.service.Disable(c.Request.Context(), tutorID, req.Code); err != nil {
+		h.log.Warn("Failed to disable two-factor authentication", slog.String("tutor_id", tutorID), slog.String("error", err.Error()))
+		handleServiceError(c, err)
+		return
+	}
+	h.log.Info("Two-factor authentication disabled", slog.String("tutor_id", tutorID))
+	c.Status(http.StatusNoContent)
+}
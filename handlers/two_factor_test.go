@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+	"tutorgo/handlers"
+	"tutorgo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTwoFactorRouter(svc *mockTwoFactorService, tutors *mockTutorService, tutorID string) *gin.Engine {
+	r := gin.New()
+	h := handlers.NewTwoFactorHandler(svc, tutors, slog.Default())
+	r.Use(withTutorID(tutorID))
+	r.GET("/auth/2fa", h.GetStatus)
+	r.POST("/auth/2fa/setup", h.Setup)
+	r.POST("/auth/2fa/enable", h.Enable)
+	r.POST("/auth/2fa/disable", h.Disable)
+	return r
+}
+
+func TestTwoFactorEnable_ReturnsRecoveryCodes(t *testing.T) {
+	svc := new(mockTwoFactorService)
+	r := newTwoFactorRouter(svc, new(mockTutorService), testTutorID)
+
+	svc.On("Enable", mock.Anything, testTutorID, "123456").
+		Return(models.RecoveryCodes{Codes: []string{"ABCD-EFGH"}}, nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/2fa/enable", models.TwoFactorCodeRequest{Code: "123456"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.RecoveryCodes
+	decodeJSON(t, w, &got)
+	assert.Equal(t, []string{"ABCD-EFGH"}, got.Codes)
+}
+
+func TestTwoFactorDisable_WrongPassword(t *testing.T) {
+	svc := new(mockTwoFactorService)
+	tutors := new(mockTutorService)
+	r := newTwoFactorRouter(svc, tutors, testTutorID)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	tutors.On("GetPasswordHash", mock.Anything, testTutorID).Return(string(hash), nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/2fa/disable",
+		models.DisableTwoFactorRequest{Password: "guessed", Code: "123456"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	svc.AssertNotCalled(t, "Disable", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTwoFactorDisable_Success(t *testing.T) {
+	svc := new(mockTwoFactorService)
+	tutors := new(mockTutorService)
+	r := newTwoFactorRouter(svc, tutors, testTutorID)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	tutors.On("GetPasswordHash", mock.Anything, testTutorID).Return(string(hash), nil)
+	svc.On("Disable", mock.Anything, testTutorID, "123456").Return(nil)
+
+	w := makeRequest(t, r, http.MethodPost, "/auth/2fa/disable",
+		models.DisableTwoFactorRequest{Password: "password123", Code: "123456"})
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestTwoFactorGetStatus_Unauthorized(t *testing.T) {
+	r := newTwoFactorRouter(new(mockTwoFactorService), new(mockTutorService), "")
+
+	w := makeRequest(t, r, http.MethodGet, "/auth/2fa", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,113 @@
+// Package ics handles the subset of RFC 5545 iCalendar TutorGo exchanges with
+// calendar apps. Encode writes the subscription feed: a VCALENDAR of VEVENTs with
+// UTC start/end times, text escaped and content lines folded at 75 octets as the
+// RFC requires. Parse reads VEVENTs from files exported by other calendars.
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses (RFC 5545 §3.8.1.11).
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	utcLayout = "20060102T150405Z"
+	// maxLineOctets is the folding limit, excluding the CRLF.
+	maxLineOctets = 75
+)
+
+type Event struct {
+	// UID must stay the same for the same event across feed refreshes, or
+	// calendar clients show duplicates.
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Status      string // empty omits the property
+}
+
+type Calendar struct {
+	ProdID   string
+	Name     string // X-WR-CALNAME, shown by Google and Apple as the calendar title
+	Timezone string // X-WR-TIMEZONE, a display hint; event times are in UTC
+	Events   []Event
+}
+
+// Encode renders the calendar. stamp is written as DTSTAMP of every event.
+func (c Calendar) Encode(stamp time.Time) []byte {
+	var b bytes.Buffer
+	line := func(name, value string) { writeLine(&b, name+":"+value) }
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.Timezone != "" {
+		line("X-WR-TIMEZONE", c.Timezone)
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", formatUTC(stamp))
+		line("DTSTART", formatUTC(e.Start))
+		line("DTEND", formatUTC(e.End))
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.Bytes()
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format(utcLayout)
+}
+
+// escapeText escapes a TEXT value (RFC 5545 §3.3.11).
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeLine writes a content line, folding it so no physical line exceeds 75
+// octets. Continuation lines start with a single space, which counts towards
+// the limit. Folds never split a UTF-8 sequence.
+func writeLine(b *bytes.Buffer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
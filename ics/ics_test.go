@@ -0,0 +1,78 @@
+package ics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/ics"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var stamp = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestEncode_Event(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	cal := ics.Calendar{
+		ProdID:   "-//TutorGo//Calendar//EN",
+		Name:     "Lessons",
+		Timezone: "Europe/Moscow",
+		Events: []ics.Event{{
+			UID:     "lesson-1@tutorgo",
+			Start:   time.Date(2026, 3, 2, 17, 0, 0, 0, moscow),
+			End:     time.Date(2026, 3, 2, 18, 0, 0, 0, moscow),
+			Summary: "Math, algebra",
+			Status:  ics.StatusCancelled,
+		}},
+	}
+
+	out := string(cal.Encode(stamp))
+
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//TutorGo//Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Lessons",
+		"X-WR-TIMEZONE:Europe/Moscow",
+		"BEGIN:VEVENT",
+		"UID:lesson-1@tutorgo",
+		"DTSTAMP:20260301T120000Z",
+		"DTSTART:20260302T140000Z",
+		"DTEND:20260302T150000Z",
+		`SUMMARY:Math\, algebra`,
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), out)
+}
+
+func TestEncode_EscapesText(t *testing.T) {
+	cal := ics.Calendar{Events: []ics.Event{{
+		UID:         "x",
+		Summary:     `a;b\c`,
+		Description: "line one\nline two",
+	}}}
+
+	out := string(cal.Encode(stamp))
+
+	assert.Contains(t, out, "\r\nSUMMARY:"+`a\;b\\c`+"\r\n")
+	assert.Contains(t, out, "\r\nDESCRIPTION:"+`line one\nline two`+"\r\n")
+}
+
+func TestEncode_FoldsLongLines(t *testing.T) {
+	long := strings.Repeat("Ж", 60) // 120 octets
+	cal := ics.Calendar{Events: []ics.Event{{UID: "x", Description: long}}}
+
+	out := string(cal.Encode(stamp))
+
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(l), 75)
+		assert.True(t, utf8.ValidString(l), "fold split a rune: %q", l)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "DESCRIPTION:"+long+"\r\n")
+}
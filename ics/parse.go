@@ -0,0 +1,291 @@
+package ics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedEvent is a VEVENT read from an uploaded calendar. Date-times are
+// resolved to instants; the zone they were written in is kept in TZID.
+type ParsedEvent struct {
+	UID          string
+	RecurrenceID *time.Time // set on an edited instance of a recurring event
+	Summary      string
+	Description  string
+	Status       string
+	Start        time.Time
+	End          time.Time // from DTEND or DURATION; equals Start when neither is given
+	AllDay       bool
+	TZID         string // IANA zone of DTSTART; empty for UTC and floating times
+	RRule        string
+	ExDates      []time.Time
+	// Problem explains why the event could not be read completely, e.g. an
+	// unknown TZID. The other fields are best effort.
+	Problem string
+}
+
+// Parse reads the VEVENTs of an iCalendar stream. Floating date-times (no "Z",
+// no TZID) are read in loc. TZIDs must be IANA names, which is what Google and
+// Apple Calendar export; VTIMEZONE definitions are not interpreted.
+func Parse(r io.Reader, loc *time.Location) ([]ParsedEvent, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []ParsedEvent
+		current *ParsedEvent
+		nested  int // depth of components inside the current VEVENT (VALARM)
+		seenCal bool
+	)
+	for _, raw := range lines {
+		if raw == "" {
+			continue
+		}
+		name, params, value, err := splitLine(raw)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			seenCal = true
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && current == nil:
+			current = &ParsedEvent{}
+		case current == nil:
+			// Calendar properties and other components (VTIMEZONE, VTODO).
+		case name == "BEGIN":
+			nested++
+		case name == "END" && nested > 0:
+			nested--
+		case nested > 0:
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current.End.IsZero() {
+				current.End = current.Start
+			}
+			events = append(events, *current)
+			current = nil
+		default:
+			current.set(name, params, value, loc)
+		}
+	}
+	if !seenCal {
+		return nil, errors.New("ics: not an iCalendar file")
+	}
+	return events, nil
+}
+
+func (e *ParsedEvent) set(name string, params map[string]string, value string, loc *time.Location) {
+	fail := func(format string, args ...any) {
+		if e.Problem == "" {
+			e.Problem = fmt.Sprintf(format, args...)
+		}
+	}
+	switch name {
+	case "UID":
+		e.UID = value
+	case "SUMMARY":
+		e.Summary = unescapeText(value)
+	case "DESCRIPTION":
+		e.Description = unescapeText(value)
+	case "STATUS":
+		e.Status = strings.ToUpper(value)
+	case "RRULE":
+		e.RRule = value
+	case "DTSTART":
+		t, allDay, err := parseDateTime(value, params, loc)
+		if err != nil {
+			fail("DTSTART: %v", err)
+			return
+		}
+		e.Start, e.AllDay, e.TZID = t, allDay, params["TZID"]
+	case "DTEND":
+		t, _, err := parseDateTime(value, params, loc)
+		if err != nil {
+			fail("DTEND: %v", err)
+			return
+		}
+		e.End = t
+	case "DURATION":
+		d, err := parseDuration(value)
+		if err != nil {
+			fail("DURATION: %v", err)
+			return
+		}
+		// DTSTART precedes DURATION in every exporter we have seen; RFC 5545
+		// doesn't promise it, hence the zero check.
+		if !e.Start.IsZero() {
+			e.End = e.Start.Add(d)
+		}
+	case "RECURRENCE-ID":
+		t, _, err := parseDateTime(value, params, loc)
+		if err != nil {
+			fail("RECURRENCE-ID: %v", err)
+			return
+		}
+		e.RecurrenceID = &t
+	case "EXDATE":
+		for _, v := range strings.Split(value, ",") {
+			t, _, err := parseDateTime(v, params, loc)
+			if err != nil {
+				fail("EXDATE: %v", err)
+				return
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	}
+}
+
+// unfold reads content lines, joining continuation lines (RFC 5545 §3.1).
+// Bare LF line endings are accepted too.
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// splitLine parses `NAME;PARAM=VALUE;PARAM="QUOTED":value`. Names and parameter
+// names are upper-cased; parameter values lose their quotes.
+func splitLine(line string) (string, map[string]string, string, error) {
+	inQuotes := false
+	colon := -1
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ':':
+			if !inQuotes {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", fmt.Errorf("ics: malformed line %q", line)
+	}
+	head, value := line[:colon], line[colon+1:]
+
+	var parts []string
+	inQuotes = false
+	start := 0
+	for i := 0; i < len(head); i++ {
+		switch head[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				parts = append(parts, head[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, head[start:])
+
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value, nil
+}
+
+// parseDateTime reads a DATE or DATE-TIME value. The bool reports a DATE, which
+// is returned as midnight in loc.
+func parseDateTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		zone, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = zone
+	}
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration reads the dur-value subset used for events: P[nW][nD][T[nH][nM][nS]].
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(value, "+")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+		case r == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			num = ""
+			switch {
+			case r == 'W' && !inTime:
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				total += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return total, nil
+}
+
+// unescapeText reverses escapeText.
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
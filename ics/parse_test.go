@@ -0,0 +1,122 @@
+package ics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/ics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, lines ...string) []ics.ParsedEvent {
+	t.Helper()
+	events, err := ics.Parse(strings.NewReader(strings.Join(lines, "\r\n")), time.UTC)
+	require.NoError(t, err)
+	return events
+}
+
+func TestParse_RecurringEventWithTZID(t *testing.T) {
+	events := parse(t,
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Moscow",
+		"BEGIN:STANDARD",
+		"DTSTART:19700101T000000",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:abc@google.com",
+		"DTSTART;TZID=Europe/Moscow:20260302T170000",
+		"DTEND;TZID=Europe/Moscow:20260302T180000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"EXDATE;TZID=Europe/Moscow:20260309T170000,20260316T170000",
+		`SUMMARY:Math\, Ivan`,
+		"DESCRIPTION:Chapter 3\\nexercises",
+		" 1-10",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	require.Len(t, events, 1)
+	e := events[0]
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	assert.Equal(t, "abc@google.com", e.UID)
+	assert.Equal(t, "Math, Ivan", e.Summary)
+	assert.Equal(t, "Chapter 3\nexercises1-10", e.Description)
+	assert.True(t, e.Start.Equal(time.Date(2026, 3, 2, 17, 0, 0, 0, moscow)))
+	assert.Equal(t, time.Hour, e.End.Sub(e.Start))
+	assert.Equal(t, "Europe/Moscow", e.TZID)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", e.RRule)
+	require.Len(t, e.ExDates, 2)
+	assert.True(t, e.ExDates[1].Equal(time.Date(2026, 3, 16, 17, 0, 0, 0, moscow)))
+	assert.Empty(t, e.Problem)
+}
+
+func TestParse_DurationAllDayAndOverrides(t *testing.T) {
+	events := parse(t,
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:one",
+		"DTSTART:20260302T140000Z",
+		"DURATION:PT1H30M",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:two",
+		"DTSTART;VALUE=DATE:20260305",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:three",
+		"RECURRENCE-ID:20260309T140000Z",
+		"DTSTART:20260310T140000Z",
+		"STATUS:cancelled",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, 90*time.Minute, events[0].End.Sub(events[0].Start))
+	assert.True(t, events[1].AllDay)
+	require.NotNil(t, events[2].RecurrenceID)
+	assert.True(t, events[2].RecurrenceID.Equal(time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "CANCELLED", events[2].Status)
+}
+
+func TestParse_UnknownTZIDIsReportedPerEvent(t *testing.T) {
+	events := parse(t,
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:x",
+		`DTSTART;TZID="Russian Standard Time":20260302T170000`,
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0].Problem, "Russian Standard Time")
+}
+
+func TestParse_RoundTripsEncode(t *testing.T) {
+	start := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	cal := ics.Calendar{Events: []ics.Event{{
+		UID: "x", Start: start, End: start.Add(time.Hour),
+		Summary: strings.Repeat("Алгебра; ", 10), Status: ics.StatusCancelled,
+	}}}
+
+	events, err := ics.Parse(strings.NewReader(string(cal.Encode(stamp))), time.UTC)
+
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, strings.Repeat("Алгебра; ", 10), events[0].Summary)
+	assert.True(t, events[0].End.Equal(start.Add(time.Hour)))
+}
+
+func TestParse_RejectsNonCalendar(t *testing.T) {
+	_, err := ics.Parse(strings.NewReader("hello: world"), time.UTC)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package mail_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	netmail "net/mail"
+	"testing"
+	"time"
+	"tutorgo/mail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	from := netmail.Address{Name: "TutorGo", Address: "noreply@tutorgo.app"}
+	to := netmail.Address{Address: "anna@example.com"}
+	date := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+	raw := mail.Compose(from, to, mail.Message{Subject: "Сброс пароля", Body: "Hello,\nfollow the link."}, date)
+
+	msg, err := netmail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, `"TutorGo" <noreply@tutorgo.app>`, msg.Header.Get("From"))
+	assert.Equal(t, "<anna@example.com>", msg.Header.Get("To"))
+	subject, err := new(netmail.AddressParser).WordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Сброс пароля", subject)
+	sent, err := msg.Header.Date()
+	require.NoError(t, err)
+	assert.True(t, sent.Equal(date))
+	assert.Contains(t, string(raw), "\r\n\r\nHello,\r\nfollow the link.")
+}
+
+func TestNewSMTP_InvalidFrom(t *testing.T) {
+	_, err := mail.NewSMTP("localhost", "25", "", "", "not an address")
+	assert.Error(t, err)
+}
+
+func TestSMTP_GivesUpWithContext(t *testing.T) {
+	// A server that accepts the connection and never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close() // held open, silent, until the test ends
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	sender, err := mail.NewSMTP(host, port, "", "", "noreply@tutorgo.app")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = sender.Send(ctx, mail.Message{To: "anna@example.com", Subject: "Hi", Body: "link"})
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	sender := mail.NewLog(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	err := sender.Send(context.Background(), mail.Message{To: "anna@example.com", Subject: "Hi", Body: "link"})
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "to=anna@example.com")
+	assert.Contains(t, buf.String(), "body=link")
+}
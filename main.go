@@ -10,6 +10,7 @@ import (
 	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // series and tutors use IANA zones; the alpine runtime image ships no zoneinfo
 
 	"tutorgo/config"
 	"tutorgo/database"
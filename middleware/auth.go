@@ -1,54 +1,139 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
+	"tutorgo/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func Auth(jwtSecret string) gin.HandlerFunc {
+// SessionChecker tells whether a session is still signed in.
+type SessionChecker interface {
+	IsActive(ctx context.Context, id string) (bool, error)
+}
+
+// Auth accepts tutors' access tokens whose session is still active, so signing a
+// device out takes effect at once rather than when its token expires. Tokens
+// with no role claim predate roles and are tutors'. It sets tutorID and
+// sessionID.
+func Auth(jwtSecret string, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		claims, ok := bearerClaims(c, jwtSecret)
+		if !ok {
 			return
 		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+		if role, ok := claims["role"]; ok && role != models.RoleTutor {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(
-			parts[1],
-			jwt.MapClaims{},
-			func(token *jwt.Token) (interface{}, error) {
-				return []byte(jwtSecret), nil
-			},
-			jwt.WithValidMethods([]string{"HS256"}),
-		)
-		if err != nil || !token.Valid {
+		tutorID, ok := claims["id"].(string)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
+		sessionID, ok := claims["sid"].(string)
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
+		active, err := sessions.IsActive(c.Request.Context(), sessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
+			return
+		}
 
-		tutorID, ok := claims["id"].(string)
+		c.Set("tutorID", tutorID)
+		c.Set("sessionID", sessionID)
+
+		c.Next()
+	}
+}
+
+// Portal accepts the tokens of students and parents whose portal account still
+// exists, so a tutor revoking one takes effect at once. It sets
+// portalAccountID, studentID and role; the student is the only one whose data
+// the caller may read.
+func Portal(jwtSecret string, accounts SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := bearerClaims(c, jwtSecret)
+		if !ok {
+			return
+		}
+		role, _ := claims["role"].(string)
+		if role != models.RoleStudent && role != models.RoleParent {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+
+		accountID, ok := claims["id"].(string)
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
+		studentID, ok := claims["student_id"].(string)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+		active, err := accounts.IsActive(c.Request.Context(), accountID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
+			return
+		}
 
-		c.Set("tutorID", tutorID)
+		c.Set("portalAccountID", accountID)
+		c.Set("studentID", studentID)
+		c.Set("role", role)
 
 		c.Next()
 	}
 }
+
+// bearerClaims reads the claims of the request's bearer token, aborting with 401
+// when there is no valid one.
+func bearerClaims(c *gin.Context, jwtSecret string) (jwt.MapClaims, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+		return nil, false
+	}
+
+	token, err := jwt.ParseWithClaims(
+		parts[1],
+		jwt.MapClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(jwtSecret), nil
+		},
+		jwt.WithValidMethods([]string{"HS256"}),
+	)
+	if err != nil || !token.Valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil, false
+	}
+	return claims, true
+}
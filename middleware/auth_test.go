@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tutorgo/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const authSecret = "test-secret"
+
+// activeSessions reports the sessions in it as signed in.
+type activeSessions map[string]bool
+
+func (s activeSessions) IsActive(_ context.Context, id string) (bool, error) {
+	return s[id], nil
+}
+
+func newAuthRouter(sessions middleware.SessionChecker) *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.Auth(authSecret, sessions))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("tutorID")+"/"+c.GetString("sessionID"))
+	})
+	return router
+}
+
+func signed(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(authSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func authRequest(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuth_ActiveSession(t *testing.T) {
+	router := newAuthRouter(activeSessions{"s1": true})
+
+	w := authRequest(router, signed(t, jwt.MapClaims{"id": "t1", "sid": "s1"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "t1/s1", w.Body.String())
+}
+
+func TestAuth_RevokedSession(t *testing.T) {
+	router := newAuthRouter(activeSessions{})
+
+	w := authRequest(router, signed(t, jwt.MapClaims{"id": "t1", "sid": "s1"}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuth_TokenWithoutSession(t *testing.T) {
+	router := newAuthRouter(activeSessions{"s1": true})
+
+	w := authRequest(router, signed(t, jwt.MapClaims{"id": "t1"}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuth_PortalTokenForbidden(t *testing.T) {
+	router := newAuthRouter(activeSessions{"a1": true})
+
+	w := authRequest(router, signed(t, jwt.MapClaims{"id": "a1", "sid": "a1", "role": "parent", "student_id": "st1"}))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func newPortalRouter(accounts middleware.SessionChecker) *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.Portal(authSecret, accounts))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("portalAccountID")+"/"+c.GetString("studentID")+"/"+c.GetString("role"))
+	})
+	return router
+}
+
+func TestPortal_StudentToken(t *testing.T) {
+	router := newPortalRouter(activeSessions{"a1": true})
+
+	w := authRequest(router, signed(t, jwt.MapClaims{"id": "a1", "role": "student", "student_id": "st1"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "a1/st1/student", w.Body.String())
+}
+
+func TestPortal_RevokedAccount(t *testing.T) {
+	router := newPortalRouter(activeSessions{})
+
+	w := authRequest(router, signed(t, jwt.MapClaims{"id": "a1", "role": "parent", "student_id": "st1"}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPortal_TutorTokenForbidden(t *testing.T) {
+	router := newPortalRouter(activeSessions{"s1": true})
+
+	for _, claims := range []jwt.MapClaims{
+		{"id": "t1", "sid": "s1"},
+		{"id": "t1", "sid": "s1", "role": "tutor"},
+	} {
+		w := authRequest(router, signed(t, claims))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,37 @@
+-- +goose Up
+-- A series is now a first-class entity: an RRULE anchored at DTSTART in the
+-- tutor's timezone, expanded into lessons up to generated_until.
+-- rrule is NULL for series created from an explicit list of dates (POST /lessons/bulk),
+-- including every series that existed before this migration.
+CREATE TABLE lesson_series (
+    id               UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    course_id        UUID          NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    rrule            TEXT,
+    dtstart          TIMESTAMPTZ   NOT NULL,
+    timezone         TEXT          NOT NULL DEFAULT 'UTC',
+    duration_minutes INT           NOT NULL,
+    notes            TEXT          NOT NULL DEFAULT '',
+    exdates          TIMESTAMPTZ[] NOT NULL DEFAULT '{}',
+    generated_until  TIMESTAMPTZ   NOT NULL,
+    created_at       TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_lesson_series_course ON lesson_series(course_id);
+
+INSERT INTO lesson_series (id, course_id, dtstart, duration_minutes, notes, generated_until)
+SELECT series_id,
+       (array_agg(course_id ORDER BY scheduled_at))[1],
+       MIN(scheduled_at),
+       (array_agg(duration_minutes ORDER BY scheduled_at))[1],
+       COALESCE((array_agg(notes ORDER BY scheduled_at))[1], ''),
+       MAX(scheduled_at) + interval '1 second'
+FROM lessons
+WHERE series_id IS NOT NULL
+GROUP BY series_id;
+
+ALTER TABLE lessons
+    ADD CONSTRAINT fk_lessons_series
+    FOREIGN KEY (series_id) REFERENCES lesson_series(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE lessons DROP CONSTRAINT IF EXISTS fk_lessons_series;
+DROP TABLE IF EXISTS lesson_series;
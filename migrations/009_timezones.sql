@@ -0,0 +1,9 @@
+-- +goose Up
+-- IANA zone names (e.g. 'Europe/Moscow'). Wall-clock scheduling, month boundaries
+-- and calendar days are computed in the tutor's zone.
+ALTER TABLE tutors   ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE students ADD COLUMN timezone TEXT NULL;
+
+-- +goose Down
+ALTER TABLE students DROP COLUMN IF EXISTS timezone;
+ALTER TABLE tutors   DROP COLUMN IF EXISTS timezone;
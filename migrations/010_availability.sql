@@ -0,0 +1,40 @@
+-- +goose Up
+-- Working hours are wall-clock times in the tutor's timezone. weekday follows Go's
+-- time.Weekday: 0 = Sunday.
+CREATE TABLE working_hours (
+    id         UUID     PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id   UUID     NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    weekday    SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME     NOT NULL,
+    end_time   TIME     NOT NULL,
+    CHECK (start_time < end_time)
+);
+CREATE INDEX idx_working_hours_tutor ON working_hours(tutor_id);
+
+-- Overrides replace the weekly template for a single date.
+CREATE TABLE availability_overrides (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id   UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    date       DATE NOT NULL,
+    start_time TIME NOT NULL,
+    end_time   TIME NOT NULL,
+    CHECK (start_time < end_time)
+);
+CREATE INDEX idx_availability_overrides_tutor_date ON availability_overrides(tutor_id, date);
+
+-- Vacations, holidays and other ranges when the tutor is not available at all.
+CREATE TABLE blackouts (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id   UUID        NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    starts_at  TIMESTAMPTZ NOT NULL,
+    ends_at    TIMESTAMPTZ NOT NULL,
+    reason     TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (starts_at < ends_at)
+);
+CREATE INDEX idx_blackouts_tutor ON blackouts(tutor_id, starts_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS blackouts;
+DROP TABLE IF EXISTS availability_overrides;
+DROP TABLE IF EXISTS working_hours;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- Lessons requested through a public booking link wait in 'pending' until the tutor
+-- confirms (-> 'scheduled') or declines them.
+ALTER TABLE lessons DROP CONSTRAINT IF EXISTS lessons_status_check;
+ALTER TABLE lessons ADD CONSTRAINT lessons_status_check
+    CHECK (status IN ('pending', 'scheduled', 'completed', 'cancelled', 'missed'));
+
+CREATE TABLE booking_links (
+    id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id         UUID        NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    course_id        UUID        NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    token            TEXT        NOT NULL UNIQUE,
+    title            TEXT        NOT NULL DEFAULT '',
+    duration_minutes INT         NOT NULL,
+    min_notice_hours INT         NOT NULL DEFAULT 12,
+    horizon_days     INT         NOT NULL DEFAULT 30,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_booking_links_tutor ON booking_links(tutor_id);
+
+-- The request outlives its lesson: a declined booking deletes the pending lesson
+-- but keeps who asked for which slot.
+CREATE TABLE booking_requests (
+    id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id         UUID        NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    link_id          UUID        REFERENCES booking_links(id) ON DELETE SET NULL,
+    lesson_id        UUID        REFERENCES lessons(id) ON DELETE SET NULL,
+    scheduled_at     TIMESTAMPTZ NOT NULL,
+    duration_minutes INT         NOT NULL,
+    name             TEXT        NOT NULL,
+    contact          TEXT        NOT NULL,
+    message          TEXT        NOT NULL DEFAULT '',
+    status           VARCHAR(10) NOT NULL DEFAULT 'pending'
+                         CHECK (status IN ('pending', 'confirmed', 'declined')),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_booking_requests_tutor ON booking_requests(tutor_id, status);
+CREATE INDEX idx_booking_requests_lesson ON booking_requests(lesson_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS booking_requests;
+DROP TABLE IF EXISTS booking_links;
+DELETE FROM lessons WHERE status = 'pending';
+ALTER TABLE lessons DROP CONSTRAINT IF EXISTS lessons_status_check;
+ALTER TABLE lessons ADD CONSTRAINT lessons_status_check
+    CHECK (status IN ('scheduled', 'completed', 'cancelled', 'missed'));
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Secret for the public ICS feed URL. NULL means the feed is off; rotating
+-- replaces it, so old subscription URLs stop working.
+ALTER TABLE tutors ADD COLUMN calendar_token TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE tutors DROP COLUMN IF EXISTS calendar_token;
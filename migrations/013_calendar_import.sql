@@ -0,0 +1,25 @@
+-- +goose Up
+-- An uploaded .ics file, parsed and waiting for the tutor to map titles to
+-- courses. Deleted once committed.
+CREATE TABLE calendar_imports (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id   UUID        NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    events     JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_calendar_imports_tutor ON calendar_imports(tutor_id);
+
+-- Every VEVENT UID that has been turned into a lesson or series, so importing
+-- the same file again is a no-op. Rows outlive the lessons they created.
+CREATE TABLE imported_events (
+    tutor_id    UUID        NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    uid         TEXT        NOT NULL,
+    lesson_id   UUID        REFERENCES lessons(id) ON DELETE SET NULL,
+    series_id   UUID        REFERENCES lesson_series(id) ON DELETE SET NULL,
+    imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tutor_id, uid)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS imported_events;
+DROP TABLE IF EXISTS calendar_imports;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- Cancellation policy: a lesson cancelled less than cancel_cutoff_hours before it
+-- starts is charged per late_cancel_charge; a no-show (missed) per no_show_charge.
+-- The defaults keep the old behaviour: cancelling is free, a no-show is charged.
+ALTER TABLE tutors
+    ADD COLUMN cancel_cutoff_hours INT  NOT NULL DEFAULT 24     CHECK (cancel_cutoff_hours >= 0),
+    ADD COLUMN late_cancel_charge  TEXT NOT NULL DEFAULT 'free' CHECK (late_cancel_charge IN ('full', 'half', 'free')),
+    ADD COLUMN no_show_charge      TEXT NOT NULL DEFAULT 'full' CHECK (no_show_charge IN ('full', 'half', 'free'));
+
+-- A course either overrides the whole policy or inherits the tutor's.
+ALTER TABLE courses
+    ADD COLUMN cancel_cutoff_hours INT  CHECK (cancel_cutoff_hours >= 0),
+    ADD COLUMN late_cancel_charge  TEXT CHECK (late_cancel_charge IN ('full', 'half', 'free')),
+    ADD COLUMN no_show_charge      TEXT CHECK (no_show_charge IN ('full', 'half', 'free')),
+    ADD CONSTRAINT courses_cancellation_policy_check CHECK (
+        (cancel_cutoff_hours IS NULL) = (late_cancel_charge IS NULL)
+        AND (late_cancel_charge IS NULL) = (no_show_charge IS NULL));
+
+-- What a cancelled or missed lesson costs, fixed when it got that status so later
+-- policy changes don't rewrite history. NULL on older rows: missed counted in
+-- full, cancelled was free.
+ALTER TABLE lessons ADD COLUMN charge TEXT CHECK (charge IN ('full', 'half', 'free'));
+
+CREATE TABLE lesson_status_events (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    lesson_id   UUID        NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status   TEXT        NOT NULL,
+    charge      TEXT,
+    actor_type  TEXT        NOT NULL,
+    actor_id    UUID,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_lesson_status_events_lesson ON lesson_status_events(lesson_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS lesson_status_events;
+ALTER TABLE lessons DROP COLUMN IF EXISTS charge;
+ALTER TABLE courses
+    DROP CONSTRAINT IF EXISTS courses_cancellation_policy_check,
+    DROP COLUMN IF EXISTS cancel_cutoff_hours,
+    DROP COLUMN IF EXISTS late_cancel_charge,
+    DROP COLUMN IF EXISTS no_show_charge;
+ALTER TABLE tutors
+    DROP COLUMN IF EXISTS cancel_cutoff_hours,
+    DROP COLUMN IF EXISTS late_cancel_charge,
+    DROP COLUMN IF EXISTS no_show_charge;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE lesson_status_events ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE lesson_status_events DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- The slot a lesson was first planned for, kept once it has been moved.
+ALTER TABLE lessons ADD COLUMN original_scheduled_at TIMESTAMPTZ;
+
+-- A move is a timeline entry too: it keeps the status and records both slots.
+ALTER TABLE lesson_status_events
+    ADD COLUMN previous_scheduled_at TIMESTAMPTZ,
+    ADD COLUMN scheduled_at          TIMESTAMPTZ;
+
+-- Every reschedule of a lesson. The tutor either moves the lesson right away
+-- ('applied') or proposes a time through a public link, which the student
+-- accepts or declines; a newer proposal or a direct move withdraws the open one.
+CREATE TABLE lesson_reschedules (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    lesson_id   UUID        NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    token       TEXT        UNIQUE,
+    original_at TIMESTAMPTZ NOT NULL,
+    proposed_at TIMESTAMPTZ NOT NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    status      VARCHAR(10) NOT NULL
+                    CHECK (status IN ('applied', 'proposed', 'accepted', 'declined', 'withdrawn')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+CREATE INDEX idx_lesson_reschedules_lesson ON lesson_reschedules(lesson_id, created_at);
+CREATE UNIQUE INDEX idx_lesson_reschedules_open ON lesson_reschedules(lesson_id) WHERE status = 'proposed';
+
+-- +goose Down
+DROP TABLE IF EXISTS lesson_reschedules;
+ALTER TABLE lesson_status_events
+    DROP COLUMN IF EXISTS previous_scheduled_at,
+    DROP COLUMN IF EXISTS scheduled_at;
+ALTER TABLE lessons DROP COLUMN IF EXISTS original_scheduled_at;
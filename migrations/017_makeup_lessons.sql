@@ -0,0 +1,17 @@
+-- +goose Up
+-- A makeup lesson points at the cancelled or missed lesson it makes up for.
+-- makeup_owed marks a cancelled or missed lesson as owing the student a makeup;
+-- the credit is used up once a makeup has taken place (completed or missed).
+ALTER TABLE lessons
+    ADD COLUMN makeup_for  UUID    REFERENCES lessons(id) ON DELETE SET NULL,
+    ADD COLUMN makeup_owed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One makeup per lesson; a cancelled makeup frees the credit for another one.
+CREATE UNIQUE INDEX idx_lessons_makeup_for ON lessons(makeup_for)
+    WHERE makeup_for IS NOT NULL AND status <> 'cancelled';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_lessons_makeup_for;
+ALTER TABLE lessons
+    DROP COLUMN IF EXISTS makeup_for,
+    DROP COLUMN IF EXISTS makeup_owed;
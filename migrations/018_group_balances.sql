@@ -0,0 +1,16 @@
+-- +goose Up
+-- In a group course a payment belongs to one of the enrolled students; NULL on
+-- individual courses and on group payments made before this migration.
+ALTER TABLE payments ADD COLUMN student_id UUID REFERENCES students(id) ON DELETE SET NULL;
+CREATE INDEX idx_payments_course_student ON payments(course_id, student_id);
+
+-- What an absence costs the student, fixed when it is recorded (see lessons.charge).
+-- NULL on present rows and on absences recorded before this migration, which were free.
+ALTER TABLE lesson_attendances ADD COLUMN charge TEXT CHECK (charge IN ('full', 'half', 'free'));
+CREATE INDEX idx_lesson_attendances_student ON lesson_attendances(student_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_lesson_attendances_student;
+ALTER TABLE lesson_attendances DROP COLUMN IF EXISTS charge;
+DROP INDEX IF EXISTS idx_payments_course_student;
+ALTER TABLE payments DROP COLUMN IF EXISTS student_id;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- ISO 4217 codes. A tutor's currency is their home currency: income totals are
+-- converted into it. Courses are priced in their own currency, and a payment is
+-- recorded in the currency it was received in, by default the course's.
+ALTER TABLE tutors  ADD COLUMN currency TEXT NOT NULL DEFAULT 'KZT' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE courses ADD COLUMN currency TEXT NOT NULL DEFAULT 'KZT' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE payments ADD COLUMN currency TEXT;
+UPDATE payments p SET currency = c.currency FROM courses c WHERE c.id = p.course_id;
+ALTER TABLE payments ALTER COLUMN currency SET NOT NULL;
+ALTER TABLE payments ADD CONSTRAINT payments_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+
+-- How many units of the tutor's home currency one unit of currency buys. A
+-- currency without a rate is reported on its own and left out of the totals.
+CREATE TABLE exchange_rates (
+    tutor_id   UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    currency   TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    rate       NUMERIC(18,8) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tutor_id, currency)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE courses  DROP COLUMN IF EXISTS currency;
+ALTER TABLE tutors   DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,110 @@
+-- +goose Up
+-- A payment row only says who paid whom for what; the money itself is in
+-- payment_entries, an append-only ledger. Corrections, voids and refunds add
+-- compensating entries, and balances and income are sums over the ledger.
+-- Payments outlive their course: deleting it detaches them, and subject keeps
+-- what they were for.
+ALTER TABLE payments ADD COLUMN tutor_id UUID REFERENCES tutors(id) ON DELETE CASCADE;
+ALTER TABLE payments ADD COLUMN subject TEXT NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+UPDATE payments p SET tutor_id = c.tutor_id, subject = c.subject, created_at = p.paid_at
+FROM courses c WHERE c.id = p.course_id;
+ALTER TABLE payments ALTER COLUMN tutor_id SET NOT NULL;
+CREATE INDEX idx_payments_tutor ON payments(tutor_id);
+
+ALTER TABLE payments ALTER COLUMN course_id DROP NOT NULL;
+ALTER TABLE payments DROP CONSTRAINT payments_course_id_fkey;
+ALTER TABLE payments ADD CONSTRAINT payments_course_id_fkey
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE SET NULL;
+
+-- payment: money received (the first entry, and the re-posting after a
+-- correction); reversal: cancels the last payment entry, at its date; refund:
+-- money and lessons given back, at the date they were. Amounts and lesson
+-- counts are signed. seq orders entries written in the same transaction.
+CREATE TABLE payment_entries (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seq           BIGSERIAL NOT NULL,
+    payment_id    UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    kind          TEXT NOT NULL CHECK (kind IN ('payment', 'reversal', 'refund')),
+    amount        NUMERIC(10,2) NOT NULL,
+    lessons_count INT NOT NULL,
+    effective_at  TIMESTAMPTZ NOT NULL,
+    reason        TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_payment_entries_payment ON payment_entries(payment_id, seq);
+CREATE INDEX idx_payment_entries_effective_at ON payment_entries(effective_at);
+
+INSERT INTO payment_entries (payment_id, kind, amount, lessons_count, effective_at, created_at)
+SELECT id, 'payment', amount, lessons_count, paid_at, paid_at FROM payments;
+
+ALTER TABLE payments DROP COLUMN amount;
+ALTER TABLE payments DROP COLUMN lessons_count;
+ALTER TABLE payments DROP COLUMN paid_at;
+
+-- Entries are never changed. Deleting them directly is refused too; they only go
+-- with their tutor's account, through the cascade.
+-- +goose StatementBegin
+CREATE FUNCTION payment_entries_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'payment_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER payment_entries_no_update
+    BEFORE UPDATE ON payment_entries
+    FOR EACH ROW EXECUTE FUNCTION payment_entries_append_only();
+CREATE TRIGGER payment_entries_no_delete
+    BEFORE DELETE ON payment_entries
+    FOR EACH ROW WHEN (pg_trigger_depth() = 0) EXECUTE FUNCTION payment_entries_append_only();
+
+-- The current state of each payment: the last posted amount, lessons and date,
+-- what has been refunded, and whether the last posting was reversed.
+CREATE VIEW payment_states AS
+SELECT p.id, p.tutor_id, p.course_id, p.subject, p.student_id, p.currency,
+       posted.amount, posted.lessons_count, posted.effective_at AS paid_at,
+       COALESCE(-refunds.amount, 0) AS refunded,
+       COALESCE(-refunds.lessons_count, 0)::int AS lessons_refunded,
+       last.kind = 'reversal' AS voided
+FROM payments p
+CROSS JOIN LATERAL (
+    SELECT e.amount, e.lessons_count, e.effective_at FROM payment_entries e
+    WHERE e.payment_id = p.id AND e.kind = 'payment'
+    ORDER BY e.seq DESC LIMIT 1
+) posted
+CROSS JOIN LATERAL (
+    SELECT e.kind FROM payment_entries e
+    WHERE e.payment_id = p.id AND e.kind <> 'refund'
+    ORDER BY e.seq DESC LIMIT 1
+) last
+CROSS JOIN LATERAL (
+    SELECT SUM(e.amount) AS amount, SUM(e.lessons_count) AS lessons_count
+    FROM payment_entries e
+    WHERE e.payment_id = p.id AND e.kind = 'refund'
+) refunds;
+
+-- +goose Down
+DROP VIEW IF EXISTS payment_states;
+ALTER TABLE payments ADD COLUMN amount NUMERIC(10,2);
+ALTER TABLE payments ADD COLUMN lessons_count INT;
+ALTER TABLE payments ADD COLUMN paid_at TIMESTAMPTZ;
+UPDATE payments p SET amount = e.amount, lessons_count = e.lessons_count, paid_at = e.effective_at
+FROM (SELECT payment_id, SUM(amount) AS amount, SUM(lessons_count) AS lessons_count, MIN(effective_at) AS effective_at
+      FROM payment_entries GROUP BY payment_id) e
+WHERE e.payment_id = p.id;
+DELETE FROM payments WHERE course_id IS NULL OR amount IS NULL OR amount <= 0;
+ALTER TABLE payments ALTER COLUMN amount SET NOT NULL;
+ALTER TABLE payments ALTER COLUMN lessons_count SET NOT NULL;
+ALTER TABLE payments ALTER COLUMN paid_at SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payments_course_paid_at ON payments(course_id, paid_at DESC);
+DROP TABLE IF EXISTS payment_entries;
+DROP FUNCTION IF EXISTS payment_entries_append_only();
+ALTER TABLE payments DROP CONSTRAINT payments_course_id_fkey;
+ALTER TABLE payments ALTER COLUMN course_id SET NOT NULL;
+ALTER TABLE payments ADD CONSTRAINT payments_course_id_fkey
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE;
+DROP INDEX IF EXISTS idx_payments_tutor;
+ALTER TABLE payments DROP COLUMN IF EXISTS created_at;
+ALTER TABLE payments DROP COLUMN IF EXISTS subject;
+ALTER TABLE payments DROP COLUMN IF EXISTS tutor_id;
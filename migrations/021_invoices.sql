@@ -0,0 +1,78 @@
+-- +goose Up
+-- Requisites printed on the tutor's invoices.
+ALTER TABLE tutors ADD COLUMN legal_name   TEXT NOT NULL DEFAULT '';
+ALTER TABLE tutors ADD COLUMN tax_id       TEXT NOT NULL DEFAULT '';
+ALTER TABLE tutors ADD COLUMN address      TEXT NOT NULL DEFAULT '';
+ALTER TABLE tutors ADD COLUMN bank_name    TEXT NOT NULL DEFAULT '';
+ALTER TABLE tutors ADD COLUMN bank_account TEXT NOT NULL DEFAULT '';
+ALTER TABLE tutors ADD COLUMN bank_code    TEXT NOT NULL DEFAULT '';
+-- The number the tutor's next sent invoice gets.
+ALTER TABLE tutors ADD COLUMN next_invoice_number INT NOT NULL DEFAULT 1;
+
+-- An invoice is numbered when it is sent, so deleted drafts leave no gaps. Overdue
+-- is not stored: it is a sent invoice past its due date in the tutor's timezone.
+-- Invoices outlive their course and student, like payments; bill_to keeps who
+-- they were for.
+CREATE TABLE invoices (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id   UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    course_id  UUID REFERENCES courses(id) ON DELETE SET NULL,
+    student_id UUID REFERENCES students(id) ON DELETE SET NULL,
+    number     INT,
+    status     TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sent', 'paid')),
+    currency   TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    bill_to    TEXT NOT NULL DEFAULT '',
+    notes      TEXT NOT NULL DEFAULT '',
+    issue_date DATE NOT NULL,
+    due_date   DATE NOT NULL CHECK (due_date >= issue_date),
+    total      NUMERIC(10,2) NOT NULL,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at    TIMESTAMPTZ,
+    paid_at    TIMESTAMPTZ,
+    UNIQUE (tutor_id, number),
+    CHECK ((status = 'draft') = (number IS NULL))
+);
+CREATE INDEX idx_invoices_tutor_created ON invoices(tutor_id, created_at DESC);
+CREATE UNIQUE INDEX idx_invoices_payment ON invoices(payment_id);
+
+-- A line is either a charged lesson or a payment package. A payment is billed
+-- once, and so is a lesson to each student: student_id is whom a lesson line
+-- bills, as group lessons are billed to every member separately.
+CREATE TABLE invoice_items (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id  UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    description TEXT NOT NULL,
+    quantity    NUMERIC(6,2) NOT NULL,
+    unit_price  NUMERIC(10,2) NOT NULL,
+    amount      NUMERIC(10,2) NOT NULL,
+    lesson_id   UUID REFERENCES lessons(id) ON DELETE SET NULL,
+    student_id  UUID REFERENCES students(id) ON DELETE SET NULL,
+    payment_id  UUID REFERENCES payments(id) ON DELETE SET NULL
+);
+CREATE INDEX idx_invoice_items_invoice ON invoice_items(invoice_id, position);
+CREATE UNIQUE INDEX idx_invoice_items_lesson ON invoice_items(lesson_id, student_id);
+CREATE UNIQUE INDEX idx_invoice_items_payment ON invoice_items(payment_id);
+
+-- Invoices with the status they read as: sent ones past due are overdue.
+CREATE VIEW invoice_states AS
+SELECT i.id, i.tutor_id, i.number,
+       CASE WHEN i.status = 'sent' AND i.due_date < (NOW() AT TIME ZONE t.timezone)::date
+            THEN 'overdue' ELSE i.status END AS status,
+       i.course_id, i.student_id, i.currency, i.bill_to, i.notes, i.issue_date, i.due_date,
+       i.total, i.payment_id, i.created_at, i.sent_at, i.paid_at
+FROM invoices i
+JOIN tutors t ON t.id = i.tutor_id;
+
+-- +goose Down
+DROP VIEW IF EXISTS invoice_states;
+DROP TABLE IF EXISTS invoice_items;
+DROP TABLE IF EXISTS invoices;
+ALTER TABLE tutors DROP COLUMN IF EXISTS next_invoice_number;
+ALTER TABLE tutors DROP COLUMN IF EXISTS bank_code;
+ALTER TABLE tutors DROP COLUMN IF EXISTS bank_account;
+ALTER TABLE tutors DROP COLUMN IF EXISTS bank_name;
+ALTER TABLE tutors DROP COLUMN IF EXISTS address;
+ALTER TABLE tutors DROP COLUMN IF EXISTS tax_id;
+ALTER TABLE tutors DROP COLUMN IF EXISTS legal_name;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- What a tutor sells: a package of lessons, optionally valid for valid_days
+-- after the sale, or a monthly subscription, whose lessons are valid for the
+-- month it was sold for. Changing or deleting a package does not touch what has
+-- been sold.
+CREATE TABLE packages (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id      UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    name          TEXT NOT NULL,
+    kind          TEXT NOT NULL CHECK (kind IN ('package', 'subscription')),
+    lessons_count INT NOT NULL CHECK (lessons_count > 0),
+    price         NUMERIC(10,2) NOT NULL CHECK (price > 0),
+    currency      TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    valid_days    INT CHECK (valid_days > 0),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (kind = 'package' OR valid_days IS NULL)
+);
+CREATE INDEX idx_packages_tutor ON packages(tutor_id);
+
+-- The lessons a sold package or subscription period gives, as a payment whose
+-- lessons are valid from starts_at until expires_at (NULL: they don't expire).
+-- How many there are is the payment's, so refunds and voids take them back.
+-- name and kind are copied from the package at the sale.
+CREATE TABLE lesson_credits (
+    payment_id UUID PRIMARY KEY REFERENCES payments(id) ON DELETE CASCADE,
+    package_id UUID REFERENCES packages(id) ON DELETE SET NULL,
+    name       TEXT NOT NULL,
+    kind       TEXT NOT NULL CHECK (kind IN ('package', 'subscription')),
+    starts_at  TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ CHECK (expires_at > starts_at)
+);
+CREATE INDEX idx_lesson_credits_package ON lesson_credits(package_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS lesson_credits;
+DROP TABLE IF EXISTS packages;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- A signed-in device. Access tokens name their session and stop working once it
+-- is revoked or expired. The refresh token is stored only as its SHA-256 and is
+-- replaced on every refresh; previous_hash keeps the one it replaced, so a
+-- refresh token used twice, as a stolen copy would be, is noticed and the
+-- session revoked.
+CREATE TABLE sessions (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id      UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    refresh_hash  TEXT NOT NULL UNIQUE,
+    previous_hash TEXT,
+    user_agent    TEXT NOT NULL DEFAULT '',
+    ip            TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    revoked_at    TIMESTAMPTZ
+);
+CREATE INDEX idx_sessions_tutor ON sessions(tutor_id);
+CREATE INDEX idx_sessions_previous_hash ON sessions(previous_hash);
+
+-- +goose Down
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- email_verified_at is set once the tutor follows the link mailed to their
+-- address, and cleared when the address changes.
+ALTER TABLE tutors ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- A token mailed to a tutor: to verify their email or to reset their password.
+-- The token itself is signed and carries this row's id; the row makes it expire
+-- and work only once. email is the address it was sent to, so a verification
+-- link stops counting once the address changes.
+CREATE TABLE account_tokens (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id   UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    purpose    TEXT NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    email      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+CREATE INDEX idx_account_tokens_tutor ON account_tokens(tutor_id, purpose);
+
+-- +goose Down
+DROP TABLE IF EXISTS account_tokens;
+ALTER TABLE tutors DROP COLUMN IF EXISTS email_verified_at;
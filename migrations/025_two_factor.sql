@@ -0,0 +1,25 @@
+-- +goose Up
+-- totp_secret is set when the tutor starts enrolling and kept once they confirm
+-- a code, at which point totp_enabled_at is set. totp_last_step is the last
+-- 30-second step a code was accepted for; codes of that step or earlier are
+-- refused, so each works once.
+ALTER TABLE tutors
+    ADD COLUMN totp_secret     TEXT,
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN totp_last_step  BIGINT;
+
+-- One-off codes for signing in without the authenticator, stored as SHA-256.
+CREATE TABLE recovery_codes (
+    id        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tutor_id  UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at   TIMESTAMPTZ,
+    UNIQUE (tutor_id, code_hash)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE tutors
+    DROP COLUMN IF EXISTS totp_secret,
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_last_step;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Homework the tutor sets on a lesson, shown to the student and their parents.
+ALTER TABLE lessons ADD COLUMN homework TEXT NOT NULL DEFAULT '';
+
+-- A student's own sign-in, or a parent's, to a read-only view of the student's
+-- lessons, balance and homework. The tutor invites by email; password_hash is
+-- set, and joined_at with it, once the invitation is accepted. An email has one
+-- account, as it is what signing in goes by.
+CREATE TABLE portal_accounts (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id    UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    role          TEXT NOT NULL CHECK (role IN ('student', 'parent')),
+    name          TEXT NOT NULL DEFAULT '',
+    email         TEXT NOT NULL UNIQUE,
+    password_hash TEXT,
+    invited_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    joined_at     TIMESTAMPTZ
+);
+CREATE INDEX idx_portal_accounts_student ON portal_accounts(student_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS portal_accounts;
+ALTER TABLE lessons DROP COLUMN IF EXISTS homework;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- A tutoring school: tutors who share their schedules and income with the
+-- school's staff. Each tutor keeps their own students, courses and payments;
+-- the school sees them through its members, and can move students and courses
+-- between them.
+CREATE TABLE organizations (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A tutor's place in a school. Invitations are rows with no joined_at yet: a
+-- tutor's schedule and income are only shared once they accept.
+CREATE TABLE organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    tutor_id        UUID NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+    role            TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'tutor', 'accountant')),
+    invited_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    joined_at       TIMESTAMPTZ,
+    PRIMARY KEY (organization_id, tutor_id)
+);
+CREATE INDEX idx_organization_members_tutor ON organization_members(tutor_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- A proposal the tutor made into a busy slot on purpose (?force=true): the
+-- student's acceptance doesn't check the slot again.
+ALTER TABLE lesson_reschedules ADD COLUMN force BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE lesson_reschedules DROP COLUMN IF EXISTS force;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- An email may have an account for each of several students: a parent of two
+-- children, or a student taught by two tutors. Signing in picks the student.
+ALTER TABLE portal_accounts DROP CONSTRAINT portal_accounts_email_key;
+ALTER TABLE portal_accounts ADD CONSTRAINT portal_accounts_email_student_key UNIQUE (email, student_id);
+
+-- +goose Down
+ALTER TABLE portal_accounts DROP CONSTRAINT portal_accounts_email_student_key;
+ALTER TABLE portal_accounts ADD CONSTRAINT portal_accounts_email_key UNIQUE (email);
@@ -0,0 +1,134 @@
+package models
+
+import "tutorgo/money"
+
+// Income analytics bucket sizes. A week starts on Monday.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// IncomeAnalytics covers the dates From to To, both included, in the tutor's
+// timezone. Received is the money taken in, Earned the price of the lessons
+// charged under the cancellation policy and Forecast the price of scheduled
+// lessons that prepaid ones don't cover. Debt is what students owe now for
+// lessons charged beyond what they paid, whatever the period. All amounts are in
+// the tutor's home currency; currencies without an exchange rate are left out
+// and listed in Unconverted.
+type IncomeAnalytics struct {
+	Currency    string            `json:"currency"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Bucket      string            `json:"bucket"`
+	Received    money.Amount      `json:"received"`
+	Earned      money.Amount      `json:"earned"`
+	Forecast    money.Amount      `json:"forecast"`
+	Debt        money.Amount      `json:"debt"`
+	Buckets     []IncomeBucket    `json:"buckets"`
+	BySubject   []IncomeBreakdown `json:"by_subject"`
+	ByCourse    []IncomeBreakdown `json:"by_course"`
+	ByStudent   []IncomeBreakdown `json:"by_student"`
+	Debts       []StudentDebt     `json:"debts"`
+	Unconverted []string          `json:"unconverted"`
+}
+
+// IncomeBucket is the day, week or month starting on Start.
+type IncomeBucket struct {
+	Start    string       `json:"start"`
+	Received money.Amount `json:"received"`
+	Earned   money.Amount `json:"earned"`
+	Forecast money.Amount `json:"forecast"`
+}
+
+// IncomeBreakdown is the income of one subject, course or student. ID is the
+// course's or student's; it is nil for payments of deleted courses and for group
+// payments not attributed to a member.
+type IncomeBreakdown struct {
+	ID       *string      `json:"id"`
+	Name     string       `json:"name"`
+	Received money.Amount `json:"received"`
+	Earned   money.Amount `json:"earned"`
+	Forecast money.Amount `json:"forecast"`
+}
+
+// StudentDebt is a negative balance on a course, or on a member's account in a
+// group course: Lessons charged beyond those paid for, priced in the course's
+// currency as Amount and in the home currency as Converted (nil without a rate).
+type StudentDebt struct {
+	CourseID    string        `json:"course_id"`
+	Subject     string        `json:"subject"`
+	StudentID   string        `json:"student_id"`
+	StudentName string        `json:"student_name"`
+	Lessons     float64       `json:"lessons"`
+	Currency    string        `json:"currency"`
+	Amount      money.Amount  `json:"amount"`
+	Converted   *money.Amount `json:"converted"`
+}
+
+// Workload covers the lessons from From to To, both included, in the tutor's
+// timezone; Previous is the period of the same length just before. Pending
+// booking requests are not lessons yet and are left out.
+type Workload struct {
+	Summary  WorkloadSummary   `json:"summary"`
+	Previous WorkloadSummary   `json:"previous"`
+	Weeks    []WorkloadWeek    `json:"weeks"`
+	Students []StudentWorkload `json:"students"`
+	Heatmap  []HeatmapCell     `json:"heatmap"`
+}
+
+// WorkloadSummary counts a period's lessons by status. CancellationRate is the
+// share of lessons cancelled, NoShowRate the share of those due to take place
+// (completed or missed) that were missed. StudentLessons counts completed lessons
+// once per student taught, so a group lesson counts for each member present;
+// LessonsPerStudentMonth spreads them over the Students taught and the months of
+// the period.
+type WorkloadSummary struct {
+	From                   string  `json:"from"`
+	To                     string  `json:"to"`
+	Lessons                int     `json:"lessons"`
+	Scheduled              int     `json:"scheduled"`
+	Completed              int     `json:"completed"`
+	Cancelled              int     `json:"cancelled"`
+	Missed                 int     `json:"missed"`
+	HoursTaught            float64 `json:"hours_taught"`
+	CancellationRate       float64 `json:"cancellation_rate"`
+	NoShowRate             float64 `json:"no_show_rate"`
+	Students               int     `json:"students"`
+	StudentLessons         int     `json:"student_lessons"`
+	LessonsPerStudentMonth float64 `json:"lessons_per_student_month"`
+}
+
+// WorkloadWeek is the week, Monday to Sunday, starting on Start.
+type WorkloadWeek struct {
+	Start       string  `json:"start"`
+	Scheduled   int     `json:"scheduled"`
+	Completed   int     `json:"completed"`
+	Cancelled   int     `json:"cancelled"`
+	Missed      int     `json:"missed"`
+	HoursTaught float64 `json:"hours_taught"`
+}
+
+// StudentWorkload is one student's lessons in the period. In a group course a
+// completed lesson the student was absent from is missed.
+type StudentWorkload struct {
+	StudentID        string  `json:"student_id"`
+	Name             string  `json:"name"`
+	Lessons          int     `json:"lessons"`
+	Completed        int     `json:"completed"`
+	Cancelled        int     `json:"cancelled"`
+	Missed           int     `json:"missed"`
+	HoursTaught      float64 `json:"hours_taught"`
+	CancellationRate float64 `json:"cancellation_rate"`
+	NoShowRate       float64 `json:"no_show_rate"`
+	LessonsPerMonth  float64 `json:"lessons_per_month"`
+}
+
+// HeatmapCell counts the lessons, cancelled ones aside, starting at Hour on
+// Weekday (1 is Monday, 7 Sunday) in the tutor's timezone.
+type HeatmapCell struct {
+	Weekday int     `json:"weekday"`
+	Hour    int     `json:"hour"`
+	Lessons int     `json:"lessons"`
+	Hours   float64 `json:"hours"`
+}
@@ -5,11 +5,15 @@ type LessonAttendance struct {
 	LessonID  string `json:"lesson_id"`
 	StudentID string `json:"student_id"`
 	Status    string `json:"status"`
+	// Charge is what an absence costs the student; nil when present.
+	Charge *string `json:"charge,omitempty"`
 }
 
 type AttendanceEntry struct {
 	StudentID string `json:"student_id" validate:"required,uuid"`
 	Status    string `json:"status"     validate:"required,oneof=present absent"`
+	// Charge overrides the course's no-show charge for an absence.
+	Charge *string `json:"charge" validate:"omitempty,oneof=full half free"`
 }
 
 type UpdateAttendanceRequest struct {
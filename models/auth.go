@@ -1,11 +1,14 @@
 package models
 
+import "time"
+
 type RegisterRequest struct {
 	Email     string `json:"email"      validate:"required,email"`
 	Password  string `json:"password"   validate:"required,min=6"`
 	FirstName string `json:"first_name" validate:"required,min=2"`
 	LastName  string `json:"last_name"  validate:"required,min=2"`
 	Phone     string `json:"phone"      validate:"omitempty,min=10"`
+	Timezone  string `json:"timezone"   validate:"omitempty,timezone"`
 }
 
 type LoginRequest struct {
@@ -14,6 +17,10 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// LoginResponse carries a short-lived access token, valid until ExpiresAt, and
+// the refresh token to get the next one with. Each refresh token works once.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
 }
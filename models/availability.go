@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// WorkingHours is one interval of the tutor's weekly template. Start and End are
+// "HH:MM" in the tutor's timezone; Weekday follows time.Weekday (0 = Sunday).
+type WorkingHours struct {
+	ID      string `json:"id"`
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+type WorkingHoursInput struct {
+	Weekday int    `json:"weekday" validate:"min=0,max=6"`
+	Start   string `json:"start"   validate:"required,datetime=15:04"`
+	End     string `json:"end"     validate:"required,datetime=15:04"`
+}
+
+// SetWorkingHoursRequest replaces the whole weekly template; an empty list clears it.
+type SetWorkingHoursRequest struct {
+	Hours []WorkingHoursInput `json:"hours" validate:"max=50,dive"`
+}
+
+// AvailabilityOverride replaces the weekly template on Date. A date may have several.
+type AvailabilityOverride struct {
+	ID    string `json:"id"`
+	Date  string `json:"date"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type CreateOverrideRequest struct {
+	Date  string `json:"date"  validate:"required,datetime=2006-01-02"`
+	Start string `json:"start" validate:"required,datetime=15:04"`
+	End   string `json:"end"   validate:"required,datetime=15:04"`
+}
+
+type Blackout struct {
+	ID       string    `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type CreateBlackoutRequest struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at"   validate:"required,gtfield=StartsAt"`
+	Reason   string    `json:"reason"    validate:"omitempty,max=200"`
+}
+
+type FreeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type BookingLink struct {
+	ID              string    `json:"id"`
+	CourseID        string    `json:"course_id"`
+	Token           string    `json:"token"`
+	Title           string    `json:"title"`
+	DurationMinutes int       `json:"duration_minutes"`
+	MinNoticeHours  int       `json:"min_notice_hours"`
+	HorizonDays     int       `json:"horizon_days"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type CreateBookingLinkRequest struct {
+	CourseID        string `json:"course_id"        validate:"required,uuid"`
+	Title           string `json:"title"            validate:"omitempty,max=200"`
+	DurationMinutes int    `json:"duration_minutes" validate:"required,gt=0,max=480"`
+	MinNoticeHours  *int   `json:"min_notice_hours" validate:"omitempty,min=0,max=720"`
+	HorizonDays     *int   `json:"horizon_days"     validate:"omitempty,min=1,max=90"`
+}
+
+// PublicBookingLink is what a visitor sees behind a booking link. The untagged
+// fields stay on the server.
+type PublicBookingLink struct {
+	Title           string `json:"title"`
+	Subject         string `json:"subject"`
+	TutorName       string `json:"tutor_name"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Timezone        string `json:"timezone"`
+
+	LinkID         string `json:"-"`
+	TutorID        string `json:"-"`
+	CourseID       string `json:"-"`
+	MinNoticeHours int    `json:"-"`
+	HorizonDays    int    `json:"-"`
+}
+
+type CreateBookingRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at" validate:"required"`
+	Name        string    `json:"name"         validate:"required,max=100"`
+	Contact     string    `json:"contact"      validate:"required,max=200"`
+	Message     string    `json:"message"      validate:"omitempty,max=1000"`
+}
+
+type BookingRequest struct {
+	ID              string    `json:"id"`
+	LinkID          *string   `json:"link_id"`
+	LessonID        *string   `json:"lesson_id"`
+	ScheduledAt     time.Time `json:"scheduled_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Name            string    `json:"name"`
+	Contact         string    `json:"contact"`
+	Message         string    `json:"message"`
+	Status          string    `json:"status"` // pending, confirmed, declined or expired
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// CalendarFeed is the tutor's ICS subscription. Path is relative to the API base
+// URL and is empty while the feed is disabled.
+type CalendarFeed struct {
+	Enabled bool   `json:"enabled"`
+	Token   string `json:"token,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// FeedOwner is the tutor a feed token belongs to.
+type FeedOwner struct {
+	TutorID   string
+	FirstName string
+	LastName  string
+	Timezone  string
+}
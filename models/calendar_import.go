@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Reasons an uploaded event is not turned into lessons.
+const (
+	ImportSkipAlreadyImported = "already_imported"
+	ImportSkipAllDay          = "all_day"
+	ImportSkipCancelled       = "cancelled"
+	ImportSkipPast            = "in_the_past"
+	ImportSkipUnsupportedRule = "unsupported_rrule"
+	ImportSkipInvalid         = "invalid"
+	ImportSkipUnmapped        = "unmapped"
+)
+
+// CalendarImport is the preview of an uploaded .ics file.
+type CalendarImport struct {
+	ID        string        `json:"id"`
+	Events    []ImportEvent `json:"events"`
+	Titles    []ImportTitle `json:"titles"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ImportEvent is one VEVENT as it will be imported: a single lesson, or a series
+// when RRule is set. UID is the VEVENT UID, suffixed with the RECURRENCE-ID for
+// an edited instance of a recurring event.
+type ImportEvent struct {
+	UID             string      `json:"uid"`
+	Title           string      `json:"title"`
+	Notes           string      `json:"notes"`
+	ScheduledAt     time.Time   `json:"scheduled_at"`
+	DurationMinutes int         `json:"duration_minutes"`
+	Timezone        string      `json:"timezone"`
+	RRule           *string     `json:"rrule,omitempty"`
+	ExDates         []time.Time `json:"exdates,omitempty"`
+	// Occurrences is the number of upcoming lessons the event would create.
+	Occurrences int    `json:"occurrences"`
+	Skip        string `json:"skip,omitempty"`
+	Problem     string `json:"problem,omitempty"`
+}
+
+// ImportTitle groups importable events by title. CourseID suggests the course
+// whose subject matches the title, if any.
+type ImportTitle struct {
+	Title    string  `json:"title"`
+	Events   int     `json:"events"`
+	CourseID *string `json:"course_id"`
+}
+
+// CommitImportRequest maps event titles to courses. Events whose title isn't
+// mapped are skipped.
+type CommitImportRequest struct {
+	Mappings []ImportMapping `json:"mappings" validate:"required,min=1,dive"`
+}
+
+// ImportMapping assigns a title either to an existing course or to a course
+// created by the import; exactly one of CourseID and NewCourse is set.
+type ImportMapping struct {
+	Title     string               `json:"title"      validate:"required"`
+	CourseID  *string              `json:"course_id"  validate:"omitempty,uuid"`
+	NewCourse *CreateCourseRequest `json:"new_course"`
+}
+
+type ImportSkipped struct {
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+type ImportResult struct {
+	CoursesCreated int             `json:"courses_created"`
+	LessonsCreated int             `json:"lessons_created"`
+	SeriesCreated  int             `json:"series_created"`
+	Skipped        []ImportSkipped `json:"skipped"`
+}
+
+// ImportPlan is everything a commit writes, in one transaction.
+type ImportPlan struct {
+	NewCourses []ImportNewCourse
+	Items      []ImportItem
+}
+
+type ImportNewCourse struct {
+	Title  string
+	Course CreateCourseRequest
+}
+
+// ImportItem is an event ready to be written. CourseID is empty when the course
+// is one of the plan's NewCourses, matched by Title. Series items carry the
+// materialized occurrences; single lessons use ScheduledAt.
+type ImportItem struct {
+	UID             string
+	Title           string
+	CourseID        string
+	ScheduledAt     time.Time
+	DurationMinutes int
+	Notes           string
+	Series          *LessonSeries
+	Occurrences     []time.Time
+}
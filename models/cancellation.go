@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// How much of a cancelled or missed lesson is billed.
+const (
+	ChargeFull = "full"
+	ChargeHalf = "half"
+	ChargeFree = "free"
+)
+
+type CancellationPolicy struct {
+	// CutoffHours: cancelling later than this before the start is a late cancel.
+	CutoffHours      int    `json:"cutoff_hours"`
+	LateCancelCharge string `json:"late_cancel_charge"`
+	NoShowCharge     string `json:"no_show_charge"`
+}
+
+// CoursePolicy is the policy in effect for a course; Inherited means the course
+// has no override and follows the tutor's.
+type CoursePolicy struct {
+	CancellationPolicy
+	Inherited bool `json:"inherited"`
+}
+
+type SetCancellationPolicyRequest struct {
+	CutoffHours      int    `json:"cutoff_hours"       validate:"gte=0,lte=720"`
+	LateCancelCharge string `json:"late_cancel_charge" validate:"required,oneof=full half free"`
+	NoShowCharge     string `json:"no_show_charge"     validate:"required,oneof=full half free"`
+}
+
+// Who changed a lesson's status. The system actor is the auto-complete job; the
+// student acts through a public link and has no id.
+const (
+	ActorTutor   = "tutor"
+	ActorSystem  = "system"
+	ActorStudent = "student"
+)
+
+type Actor struct {
+	Type string
+	ID   *string // nil for the system
+}
+
+// TutorActor is the tutor acting through the API.
+func TutorActor(tutorID string) Actor {
+	return Actor{Type: ActorTutor, ID: &tutorID}
+}
+
+// StatusChange is the status transition a lesson update makes. From is the
+// status the lesson must still have when the update is written.
+type StatusChange struct {
+	From   string
+	Charge *string
+	Actor  Actor
+	Reason string
+}
+
+// LessonStatusEvent is one entry of a lesson's status timeline. A move keeps the
+// status and fills in PreviousScheduledAt and ScheduledAt.
+type LessonStatusEvent struct {
+	ID         string    `json:"id"`
+	LessonID   string    `json:"lesson_id"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Charge     *string   `json:"charge,omitempty"`
+	Reason     string    `json:"reason"`
+	ActorType  string    `json:"actor_type"`
+	ActorID    *string   `json:"actor_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	PreviousScheduledAt *time.Time `json:"previous_scheduled_at,omitempty"`
+	ScheduledAt         *time.Time `json:"scheduled_at,omitempty"`
+}
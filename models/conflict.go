@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ScheduleOptions are the query flags accepted by endpoints that put lessons or
+// tasks on the calendar.
+type ScheduleOptions struct {
+	// Force writes even when the new slots overlap existing lessons or tasks.
+	Force bool `form:"force"`
+	// DryRun only reports conflicts; nothing is written.
+	DryRun bool `form:"dry_run"`
+}
+
+// TimeSlot is a span of the tutor's time that is about to be occupied.
+type TimeSlot struct {
+	ScheduledAt     time.Time
+	DurationMinutes int
+}
+
+// ScheduleConflict is an existing lesson or task overlapping a requested slot, or
+// another slot of the same request (kind "slot", without an id or title).
+type ScheduleConflict struct {
+	Kind            string    `json:"kind"` // "lesson", "task" or "slot"
+	ID              string    `json:"id"`
+	Title           string    `json:"title"` // course subject or task title
+	ScheduledAt     time.Time `json:"scheduled_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+}
+
+// OccurrenceConflicts lists everything a single requested slot overlaps.
+type OccurrenceConflicts struct {
+	ScheduledAt     time.Time          `json:"scheduled_at"`
+	DurationMinutes int                `json:"duration_minutes"`
+	Conflicts       []ScheduleConflict `json:"conflicts"`
+}
+
+// ConflictExclusion names the items being rewritten so they don't conflict with
+// their own current slots. Empty fields exclude nothing.
+type ConflictExclusion struct {
+	LessonID string
+	SeriesID string
+	TaskID   string
+}
@@ -1,34 +1,64 @@
 package models
 
-import "time"
+import (
+	"time"
+	"tutorgo/money"
+)
 
 type Course struct {
-	ID             string     `json:"id"`
-	StudentID      *string    `json:"student_id"`
-	TutorID        string     `json:"tutor_id"`
-	Subject        string     `json:"subject"`
-	PricePerLesson float64    `json:"price_per_lesson"`
-	StartedAt      time.Time  `json:"started_at"`
-	EndedAt        *time.Time `json:"ended_at"`
+	ID             string       `json:"id"`
+	StudentID      *string      `json:"student_id"`
+	TutorID        string       `json:"tutor_id"`
+	Subject        string       `json:"subject"`
+	PricePerLesson money.Amount `json:"price_per_lesson"`
+	Currency       string       `json:"currency"`
+	StartedAt      time.Time    `json:"started_at"`
+	EndedAt        *time.Time   `json:"ended_at"`
 }
 
+// CourseBalance counts prepaid lessons against charged ones. LessonsCompleted
+// includes missed and late-cancelled lessons as far as the cancellation policy
+// charges them, so it can be fractional. Package lessons left unused when their
+// validity ended are LessonsExpired and no longer remain; Packages details each
+// sold package and subscription month.
 type CourseBalance struct {
-	LessonsPaid      int `json:"lessons_paid"`
-	LessonsCompleted int `json:"lessons_completed"`
-	LessonsRemaining int `json:"lessons_remaining"`
+	LessonsPaid      int             `json:"lessons_paid"`
+	LessonsCompleted float64         `json:"lessons_completed"`
+	LessonsExpired   float64         `json:"lessons_expired"`
+	LessonsRemaining float64         `json:"lessons_remaining"`
+	Packages         []LessonCredits `json:"packages"`
 }
 
+// StudentBalance is CourseBalance for one member of a group course: payments
+// attributed to the student against the lessons they attended or were charged
+// for being absent from.
+type StudentBalance struct {
+	StudentID        string          `json:"student_id"`
+	FirstName        string          `json:"first_name"`
+	LastName         string          `json:"last_name"`
+	LessonsPaid      int             `json:"lessons_paid"`
+	LessonsCompleted float64         `json:"lessons_completed"`
+	LessonsExpired   float64         `json:"lessons_expired"`
+	LessonsRemaining float64         `json:"lessons_remaining"`
+	Packages         []LessonCredits `json:"packages"`
+}
+
+// CreateCourseRequest prices the course in the tutor's home currency when
+// Currency is empty.
 type CreateCourseRequest struct {
-	StudentID      *string    `json:"student_id"       validate:"omitempty,uuid"`
-	Subject        string     `json:"subject"          validate:"required,min=2"`
-	PricePerLesson float64    `json:"price_per_lesson" validate:"required,gt=0"`
-	StartedAt      time.Time  `json:"started_at"       validate:"required"`
-	EndedAt        *time.Time `json:"ended_at"`
+	StudentID      *string      `json:"student_id"       validate:"omitempty,uuid"`
+	Subject        string       `json:"subject"          validate:"required,min=2"`
+	PricePerLesson money.Amount `json:"price_per_lesson" validate:"required,gt=0"`
+	Currency       string       `json:"currency"         validate:"omitempty,iso4217"`
+	StartedAt      time.Time    `json:"started_at"       validate:"required"`
+	EndedAt        *time.Time   `json:"ended_at"`
 }
 
+// UpdateCourseRequest keeps the current currency when Currency is empty.
 type UpdateCourseRequest struct {
-	Subject        string     `json:"subject"          validate:"required,min=2"`
-	PricePerLesson float64    `json:"price_per_lesson" validate:"required,gt=0"`
-	StartedAt      time.Time  `json:"started_at"       validate:"required"`
-	EndedAt        *time.Time `json:"ended_at"`
+	Subject        string       `json:"subject"          validate:"required,min=2"`
+	PricePerLesson money.Amount `json:"price_per_lesson" validate:"required,gt=0"`
+	Currency       string       `json:"currency"         validate:"omitempty,iso4217"`
+	StartedAt      time.Time    `json:"started_at"       validate:"required"`
+	EndedAt        *time.Time   `json:"ended_at"`
 }
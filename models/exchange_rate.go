@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+	"tutorgo/money"
+)
+
+// ExchangeRate is how many units of the tutor's home currency one unit of
+// Currency buys.
+type ExchangeRate struct {
+	Currency  string     `json:"currency"`
+	Rate      money.Rate `json:"rate"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type SetExchangeRateRequest struct {
+	Rate money.Rate `json:"rate"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// Export file formats.
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// ExportOptions say how an export is written: its format and the language of its
+// headers and statuses ("en" or "ru").
+type ExportOptions struct {
+	Format string
+	Lang   string
+}
+
+// LessonExportFilter narrows the lessons exported to those from the date From to
+// To, both included, in the tutor's timezone, and optionally to one status and
+// one course.
+type LessonExportFilter struct {
+	From     string
+	To       string
+	Status   string
+	CourseID *string
+}
+
+// CourseRow is a course with its student's name, nil for a group course.
+type CourseRow struct {
+	Course
+	StudentName *string
+}
+
+// PaymentRow is a payment with the name of the student who paid: the member for
+// a group payment, the course's student otherwise.
+type PaymentRow struct {
+	Payment
+	StudentName *string
+}
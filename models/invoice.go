@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+	"tutorgo/money"
+)
+
+// Invoice statuses. Only draft, sent and paid are stored; a sent invoice past its
+// due date reads as overdue.
+const (
+	InvoiceDraft   = "draft"
+	InvoiceSent    = "sent"
+	InvoicePaid    = "paid"
+	InvoiceOverdue = "overdue"
+)
+
+// Invoice sources.
+const (
+	InvoiceFromLessons = "lessons"
+	InvoiceFromPayment = "payment"
+)
+
+// Invoice bills a student for lessons or a payment package. Number is nil until
+// the invoice is sent. Dates are "YYYY-MM-DD" in the tutor's timezone.
+type Invoice struct {
+	ID        string        `json:"id"`
+	Number    *int          `json:"number"`
+	Status    string        `json:"status"`
+	CourseID  *string       `json:"course_id"`
+	StudentID *string       `json:"student_id"`
+	Currency  string        `json:"currency"`
+	BillTo    string        `json:"bill_to"`
+	Notes     string        `json:"notes"`
+	IssueDate string        `json:"issue_date"`
+	DueDate   string        `json:"due_date"`
+	Total     money.Amount  `json:"total"`
+	PaymentID *string       `json:"payment_id"`
+	CreatedAt time.Time     `json:"created_at"`
+	SentAt    *time.Time    `json:"sent_at"`
+	PaidAt    *time.Time    `json:"paid_at"`
+	Items     []InvoiceItem `json:"items,omitempty"`
+}
+
+// InvoiceItem is one line: a charged lesson, whose Quantity is the charged share
+// of it, or a payment package.
+type InvoiceItem struct {
+	ID          string       `json:"id"`
+	Position    int          `json:"position"`
+	Description string       `json:"description"`
+	Quantity    float64      `json:"quantity"`
+	UnitPrice   money.Amount `json:"unit_price"`
+	Amount      money.Amount `json:"amount"`
+	LessonID    *string      `json:"lesson_id,omitempty"`
+	StudentID   *string      `json:"student_id,omitempty"`
+	PaymentID   *string      `json:"payment_id,omitempty"`
+}
+
+// InvoiceFilter narrows the invoice list; Status may be overdue.
+type InvoiceFilter struct {
+	Status   string `form:"status"`
+	CourseID string `form:"course_id"`
+}
+
+// CreateInvoiceRequest drafts an invoice. From lessons, it bills the course's
+// charged lessons between From and To that no invoice has billed yet; in a group
+// course StudentID picks whose attendance is billed. From a payment, it bills the
+// payment as one line. BillTo defaults to the student's name, IssueDate to today
+// and DueDate to two weeks after it.
+type CreateInvoiceRequest struct {
+	Source    string  `json:"source"     validate:"required,oneof=lessons payment"`
+	CourseID  string  `json:"course_id"  validate:"required_if=Source lessons,omitempty,uuid"`
+	StudentID *string `json:"student_id" validate:"omitempty,uuid"`
+	From      string  `json:"from"       validate:"required_if=Source lessons,omitempty,datetime=2006-01-02"`
+	To        string  `json:"to"         validate:"required_if=Source lessons,omitempty,datetime=2006-01-02"`
+	PaymentID string  `json:"payment_id" validate:"required_if=Source payment,omitempty,uuid"`
+	BillTo    string  `json:"bill_to"    validate:"max=200"`
+	Notes     string  `json:"notes"      validate:"max=1000"`
+	IssueDate string  `json:"issue_date" validate:"omitempty,datetime=2006-01-02"`
+	DueDate   string  `json:"due_date"   validate:"omitempty,datetime=2006-01-02"`
+}
+
+// PayInvoiceRequest marks a sent invoice paid by PaymentID. Without it, the
+// payment the invoice was drafted from is used, or a payment for the total is
+// recorded on the invoice's course. PaidAt defaults to now.
+type PayInvoiceRequest struct {
+	PaymentID *string    `json:"payment_id" validate:"omitempty,uuid"`
+	PaidAt    *time.Time `json:"paid_at"`
+}
@@ -16,14 +16,33 @@ type Lesson struct {
 	DurationMinutes int       `json:"duration_minutes"`
 	Status          string    `json:"status"`
 	Notes           string    `json:"notes"`
-	SeriesID        *string   `json:"series_id,omitempty"`
+	// Homework is shown to the student and their parents; notes are not.
+	Homework string  `json:"homework"`
+	SeriesID *string `json:"series_id,omitempty"`
+	// OriginalScheduledAt is the slot the lesson was planned for before it was moved.
+	OriginalScheduledAt *time.Time `json:"original_scheduled_at,omitempty"`
+	// MakeupFor is the cancelled or missed lesson this one makes up for.
+	MakeupFor *string `json:"makeup_for,omitempty"`
+	// MakeupOwed marks a cancelled or missed lesson as owing the student a makeup.
+	MakeupOwed bool `json:"makeup_owed,omitempty"`
+	// Charge is set on cancelled and missed lessons (see CancellationPolicy).
+	Charge *string `json:"charge,omitempty"`
+	// Warnings are only filled in on create; they never block the write.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
+// Lesson warnings.
+const (
+	WarningOutsideWorkingHours = "outside_working_hours"
+	WarningDuringBlackout      = "during_blackout"
+)
+
 type CreateLessonRequest struct {
 	CourseID        string    `json:"course_id"        validate:"required,uuid"`
 	ScheduledAt     time.Time `json:"scheduled_at"     validate:"required"`
 	DurationMinutes int       `json:"duration_minutes" validate:"required,gt=0"`
 	Notes           string    `json:"notes"            validate:"omitempty,max=500"`
+	MakeupFor       *string   `json:"makeup_for"       validate:"omitempty,uuid"`
 }
 
 type UpdateLessonRequest struct {
@@ -31,15 +50,13 @@ type UpdateLessonRequest struct {
 	DurationMinutes int       `json:"duration_minutes" validate:"required,gt=0"`
 	Status          string    `json:"status"           validate:"omitempty,oneof=scheduled completed cancelled missed"`
 	Notes           string    `json:"notes"            validate:"omitempty,max=500"`
-}
-
-// UpdateSeriesRequest patches all lessons in a series. All fields are optional.
-// NewTime format: "HH:MM" (UTC). FromDate: RFC3339 date used as lower bound.
-type UpdateSeriesRequest struct {
-	FromDate        *string `json:"from_date"`
-	NewTime         *string `json:"new_time"         validate:"omitempty"`
-	DurationMinutes *int    `json:"duration_minutes" validate:"omitempty,gt=0"`
-	Notes           *string `json:"notes"            validate:"omitempty,max=500"`
+	// Reason is stored with the status change, if the update makes one.
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+	// MakeupOwed, when set, grants or takes back a makeup credit for a cancelled
+	// or missed lesson.
+	MakeupOwed *bool `json:"makeup_owed"`
+	// Homework, when set, replaces the lesson's homework.
+	Homework *string `json:"homework" validate:"omitempty,max=2000"`
 }
 
 type CalendarLesson struct {
@@ -53,4 +70,9 @@ type CalendarLesson struct {
 	StudentName     *string   `json:"student_name"`
 	IsGroup         bool      `json:"is_group"`
 	SeriesID        *string   `json:"series_id,omitempty"`
+	LocalDate       string    `json:"local_date"`
+	// OriginalScheduledAt is set on moved lessons.
+	OriginalScheduledAt *time.Time `json:"original_scheduled_at,omitempty"`
+	// MakeupFor is set on makeup lessons.
+	MakeupFor *string `json:"makeup_for,omitempty"`
 }
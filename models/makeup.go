@@ -0,0 +1,10 @@
+package models
+
+// MakeupCredits counts a course's cancelled and missed lessons that owe the student
+// a makeup which hasn't taken place yet. Scheduled is how many of them already
+// have one on the calendar.
+type MakeupCredits struct {
+	CourseID  string `json:"course_id"`
+	Owed      int    `json:"owed"`
+	Scheduled int    `json:"scheduled"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Roles in an organization. Owners and admins run it; tutors teach and see the
+// shared calendar; accountants see income.
+const (
+	OrgRoleOwner      = "owner"
+	OrgRoleAdmin      = "admin"
+	OrgRoleTutor      = "tutor"
+	OrgRoleAccountant = "accountant"
+)
+
+// Organization is a school as one of its members sees it: Role and JoinedAt
+// are theirs, and JoinedAt is nil while they are only invited.
+type Organization struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	Role      string     `json:"role"`
+	JoinedAt  *time.Time `json:"joined_at"`
+}
+
+type OrganizationMember struct {
+	TutorID   string     `json:"tutor_id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	InvitedAt time.Time  `json:"invited_at"`
+	JoinedAt  *time.Time `json:"joined_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role"  validate:"required,oneof=owner admin tutor accountant"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin tutor accountant"`
+}
+
+// AssignTutorRequest moves a student or a course to another member.
+type AssignTutorRequest struct {
+	TutorID string `json:"tutor_id" validate:"required,uuid"`
+}
+
+// OrganizationLesson is a lesson in the school's calendar, with whose it is.
+type OrganizationLesson struct {
+	CalendarLesson
+	TutorID   string `json:"tutor_id"`
+	TutorName string `json:"tutor_name"`
+}
+
+// OrganizationIncome is the month's income of each member, in their own home
+// currency, and the school's totals by currency. The totals are not converted:
+// members keep their own exchange rates.
+type OrganizationIncome struct {
+	ByTutor    []TutorIncome    `json:"by_tutor"`
+	ByCurrency []CurrencyIncome `json:"by_currency"`
+}
+
+type TutorIncome struct {
+	TutorID   string        `json:"tutor_id"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	Income    MonthlyIncome `json:"income"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+	"tutorgo/money"
+)
+
+// Package kinds.
+const (
+	KindPackage      = "package"
+	KindSubscription = "subscription"
+)
+
+// Package is a product the tutor sells: LessonsCount lessons for Price. A package
+// is valid for ValidDays after the sale, or indefinitely when that is nil; a
+// subscription is sold by the month and its lessons are valid for that month.
+type Package struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Kind         string       `json:"kind"`
+	LessonsCount int          `json:"lessons_count"`
+	Price        money.Amount `json:"price"`
+	Currency     string       `json:"currency"`
+	ValidDays    *int         `json:"valid_days"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// CreatePackageRequest prices the package in the tutor's home currency when
+// Currency is empty.
+type CreatePackageRequest struct {
+	Name         string       `json:"name"          validate:"required,min=2,max=100"`
+	Kind         string       `json:"kind"          validate:"required,oneof=package subscription"`
+	LessonsCount int          `json:"lessons_count" validate:"required,gt=0,lte=500"`
+	Price        money.Amount `json:"price"         validate:"required,gt=0"`
+	Currency     string       `json:"currency"      validate:"omitempty,iso4217"`
+	ValidDays    *int         `json:"valid_days"    validate:"omitempty,gt=0,lte=3650"`
+}
+
+// UpdatePackageRequest changes what future sales give; the kind stays. It keeps
+// the current currency when Currency is empty.
+type UpdatePackageRequest struct {
+	Name         string       `json:"name"          validate:"required,min=2,max=100"`
+	LessonsCount int          `json:"lessons_count" validate:"required,gt=0,lte=500"`
+	Price        money.Amount `json:"price"         validate:"required,gt=0"`
+	Currency     string       `json:"currency"      validate:"omitempty,iso4217"`
+	ValidDays    *int         `json:"valid_days"    validate:"omitempty,gt=0,lte=3650"`
+}
+
+// SellPackageRequest records the sale of a package, or of a subscription month,
+// on a course: a payment of its price and lesson credits valid from StartsAt,
+// which defaults to PaidAt. StudentID is the buyer in a group course.
+type SellPackageRequest struct {
+	CourseID  string     `json:"course_id"  validate:"required,uuid"`
+	StudentID *string    `json:"student_id" validate:"omitempty,uuid"`
+	PaidAt    time.Time  `json:"paid_at"    validate:"required"`
+	StartsAt  *time.Time `json:"starts_at"`
+}
+
+// LessonCredits are the lessons a sold package or subscription month gives, and
+// how they have gone: used by charged lessons in their validity, the rest
+// expired once it ended. Ad-hoc payments have no credits of their own.
+type LessonCredits struct {
+	PaymentID        string     `json:"payment_id"`
+	PackageID        *string    `json:"package_id"`
+	Name             string     `json:"name"`
+	Kind             string     `json:"kind"`
+	StudentID        *string    `json:"student_id,omitempty"`
+	LessonsCount     int        `json:"lessons_count"`
+	LessonsUsed      float64    `json:"lessons_used"`
+	LessonsRemaining float64    `json:"lessons_remaining"`
+	LessonsExpired   float64    `json:"lessons_expired"`
+	StartsAt         time.Time  `json:"starts_at"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+// LessonCharge is the part of a lesson held against a balance. StudentID is set
+// for a member of a group course.
+type LessonCharge struct {
+	At        time.Time
+	Share     float64
+	StudentID *string
+}
+
+type PackageSale struct {
+	Payment Payment       `json:"payment"`
+	Credits LessonCredits `json:"credits"`
+}
@@ -1,18 +1,96 @@
 package models
 
-import "time"
+import (
+	"time"
+	"tutorgo/money"
+)
 
+// Payment is the current state of a payment as its ledger entries add up: the
+// last posted amount, lessons and date, what has been refunded since, and whether
+// the payment was voided. CourseID is nil once the course has been deleted;
+// Subject still says what the payment was for.
 type Payment struct {
-	ID           string    `json:"id"`
-	CourseID     string    `json:"course_id"`
-	Amount       float64   `json:"amount"`
-	LessonsCount int       `json:"lessons_count"`
-	PaidAt       time.Time `json:"paid_at"`
+	ID              string       `json:"id"`
+	CourseID        *string      `json:"course_id"`
+	Subject         string       `json:"subject"`
+	StudentID       *string      `json:"student_id,omitempty"`
+	Amount          money.Amount `json:"amount"`
+	Currency        string       `json:"currency"`
+	LessonsCount    int          `json:"lessons_count"`
+	PaidAt          time.Time    `json:"paid_at"`
+	Refunded        money.Amount `json:"refunded"`
+	LessonsRefunded int          `json:"lessons_refunded"`
+	Voided          bool         `json:"voided"`
 }
 
+// Ledger entry kinds.
+const (
+	EntryPayment  = "payment"
+	EntryReversal = "reversal"
+	EntryRefund   = "refund"
+)
+
+// PaymentEntry is one line of a payment's append-only ledger. Amount and
+// LessonsCount are signed: reversals and refunds are negative.
+type PaymentEntry struct {
+	ID           string       `json:"id"`
+	PaymentID    string       `json:"payment_id"`
+	Kind         string       `json:"kind"`
+	Amount       money.Amount `json:"amount"`
+	LessonsCount int          `json:"lessons_count"`
+	EffectiveAt  time.Time    `json:"effective_at"`
+	Reason       string       `json:"reason"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// MonthlyIncome covers the current calendar month: Total is the money received,
+// Earned the price of the lessons charged this month under the cancellation policy.
+// ByCurrency has both per currency; Total and Earned convert them into the tutor's
+// home currency, leaving out the Unconverted currencies that have no exchange rate.
+type MonthlyIncome struct {
+	Currency    string           `json:"currency"`
+	Total       money.Amount     `json:"total"`
+	Earned      money.Amount     `json:"earned"`
+	ByCurrency  []CurrencyIncome `json:"by_currency"`
+	Unconverted []string         `json:"unconverted"`
+}
+
+type CurrencyIncome struct {
+	Currency string       `json:"currency"`
+	Total    money.Amount `json:"total"`
+	Earned   money.Amount `json:"earned"`
+}
+
+// CreatePaymentRequest records the payment in the course's currency when
+// Currency is empty.
 type CreatePaymentRequest struct {
-	CourseID     string    `json:"course_id"     validate:"required,uuid"`
-	Amount       float64   `json:"amount"        validate:"required,gt=0"`
-	LessonsCount int       `json:"lessons_count" validate:"required,gt=0"`
-	PaidAt       time.Time `json:"paid_at"       validate:"required"`
+	CourseID string `json:"course_id"     validate:"required,uuid"`
+	// StudentID attributes the payment to a member of a group course.
+	StudentID    *string      `json:"student_id"    validate:"omitempty,uuid"`
+	Amount       money.Amount `json:"amount"        validate:"required,gt=0"`
+	Currency     string       `json:"currency"      validate:"omitempty,iso4217"`
+	LessonsCount int          `json:"lessons_count" validate:"required,gt=0"`
+	PaidAt       time.Time    `json:"paid_at"       validate:"required"`
+}
+
+// UpdatePaymentRequest corrects a mistyped payment: the last posting is reversed
+// and the new values are posted in its place.
+type UpdatePaymentRequest struct {
+	Amount       money.Amount `json:"amount"        validate:"required,gt=0"`
+	LessonsCount int          `json:"lessons_count" validate:"required,gt=0"`
+	PaidAt       time.Time    `json:"paid_at"       validate:"required"`
+	Reason       string       `json:"reason"        validate:"max=500"`
+}
+
+type VoidPaymentRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// RefundPaymentRequest gives back part or all of a payment. RefundedAt defaults
+// to now.
+type RefundPaymentRequest struct {
+	Amount       money.Amount `json:"amount"        validate:"required,gt=0"`
+	LessonsCount int          `json:"lessons_count" validate:"gte=0"`
+	RefundedAt   *time.Time   `json:"refunded_at"`
+	Reason       string       `json:"reason"        validate:"max=500"`
 }
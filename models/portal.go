@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// Roles of the principals tokens are issued to. Tutors own their data; students
+// and parents see one student's through the portal, read-only.
+const (
+	RoleTutor   = "tutor"
+	RoleStudent = "student"
+	RoleParent  = "parent"
+)
+
+// PortalAccount is a student's or parent's sign-in to the portal. JoinedAt is
+// nil until the invitation is accepted.
+type PortalAccount struct {
+	ID        string     `json:"id"`
+	StudentID string     `json:"student_id"`
+	Role      string     `json:"role"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	InvitedAt time.Time  `json:"invited_at"`
+	JoinedAt  *time.Time `json:"joined_at"`
+	// MailFailed is set by an invitation whose email couldn't be sent. The
+	// account is kept; inviting the email again resends the link.
+	MailFailed bool `json:"mail_failed,omitempty"`
+}
+
+type InvitePortalRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role"  validate:"required,oneof=student parent"`
+	Name  string `json:"name"  validate:"max=100"`
+}
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token"    validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// PortalLoginRequest signs in for one student. StudentID is needed only when the
+// email and password open accounts for several.
+type PortalLoginRequest struct {
+	Email     string `json:"email"      validate:"required,email"`
+	Password  string `json:"password"   validate:"required"`
+	StudentID string `json:"student_id" validate:"omitempty,uuid"`
+}
+
+type PortalLoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Role      string    `json:"role"`
+}
+
+// PortalLogin is a joined account an email signs in with, and the student it is
+// for. A parent of two children has one for each.
+type PortalLogin struct {
+	Account          PortalAccount
+	StudentFirstName string
+	StudentLastName  string
+	PasswordHash     string
+}
+
+// PortalStudent is a student to choose from when signing in.
+type PortalStudent struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// PortalProfile is who is signed in to the portal, for which student of which
+// tutor.
+type PortalProfile struct {
+	Account          PortalAccount `json:"account"`
+	StudentFirstName string        `json:"student_first_name"`
+	StudentLastName  string        `json:"student_last_name"`
+	TutorFirstName   string        `json:"tutor_first_name"`
+	TutorLastName    string        `json:"tutor_last_name"`
+	// Timezone is the student's, or the tutor's when the student has none; the
+	// portal shows times and takes dates in it.
+	Timezone string `json:"timezone"`
+}
+
+// PortalLesson is a lesson as the student and their parents see it: the tutor's
+// notes stay private. CallURL is set on scheduled lessons.
+type PortalLesson struct {
+	ID              string    `json:"id"`
+	CourseID        string    `json:"course_id"`
+	Subject         string    `json:"subject"`
+	ScheduledAt     time.Time `json:"scheduled_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Status          string    `json:"status"`
+	IsGroup         bool      `json:"is_group"`
+	Homework        string    `json:"homework"`
+	CallURL         *string   `json:"call_url,omitempty"`
+}
+
+// PortalBalance is the student's balance on one course: the course's, or in a
+// group their own share of it.
+type PortalBalance struct {
+	CourseID         string  `json:"course_id"`
+	Subject          string  `json:"subject"`
+	IsGroup          bool    `json:"is_group"`
+	LessonsPaid      int     `json:"lessons_paid"`
+	LessonsCompleted float64 `json:"lessons_completed"`
+	LessonsExpired   float64 `json:"lessons_expired"`
+	LessonsRemaining float64 `json:"lessons_remaining"`
+}
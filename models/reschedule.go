@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Reschedule statuses. A direct move is applied at once; a proposal stays open
+// until the student accepts or declines it, or the tutor withdraws it.
+const (
+	RescheduleApplied   = "applied"
+	RescheduleProposed  = "proposed"
+	RescheduleAccepted  = "accepted"
+	RescheduleDeclined  = "declined"
+	RescheduleWithdrawn = "withdrawn"
+)
+
+type Reschedule struct {
+	ID         string     `json:"id"`
+	LessonID   string     `json:"lesson_id"`
+	OriginalAt time.Time  `json:"original_at"`
+	ProposedAt time.Time  `json:"proposed_at"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	// Force is set on proposals made with ?force=true: accepting them skips the
+	// check that the slot is free.
+	Force bool `json:"force"`
+	// Token and Path are set on proposals; the path is what the student opens.
+	Token *string `json:"token,omitempty"`
+	Path  string  `json:"path,omitempty"`
+	// Warnings are only filled in on create; they never block the write.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type RescheduleLessonRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at" validate:"required"`
+	Reason      string    `json:"reason"       validate:"omitempty,max=500"`
+	// Propose sends the new time to the student instead of moving the lesson now.
+	Propose bool `json:"propose"`
+}
+
+// PublicReschedule is what the student sees behind a proposal link. The untagged
+// fields stay on the server.
+type PublicReschedule struct {
+	Subject         string    `json:"subject"`
+	TutorName       string    `json:"tutor_name"`
+	Timezone        string    `json:"timezone"`
+	DurationMinutes int       `json:"duration_minutes"`
+	OriginalAt      time.Time `json:"original_at"`
+	ProposedAt      time.Time `json:"proposed_at"`
+	Reason          string    `json:"reason"`
+	Status          string    `json:"status"`
+
+	ID       string `json:"-"`
+	LessonID string `json:"-"`
+	TutorID  string `json:"-"`
+}
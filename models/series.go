@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// LessonSeries is a recurring lesson rule. RRule is nil for series created from
+// an explicit list of dates; such series can be edited and deleted but not extended.
+type LessonSeries struct {
+	ID              string      `json:"id"`
+	CourseID        string      `json:"course_id"`
+	RRule           *string     `json:"rrule"`
+	DTStart         time.Time   `json:"dtstart"`
+	Timezone        string      `json:"timezone"`
+	DurationMinutes int         `json:"duration_minutes"`
+	Notes           string      `json:"notes"`
+	ExDates         []time.Time `json:"exdates"`
+	GeneratedUntil  time.Time   `json:"generated_until"`
+}
+
+// CreateSeriesRequest describes a series by rule. DTStart and ExDates are wall-clock
+// date-times ("2006-01-02T15:04") in Timezone; RFC3339 values are accepted too.
+// Timezone defaults to the tutor's.
+type CreateSeriesRequest struct {
+	CourseID        string   `json:"course_id"        validate:"required,uuid"`
+	RRule           string   `json:"rrule"            validate:"required"`
+	DTStart         string   `json:"dtstart"          validate:"required"`
+	Timezone        string   `json:"timezone"         validate:"omitempty,timezone"`
+	DurationMinutes int      `json:"duration_minutes" validate:"required,gt=0"`
+	Notes           string   `json:"notes"            validate:"omitempty,max=500"`
+	ExDates         []string `json:"exdates"`
+}
+
+// ExtendSeriesRequest moves the horizon up to which lessons are materialized.
+type ExtendSeriesRequest struct {
+	Until time.Time `json:"until" validate:"required"`
+}
+
+// UpdateSeriesRequest patches a series from FromDate onwards (the whole series if omitted).
+// All fields are optional. NewTime format: "HH:MM" in the series timezone, which is the
+// tutor's zone unless another one was given at creation.
+// Changing RRule regenerates the scheduled lessons from FromDate.
+type UpdateSeriesRequest struct {
+	FromDate        *string `json:"from_date"`
+	NewTime         *string `json:"new_time"         validate:"omitempty"`
+	DurationMinutes *int    `json:"duration_minutes" validate:"omitempty,gt=0"`
+	Notes           *string `json:"notes"            validate:"omitempty,max=500"`
+	RRule           *string `json:"rrule"            validate:"omitempty"`
+}
+
+type SeriesWithLessons struct {
+	Series  LessonSeries `json:"series"`
+	Lessons []Lesson     `json:"lessons"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Session is a device signed in as the tutor. Current marks the one the request
+// comes from.
+type Session struct {
+	ID         string    `json:"id"`
+	TutorID    string    `json:"tutor_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// SessionMeta describes the device signing in or refreshing.
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
@@ -1,28 +1,31 @@
 package models
 
 type Student struct {
-	ID        string `json:"id"`
-	TutorID   string `json:"tutor_id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Phone     string `json:"phone"`
-	Email     string `json:"email"`
-	Notes     string `json:"notes"`
-	Active    bool   `json:"active"`
+	ID        string  `json:"id"`
+	TutorID   string  `json:"tutor_id"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Phone     string  `json:"phone"`
+	Email     string  `json:"email"`
+	Notes     string  `json:"notes"`
+	Active    bool    `json:"active"`
+	Timezone  *string `json:"timezone"`
 }
 
 type CreateStudentRequest struct {
-	FirstName string `json:"first_name" validate:"required,min=2"`
-	LastName  string `json:"last_name"  validate:"omitempty,min=2"`
-	Phone     string `json:"phone"      validate:"omitempty,min=10"`
-	Email     string `json:"email"      validate:"omitempty,email"`
-	Notes     string `json:"notes"      validate:"omitempty,max=500"`
+	FirstName string  `json:"first_name" validate:"required,min=2"`
+	LastName  string  `json:"last_name"  validate:"omitempty,min=2"`
+	Phone     string  `json:"phone"      validate:"omitempty,min=10"`
+	Email     string  `json:"email"      validate:"omitempty,email"`
+	Notes     string  `json:"notes"      validate:"omitempty,max=500"`
+	Timezone  *string `json:"timezone"   validate:"omitempty,timezone"`
 }
 
 type UpdateStudentRequest struct {
-	FirstName string `json:"first_name" validate:"required,min=2"`
-	LastName  string `json:"last_name"  validate:"omitempty,min=2"`
-	Phone     string `json:"phone"      validate:"omitempty,min=10"`
-	Email     string `json:"email"      validate:"omitempty,email"`
-	Notes     string `json:"notes"      validate:"omitempty,max=500"`
+	FirstName string  `json:"first_name" validate:"required,min=2"`
+	LastName  string  `json:"last_name"  validate:"omitempty,min=2"`
+	Phone     string  `json:"phone"      validate:"omitempty,min=10"`
+	Email     string  `json:"email"      validate:"omitempty,email"`
+	Notes     string  `json:"notes"      validate:"omitempty,max=500"`
+	Timezone  *string `json:"timezone"   validate:"omitempty,timezone"`
 }
@@ -0,0 +1,23 @@
+package models
+
+// StudentImport reports an uploaded CSV of students row by row. A dry run only
+// checks the rows; otherwise they are imported together, or not at all when
+// any of them has errors.
+type StudentImport struct {
+	DryRun          bool               `json:"dry_run"`
+	Rows            []StudentImportRow `json:"rows"`
+	Valid           int                `json:"valid"`
+	Invalid         int                `json:"invalid"`
+	StudentsCreated int                `json:"students_created"`
+	CoursesCreated  int                `json:"courses_created"`
+}
+
+// StudentImportRow is one student of the file, on Line counting the header as
+// line 1, with the individual course to create for them if the row has one.
+// Errors are keyed by column, like validation errors of the API.
+type StudentImportRow struct {
+	Line    int                  `json:"line"`
+	Student CreateStudentRequest `json:"student"`
+	Course  *CreateCourseRequest `json:"course,omitempty"`
+	Errors  map[string]string    `json:"errors,omitempty"`
+}
@@ -1,11 +1,26 @@
 package models
 
 type Tutor struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Phone     string `json:"phone"`
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	// EmailVerified is cleared when the email changes.
+	EmailVerified bool   `json:"email_verified"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Phone         string `json:"phone"`
+	Timezone      string `json:"timezone"`
+	Currency      string `json:"currency"`
+	// Requisites are printed on the tutor's invoices.
+	Requisites Requisites `json:"requisites"`
+}
+
+type Requisites struct {
+	LegalName   string `json:"legal_name"   validate:"max=200"`
+	TaxID       string `json:"tax_id"       validate:"max=50"`
+	Address     string `json:"address"      validate:"max=300"`
+	BankName    string `json:"bank_name"    validate:"max=200"`
+	BankAccount string `json:"bank_account" validate:"max=50"`
+	BankCode    string `json:"bank_code"    validate:"max=50"`
 }
 
 type CreateTutorRequest struct {
@@ -13,13 +28,20 @@ type CreateTutorRequest struct {
 	FirstName string `json:"first_name" validate:"required,min=2"`
 	LastName  string `json:"last_name"  validate:"required,min=2"`
 	Phone     string `json:"phone"      validate:"omitempty,min=10"`
+	Timezone  string `json:"timezone"   validate:"omitempty,timezone"`
+	Currency  string `json:"currency"   validate:"omitempty,iso4217"`
 }
 
+// UpdateTutorRequest keeps the current timezone and currency when they are empty,
+// and the current requisites when Requisites is nil.
 type UpdateTutorRequest struct {
-	Email     string `json:"email"      validate:"required,email"`
-	FirstName string `json:"first_name" validate:"required,min=2"`
-	LastName  string `json:"last_name"  validate:"required,min=2"`
-	Phone     string `json:"phone"      validate:"omitempty,min=10"`
+	Email      string      `json:"email"      validate:"required,email"`
+	FirstName  string      `json:"first_name" validate:"required,min=2"`
+	LastName   string      `json:"last_name"  validate:"required,min=2"`
+	Phone      string      `json:"phone"      validate:"omitempty,min=10"`
+	Timezone   string      `json:"timezone"   validate:"omitempty,timezone"`
+	Currency   string      `json:"currency"   validate:"omitempty,iso4217"`
+	Requisites *Requisites `json:"requisites"`
 }
 
 type ChangePasswordRequest struct {
@@ -0,0 +1,50 @@
+package models
+
+// TwoFactorStatus tells whether sign-in asks for a code, and how many recovery
+// codes are left unused.
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorSetup is shown while enrolling: the URI goes into a QR code for the
+// authenticator app, the secret is for typing in by hand.
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorState is what is stored of a tutor's enrollment.
+type TwoFactorState struct {
+	Email   string
+	Secret  string
+	Enabled bool
+}
+
+// RecoveryCodes are shown once, when two-factor authentication is turned on.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// DisableTwoFactorRequest asks for the password and a code again, so a device
+// left signed in is not enough to turn it off.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code"     validate:"required,max=32"`
+}
+
+// TwoFactorChallenge answers a correct password when the account has two-factor
+// authentication: the challenge token and a code then sign in.
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code"            validate:"required,max=32"`
+}
@@ -0,0 +1,230 @@
+// Package money keeps amounts exact from the database to JSON. An Amount is a
+// whole number of hundredths, matching the NUMERIC(10,2) columns it is stored
+// in, so sums never drift the way float64 ones do. A Rate is an exchange rate
+// held as an exact fraction; converting an Amount rounds half away from zero to
+// the nearest hundredth, as Postgres' ROUND does.
+package money
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Amount is a sum of money in hundredths of the currency unit.
+type Amount int64
+
+// Scales of the values written to the database: NUMERIC(10,2) for amounts,
+// NUMERIC(18,8) for rates.
+const (
+	amountScale = 2
+	rateScale   = 8
+)
+
+// FromUnits returns n whole currency units.
+func FromUnits(n int64) Amount {
+	return Amount(n * 100)
+}
+
+// ParseAmount reads a decimal such as "1234.5". More than two decimal places is
+// an error rather than a silent rounding.
+func ParseAmount(s string) (Amount, error) {
+	r, err := parseDecimal(s)
+	if err != nil {
+		return 0, err
+	}
+	cents := new(big.Rat).Mul(r, big.NewRat(100, 1))
+	if !cents.IsInt() {
+		return 0, fmt.Errorf("money: %q has more than %d decimal places", s, amountScale)
+	}
+	if !cents.Num().IsInt64() {
+		return 0, fmt.Errorf("money: %q is out of range", s)
+	}
+	return Amount(cents.Num().Int64()), nil
+}
+
+// String formats the amount with exactly two decimal places, e.g. "-12.30".
+func (a Amount) String() string {
+	sign, n := "", int64(a)
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// MarshalJSON writes the amount as a JSON number, so clients keep reading it the
+// way they read the float64 it replaced.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	v, err := ParseAmount(unquote(b))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner. Values with more than two decimal
+// places, e.g. a price times a half charge, are rounded.
+func (a *Amount) ScanNumeric(v pgtype.Numeric) error {
+	r, err := numericRat(v)
+	if err != nil {
+		return err
+	}
+	cents := round(r, amountScale)
+	if !cents.IsInt64() {
+		return errors.New("money: amount out of range")
+	}
+	*a = Amount(cents.Int64())
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer.
+func (a Amount) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(a)), Exp: -amountScale, Valid: true}, nil
+}
+
+// Convert returns the amount in another currency, r being how many of its units
+// one unit of a's currency buys.
+func (a Amount) Convert(r Rate) Amount {
+	v := new(big.Rat).Mul(big.NewRat(int64(a), 100), r.rat())
+	return Amount(round(v, amountScale).Int64())
+}
+
+// Rate is an exchange rate: how many units of one currency a unit of another
+// buys. The zero Rate is 0.
+type Rate struct {
+	r *big.Rat
+}
+
+// ParseRate reads a decimal such as "0.0021".
+func ParseRate(s string) (Rate, error) {
+	r, err := parseDecimal(s)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{r: r}, nil
+}
+
+func (r Rate) rat() *big.Rat {
+	if r.r == nil {
+		return new(big.Rat)
+	}
+	return r.r
+}
+
+// Sign returns -1, 0 or +1.
+func (r Rate) Sign() int {
+	return r.rat().Sign()
+}
+
+// String formats the rate with up to eight decimal places and no trailing zeros.
+func (r Rate) String() string {
+	s := r.rat().FloatString(rateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MarshalJSON writes the rate as a JSON number.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (r *Rate) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	v, err := ParseRate(unquote(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner.
+func (r *Rate) ScanNumeric(v pgtype.Numeric) error {
+	rat, err := numericRat(v)
+	if err != nil {
+		return err
+	}
+	r.r = rat
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer, rounding to the column's eight
+// decimal places.
+func (r Rate) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: round(r.rat(), rateScale), Exp: -rateScale, Valid: true}, nil
+}
+
+func unquote(b []byte) string {
+	s := string(b)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseDecimal reads a plain or exponent decimal. big.Rat would also take "1/3",
+// which isn't a decimal.
+func parseDecimal(s string) (*big.Rat, error) {
+	if s == "" || strings.Contains(s, "/") {
+		return nil, fmt.Errorf("money: invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("money: invalid decimal %q", s)
+	}
+	return r, nil
+}
+
+func numericRat(v pgtype.Numeric) (*big.Rat, error) {
+	if !v.Valid {
+		return nil, errors.New("money: cannot scan NULL")
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return nil, errors.New("money: cannot scan a non-finite numeric")
+	}
+	r := new(big.Rat).SetInt(v.Int)
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(v.Exp))), nil)
+	if v.Exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(pow)), nil
+	}
+	return r.Quo(r, new(big.Rat).SetInt(pow)), nil
+}
+
+// round returns r scaled by 10^places and rounded half away from zero.
+func round(r *big.Rat, places int) *big.Int {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	num := new(big.Int).Mul(r.Num(), pow)
+	neg := num.Sign() < 0
+	num.Abs(num)
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if m.Mul(m, big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return q
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"tutorgo/money"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]money.Amount{
+		"0":       0,
+		"12":      1200,
+		"1234.5":  123450,
+		"0.1":     10,
+		"-0.01":   -1,
+		"1e3":     100000,
+		"5000.00": 500000,
+	}
+	for in, want := range cases {
+		got, err := money.ParseAmount(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestParseAmount_Errors(t *testing.T) {
+	for _, in := range []string{"", "abc", "1/3", "0.001", "12.345"} {
+		_, err := money.ParseAmount(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	assert.Equal(t, "1234.50", money.Amount(123450).String())
+	assert.Equal(t, "0.05", money.Amount(5).String())
+	assert.Equal(t, "-12.30", money.Amount(-1230).String())
+	assert.Equal(t, "5000.00", money.FromUnits(5000).String())
+}
+
+func TestAmount_JSON(t *testing.T) {
+	var v struct {
+		Amount money.Amount `json:"amount"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 0.1}`), &v))
+	assert.Equal(t, money.Amount(10), v.Amount)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": "19.99"}`), &v))
+	assert.Equal(t, money.Amount(1999), v.Amount)
+
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 19.99}`, string(out))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": 0.333}`), &v))
+}
+
+// Ten payments of 0.10 are exactly 1.00, which float64 doesn't manage.
+func TestAmount_NoDrift(t *testing.T) {
+	var sum money.Amount
+	for i := 0; i < 10; i++ {
+		a, err := money.ParseAmount("0.1")
+		require.NoError(t, err)
+		sum += a
+	}
+	assert.Equal(t, money.FromUnits(1), sum)
+}
+
+func TestAmount_ScanNumeric(t *testing.T) {
+	var a money.Amount
+
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(123450), Exp: -2, Valid: true}))
+	assert.Equal(t, money.Amount(123450), a)
+
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(15), Exp: 2, Valid: true}))
+	assert.Equal(t, money.FromUnits(1500), a)
+
+	// 12.345, e.g. a price times a half charge, rounds half away from zero.
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(12345), Exp: -3, Valid: true}))
+	assert.Equal(t, money.Amount(1235), a)
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(-12345), Exp: -3, Valid: true}))
+	assert.Equal(t, money.Amount(-1235), a)
+
+	assert.Error(t, a.ScanNumeric(pgtype.Numeric{}))
+	assert.Error(t, a.ScanNumeric(pgtype.Numeric{NaN: true, Valid: true}))
+}
+
+func TestAmount_NumericValue(t *testing.T) {
+	n, err := money.Amount(-1230).NumericValue()
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(-1230), n.Int)
+	assert.Equal(t, int32(-2), n.Exp)
+	assert.True(t, n.Valid)
+}
+
+func TestAmount_Convert(t *testing.T) {
+	rate, err := money.ParseRate("0.0021")
+	require.NoError(t, err)
+
+	// 5000.00 KZT at 0.0021 is 10.50.
+	assert.Equal(t, money.Amount(1050), money.FromUnits(5000).Convert(rate))
+	// 0.05 at 0.5 is 0.025, rounded up to 0.03.
+	half, err := money.ParseRate("0.5")
+	require.NoError(t, err)
+	assert.Equal(t, money.Amount(3), money.Amount(5).Convert(half))
+}
+
+func TestRate(t *testing.T) {
+	r, err := money.ParseRate("476.50")
+	require.NoError(t, err)
+	assert.Equal(t, "476.5", r.String())
+	assert.Equal(t, 1, r.Sign())
+
+	n, err := r.NumericValue()
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(47650000000), n.Int)
+	assert.Equal(t, int32(-8), n.Exp)
+
+	var back money.Rate
+	require.NoError(t, back.ScanNumeric(n))
+	assert.Equal(t, "476.5", back.String())
+
+	var v struct {
+		Rate money.Rate `json:"rate"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"rate": "0.0021"}`), &v))
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"rate": 0.0021}`, string(out))
+
+	assert.Equal(t, 0, money.Rate{}.Sign())
+	_, err = money.ParseRate("1/3")
+	assert.Error(t, err)
+}
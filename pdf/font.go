@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Font is what text is set in: Helvetica or a TrueType font from ParseTrueType.
+type Font interface {
+	// Has reports whether the font draws r as itself. Helvetica transliterates
+	// Cyrillic and shows other non-ASCII runes as '?'.
+	Has(r rune) bool
+
+	// encode returns s as a string operand for Tj.
+	encode(s string) string
+	// width is the advance of s in thousandths of the font size.
+	width(s string) float64
+	// write adds the font's objects for the runes the document uses and returns
+	// the id of its font dictionary.
+	write(w *writer, runes []rune) int
+}
+
+// Helvetica is one of the standard PDF fonts every viewer has, so nothing is
+// embedded.
+var Helvetica Font = helvetica{}
+
+type helvetica struct{}
+
+// helveticaWidths are the advances of ASCII 32-126 from the Helvetica AFM.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a-m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n-z
+	334, 260, 334, 584, // { to ~
+}
+
+func (helvetica) Has(r rune) bool {
+	return r >= 32 && r <= 126
+}
+
+// ascii maps s onto what Helvetica can show.
+func (helvetica) ascii(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r == '№':
+			b.WriteString("No.")
+		case r == '\u2014' || r == '\u2013':
+			b.WriteByte('-')
+		case r == '\u00a0' || r == '\t' || r == '\n':
+			b.WriteByte(' ')
+		default:
+			if t, ok := translit[r]; ok {
+				b.WriteString(t)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+func (h helvetica) encode(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return "(" + r.Replace(h.ascii(s)) + ")"
+}
+
+func (h helvetica) width(s string) float64 {
+	var w int
+	for _, c := range h.ascii(s) {
+		w += helveticaWidths[c-32]
+	}
+	return float64(w)
+}
+
+func (helvetica) write(w *writer, _ []rune) int {
+	id := w.alloc()
+	w.object(id, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	return id
+}
+
+// translit spells Russian and Kazakh Cyrillic in Latin letters, so names stay
+// readable in Helvetica.
+var translit = func() map[rune]string {
+	pairs := []string{
+		"А", "A", "Б", "B", "В", "V", "Г", "G", "Д", "D", "Е", "E", "Ё", "Yo", "Ж", "Zh", "З", "Z",
+		"И", "I", "Й", "Y", "К", "K", "Л", "L", "М", "M", "Н", "N", "О", "O", "П", "P", "Р", "R",
+		"С", "S", "Т", "T", "У", "U", "Ф", "F", "Х", "Kh", "Ц", "Ts", "Ч", "Ch", "Ш", "Sh", "Щ", "Shch",
+		"Ъ", "", "Ы", "Y", "Ь", "", "Э", "E", "Ю", "Yu", "Я", "Ya",
+		"Ә", "A", "Ғ", "G", "Қ", "Q", "Ң", "N", "Ө", "O", "Ұ", "U", "Ү", "U", "Һ", "H", "І", "I",
+	}
+	m := make(map[rune]string, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		upper := []rune(pairs[i])[0]
+		m[upper] = pairs[i+1]
+		lower := []rune(strings.ToLower(pairs[i]))[0]
+		m[lower] = strings.ToLower(pairs[i+1])
+	}
+	return m
+}()
+
+// hex16 writes glyph or UTF-16 code units as a hex string operand.
+func hex16(units []uint16) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, u := range units {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
@@ -0,0 +1,149 @@
+// Package pdf writes the small documents TutorGo serves, such as invoices: A4
+// pages of single-font text and rules, in PDF 1.4. Text is set either in the
+// built-in Helvetica, which needs no font file but covers ASCII only, or in a
+// TrueType font embedded whole into the file.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A4 in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Document struct {
+	font  Font
+	pages []*Page
+	used  map[rune]bool
+}
+
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// New starts a document set in font; nil means Helvetica.
+func New(font Font) *Document {
+	if font == nil {
+		font = Helvetica
+	}
+	return &Document{font: font, used: map[rune]bool{}}
+}
+
+func (d *Document) Font() Font {
+	return d.font
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// TextWidth is the width of s set at size, in points.
+func (d *Document) TextWidth(s string, size float64) float64 {
+	return d.font.width(s) * size / 1000
+}
+
+// Text sets s with its baseline starting at (x, y); y grows upwards from the
+// bottom of the page.
+func (p *Page) Text(x, y, size float64, s string) {
+	for _, r := range s {
+		p.doc.used[r] = true
+	}
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s %s Td %s Tj ET\n", num(size), num(x), num(y), p.doc.font.encode(s))
+}
+
+// TextRight sets s so that it ends at right.
+func (p *Page) TextRight(right, y, size float64, s string) {
+	p.Text(right-p.doc.TextWidth(s, size), y, size, s)
+}
+
+// Line draws a rule width points thick.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Gray sets the colour of what follows: 0 is black, 1 white.
+func (p *Page) Gray(level float64) {
+	fmt.Fprintf(&p.content, "%s g %s G\n", num(level), num(level))
+}
+
+// Bytes serializes the document. A document without pages gets one blank page.
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	catalog := w.alloc()
+	pages := w.alloc()
+
+	runes := make([]rune, 0, len(d.used))
+	for r := range d.used {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	font := d.font.write(w, runes)
+
+	kids := make([]string, len(d.pages))
+	for i, p := range d.pages {
+		content := w.alloc()
+		w.stream(content, "", p.content.Bytes())
+		page := w.alloc()
+		w.object(page, fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pages, num(PageWidth), num(PageHeight), font, content))
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	w.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, catalog, xref)
+	return w.buf.Bytes()
+}
+
+// writer lays out numbered objects and remembers where each one starts for the
+// cross-reference table.
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) alloc() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) object(id int, body string) {
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a stream object; dict holds the entries besides /Length.
+func (w *writer) stream(id int, dict string, data []byte) {
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", id, len(data), dict)
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// num formats a coordinate to two decimals, without trailing zeros.
+func num(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
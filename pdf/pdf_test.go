@@ -0,0 +1,146 @@
+package pdf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"regexp"
+	"strconv"
+	"testing"
+	"tutorgo/pdf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Helvetica(t *testing.T) {
+	doc := pdf.New(nil)
+	page := doc.AddPage()
+	page.Text(50, 800, 12, "Invoice (No. 7)")
+	page.Line(50, 790, 545.28, 790, 0.5)
+
+	out := doc.Bytes()
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/BaseFont /Helvetica")
+	assert.Contains(t, string(out), `BT /F1 12 Tf 50 800 Td (Invoice \(No. 7\)) Tj ET`)
+	assert.Contains(t, string(out), "0.5 w 50 790 m 545.28 790 l S")
+	assertXref(t, out)
+}
+
+func TestDocument_HelveticaTransliterates(t *testing.T) {
+	doc := pdf.New(nil)
+	doc.AddPage().Text(0, 0, 10, "Счёт № 5 — Әлия")
+
+	assert.False(t, doc.Font().Has('С'))
+	assert.Contains(t, string(doc.Bytes()), "(Schyot No. 5 - Aliya) Tj")
+}
+
+func TestDocument_TextWidth(t *testing.T) {
+	doc := pdf.New(nil)
+
+	// A is 667 and a space 278 thousandths of the size in Helvetica.
+	assert.InDelta(t, 16.12, doc.TextWidth("A A", 10), 0.001)
+}
+
+func TestDocument_NoPagesGetsBlankPage(t *testing.T) {
+	out := pdf.New(nil).Bytes()
+
+	assert.Contains(t, string(out), "/Count 1")
+	assertXref(t, out)
+}
+
+func TestParseTrueType(t *testing.T) {
+	font, err := pdf.ParseTrueType(testFont())
+	require.NoError(t, err)
+
+	assert.True(t, font.Has('A'))
+	assert.True(t, font.Has('Я'))
+	assert.False(t, font.Has('B'))
+
+	doc := pdf.New(font)
+	// 1000 units per em: A is 600 and Я 700 wide.
+	assert.InDelta(t, 13, doc.TextWidth("AЯ", 10), 0.001)
+
+	doc.AddPage().Text(10, 20, 10, "AЯ")
+	out := string(doc.Bytes())
+	assert.Contains(t, out, "<00010002> Tj")
+	assert.Contains(t, out, "/Encoding /Identity-H")
+	assert.Contains(t, out, "/W [1 [600] 2 [700]]")
+	assert.Contains(t, out, "<0002> <042F>")
+	assertXref(t, []byte(out))
+}
+
+func TestParseTrueType_Rejects(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":      nil,
+		"collection": append([]byte("ttcf"), make([]byte, 12)...),
+		"truncated":  testFont()[:40],
+	} {
+		_, err := pdf.ParseTrueType(data)
+		assert.Error(t, err, name)
+	}
+}
+
+// assertXref checks that every cross-reference entry points at its object.
+func assertXref(t *testing.T, out []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, m)
+	start, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[start:], -1)
+	require.NotEmpty(t, entries)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		assert.True(t, bytes.HasPrefix(out[off:], []byte(strconv.Itoa(i+1)+" 0 obj")), "object %d", i+1)
+	}
+}
+
+// testFont builds the smallest font ParseTrueType accepts: .notdef, A and Я, at
+// 1000 units per em, with a format 4 cmap.
+func testFont() []byte {
+	be := binary.BigEndian
+	u16 := func(vs ...int) []byte {
+		b := make([]byte, 2*len(vs))
+		for i, v := range vs {
+			be.PutUint16(b[2*i:], uint16(v))
+		}
+		return b
+	}
+
+	head := make([]byte, 54)
+	be.PutUint16(head[18:], 1000)
+	copy(head[36:], u16(0, 0xFF38, 1000, 900)) // bbox 0 -200 1000 900
+	hhea := make([]byte, 36)
+	copy(hhea[4:], u16(800, 0xFF38))
+	be.PutUint16(hhea[34:], 3)
+	maxp := u16(0, 0x5000, 3)
+	hmtx := u16(500, 0, 600, 0, 700, 0)
+
+	// Segments A, Я and the closing 0xFFFF; deltas map them onto glyphs 1 and 2.
+	sub := append(u16(4, 0, 0, 6, 0, 0, 0), u16(0x41, 0x42F, 0xFFFF)...)
+	sub = append(sub, u16(0)...)
+	sub = append(sub, u16(0x41, 0x42F, 0xFFFF)...)
+	sub = append(sub, u16(1-0x41, 2-0x42F, 1)...)
+	sub = append(sub, u16(0, 0, 0)...)
+	be.PutUint16(sub[2:], uint16(len(sub)))
+	cmap := append(u16(0, 1, 3, 1), 0, 0, 0, 12)
+	cmap = append(cmap, sub...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"cmap", cmap}, {"head", head}, {"hhea", hhea}, {"hmtx", hmtx}, {"maxp", maxp}}
+	out := append([]byte{0, 1, 0, 0}, u16(len(tables), 0, 0, 0)...)
+	off := len(out) + 16*len(tables)
+	var body []byte
+	for _, tb := range tables {
+		rec := make([]byte, 16)
+		copy(rec, tb.tag)
+		be.PutUint32(rec[8:], uint32(off+len(body)))
+		be.PutUint32(rec[12:], uint32(len(tb.data)))
+		out = append(out, rec...)
+		body = append(body, tb.data...)
+	}
+	return append(out, body...)
+}
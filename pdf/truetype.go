@@ -0,0 +1,289 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// TrueType is a TrueType (glyf-outline) font embedded whole. Text is written as
+// glyph ids (Identity-H) with a ToUnicode map, so it can be searched and copied.
+// A TrueType is read-only after parsing and safe to share between documents.
+type TrueType struct {
+	data       []byte
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	advances   []int
+	glyphs     map[rune]uint16
+}
+
+var errBadFont = errors.New("pdf: not a usable TrueType font")
+
+// ParseTrueType reads the tables needed to lay out and embed the font: head,
+// hhea, hmtx and a Unicode cmap (format 4 or 12).
+func ParseTrueType(data []byte) (*TrueType, error) {
+	if len(data) < 12 {
+		return nil, errBadFont
+	}
+	if v := binary.BigEndian.Uint32(data); v != 0x00010000 && v != 0x74727565 { // 1.0 or 'true'
+		return nil, fmt.Errorf("%w: unsupported sfnt version %#x", errBadFont, v)
+	}
+	tables := map[string][]byte{}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, errBadFont
+		}
+		tag := string(data[rec : rec+4])
+		off := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if off < 0 || length < 0 || off+length > len(data) {
+			return nil, fmt.Errorf("%w: table %q out of bounds", errBadFont, tag)
+		}
+		tables[tag] = data[off : off+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("%w: no %s table", errBadFont, tag)
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errBadFont
+	}
+	f := &TrueType{
+		data:       data,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		bbox: [4]int{
+			int(int16(binary.BigEndian.Uint16(head[36:]))),
+			int(int16(binary.BigEndian.Uint16(head[38:]))),
+			int(int16(binary.BigEndian.Uint16(head[40:]))),
+			int(int16(binary.BigEndian.Uint16(head[42:]))),
+		},
+		ascent:  int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent: int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, errBadFont
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || numMetrics > numGlyphs || len(hmtx) < 4*numMetrics {
+		return nil, errBadFont
+	}
+	f.advances = make([]int, numGlyphs)
+	for g := 0; g < numGlyphs; g++ {
+		m := g
+		if m >= numMetrics {
+			m = numMetrics - 1 // the rest share the last advance
+		}
+		f.advances[g] = int(binary.BigEndian.Uint16(hmtx[4*m:]))
+	}
+
+	glyphs, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.glyphs = glyphs
+	return f, nil
+}
+
+// parseCmap picks the best Unicode subtable: full-repertoire format 12, then BMP
+// format 4.
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errBadFont
+	}
+	var bmp, full []byte
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			return nil, errBadFont
+		}
+		platform := binary.BigEndian.Uint16(cmap[rec:])
+		encoding := binary.BigEndian.Uint16(cmap[rec+2:])
+		off := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+		if off+2 > len(cmap) {
+			return nil, errBadFont
+		}
+		sub := cmap[off:]
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch binary.BigEndian.Uint16(sub) {
+		case 4:
+			bmp = sub
+		case 12:
+			full = sub
+		}
+	}
+	switch {
+	case full != nil:
+		return parseFormat12(full)
+	case bmp != nil:
+		return parseFormat4(bmp)
+	}
+	return nil, fmt.Errorf("%w: no Unicode cmap", errBadFont)
+}
+
+func parseFormat4(t []byte) (map[rune]uint16, error) {
+	if len(t) < 14 {
+		return nil, errBadFont
+	}
+	segs := int(binary.BigEndian.Uint16(t[6:])) / 2
+	ends, starts := 14, 16+2*segs
+	deltas, ranges := starts+2*segs, starts+4*segs
+	if ranges+2*segs > len(t) {
+		return nil, errBadFont
+	}
+	u16 := func(off int) int { return int(binary.BigEndian.Uint16(t[off:])) }
+
+	glyphs := map[rune]uint16{}
+	for i := 0; i < segs; i++ {
+		start, end := u16(starts+2*i), u16(ends+2*i)
+		delta, rangeOff := u16(deltas+2*i), u16(ranges+2*i)
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var g int
+			if rangeOff == 0 {
+				g = (c + delta) & 0xFFFF
+			} else {
+				at := ranges + 2*i + rangeOff + 2*(c-start)
+				if at+2 > len(t) {
+					return nil, errBadFont
+				}
+				if g = u16(at); g != 0 {
+					g = (g + delta) & 0xFFFF
+				}
+			}
+			if g != 0 {
+				glyphs[rune(c)] = uint16(g)
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+func parseFormat12(t []byte) (map[rune]uint16, error) {
+	if len(t) < 16 {
+		return nil, errBadFont
+	}
+	groups := int(binary.BigEndian.Uint32(t[12:]))
+	if 16+12*groups > len(t) {
+		return nil, errBadFont
+	}
+	glyphs := map[rune]uint16{}
+	for i := 0; i < groups; i++ {
+		g := t[16+12*i:]
+		start := binary.BigEndian.Uint32(g)
+		end := binary.BigEndian.Uint32(g[4:])
+		first := binary.BigEndian.Uint32(g[8:])
+		if end < start || end > 0x10FFFF {
+			return nil, errBadFont
+		}
+		for c := start; c <= end; c++ {
+			glyphs[rune(c)] = uint16(first + c - start)
+		}
+	}
+	return glyphs, nil
+}
+
+func (f *TrueType) Has(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// glyph maps r to its glyph; runes the font lacks get .notdef, glyph 0.
+func (f *TrueType) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// scale converts font units to thousandths of an em.
+func (f *TrueType) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+func (f *TrueType) advance(g uint16) int {
+	if int(g) >= len(f.advances) {
+		return 0
+	}
+	return f.scale(f.advances[g])
+}
+
+func (f *TrueType) encode(s string) string {
+	units := make([]uint16, 0, len(s))
+	for _, r := range s {
+		units = append(units, f.glyph(r))
+	}
+	return hex16(units)
+}
+
+func (f *TrueType) width(s string) float64 {
+	var w int
+	for _, r := range s {
+		w += f.advance(f.glyph(r))
+	}
+	return float64(w)
+}
+
+const embeddedName = "/TutorGoEmbedded"
+
+func (f *TrueType) write(w *writer, runes []rune) int {
+	font, cid, desc, file, toUnicode := w.alloc(), w.alloc(), w.alloc(), w.alloc(), w.alloc()
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(f.data)
+	zw.Close()
+	w.stream(file, fmt.Sprintf(" /Length1 %d /Filter /FlateDecode", len(f.data)), compressed.Bytes())
+
+	w.object(desc, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName %s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		embeddedName, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), file))
+
+	// Widths and Unicode mappings only for the glyphs the document uses.
+	var widths, chars strings.Builder
+	seen := map[uint16]bool{}
+	var mappings []string
+	for _, r := range runes {
+		g := f.glyph(r)
+		if g == 0 || seen[g] {
+			continue
+		}
+		seen[g] = true
+		fmt.Fprintf(&widths, "%d [%d] ", g, f.advance(g))
+		mappings = append(mappings, hex16([]uint16{g})+" "+hex16(utf16.Encode([]rune{r})))
+	}
+	w.object(cid, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont %s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		embeddedName, desc, strings.TrimSpace(widths.String())))
+
+	chars.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for len(mappings) > 0 {
+		n := min(len(mappings), 100) // at most 100 entries per block
+		fmt.Fprintf(&chars, "%d beginbfchar\n%s\nendbfchar\n", n, strings.Join(mappings[:n], "\n"))
+		mappings = mappings[n:]
+	}
+	chars.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	w.stream(toUnicode, "", []byte(chars.String()))
+
+	w.object(font, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont %s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		embeddedName, cid, toUnicode))
+	return font
+}
@@ -0,0 +1,273 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules used
+// by lesson series: FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, BYDAY, UNTIL and
+// COUNT. Occurrences keep the wall-clock time of DTSTART in its location, so a
+// 17:00 lesson stays at 17:00 across DST changes.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxOccurrences caps a single expansion so an unbounded rule with a far
+// horizon can't produce an arbitrarily large batch.
+const maxOccurrences = 1000
+
+// ErrTooManyOccurrences is returned by Between when the window holds more
+// occurrences than a single expansion may produce.
+var ErrTooManyOccurrences = fmt.Errorf("rrule: more than %d occurrences", maxOccurrences)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	Until    *time.Time
+	Count    int
+}
+
+// Parse reads an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10".
+// A leading "RRULE:" prefix is accepted.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, errors.New("rrule is empty")
+	}
+
+	rule := Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("rrule: malformed part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(value)); f {
+			case Daily, Weekly, Monthly:
+				rule.Freq = f
+			default:
+				return Rule{}, fmt.Errorf("rrule: unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("rrule: invalid INTERVAL %q", value)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("rrule: invalid COUNT %q", value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = &t
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				wd, ok := weekdayCodes[strings.ToUpper(code)]
+				if !ok {
+					return Rule{}, fmt.Errorf("rrule: unsupported BYDAY %q", code)
+				}
+				// A repeated day would emit the same occurrence twice.
+				if !slices.Contains(rule.ByDay, wd) {
+					rule.ByDay = append(rule.ByDay, wd)
+				}
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return Rule{}, fmt.Errorf("rrule: only WKST=MO is supported")
+			}
+		default:
+			return Rule{}, fmt.Errorf("rrule: unsupported part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, errors.New("rrule: FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return Rule{}, errors.New("rrule: COUNT and UNTIL are mutually exclusive")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return Rule{}, errors.New("rrule: BYDAY is only supported with FREQ=WEEKLY")
+	}
+	return rule, nil
+}
+
+// parseUntil accepts both the DATE form (20260630) and the UTC DATE-TIME form
+// (20260630T235959Z). A bare date means "through the end of that day".
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("rrule: invalid UNTIL %q", value)
+}
+
+// String renders the rule back into canonical RRULE form.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			codes[i] = strings.ToUpper(wd.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Bounded reports whether the rule ends on its own (UNTIL or COUNT).
+func (r Rule) Bounded() bool {
+	return r.Until != nil || r.Count > 0
+}
+
+// Between returns occurrences t with from <= t < to. dtstart carries the
+// location the rule is evaluated in. COUNT is applied from dtstart, so
+// occurrences before from still consume the count. A window holding more than
+// maxOccurrences occurrences is rejected with ErrTooManyOccurrences rather
+// than cut short.
+func (r Rule) Between(dtstart, from, to time.Time) ([]time.Time, error) {
+	var out []time.Time
+	tooMany := false
+	r.iterate(dtstart, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) {
+			if len(out) == maxOccurrences {
+				tooMany = true
+				return false
+			}
+			out = append(out, t)
+		}
+		return true
+	})
+	if tooMany {
+		return nil, ErrTooManyOccurrences
+	}
+	return out, nil
+}
+
+// Last returns the latest occurrence before the given time, or false when the
+// rule has none.
+func (r Rule) Last(dtstart, before time.Time) (time.Time, bool) {
+	var last time.Time
+	found := false
+	r.iterate(dtstart, func(t time.Time) bool {
+		if !t.Before(before) {
+			return false
+		}
+		last, found = t, true
+		return true
+	})
+	return last, found
+}
+
+// iterate walks occurrences in chronological order until fn returns false or
+// the rule is exhausted.
+func (r Rule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if t.Before(dtstart) {
+			return true
+		}
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+
+	switch r.Freq {
+	case Daily:
+		for i := 0; ; i += interval {
+			if !emit(time.Date(y, m, d+i, hh, mm, ss, 0, loc)) {
+				return
+			}
+		}
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{dtstart.Weekday()}
+		}
+		offsets := make([]int, 0, len(days))
+		for _, wd := range days {
+			offsets = append(offsets, mondayOffset(wd))
+		}
+		sort.Ints(offsets)
+		weekStart := d - mondayOffset(dtstart.Weekday())
+		for week := 0; ; week += interval {
+			for _, off := range offsets {
+				if !emit(time.Date(y, m, weekStart+week*7+off, hh, mm, ss, 0, loc)) {
+					return
+				}
+			}
+		}
+	case Monthly:
+		// Months without the DTSTART day (e.g. the 31st) are skipped, as RFC 5545 requires.
+		for i, skipped := 0, 0; skipped < 12; i += interval {
+			t := time.Date(y, m+time.Month(i), d, hh, mm, ss, 0, loc)
+			if t.Day() != d {
+				skipped++
+				continue
+			}
+			skipped = 0
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+func mondayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
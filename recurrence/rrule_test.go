@@ -0,0 +1,173 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+	"tutorgo/recurrence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	rule, err := recurrence.Parse("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10")
+
+	require.NoError(t, err)
+	assert.Equal(t, recurrence.Weekly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, []time.Weekday{time.Monday, time.Thursday}, rule.ByDay)
+	assert.Equal(t, 10, rule.Count)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10", rule.String())
+}
+
+func TestParse_RepeatedByDay(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=WEEKLY;BYDAY=MO,MO;COUNT=2")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Weekday{time.Monday}, rule.ByDay)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO;COUNT=2", rule.String())
+
+	dtstart := time.Date(2026, time.May, 4, 10, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 1, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.May, 4, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 11, 10, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;COUNT=3;UNTIL=20260601",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;INTERVAL=0",
+	}
+	for _, c := range cases {
+		_, err := recurrence.Parse(c)
+		assert.Error(t, err, c)
+	}
+}
+
+func TestBetween_WeeklyByDayCount(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4")
+	require.NoError(t, err)
+
+	// Thursday 2026-05-07 17:00 UTC: the Monday of that week is before DTSTART and must be skipped.
+	dtstart := time.Date(2026, time.May, 7, 17, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.May, 7, 17, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 11, 17, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 14, 17, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 18, 17, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestBetween_BiweeklyUntil(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=WEEKLY;INTERVAL=2;UNTIL=20260601")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2026, time.May, 4, 10, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.May, 4, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 18, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, time.June, 1, 10, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestBetween_CountConsumedBeforeFrom(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY;COUNT=5")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart.AddDate(0, 0, 3), dtstart.AddDate(0, 1, 0))
+	require.NoError(t, err)
+
+	assert.Len(t, got, 2)
+}
+
+func TestBetween_KeepsWallClockAcrossDST(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	rule, err := recurrence.Parse("FREQ=WEEKLY")
+	require.NoError(t, err)
+
+	// DST in Berlin starts on 2026-03-29.
+	dtstart := time.Date(2026, time.March, 23, 17, 0, 0, 0, berlin)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 14))
+	require.NoError(t, err)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, 17, got[0].Hour())
+	assert.Equal(t, 17, got[1].Hour())
+	assert.Equal(t, 16, got[0].UTC().Hour())
+	assert.Equal(t, 15, got[1].UTC().Hour())
+}
+
+func TestBetween_MonthlySkipsShortMonths(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=MONTHLY;COUNT=3")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2026, time.January, 31, 12, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.January, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 31, 12, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestBetween_UnboundedStopsAtHorizon(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 10))
+	require.NoError(t, err)
+
+	assert.Len(t, got, 10)
+	assert.False(t, rule.Bounded())
+}
+
+func TestBetween_TooManyOccurrences(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)
+	got, err := rule.Between(dtstart, dtstart, dtstart.AddDate(3, 0, 0))
+
+	assert.ErrorIs(t, err, recurrence.ErrTooManyOccurrences)
+	assert.Empty(t, got)
+}
+
+func TestLast_BeforeCutoff(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY")
+	require.NoError(t, err)
+
+	// Further back than a single expansion may reach.
+	dtstart := time.Date(2020, time.January, 1, 9, 0, 0, 0, time.UTC)
+	last, ok := rule.Last(dtstart, time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC))
+
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, time.April, 30, 9, 0, 0, 0, time.UTC), last)
+
+	_, ok = rule.Last(dtstart, dtstart)
+	assert.False(t, ok)
+}
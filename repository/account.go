@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AccountRepository interface {
+	CreateToken(ctx context.Context, tutorID string, purpose string, email string, expiresAt time.Time) (string, error)
+	VerifyEmail(ctx context.Context, tokenID string) (string, error)
+	ResetPassword(ctx context.Context, tokenID string, passwordHash string) (string, error)
+}
+
+type accountRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewAccountRepository(conn *pgxpool.Pool) AccountRepository {
+	return &accountRepository{conn: conn}
+}
+
+func (r *accountRepository) CreateToken(ctx context.Context, tutorID string, purpose string, email string, expiresAt time.Time) (string, error) {
+	var id string
+	err := r.conn.QueryRow(ctx,
+		`INSERT INTO account_tokens (tutor_id, purpose, email, expires_at)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id`,
+		tutorID, purpose, email, expiresAt,
+	).Scan(&id)
+	return id, err
+}
+
+// useToken marks the token used, returning its tutor and the email it was sent
+// to; pgx.ErrNoRows if it is not one of purpose, already used or expired.
+func useToken(ctx context.Context, tx pgx.Tx, tokenID string, purpose string) (string, string, error) {
+	var tutorID, email string
+	err := tx.QueryRow(ctx,
+		`UPDATE account_tokens SET used_at = NOW()
+		 WHERE id = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		 RETURNING tutor_id, email`,
+		tokenID, purpose,
+	).Scan(&tutorID, &email)
+	return tutorID, email, err
+}
+
+// VerifyEmail uses a verify_email token and marks the tutor's email verified,
+// returning the tutor; pgx.ErrNoRows if the token can't be used or the email has
+// changed since it was sent.
+func (r *accountRepository) VerifyEmail(ctx context.Context, tokenID string) (string, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	tutorID, email, err := useToken(ctx, tx, tokenID, models.TokenVerifyEmail)
+	if err != nil {
+		return "", err
+	}
+	tag, err := tx.Exec(ctx,
+		`UPDATE tutors SET email_verified_at = COALESCE(email_verified_at, NOW())
+		 WHERE id = $1 AND email = $2`, tutorID, email)
+	if err != nil {
+		return "", err
+	}
+	if tag.RowsAffected() == 0 {
+		return "", pgx.ErrNoRows
+	}
+	return tutorID, tx.Commit(ctx)
+}
+
+// ResetPassword uses a reset_password token to set the tutor's password, then
+// revokes every session and every other reset link of theirs. It returns the
+// tutor; pgx.ErrNoRows if the token can't be used.
+func (r *accountRepository) ResetPassword(ctx context.Context, tokenID string, passwordHash string) (string, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	tutorID, _, err := useToken(ctx, tx, tokenID, models.TokenResetPassword)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE tutors SET password_hash = $1 WHERE id = $2`, passwordHash, tutorID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE sessions SET revoked_at = NOW()
+		 WHERE tutor_id = $1 AND revoked_at IS NULL`, tutorID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE account_tokens SET used_at = NOW()
+		 WHERE tutor_id = $1 AND purpose = 'reset_password' AND used_at IS NULL`, tutorID); err != nil {
+		return "", err
+	}
+	return tutorID, tx.Commit(ctx)
+}
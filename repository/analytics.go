@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AnalyticsRepository interface {
+	GetIncome(ctx context.Context, tutorID string, from string, to string, bucket string) (models.IncomeAnalytics, error)
+	GetWorkload(ctx context.Context, tutorID string, from string, to string) (models.Workload, error)
+	GetWorkloadSummary(ctx context.Context, tutorID string, from string, to string) (models.WorkloadSummary, error)
+}
+
+type analyticsRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewAnalyticsRepository(conn *pgxpool.Pool) AnalyticsRepository {
+	return &analyticsRepository{conn: conn}
+}
+
+// incomeFacts are the CTEs the income queries share, for tutor $1 and the local
+// dates $2 to $3:
+//
+//   - rates: how many home currency units a unit of each currency buys;
+//   - accounts: the prepaid lessons left on each individual course and on each
+//     member's account in a group course, negative when in debt;
+//   - facts: one row per ledger entry, charged lesson and forecast lesson of the
+//     period, with its local date and what it is for.
+//
+// A forecast lesson is a scheduled lesson past the ones its account has prepaid,
+// taken in order; makeups are left out as they replace a lesson already charged.
+const incomeFacts = `WITH tutor AS (
+	SELECT id, timezone, currency,
+	       $2::date::timestamp AT TIME ZONE timezone AS start,
+	       ($3::date + 1)::timestamp AT TIME ZONE timezone AS "end"
+	FROM tutors WHERE id = $1
+),
+rates AS (
+	SELECT currency, rate FROM exchange_rates WHERE tutor_id = $1
+	UNION ALL
+	SELECT currency, 1 FROM tutor
+),
+accounts AS (
+	SELECT c.id AS course_id, c.subject, c.currency, c.price_per_lesson,
+	       COALESCE(e.student_id, c.student_id) AS student_id,
+	       COALESCE((SELECT SUM(pe.lessons_count) FROM payment_entries pe
+	                 JOIN payments p ON p.id = pe.payment_id
+	                 WHERE p.course_id = c.id AND (e.student_id IS NULL OR p.student_id = e.student_id)), 0)
+	       - COALESCE(CASE
+	             WHEN e.student_id IS NULL THEN
+	                 (SELECT SUM(` + chargedShare + `) FROM lessons l WHERE l.course_id = c.id)
+	             ELSE
+	                 (SELECT SUM(` + attendedShare + `)
+	                  FROM lesson_attendances a
+	                  JOIN lessons l ON l.id = a.lesson_id
+	                  WHERE l.course_id = c.id AND a.student_id = e.student_id)
+	         END, 0) AS remaining
+	FROM courses c
+	LEFT JOIN course_enrollments e ON e.course_id = c.id AND c.student_id IS NULL
+	WHERE c.tutor_id = $1 AND COALESCE(e.student_id, c.student_id) IS NOT NULL
+),
+facts AS (
+	SELECT (e.effective_at AT TIME ZONE t.timezone)::date AS local_date,
+	       p.course_id, COALESCE(c.subject, p.subject) AS subject,
+	       COALESCE(p.student_id, c.student_id) AS student_id, p.currency,
+	       e.amount AS received, 0 AS earned, 0 AS forecast
+	FROM payment_entries e
+	JOIN payments p ON p.id = e.payment_id
+	LEFT JOIN courses c ON c.id = p.course_id
+	CROSS JOIN tutor t
+	WHERE p.tutor_id = $1 AND e.effective_at >= t.start AND e.effective_at < t."end"
+	UNION ALL
+	SELECT (l.scheduled_at AT TIME ZONE t.timezone)::date, c.id, c.subject,
+	       COALESCE(a.student_id, c.student_id), c.currency,
+	       0, c.price_per_lesson * (CASE WHEN c.student_id IS NULL THEN ` + attendedShare + ` ELSE ` + chargedShare + ` END), 0
+	FROM lessons l
+	JOIN courses c ON c.id = l.course_id
+	CROSS JOIN tutor t
+	LEFT JOIN lesson_attendances a ON a.lesson_id = l.id AND c.student_id IS NULL
+	WHERE c.tutor_id = $1 AND l.scheduled_at >= t.start AND l.scheduled_at < t."end"
+	  AND COALESCE(a.student_id, c.student_id) IS NOT NULL
+	UNION ALL
+	SELECT (u.scheduled_at AT TIME ZONE t.timezone)::date, u.course_id, u.subject, u.student_id, u.currency,
+	       0, 0, u.price_per_lesson * LEAST(1, u.n - GREATEST(u.remaining, 0))
+	FROM (
+	    SELECT ac.*, l.scheduled_at,
+	           ROW_NUMBER() OVER (PARTITION BY ac.course_id, ac.student_id ORDER BY l.scheduled_at) AS n
+	    FROM accounts ac
+	    JOIN lessons l ON l.course_id = ac.course_id
+	    WHERE l.status = 'scheduled' AND l.makeup_for IS NULL
+	) u
+	CROSS JOIN tutor t
+	WHERE u.n > GREATEST(u.remaining, 0) AND u.scheduled_at >= t.start AND u.scheduled_at < t."end"
+)
+`
+
+// GetIncome computes the income analytics in one snapshot. The amounts are
+// converted into the home currency by the tutor's exchange rates.
+func (r *analyticsRepository) GetIncome(ctx context.Context, tutorID string, from string, to string, bucket string) (models.IncomeAnalytics, error) {
+	income := models.IncomeAnalytics{
+		From: from, To: to, Bucket: bucket,
+		Buckets:   []models.IncomeBucket{},
+		BySubject: []models.IncomeBreakdown{}, ByCourse: []models.IncomeBreakdown{}, ByStudent: []models.IncomeBreakdown{},
+		Debts:       []models.StudentDebt{},
+		Unconverted: []string{},
+	}
+
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx,
+		`SELECT currency FROM tutors WHERE id = $1`, tutorID,
+	).Scan(&income.Currency); err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	if err := getIncomeBuckets(ctx, tx, tutorID, from, to, bucket, &income); err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	if err := getIncomeBreakdowns(ctx, tx, tutorID, from, to, &income); err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	if err := getDebts(ctx, tx, tutorID, from, to, &income); err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+
+	rows, err := tx.Query(ctx, incomeFacts+`
+		SELECT currency FROM (
+		    SELECT currency FROM facts WHERE received <> 0 OR earned <> 0 OR forecast <> 0
+		    UNION
+		    SELECT currency FROM accounts WHERE remaining < 0
+		) c
+		WHERE currency NOT IN (SELECT currency FROM rates)
+		ORDER BY currency`,
+		tutorID, from, to)
+	if err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var currency string
+		if err := rows.Scan(&currency); err != nil {
+			return models.IncomeAnalytics{}, err
+		}
+		income.Unconverted = append(income.Unconverted, currency)
+	}
+	if err := rows.Err(); err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	return income, tx.Commit(ctx)
+}
+
+// getIncomeBuckets fills the buckets of the period, empty ones included, and the
+// period's totals from the rollup row.
+func getIncomeBuckets(ctx context.Context, tx pgx.Tx, tutorID, from, to, bucket string, income *models.IncomeAnalytics) error {
+	rows, err := tx.Query(ctx, incomeFacts+`
+		SELECT to_char(b.start, 'YYYY-MM-DD'),
+		       COALESCE(ROUND(SUM(f.received * r.rate), 2), 0),
+		       COALESCE(ROUND(SUM(f.earned * r.rate), 2), 0),
+		       COALESCE(ROUND(SUM(f.forecast * r.rate), 2), 0)
+		FROM generate_series(date_trunc($4, $2::date::timestamp), $3::date::timestamp, ('1 ' || $4)::interval) b(start)
+		LEFT JOIN facts f ON date_trunc($4, f.local_date::timestamp) = b.start
+		LEFT JOIN rates r ON r.currency = f.currency
+		GROUP BY ROLLUP (b.start)
+		ORDER BY b.start NULLS LAST`,
+		tutorID, from, to, bucket)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var start *string
+		var b models.IncomeBucket
+		if err := rows.Scan(&start, &b.Received, &b.Earned, &b.Forecast); err != nil {
+			return err
+		}
+		if start == nil {
+			income.Received, income.Earned, income.Forecast = b.Received, b.Earned, b.Forecast
+			continue
+		}
+		b.Start = *start
+		income.Buckets = append(income.Buckets, b)
+	}
+	return rows.Err()
+}
+
+// getIncomeBreakdowns groups the period's income by subject, by course and by
+// student, largest first.
+func getIncomeBreakdowns(ctx context.Context, tx pgx.Tx, tutorID, from, to string, income *models.IncomeAnalytics) error {
+	rows, err := tx.Query(ctx, incomeFacts+`
+		SELECT g.dimension, COALESCE(g.student_id, g.course_id),
+		       CASE g.dimension
+		           WHEN 'student' THEN COALESCE(s.first_name || ' ' || s.last_name, '')
+		           ELSE g.subject
+		       END,
+		       g.received, g.earned, g.forecast
+		FROM (
+		    SELECT CASE
+		               WHEN GROUPING(f.student_id) = 0 THEN 'student'
+		               WHEN GROUPING(f.course_id) = 0 THEN 'course'
+		               ELSE 'subject'
+		           END AS dimension,
+		           f.course_id, f.subject, f.student_id,
+		           COALESCE(ROUND(SUM(f.received * r.rate), 2), 0) AS received,
+		           COALESCE(ROUND(SUM(f.earned * r.rate), 2), 0) AS earned,
+		           COALESCE(ROUND(SUM(f.forecast * r.rate), 2), 0) AS forecast
+		    FROM facts f
+		    LEFT JOIN rates r ON r.currency = f.currency
+		    GROUP BY GROUPING SETS ((f.subject), (f.course_id, f.subject), (f.student_id))
+		) g
+		LEFT JOIN students s ON s.id = g.student_id
+		ORDER BY g.received + g.earned DESC, 3`,
+		tutorID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dimension string
+		var b models.IncomeBreakdown
+		if err := rows.Scan(&dimension, &b.ID, &b.Name, &b.Received, &b.Earned, &b.Forecast); err != nil {
+			return err
+		}
+		switch dimension {
+		case "subject":
+			income.BySubject = append(income.BySubject, b)
+		case "course":
+			income.ByCourse = append(income.ByCourse, b)
+		default:
+			income.ByStudent = append(income.ByStudent, b)
+		}
+	}
+	return rows.Err()
+}
+
+// getDebts lists the accounts in debt, largest first, and totals what converts.
+func getDebts(ctx context.Context, tx pgx.Tx, tutorID, from, to string, income *models.IncomeAnalytics) error {
+	rows, err := tx.Query(ctx, incomeFacts+`
+		SELECT ac.course_id, ac.subject, ac.student_id, s.first_name || ' ' || s.last_name,
+		       (-ac.remaining)::float8, ac.currency,
+		       ROUND(-ac.remaining * ac.price_per_lesson, 2),
+		       ROUND(-ac.remaining * ac.price_per_lesson * r.rate, 2)
+		FROM accounts ac
+		JOIN students s ON s.id = ac.student_id
+		LEFT JOIN rates r ON r.currency = ac.currency
+		WHERE ac.remaining < 0
+		ORDER BY 8 DESC NULLS LAST, 4`,
+		tutorID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.StudentDebt
+		if err := rows.Scan(&d.CourseID, &d.Subject, &d.StudentID, &d.StudentName, &d.Lessons, &d.Currency, &d.Amount, &d.Converted); err != nil {
+			return err
+		}
+		if d.Converted != nil {
+			income.Debt += *d.Converted
+		}
+		income.Debts = append(income.Debts, d)
+	}
+	return rows.Err()
+}
+
+// workloadLessons are the CTEs the workload queries share: the tutor's lessons
+// between $2 and $3, read as by GetCalendar, and who took part in them. An
+// individual course's lessons are its student's; a group lesson is each member's
+// with an attendance record or, before attendance was taken, each enrolled one's.
+var workloadLessons = `WITH bounds AS (
+	SELECT t.timezone, ` + localBound("$2") + ` AS start, ` + localBound("$3") + ` AS "end"
+	FROM tutors t WHERE t.id = $1
+),
+lessons_in AS (
+	SELECT l.id, l.course_id, l.status, l.duration_minutes, c.student_id AS course_student,
+	       l.scheduled_at AT TIME ZONE t.timezone AS local_at
+	FROM lessons l
+	JOIN courses c ON c.id = l.course_id
+	JOIN tutors t ON t.id = c.tutor_id
+	CROSS JOIN bounds p
+	WHERE c.tutor_id = $1 AND l.status <> 'pending'
+	  AND l.scheduled_at >= p.start AND l.scheduled_at < p."end"
+),
+participations AS (
+	SELECT li.id, li.course_student AS student_id, li.status::text AS status, li.duration_minutes
+	FROM lessons_in li
+	WHERE li.course_student IS NOT NULL
+	UNION ALL
+	SELECT li.id, a.student_id,
+	       CASE WHEN li.status = 'completed' AND a.status = 'absent' THEN 'missed' ELSE li.status END,
+	       li.duration_minutes
+	FROM lessons_in li
+	JOIN lesson_attendances a ON a.lesson_id = li.id
+	WHERE li.course_student IS NULL
+	UNION ALL
+	SELECT li.id, e.student_id, li.status, li.duration_minutes
+	FROM lessons_in li
+	JOIN course_enrollments e ON e.course_id = li.course_id
+	WHERE li.course_student IS NULL
+	  AND NOT EXISTS (SELECT 1 FROM lesson_attendances a WHERE a.lesson_id = li.id)
+)
+`
+
+// statusCounts counts rows of a lessons_in or participations source by status,
+// with the hours of the completed ones.
+const statusCounts = `COUNT(*) FILTER (WHERE status = 'scheduled')::int,
+	COUNT(*) FILTER (WHERE status = 'completed')::int,
+	COUNT(*) FILTER (WHERE status = 'cancelled')::int,
+	COUNT(*) FILTER (WHERE status = 'missed')::int,
+	(COALESCE(SUM(duration_minutes) FILTER (WHERE status = 'completed'), 0) / 60.0)::float8`
+
+// GetWorkload reports the lessons from $2 up to $3, as instants or local dates,
+// by week, by student and by weekday and hour. Rates are left to the caller.
+func (r *analyticsRepository) GetWorkload(ctx context.Context, tutorID string, from string, to string) (models.Workload, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return models.Workload{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var w models.Workload
+	if w.Summary, err = getWorkloadSummary(ctx, tx, tutorID, from, to); err != nil {
+		return models.Workload{}, err
+	}
+	if w.Weeks, err = getWorkloadWeeks(ctx, tx, tutorID, from, to); err != nil {
+		return models.Workload{}, err
+	}
+	if w.Students, err = getStudentWorkloads(ctx, tx, tutorID, from, to); err != nil {
+		return models.Workload{}, err
+	}
+	if w.Heatmap, err = getHeatmap(ctx, tx, tutorID, from, to); err != nil {
+		return models.Workload{}, err
+	}
+	return w, tx.Commit(ctx)
+}
+
+// getWorkloadWeeks counts every week of the period, empty ones included.
+func getWorkloadWeeks(ctx context.Context, tx pgx.Tx, tutorID, from, to string) ([]models.WorkloadWeek, error) {
+	rows, err := tx.Query(ctx, workloadLessons+`
+		SELECT to_char(wk.start, 'YYYY-MM-DD'),
+		       COUNT(li.id) FILTER (WHERE li.status = 'scheduled')::int,
+		       COUNT(li.id) FILTER (WHERE li.status = 'completed')::int,
+		       COUNT(li.id) FILTER (WHERE li.status = 'cancelled')::int,
+		       COUNT(li.id) FILTER (WHERE li.status = 'missed')::int,
+		       (COALESCE(SUM(li.duration_minutes) FILTER (WHERE li.status = 'completed'), 0) / 60.0)::float8
+		FROM bounds p
+		CROSS JOIN generate_series(date_trunc('week', p.start AT TIME ZONE p.timezone),
+		                           p."end" AT TIME ZONE p.timezone - interval '1 microsecond',
+		                           interval '1 week') wk(start)
+		LEFT JOIN lessons_in li ON date_trunc('week', li.local_at) = wk.start
+		GROUP BY wk.start
+		ORDER BY wk.start`,
+		tutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weeks := []models.WorkloadWeek{}
+	for rows.Next() {
+		var wk models.WorkloadWeek
+		if err := rows.Scan(&wk.Start, &wk.Scheduled, &wk.Completed, &wk.Cancelled, &wk.Missed, &wk.HoursTaught); err != nil {
+			return nil, err
+		}
+		weeks = append(weeks, wk)
+	}
+	return weeks, rows.Err()
+}
+
+// getStudentWorkloads counts each student's lessons, busiest first.
+func getStudentWorkloads(ctx context.Context, tx pgx.Tx, tutorID, from, to string) ([]models.StudentWorkload, error) {
+	rows, err := tx.Query(ctx, workloadLessons+`
+		SELECT pa.student_id,
+		       CASE WHEN s.last_name = '' THEN s.first_name ELSE s.first_name || ' ' || s.last_name END,
+		       COUNT(*)::int, `+statusCounts+`
+		FROM participations pa
+		JOIN students s ON s.id = pa.student_id
+		GROUP BY pa.student_id, s.first_name, s.last_name
+		ORDER BY COUNT(*) DESC, 2`,
+		tutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	students := []models.StudentWorkload{}
+	for rows.Next() {
+		var sw models.StudentWorkload
+		var scheduled int
+		if err := rows.Scan(&sw.StudentID, &sw.Name, &sw.Lessons, &scheduled, &sw.Completed, &sw.Cancelled, &sw.Missed, &sw.HoursTaught); err != nil {
+			return nil, err
+		}
+		students = append(students, sw)
+	}
+	return students, rows.Err()
+}
+
+// getHeatmap counts the lessons that were not cancelled by local weekday and
+// starting hour; empty cells are left out.
+func getHeatmap(ctx context.Context, tx pgx.Tx, tutorID, from, to string) ([]models.HeatmapCell, error) {
+	rows, err := tx.Query(ctx, workloadLessons+`
+		SELECT EXTRACT(ISODOW FROM local_at)::int, EXTRACT(HOUR FROM local_at)::int,
+		       COUNT(*)::int, (SUM(duration_minutes) / 60.0)::float8
+		FROM lessons_in
+		WHERE status <> 'cancelled'
+		GROUP BY 1, 2
+		ORDER BY 1, 2`,
+		tutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := []models.HeatmapCell{}
+	for rows.Next() {
+		var cell models.HeatmapCell
+		if err := rows.Scan(&cell.Weekday, &cell.Hour, &cell.Lessons, &cell.Hours); err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+	return cells, rows.Err()
+}
+
+func (r *analyticsRepository) GetWorkloadSummary(ctx context.Context, tutorID string, from string, to string) (models.WorkloadSummary, error) {
+	return getWorkloadSummary(ctx, r.conn, tutorID, from, to)
+}
+
+// getWorkloadSummary counts the period's lessons by status. Rates are left to
+// the caller.
+func getWorkloadSummary(ctx context.Context, q querier, tutorID string, from string, to string) (models.WorkloadSummary, error) {
+	var ws models.WorkloadSummary
+	err := q.QueryRow(ctx, workloadLessons+`
+		SELECT COUNT(*)::int, `+statusCounts+`,
+		       (SELECT COUNT(DISTINCT student_id) FROM participations)::int,
+		       (SELECT COUNT(*) FROM participations WHERE status = 'completed')::int
+		FROM lessons_in`,
+		tutorID, from, to,
+	).Scan(&ws.Lessons, &ws.Scheduled, &ws.Completed, &ws.Cancelled, &ws.Missed, &ws.HoursTaught, &ws.Students, &ws.StudentLessons)
+	return ws, err
+}
@@ -31,10 +31,10 @@ func (r *attendanceRepository) Upsert(ctx context.Context, lessonID string, entr
 	batch := &pgx.Batch{}
 	for _, e := range entries {
 		batch.Queue(
-			`INSERT INTO lesson_attendances (lesson_id, student_id, status)
-			 VALUES ($1, $2, $3)
-			 ON CONFLICT (lesson_id, student_id) DO UPDATE SET status = $3`,
-			lessonID, e.StudentID, e.Status,
+			`INSERT INTO lesson_attendances (lesson_id, student_id, status, charge)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (lesson_id, student_id) DO UPDATE SET status = $3, charge = $4`,
+			lessonID, e.StudentID, e.Status, e.Charge,
 		)
 	}
 	br := tx.SendBatch(ctx, batch)
@@ -52,7 +52,7 @@ func (r *attendanceRepository) Upsert(ctx context.Context, lessonID string, entr
 
 func (r *attendanceRepository) GetByLesson(ctx context.Context, lessonID string) ([]models.LessonAttendance, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, lesson_id, student_id, status
+		`SELECT id, lesson_id, student_id, status, charge
 		 FROM lesson_attendances WHERE lesson_id = $1`, lessonID)
 	if err != nil {
 		return nil, err
@@ -62,7 +62,7 @@ func (r *attendanceRepository) GetByLesson(ctx context.Context, lessonID string)
 	var attendances []models.LessonAttendance
 	for rows.Next() {
 		var a models.LessonAttendance
-		if err := rows.Scan(&a.ID, &a.LessonID, &a.StudentID, &a.Status); err != nil {
+		if err := rows.Scan(&a.ID, &a.LessonID, &a.StudentID, &a.Status, &a.Charge); err != nil {
 			return nil, err
 		}
 		attendances = append(attendances, a)
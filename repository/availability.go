@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AvailabilityRepository interface {
+	GetWorkingHours(ctx context.Context, tutorID string) ([]models.WorkingHours, error)
+	ReplaceWorkingHours(ctx context.Context, tutorID string, hours []models.WorkingHoursInput) ([]models.WorkingHours, error)
+	GetOverrides(ctx context.Context, tutorID string, fromDate string, toDate string) ([]models.AvailabilityOverride, error)
+	CreateOverride(ctx context.Context, tutorID string, req models.CreateOverrideRequest) (models.AvailabilityOverride, error)
+	DeleteOverride(ctx context.Context, id string, tutorID string) error
+	GetBlackouts(ctx context.Context, tutorID string, from time.Time, to time.Time) ([]models.Blackout, error)
+	CreateBlackout(ctx context.Context, tutorID string, req models.CreateBlackoutRequest) (models.Blackout, error)
+	DeleteBlackout(ctx context.Context, id string, tutorID string) error
+}
+
+type availabilityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAvailabilityRepository(pool *pgxpool.Pool) AvailabilityRepository {
+	return &availabilityRepository{pool: pool}
+}
+
+func (r *availabilityRepository) GetWorkingHours(ctx context.Context, tutorID string) ([]models.WorkingHours, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		 FROM working_hours WHERE tutor_id = $1
+		 ORDER BY weekday, start_time`, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []models.WorkingHours{}
+	for rows.Next() {
+		var h models.WorkingHours
+		if err := rows.Scan(&h.ID, &h.Weekday, &h.Start, &h.End); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, rows.Err()
+}
+
+func (r *availabilityRepository) ReplaceWorkingHours(ctx context.Context, tutorID string, hours []models.WorkingHoursInput) ([]models.WorkingHours, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM working_hours WHERE tutor_id = $1`, tutorID); err != nil {
+		return nil, err
+	}
+	for _, h := range hours {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO working_hours (tutor_id, weekday, start_time, end_time)
+			 VALUES ($1, $2, $3::time, $4::time)`,
+			tutorID, h.Weekday, h.Start, h.End); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetWorkingHours(ctx, tutorID)
+}
+
+// GetOverrides returns overrides for the dates in [fromDate, toDate], both "YYYY-MM-DD".
+func (r *availabilityRepository) GetOverrides(ctx context.Context, tutorID string, fromDate string, toDate string) ([]models.AvailabilityOverride, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, to_char(date, 'YYYY-MM-DD'), to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		 FROM availability_overrides
+		 WHERE tutor_id = $1 AND date BETWEEN $2::date AND $3::date
+		 ORDER BY date, start_time`, tutorID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []models.AvailabilityOverride{}
+	for rows.Next() {
+		var o models.AvailabilityOverride
+		if err := rows.Scan(&o.ID, &o.Date, &o.Start, &o.End); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+func (r *availabilityRepository) CreateOverride(ctx context.Context, tutorID string, req models.CreateOverrideRequest) (models.AvailabilityOverride, error) {
+	var o models.AvailabilityOverride
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO availability_overrides (tutor_id, date, start_time, end_time)
+		 VALUES ($1, $2::date, $3::time, $4::time)
+		 RETURNING id, to_char(date, 'YYYY-MM-DD'), to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')`,
+		tutorID, req.Date, req.Start, req.End,
+	).Scan(&o.ID, &o.Date, &o.Start, &o.End)
+	return o, err
+}
+
+func (r *availabilityRepository) DeleteOverride(ctx context.Context, id string, tutorID string) error {
+	var deleted string
+	return r.pool.QueryRow(ctx,
+		`DELETE FROM availability_overrides WHERE id = $1 AND tutor_id = $2 RETURNING id`,
+		id, tutorID,
+	).Scan(&deleted)
+}
+
+// GetBlackouts returns the blackouts overlapping [from, to).
+func (r *availabilityRepository) GetBlackouts(ctx context.Context, tutorID string, from time.Time, to time.Time) ([]models.Blackout, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, starts_at, ends_at, reason
+		 FROM blackouts
+		 WHERE tutor_id = $1 AND starts_at < $3 AND ends_at > $2
+		 ORDER BY starts_at`, tutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blackouts := []models.Blackout{}
+	for rows.Next() {
+		var b models.Blackout
+		if err := rows.Scan(&b.ID, &b.StartsAt, &b.EndsAt, &b.Reason); err != nil {
+			return nil, err
+		}
+		blackouts = append(blackouts, b)
+	}
+	return blackouts, rows.Err()
+}
+
+func (r *availabilityRepository) CreateBlackout(ctx context.Context, tutorID string, req models.CreateBlackoutRequest) (models.Blackout, error) {
+	var b models.Blackout
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO blackouts (tutor_id, starts_at, ends_at, reason)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, starts_at, ends_at, reason`,
+		tutorID, req.StartsAt, req.EndsAt, req.Reason,
+	).Scan(&b.ID, &b.StartsAt, &b.EndsAt, &b.Reason)
+	return b, err
+}
+
+func (r *availabilityRepository) DeleteBlackout(ctx context.Context, id string, tutorID string) error {
+	var deleted string
+	return r.pool.QueryRow(ctx,
+		`DELETE FROM blackouts WHERE id = $1 AND tutor_id = $2 RETURNING id`,
+		id, tutorID,
+	).Scan(&deleted)
+}
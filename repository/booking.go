@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrSlotTaken is returned when a booked slot overlaps something that was
+// scheduled in the meantime.
+var ErrSlotTaken = errors.New("slot taken")
+
+// A booking request the tutor hasn't answered within pendingBookingTTL expires:
+// it is listed as "expired", can't be confirmed any more, and its pending lesson
+// no longer holds the slot. Declining it still clears the lesson away.
+const (
+	pendingBookingTTL = "3 days"
+	// bookingExpired matches an expired request br.
+	bookingExpired = `br.status = 'pending' AND br.created_at < NOW() - INTERVAL '` + pendingBookingTTL + `'`
+	// heldByExpiredBooking matches a lesson l left pending by an expired request.
+	heldByExpiredBooking = `EXISTS (SELECT 1 FROM booking_requests br WHERE br.lesson_id = l.id AND ` + bookingExpired + `)`
+)
+
+type BookingRepository interface {
+	CreateLink(ctx context.Context, tutorID string, token string, req models.CreateBookingLinkRequest) (models.BookingLink, error)
+	GetLinks(ctx context.Context, tutorID string) ([]models.BookingLink, error)
+	DeleteLink(ctx context.Context, id string, tutorID string) error
+	GetPublicLink(ctx context.Context, token string) (models.PublicBookingLink, error)
+	CreatePending(ctx context.Context, link models.PublicBookingLink, req models.CreateBookingRequest) (models.BookingRequest, error)
+	GetRequests(ctx context.Context, tutorID string, status string) ([]models.BookingRequest, error)
+	Confirm(ctx context.Context, lessonID string, tutorID string) error
+	Decline(ctx context.Context, lessonID string, tutorID string) error
+}
+
+type bookingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBookingRepository(pool *pgxpool.Pool) BookingRepository {
+	return &bookingRepository{pool: pool}
+}
+
+const bookingLinkColumns = `id, course_id, token, title, duration_minutes, min_notice_hours, horizon_days, created_at`
+
+func scanBookingLink(row pgx.Row) (models.BookingLink, error) {
+	var l models.BookingLink
+	err := row.Scan(&l.ID, &l.CourseID, &l.Token, &l.Title, &l.DurationMinutes, &l.MinNoticeHours, &l.HorizonDays, &l.CreatedAt)
+	return l, err
+}
+
+func (r *bookingRepository) CreateLink(ctx context.Context, tutorID string, token string, req models.CreateBookingLinkRequest) (models.BookingLink, error) {
+	return scanBookingLink(r.pool.QueryRow(ctx,
+		`INSERT INTO booking_links (tutor_id, course_id, token, title, duration_minutes, min_notice_hours, horizon_days)
+		 VALUES ($1, $2, $3, $4, $5, COALESCE($6, 12), COALESCE($7, 30))
+		 RETURNING `+bookingLinkColumns,
+		tutorID, req.CourseID, token, req.Title, req.DurationMinutes, req.MinNoticeHours, req.HorizonDays))
+}
+
+func (r *bookingRepository) GetLinks(ctx context.Context, tutorID string) ([]models.BookingLink, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+bookingLinkColumns+` FROM booking_links WHERE tutor_id = $1 ORDER BY created_at DESC`, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.BookingLink{}
+	for rows.Next() {
+		l, err := scanBookingLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+func (r *bookingRepository) DeleteLink(ctx context.Context, id string, tutorID string) error {
+	var deleted string
+	return r.pool.QueryRow(ctx,
+		`DELETE FROM booking_links WHERE id = $1 AND tutor_id = $2 RETURNING id`, id, tutorID,
+	).Scan(&deleted)
+}
+
+func (r *bookingRepository) GetPublicLink(ctx context.Context, token string) (models.PublicBookingLink, error) {
+	var l models.PublicBookingLink
+	err := r.pool.QueryRow(ctx,
+		`SELECT bl.title, c.subject, t.first_name, bl.duration_minutes, t.timezone,
+		        bl.id, bl.tutor_id, bl.course_id, bl.min_notice_hours, bl.horizon_days
+		 FROM booking_links bl
+		 JOIN courses c ON c.id = bl.course_id
+		 JOIN tutors t ON t.id = bl.tutor_id
+		 WHERE bl.token = $1`, token,
+	).Scan(&l.Title, &l.Subject, &l.TutorName, &l.DurationMinutes, &l.Timezone,
+		&l.LinkID, &l.TutorID, &l.CourseID, &l.MinNoticeHours, &l.HorizonDays)
+	return l, err
+}
+
+// CreatePending stores the requested slot as a pending lesson. Bookings for the same
+// tutor are serialized, and the slot is re-checked inside the transaction, so two
+// visitors can never both get it.
+func (r *bookingRepository) CreatePending(ctx context.Context, link models.PublicBookingLink, req models.CreateBookingRequest) (models.BookingRequest, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.BookingRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, link.TutorID); err != nil {
+		return models.BookingRequest{}, err
+	}
+	var lessonID string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO lessons (course_id, scheduled_at, duration_minutes, status, notes)
+		 SELECT $1, $2, $3, 'pending', $4
+		 WHERE NOT EXISTS (
+		     SELECT 1 FROM lessons l JOIN courses c ON c.id = l.course_id
+		     WHERE c.tutor_id = $5 AND l.status <> 'cancelled' AND NOT `+heldByExpiredBooking+`
+		       AND l.scheduled_at < $2::timestamptz + make_interval(mins => $3)
+		       AND $2::timestamptz < l.scheduled_at + make_interval(mins => l.duration_minutes)
+		 ) AND NOT EXISTS (
+		     SELECT 1 FROM tasks t
+		     WHERE t.tutor_id = $5 AND NOT t.done
+		       AND t.scheduled_at < $2::timestamptz + make_interval(mins => $3)
+		       AND $2::timestamptz < t.scheduled_at + make_interval(mins => t.duration_minutes)
+		 )
+		 RETURNING id`,
+		link.CourseID, req.ScheduledAt, link.DurationMinutes, req.Message, link.TutorID,
+	).Scan(&lessonID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.BookingRequest{}, ErrSlotTaken
+	}
+	if err != nil {
+		return models.BookingRequest{}, err
+	}
+
+	var b models.BookingRequest
+	err = tx.QueryRow(ctx,
+		`INSERT INTO booking_requests (tutor_id, link_id, lesson_id, scheduled_at, duration_minutes, name, contact, message)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING id, link_id, lesson_id, scheduled_at, duration_minutes, name, contact, message, status, created_at`,
+		link.TutorID, link.LinkID, lessonID, req.ScheduledAt, link.DurationMinutes, req.Name, req.Contact, req.Message,
+	).Scan(&b.ID, &b.LinkID, &b.LessonID, &b.ScheduledAt, &b.DurationMinutes, &b.Name, &b.Contact, &b.Message, &b.Status, &b.CreatedAt)
+	if err != nil {
+		return models.BookingRequest{}, err
+	}
+	return b, tx.Commit(ctx)
+}
+
+func (r *bookingRepository) GetRequests(ctx context.Context, tutorID string, status string) ([]models.BookingRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, link_id, lesson_id, scheduled_at, duration_minutes, name, contact, message, status, created_at
+		 FROM (
+		     SELECT br.id, br.link_id, br.lesson_id, br.scheduled_at, br.duration_minutes, br.name, br.contact, br.message,
+		            CASE WHEN `+bookingExpired+` THEN 'expired' ELSE br.status END AS status, br.created_at
+		     FROM booking_requests br
+		     WHERE br.tutor_id = $1
+		 ) r
+		 WHERE $2 = '' OR status = $2
+		 ORDER BY scheduled_at`, tutorID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.BookingRequest{}
+	for rows.Next() {
+		var b models.BookingRequest
+		if err := rows.Scan(&b.ID, &b.LinkID, &b.LessonID, &b.ScheduledAt, &b.DurationMinutes, &b.Name, &b.Contact, &b.Message, &b.Status, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		requests = append(requests, b)
+	}
+	return requests, rows.Err()
+}
+
+// Confirm turns a pending lesson into a scheduled one. pgx.ErrNoRows means the
+// lesson is not the tutor's, not pending, or its request has expired.
+func (r *bookingRepository) Confirm(ctx context.Context, lessonID string, tutorID string) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`UPDATE lessons l SET status = 'scheduled'
+		 FROM courses c
+		 WHERE c.id = l.course_id AND l.id = $1 AND c.tutor_id = $2 AND l.status = 'pending'
+		   AND NOT `+heldByExpiredBooking+`
+		 RETURNING l.id`, lessonID, tutorID,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	pending := "pending"
+	if err := insertStatusEvent(ctx, tx, lessonID, &pending, "scheduled", nil, models.TutorActor(tutorID), "booking confirmed"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE booking_requests SET status = 'confirmed' WHERE lesson_id = $1`, lessonID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Decline drops a pending lesson and marks its request declined.
+func (r *bookingRepository) Decline(ctx context.Context, lessonID string, tutorID string) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE booking_requests SET status = 'declined' WHERE lesson_id = $1 AND tutor_id = $2`,
+		lessonID, tutorID); err != nil {
+		return err
+	}
+	var id string
+	err = tx.QueryRow(ctx,
+		`DELETE FROM lessons l
+		 USING courses c
+		 WHERE c.id = l.course_id AND l.id = $1 AND c.tutor_id = $2 AND l.status = 'pending'
+		 RETURNING l.id`, lessonID, tutorID,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CalendarFeedRepository interface {
+	GetToken(ctx context.Context, tutorID string) (*string, error)
+	SetToken(ctx context.Context, tutorID string, token *string) error
+	GetOwner(ctx context.Context, token string) (models.FeedOwner, error)
+}
+
+type calendarFeedRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCalendarFeedRepository(pool *pgxpool.Pool) CalendarFeedRepository {
+	return &calendarFeedRepository{pool: pool}
+}
+
+func (r *calendarFeedRepository) GetToken(ctx context.Context, tutorID string) (*string, error) {
+	var token *string
+	err := r.pool.QueryRow(ctx,
+		`SELECT calendar_token FROM tutors WHERE id = $1`, tutorID,
+	).Scan(&token)
+	return token, err
+}
+
+// SetToken replaces the tutor's token; nil disables the feed.
+func (r *calendarFeedRepository) SetToken(ctx context.Context, tutorID string, token *string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE tutors SET calendar_token = $1 WHERE id = $2`, token, tutorID)
+	return err
+}
+
+func (r *calendarFeedRepository) GetOwner(ctx context.Context, token string) (models.FeedOwner, error) {
+	var o models.FeedOwner
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, first_name, last_name, timezone FROM tutors WHERE calendar_token = $1`, token,
+	).Scan(&o.TutorID, &o.FirstName, &o.LastName, &o.Timezone)
+	return o, err
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// importPreviewTTL is how long an uncommitted preview is kept.
+const importPreviewTTL = 24 * time.Hour
+
+type CalendarImportRepository interface {
+	Create(ctx context.Context, tutorID string, events []models.ImportEvent) (models.CalendarImport, error)
+	Get(ctx context.Context, id string, tutorID string) (models.CalendarImport, error)
+	ImportedUIDs(ctx context.Context, tutorID string, uids []string) (map[string]bool, error)
+	MatchCourses(ctx context.Context, tutorID string, titles []string) (map[string]string, error)
+	Commit(ctx context.Context, id string, tutorID string, plan models.ImportPlan) (models.ImportResult, error)
+}
+
+type calendarImportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCalendarImportRepository(pool *pgxpool.Pool) CalendarImportRepository {
+	return &calendarImportRepository{pool: pool}
+}
+
+// Create stores a preview and drops the tutor's previews that were never committed.
+func (r *calendarImportRepository) Create(ctx context.Context, tutorID string, events []models.ImportEvent) (models.CalendarImport, error) {
+	if _, err := r.pool.Exec(ctx,
+		`DELETE FROM calendar_imports WHERE tutor_id = $1 AND created_at < $2`,
+		tutorID, time.Now().Add(-importPreviewTTL)); err != nil {
+		return models.CalendarImport{}, err
+	}
+	imp := models.CalendarImport{Events: events}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO calendar_imports (tutor_id, events) VALUES ($1, $2)
+		 RETURNING id, created_at`,
+		tutorID, events,
+	).Scan(&imp.ID, &imp.CreatedAt)
+	return imp, err
+}
+
+func (r *calendarImportRepository) Get(ctx context.Context, id string, tutorID string) (models.CalendarImport, error) {
+	var imp models.CalendarImport
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, events, created_at FROM calendar_imports WHERE id = $1 AND tutor_id = $2`,
+		id, tutorID,
+	).Scan(&imp.ID, &imp.Events, &imp.CreatedAt)
+	return imp, err
+}
+
+func (r *calendarImportRepository) ImportedUIDs(ctx context.Context, tutorID string, uids []string) (map[string]bool, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT uid FROM imported_events WHERE tutor_id = $1 AND uid = ANY($2::text[])`,
+		tutorID, uids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	imported := map[string]bool{}
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		imported[uid] = true
+	}
+	return imported, rows.Err()
+}
+
+// MatchCourses maps each title to the tutor's course with the same subject,
+// ignoring case. With several candidates the most recently started one wins.
+func (r *calendarImportRepository) MatchCourses(ctx context.Context, tutorID string, titles []string) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT ON (t.title) t.title, c.id
+		 FROM unnest($2::text[]) AS t(title)
+		 JOIN courses c ON c.tutor_id = $1 AND lower(c.subject) = lower(t.title)
+		 ORDER BY t.title, c.started_at DESC`,
+		tutorID, titles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := map[string]string{}
+	for rows.Next() {
+		var title, courseID string
+		if err := rows.Scan(&title, &courseID); err != nil {
+			return nil, err
+		}
+		matches[title] = courseID
+	}
+	return matches, rows.Err()
+}
+
+// Commit writes the plan and deletes the preview. Each item first claims its UID
+// in imported_events; an item whose UID is already taken, e.g. by a concurrent
+// commit of the same file, is reported as skipped instead of written.
+func (r *calendarImportRepository) Commit(ctx context.Context, id string, tutorID string, plan models.ImportPlan) (models.ImportResult, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := models.ImportResult{Skipped: []models.ImportSkipped{}}
+	newCourses := map[string]models.CreateCourseRequest{}
+	for _, nc := range plan.NewCourses {
+		newCourses[nc.Title] = nc.Course
+	}
+	courseIDs := map[string]string{}
+
+	for _, item := range plan.Items {
+		var claimed string
+		err := tx.QueryRow(ctx,
+			`INSERT INTO imported_events (tutor_id, uid) VALUES ($1, $2)
+			 ON CONFLICT DO NOTHING
+			 RETURNING uid`,
+			tutorID, item.UID,
+		).Scan(&claimed)
+		if err == pgx.ErrNoRows {
+			result.Skipped = append(result.Skipped, models.ImportSkipped{UID: item.UID, Title: item.Title, Reason: models.ImportSkipAlreadyImported})
+			continue
+		}
+		if err != nil {
+			return models.ImportResult{}, err
+		}
+
+		// A new course is created with the first of its items that is claimed,
+		// so that committing again doesn't leave empty copies behind.
+		courseID := item.CourseID
+		if courseID == "" {
+			courseID = courseIDs[item.Title]
+		}
+		if courseID == "" {
+			course := newCourses[item.Title]
+			if err := tx.QueryRow(ctx,
+				`INSERT INTO courses (student_id, tutor_id, subject, price_per_lesson, currency, started_at, ended_at)
+				 VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), (SELECT currency FROM tutors WHERE id = $2)), $6, $7)
+				 RETURNING id`,
+				course.StudentID, tutorID, course.Subject, course.PricePerLesson, course.Currency, course.StartedAt, course.EndedAt,
+			).Scan(&courseID); err != nil {
+				return models.ImportResult{}, err
+			}
+			courseIDs[item.Title] = courseID
+			result.CoursesCreated++
+		}
+
+		if item.Series != nil {
+			series := *item.Series
+			series.CourseID = courseID
+			created, err := insertSeriesRow(ctx, tx, series)
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			lessons, err := insertSeriesLessons(ctx, tx, created, item.Occurrences)
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			if _, err := tx.Exec(ctx,
+				`UPDATE imported_events SET series_id = $1 WHERE tutor_id = $2 AND uid = $3`,
+				created.ID, tutorID, item.UID); err != nil {
+				return models.ImportResult{}, err
+			}
+			result.SeriesCreated++
+			result.LessonsCreated += len(lessons)
+			continue
+		}
+
+		var lessonID string
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO lessons (course_id, scheduled_at, duration_minutes, notes)
+			 VALUES ($1, $2, $3, $4)
+			 RETURNING id`,
+			courseID, item.ScheduledAt, item.DurationMinutes, item.Notes,
+		).Scan(&lessonID); err != nil {
+			return models.ImportResult{}, err
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE imported_events SET lesson_id = $1 WHERE tutor_id = $2 AND uid = $3`,
+			lessonID, tutorID, item.UID); err != nil {
+			return models.ImportResult{}, err
+		}
+		result.LessonsCreated++
+	}
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM calendar_imports WHERE id = $1`, id); err != nil {
+		return models.ImportResult{}, err
+	}
+	return result, tx.Commit(ctx)
+}
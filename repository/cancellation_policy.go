@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CancellationPolicyRepository interface {
+	GetForTutor(ctx context.Context, tutorID string) (models.CancellationPolicy, error)
+	SetForTutor(ctx context.Context, tutorID string, req models.SetCancellationPolicyRequest) (models.CancellationPolicy, error)
+	GetForCourse(ctx context.Context, courseID string) (models.CoursePolicy, error)
+	SetForCourse(ctx context.Context, courseID string, req *models.SetCancellationPolicyRequest) error
+}
+
+type cancellationPolicyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCancellationPolicyRepository(pool *pgxpool.Pool) CancellationPolicyRepository {
+	return &cancellationPolicyRepository{pool: pool}
+}
+
+func (r *cancellationPolicyRepository) GetForTutor(ctx context.Context, tutorID string) (models.CancellationPolicy, error) {
+	var p models.CancellationPolicy
+	err := r.pool.QueryRow(ctx,
+		`SELECT cancel_cutoff_hours, late_cancel_charge, no_show_charge FROM tutors WHERE id = $1`, tutorID,
+	).Scan(&p.CutoffHours, &p.LateCancelCharge, &p.NoShowCharge)
+	return p, err
+}
+
+func (r *cancellationPolicyRepository) SetForTutor(ctx context.Context, tutorID string, req models.SetCancellationPolicyRequest) (models.CancellationPolicy, error) {
+	var p models.CancellationPolicy
+	err := r.pool.QueryRow(ctx,
+		`UPDATE tutors SET cancel_cutoff_hours = $1, late_cancel_charge = $2, no_show_charge = $3
+		 WHERE id = $4
+		 RETURNING cancel_cutoff_hours, late_cancel_charge, no_show_charge`,
+		req.CutoffHours, req.LateCancelCharge, req.NoShowCharge, tutorID,
+	).Scan(&p.CutoffHours, &p.LateCancelCharge, &p.NoShowCharge)
+	return p, err
+}
+
+// GetForCourse resolves the course override, falling back to the tutor's policy.
+func (r *cancellationPolicyRepository) GetForCourse(ctx context.Context, courseID string) (models.CoursePolicy, error) {
+	var p models.CoursePolicy
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(c.cancel_cutoff_hours, t.cancel_cutoff_hours),
+		        COALESCE(c.late_cancel_charge, t.late_cancel_charge),
+		        COALESCE(c.no_show_charge, t.no_show_charge),
+		        c.late_cancel_charge IS NULL
+		 FROM courses c
+		 JOIN tutors t ON t.id = c.tutor_id
+		 WHERE c.id = $1`, courseID,
+	).Scan(&p.CutoffHours, &p.LateCancelCharge, &p.NoShowCharge, &p.Inherited)
+	return p, err
+}
+
+// SetForCourse stores an override; nil removes it.
+func (r *cancellationPolicyRepository) SetForCourse(ctx context.Context, courseID string, req *models.SetCancellationPolicyRequest) error {
+	var cutoff *int
+	var late, noShow *string
+	if req != nil {
+		cutoff, late, noShow = &req.CutoffHours, &req.LateCancelCharge, &req.NoShowCharge
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE courses SET cancel_cutoff_hours = $1, late_cancel_charge = $2, no_show_charge = $3
+		 WHERE id = $4`,
+		cutoff, late, noShow, courseID)
+	return err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"tutorgo/models"
+	"tutorgo/money"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepository interface {
+	GetAll(ctx context.Context, tutorID string) ([]models.ExchangeRate, error)
+	Set(ctx context.Context, tutorID string, currency string, rate money.Rate) (models.ExchangeRate, error)
+	Delete(ctx context.Context, tutorID string, currency string) error
+}
+
+type exchangeRateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(pool *pgxpool.Pool) ExchangeRateRepository {
+	return &exchangeRateRepository{pool: pool}
+}
+
+func (r *exchangeRateRepository) GetAll(ctx context.Context, tutorID string) ([]models.ExchangeRate, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT currency, rate, updated_at FROM exchange_rates WHERE tutor_id = $1 ORDER BY currency`, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		var er models.ExchangeRate
+		if err := rows.Scan(&er.Currency, &er.Rate, &er.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, er)
+	}
+	return rates, rows.Err()
+}
+
+func (r *exchangeRateRepository) Set(ctx context.Context, tutorID string, currency string, rate money.Rate) (models.ExchangeRate, error) {
+	var er models.ExchangeRate
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO exchange_rates (tutor_id, currency, rate)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (tutor_id, currency) DO UPDATE SET rate = EXCLUDED.rate, updated_at = NOW()
+		 RETURNING currency, rate, updated_at`,
+		tutorID, currency, rate,
+	).Scan(&er.Currency, &er.Rate, &er.UpdatedAt)
+	return er, err
+}
+
+// Delete removes the rate; pgx.ErrNoRows if there is none.
+func (r *exchangeRateRepository) Delete(ctx context.Context, tutorID string, currency string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM exchange_rates WHERE tutor_id = $1 AND currency = $2`, tutorID, currency)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExportRepository streams rows to each as they come from the database, so an
+// export holds one row at a time. The queries use the list queries' filters and
+// order; an error from each stops the export and is returned.
+type ExportRepository interface {
+	Students(ctx context.Context, tutorID string, search string, each func(models.Student) error) error
+	Courses(ctx context.Context, tutorID string, search string, each func(models.CourseRow) error) error
+	Lessons(ctx context.Context, tutorID string, filter models.LessonExportFilter, each func(models.CalendarLesson) error) error
+	Payments(ctx context.Context, tutorID string, each func(models.PaymentRow) error) error
+}
+
+type exportRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewExportRepository(conn *pgxpool.Pool) ExportRepository {
+	return &exportRepository{conn: conn}
+}
+
+// studentNameOf is the full name of the student aliased s, NULL without one.
+const studentNameOf = `CASE WHEN s.last_name = '' THEN s.first_name ELSE s.first_name || ' ' || s.last_name END`
+
+// stream scans each row with scan and hands it to each.
+func stream[T any](rows pgx.Rows, scan func(pgx.Row) (T, error), each func(T) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := each(v); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *exportRepository) Students(ctx context.Context, tutorID string, search string, each func(models.Student) error) error {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+studentColumns+` FROM students
+		 WHERE `+studentFilter+`
+		 ORDER BY `+studentOrder,
+		tutorID, search)
+	if err != nil {
+		return err
+	}
+	return stream(rows, scanStudent, each)
+}
+
+func (r *exportRepository) Courses(ctx context.Context, tutorID string, search string, each func(models.CourseRow) error) error {
+	rows, err := r.conn.Query(ctx,
+		`SELECT c.id, c.student_id, c.tutor_id, c.subject, c.price_per_lesson, c.currency, c.started_at, c.ended_at,
+		        `+studentNameOf+`
+		 FROM (SELECT * FROM courses WHERE `+courseFilter+`) c
+		 LEFT JOIN students s ON s.id = c.student_id
+		 ORDER BY c.started_at DESC`,
+		tutorID, search)
+	if err != nil {
+		return err
+	}
+	return stream(rows, func(row pgx.Row) (models.CourseRow, error) {
+		var c models.CourseRow
+		err := row.Scan(&c.ID, &c.StudentID, &c.TutorID, &c.Subject, &c.PricePerLesson, &c.Currency, &c.StartedAt, &c.EndedAt,
+			&c.StudentName)
+		return c, err
+	}, each)
+}
+
+// Lessons takes the calendar's lessons from the date filter.From up to, not
+// including, filter.To; the service makes To exclusive.
+func (r *exportRepository) Lessons(ctx context.Context, tutorID string, filter models.LessonExportFilter, each func(models.CalendarLesson) error) error {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+calendarColumns+`
+		 FROM `+calendarJoins+`
+		 WHERE c.tutor_id = $1
+		   AND l.scheduled_at >= `+localBound("$2")+`
+		   AND l.scheduled_at < `+localBound("$3")+`
+		   AND ($4 = '' OR l.status = $4)
+		   AND ($5::uuid IS NULL OR l.course_id = $5)
+		 ORDER BY l.scheduled_at`,
+		tutorID, filter.From, filter.To, filter.Status, filter.CourseID)
+	if err != nil {
+		return err
+	}
+	return stream(rows, scanCalendarLesson, each)
+}
+
+// Payments includes payments whose course has since been deleted, like the
+// payments list.
+func (r *exportRepository) Payments(ctx context.Context, tutorID string, each func(models.PaymentRow) error) error {
+	rows, err := r.conn.Query(ctx,
+		`SELECT p.id, p.course_id, p.subject, p.student_id, p.amount, p.currency, p.lessons_count, p.paid_at,
+		        p.refunded, p.lessons_refunded, p.voided,
+		        `+studentNameOf+`
+		 FROM (SELECT `+paymentColumns+` FROM payment_states WHERE tutor_id = $1) p
+		 LEFT JOIN courses c ON c.id = p.course_id
+		 LEFT JOIN students s ON s.id = COALESCE(p.student_id, c.student_id)
+		 ORDER BY p.paid_at DESC`,
+		tutorID)
+	if err != nil {
+		return err
+	}
+	return stream(rows, func(row pgx.Row) (models.PaymentRow, error) {
+		var p models.PaymentRow
+		err := row.Scan(&p.ID, &p.CourseID, &p.Subject, &p.StudentID, &p.Amount, &p.Currency, &p.LessonsCount, &p.PaidAt,
+			&p.Refunded, &p.LessonsRefunded, &p.Voided, &p.StudentName)
+		return p, err
+	}, each)
+}
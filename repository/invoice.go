@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrAlreadyInvoiced is returned when a lesson or payment is already billed by
+// another invoice, or a payment already settles one.
+var ErrAlreadyInvoiced = errors.New("already invoiced")
+
+// ErrInvoiceChanged means the invoice left the status it was checked in.
+var ErrInvoiceChanged = errors.New("invoice status changed")
+
+// invoiceError turns a violation of the billed-once indexes into ErrAlreadyInvoiced.
+func invoiceError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "idx_invoice_items_lesson", "idx_invoice_items_payment", "idx_invoices_payment":
+			return ErrAlreadyInvoiced
+		}
+	}
+	return err
+}
+
+type InvoiceRepository interface {
+	Create(ctx context.Context, tutorID string, invoice models.Invoice) (models.Invoice, error)
+	GetByID(ctx context.Context, id string, tutorID string) (models.Invoice, error)
+	GetAll(ctx context.Context, tutorID string, f models.InvoiceFilter, p models.Pagination) ([]models.Invoice, int, error)
+	Delete(ctx context.Context, id string, tutorID string) error
+	Send(ctx context.Context, id string, tutorID string) (models.Invoice, error)
+	Pay(ctx context.Context, id string, tutorID string, paymentID string, paidAt time.Time) (models.Invoice, error)
+	PayWithNewPayment(ctx context.Context, id string, tutorID string, req models.CreatePaymentRequest) (models.Invoice, error)
+	GetBillableLessons(ctx context.Context, courseID string, studentID string, from string, to string) ([]models.InvoiceItem, error)
+}
+
+type invoiceRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewInvoiceRepository(conn *pgxpool.Pool) InvoiceRepository {
+	return &invoiceRepository{conn: conn}
+}
+
+// invoiceColumns are read from invoice_states, which derives overdue.
+const invoiceColumns = `id, number, status, course_id, student_id, currency, bill_to, notes,
+	to_char(issue_date, 'YYYY-MM-DD'), to_char(due_date, 'YYYY-MM-DD'), total, payment_id, created_at, sent_at, paid_at`
+
+func scanInvoice(row pgx.Row) (models.Invoice, error) {
+	var i models.Invoice
+	err := row.Scan(&i.ID, &i.Number, &i.Status, &i.CourseID, &i.StudentID, &i.Currency, &i.BillTo, &i.Notes,
+		&i.IssueDate, &i.DueDate, &i.Total, &i.PaymentID, &i.CreatedAt, &i.SentAt, &i.PaidAt)
+	return i, err
+}
+
+const invoiceItemColumns = `id, position, description, quantity::float8, unit_price, amount, lesson_id, student_id, payment_id`
+
+// querier is what reads need from a pool or a transaction.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// getInvoice reads the invoice with its items.
+func getInvoice(ctx context.Context, q querier, id string, tutorID string) (models.Invoice, error) {
+	invoice, err := scanInvoice(q.QueryRow(ctx,
+		`SELECT `+invoiceColumns+` FROM invoice_states WHERE id = $1 AND tutor_id = $2`, id, tutorID))
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	rows, err := q.Query(ctx,
+		`SELECT `+invoiceItemColumns+` FROM invoice_items WHERE invoice_id = $1 ORDER BY position`, id)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	defer rows.Close()
+
+	invoice.Items = []models.InvoiceItem{}
+	for rows.Next() {
+		var it models.InvoiceItem
+		if err := rows.Scan(&it.ID, &it.Position, &it.Description, &it.Quantity, &it.UnitPrice, &it.Amount,
+			&it.LessonID, &it.StudentID, &it.PaymentID); err != nil {
+			return models.Invoice{}, err
+		}
+		invoice.Items = append(invoice.Items, it)
+	}
+	return invoice, rows.Err()
+}
+
+// Create stores a draft with its items. A lesson or payment that another invoice
+// has billed meanwhile fails it with ErrAlreadyInvoiced.
+func (r *invoiceRepository) Create(ctx context.Context, tutorID string, invoice models.Invoice) (models.Invoice, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO invoices (tutor_id, course_id, student_id, currency, bill_to, notes, issue_date, due_date, total)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7::date, $8::date, $9)
+		 RETURNING id`,
+		tutorID, invoice.CourseID, invoice.StudentID, invoice.Currency, invoice.BillTo, invoice.Notes,
+		invoice.IssueDate, invoice.DueDate, invoice.Total,
+	).Scan(&id); err != nil {
+		return models.Invoice{}, err
+	}
+	for i, it := range invoice.Items {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO invoice_items (invoice_id, position, description, quantity, unit_price, amount, lesson_id, student_id, payment_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			id, i+1, it.Description, it.Quantity, it.UnitPrice, it.Amount, it.LessonID, it.StudentID, it.PaymentID,
+		); err != nil {
+			return models.Invoice{}, invoiceError(err)
+		}
+	}
+	created, err := getInvoice(ctx, tx, id, tutorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	return created, tx.Commit(ctx)
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	return getInvoice(ctx, r.conn, id, tutorID)
+}
+
+// GetAll lists invoices newest first, without their items.
+func (r *invoiceRepository) GetAll(ctx context.Context, tutorID string, f models.InvoiceFilter, p models.Pagination) ([]models.Invoice, int, error) {
+	const where = ` WHERE tutor_id = $1 AND ($2 = '' OR status = $2) AND ($3 = '' OR course_id::text = $3)`
+	var total int
+	if err := r.conn.QueryRow(ctx,
+		`SELECT COUNT(*) FROM invoice_states`+where, tutorID, f.Status, f.CourseID,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+invoiceColumns+` FROM invoice_states`+where+`
+		 ORDER BY created_at DESC
+		 LIMIT $4 OFFSET $5`,
+		tutorID, f.Status, f.CourseID, p.Limit, p.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	invoices := []models.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, total, rows.Err()
+}
+
+// Delete removes a draft; ErrInvoiceChanged if the invoice is no longer one.
+func (r *invoiceRepository) Delete(ctx context.Context, id string, tutorID string) error {
+	tag, err := r.conn.Exec(ctx,
+		`DELETE FROM invoices WHERE id = $1 AND tutor_id = $2 AND status = 'draft'`, id, tutorID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvoiceChanged
+	}
+	return nil
+}
+
+// lockInvoice locks the invoice for a status change and checks it still has
+// status, ErrInvoiceChanged otherwise.
+func lockInvoice(ctx context.Context, tx pgx.Tx, id string, tutorID string, status string) error {
+	var current string
+	if err := tx.QueryRow(ctx,
+		`SELECT status FROM invoices WHERE id = $1 AND tutor_id = $2 FOR UPDATE`, id, tutorID,
+	).Scan(&current); err != nil {
+		return err
+	}
+	if current != status {
+		return ErrInvoiceChanged
+	}
+	return nil
+}
+
+// Send numbers a draft and marks it sent. Taking the number locks the tutor's
+// counter, so concurrent sends get consecutive numbers.
+func (r *invoiceRepository) Send(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockInvoice(ctx, tx, id, tutorID, models.InvoiceDraft); err != nil {
+		return models.Invoice{}, err
+	}
+	var number int
+	if err := tx.QueryRow(ctx,
+		`UPDATE tutors SET next_invoice_number = next_invoice_number + 1
+		 WHERE id = $1
+		 RETURNING next_invoice_number - 1`, tutorID,
+	).Scan(&number); err != nil {
+		return models.Invoice{}, err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE invoices SET number = $1, status = 'sent', sent_at = NOW() WHERE id = $2`, number, id); err != nil {
+		return models.Invoice{}, err
+	}
+	invoice, err := getInvoice(ctx, tx, id, tutorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	return invoice, tx.Commit(ctx)
+}
+
+// pay marks a sent invoice paid by the payment settle returns.
+func (r *invoiceRepository) pay(ctx context.Context, id string, tutorID string, paidAt time.Time, settle func(tx pgx.Tx) (string, error)) (models.Invoice, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockInvoice(ctx, tx, id, tutorID, models.InvoiceSent); err != nil {
+		return models.Invoice{}, err
+	}
+	paymentID, err := settle(tx)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE invoices SET status = 'paid', payment_id = $1, paid_at = $2 WHERE id = $3`,
+		paymentID, paidAt, id); err != nil {
+		return models.Invoice{}, invoiceError(err)
+	}
+	invoice, err := getInvoice(ctx, tx, id, tutorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	return invoice, tx.Commit(ctx)
+}
+
+// Pay settles the invoice with a payment already recorded.
+func (r *invoiceRepository) Pay(ctx context.Context, id string, tutorID string, paymentID string, paidAt time.Time) (models.Invoice, error) {
+	return r.pay(ctx, id, tutorID, paidAt, func(pgx.Tx) (string, error) {
+		return paymentID, nil
+	})
+}
+
+// PayWithNewPayment records req and settles the invoice with it, atomically.
+func (r *invoiceRepository) PayWithNewPayment(ctx context.Context, id string, tutorID string, req models.CreatePaymentRequest) (models.Invoice, error) {
+	return r.pay(ctx, id, tutorID, req.PaidAt, func(tx pgx.Tx) (string, error) {
+		return createPayment(ctx, tx, req)
+	})
+}
+
+// GetBillableLessons returns invoice lines for the course's lessons between from
+// and to, in the tutor's timezone, that are charged to the student and not yet
+// billed to them. An individual course charges by chargedShare, a group course by
+// the student's attendance.
+func (r *invoiceRepository) GetBillableLessons(ctx context.Context, courseID string, studentID string, from string, to string) ([]models.InvoiceItem, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT id, subject || ', ' || to_char(local_date, 'DD.MM.YYYY'), share::float8, price, ROUND(price * share, 2)
+		 FROM (
+		     SELECT l.id, l.scheduled_at, c.subject, c.price_per_lesson AS price,
+		            (l.scheduled_at AT TIME ZONE t.timezone)::date AS local_date,
+		            (CASE WHEN c.student_id IS NULL THEN `+attendedShare+` ELSE `+chargedShare+` END)::numeric AS share
+		     FROM lessons l
+		     JOIN courses c ON c.id = l.course_id
+		     JOIN tutors t ON t.id = c.tutor_id
+		     LEFT JOIN lesson_attendances a ON a.lesson_id = l.id AND a.student_id = $2
+		     WHERE l.course_id = $1
+		       AND NOT EXISTS (SELECT 1 FROM invoice_items i WHERE i.lesson_id = l.id AND i.student_id = $2)
+		 ) b
+		 WHERE share > 0 AND local_date BETWEEN $3::date AND $4::date
+		 ORDER BY scheduled_at`,
+		courseID, studentID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.InvoiceItem{}
+	for rows.Next() {
+		var it models.InvoiceItem
+		var lessonID string
+		if err := rows.Scan(&lessonID, &it.Description, &it.Quantity, &it.UnitPrice, &it.Amount); err != nil {
+			return nil, err
+		}
+		it.LessonID = &lessonID
+		it.StudentID = &studentID
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -3,11 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strings"
+	"time"
 	"tutorgo/models"
 
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -18,13 +16,12 @@ type LessonRepository interface {
 	GetByCourse(ctx context.Context, courseID string) ([]models.Lesson, error)
 	GetByID(ctx context.Context, id string) (models.Lesson, error)
 	GetByIDForTutor(ctx context.Context, id string, tutorID string) (models.Lesson, error)
-	Update(ctx context.Context, id string, req models.UpdateLessonRequest) (models.Lesson, error)
+	Update(ctx context.Context, id string, req models.UpdateLessonRequest, change models.StatusChange) (models.Lesson, error)
 	Delete(ctx context.Context, id string) error
 	DeleteByCourse(ctx context.Context, courseID string, tutorID string) error
-	DeleteSeries(ctx context.Context, seriesID string, tutorID string, fromDate *string) error
-	UpdateSeries(ctx context.Context, seriesID string, tutorID string, req models.UpdateSeriesRequest) error
 	GetCalendar(ctx context.Context, tutorID string, from string, to string) ([]models.CalendarLesson, error)
 	AutoComplete(ctx context.Context) (int64, error)
+	GetStatusEvents(ctx context.Context, lessonID string) ([]models.LessonStatusEvent, error)
 	ExistsPublic(ctx context.Context, id string) error
 }
 
@@ -36,19 +33,62 @@ func NewLessonRepository(pool *pgxpool.Pool) LessonRepository {
 	return &lessonRepository{pool: pool}
 }
 
+const lessonColumns = `l.id, l.course_id, l.scheduled_at, l.duration_minutes, l.status, l.notes, l.homework, l.series_id, l.charge,
+	l.original_scheduled_at, l.makeup_for, l.makeup_owed`
+
+func scanLesson(row pgx.Row) (models.Lesson, error) {
+	var l models.Lesson
+	err := row.Scan(&l.ID, &l.CourseID, &l.ScheduledAt, &l.DurationMinutes, &l.Status, &l.Notes, &l.Homework, &l.SeriesID, &l.Charge,
+		&l.OriginalScheduledAt, &l.MakeupFor, &l.MakeupOwed)
+	return l, err
+}
+
+// Create stores the lesson. A makeup also marks its original as owing one; if the
+// original already has a makeup that is not cancelled, it fails with ErrMakeupExists.
 func (r *lessonRepository) Create(ctx context.Context, req models.CreateLessonRequest) (models.Lesson, error) {
-	var lesson models.Lesson
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO lessons (course_id, scheduled_at, duration_minutes, notes)
-		 VALUES ($1, $2, $3, $4)
-		 RETURNING id, course_id, scheduled_at, duration_minutes, status, notes, series_id`,
-		req.CourseID, req.ScheduledAt, req.DurationMinutes, req.Notes,
-	).Scan(&lesson.ID, &lesson.CourseID, &lesson.ScheduledAt, &lesson.DurationMinutes, &lesson.Status, &lesson.Notes, &lesson.SeriesID)
-	return lesson, err
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	lesson, err := scanLesson(tx.QueryRow(ctx,
+		`INSERT INTO lessons AS l (course_id, scheduled_at, duration_minutes, notes, makeup_for)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING `+lessonColumns,
+		req.CourseID, req.ScheduledAt, req.DurationMinutes, req.Notes, req.MakeupFor))
+	if err != nil {
+		return models.Lesson{}, makeupError(err)
+	}
+	if req.MakeupFor != nil {
+		if _, err := tx.Exec(ctx, `UPDATE lessons SET makeup_owed = TRUE WHERE id = $1`, *req.MakeupFor); err != nil {
+			return models.Lesson{}, err
+		}
+	}
+	return lesson, tx.Commit(ctx)
 }
 
+// CreateBulk stores an explicit list of dates as a rule-less series.
 func (r *lessonRepository) CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest) ([]models.Lesson, error) {
-	seriesID := uuid.New().String()
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var seriesID string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO lesson_series (course_id, dtstart, timezone, duration_minutes, notes, generated_until)
+		 SELECT $1, MIN(sa::timestamptz),
+		        (SELECT t.timezone FROM courses c JOIN tutors t ON t.id = c.tutor_id WHERE c.id = $1),
+		        $2, $3, MAX(sa::timestamptz) + interval '1 second'
+		 FROM unnest($4::text[]) AS sa
+		 RETURNING id`,
+		req.CourseID, req.DurationMinutes, req.Notes, req.ScheduledAts,
+	).Scan(&seriesID)
+	if err != nil {
+		return nil, err
+	}
 
 	batch := &pgx.Batch{}
 	for _, sa := range req.ScheduledAts {
@@ -59,24 +99,26 @@ func (r *lessonRepository) CreateBulk(ctx context.Context, req models.CreateBulk
 			req.CourseID, sa, req.DurationMinutes, req.Notes, seriesID,
 		)
 	}
-	br := r.pool.SendBatch(ctx, batch)
-	defer br.Close()
+	br := tx.SendBatch(ctx, batch)
 
 	var lessons []models.Lesson
 	for range req.ScheduledAts {
 		var l models.Lesson
 		if err := br.QueryRow().Scan(&l.ID, &l.CourseID, &l.ScheduledAt, &l.DurationMinutes, &l.Status, &l.Notes, &l.SeriesID); err != nil {
+			br.Close()
 			return nil, err
 		}
 		lessons = append(lessons, l)
 	}
-	return lessons, nil
+	if err := br.Close(); err != nil {
+		return nil, err
+	}
+	return lessons, tx.Commit(ctx)
 }
 
 func (r *lessonRepository) GetByCourse(ctx context.Context, courseID string) ([]models.Lesson, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, course_id, scheduled_at, duration_minutes, status, notes, series_id
-		 FROM lessons WHERE course_id = $1 ORDER BY scheduled_at`, courseID)
+		`SELECT `+lessonColumns+` FROM lessons l WHERE l.course_id = $1 ORDER BY l.scheduled_at`, courseID)
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +126,8 @@ func (r *lessonRepository) GetByCourse(ctx context.Context, courseID string) ([]
 
 	lessons := []models.Lesson{}
 	for rows.Next() {
-		var lesson models.Lesson
-		if err := rows.Scan(&lesson.ID, &lesson.CourseID, &lesson.ScheduledAt, &lesson.DurationMinutes, &lesson.Status, &lesson.Notes, &lesson.SeriesID); err != nil {
+		lesson, err := scanLesson(rows)
+		if err != nil {
 			return nil, err
 		}
 		lessons = append(lessons, lesson)
@@ -94,39 +136,83 @@ func (r *lessonRepository) GetByCourse(ctx context.Context, courseID string) ([]
 }
 
 func (r *lessonRepository) GetByID(ctx context.Context, id string) (models.Lesson, error) {
-	var lesson models.Lesson
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, course_id, scheduled_at, duration_minutes, status, notes, series_id
-		 FROM lessons WHERE id = $1`, id,
-	).Scan(&lesson.ID, &lesson.CourseID, &lesson.ScheduledAt, &lesson.DurationMinutes, &lesson.Status, &lesson.Notes, &lesson.SeriesID)
-	return lesson, err
+	return scanLesson(r.pool.QueryRow(ctx,
+		`SELECT `+lessonColumns+` FROM lessons l WHERE l.id = $1`, id))
 }
 
 func (r *lessonRepository) GetByIDForTutor(ctx context.Context, id string, tutorID string) (models.Lesson, error) {
-	var lesson models.Lesson
-	err := r.pool.QueryRow(ctx,
-		`SELECT l.id, l.course_id, l.scheduled_at, l.duration_minutes, l.status, l.notes, l.series_id
+	return scanLesson(r.pool.QueryRow(ctx,
+		`SELECT `+lessonColumns+`
 		 FROM lessons l
 		 JOIN courses c ON c.id = l.course_id
-		 WHERE l.id = $1 AND c.tutor_id = $2`, id, tutorID,
-	).Scan(&lesson.ID, &lesson.CourseID, &lesson.ScheduledAt, &lesson.DurationMinutes, &lesson.Status, &lesson.Notes, &lesson.SeriesID)
-	return lesson, err
+		 WHERE l.id = $1 AND c.tutor_id = $2`, id, tutorID))
 }
 
-func (r *lessonRepository) Update(ctx context.Context, id string, req models.UpdateLessonRequest) (models.Lesson, error) {
-	var lesson models.Lesson
-	err := r.pool.QueryRow(ctx,
-		`UPDATE lessons SET scheduled_at=$1, duration_minutes=$2, status=$3, notes=$4
-		 WHERE id=$5
-		 RETURNING id, course_id, scheduled_at, duration_minutes, status, notes, series_id`,
-		req.ScheduledAt, req.DurationMinutes, req.Status, req.Notes, id,
-	).Scan(&lesson.ID, &lesson.CourseID, &lesson.ScheduledAt, &lesson.DurationMinutes, &lesson.Status, &lesson.Notes, &lesson.SeriesID)
-	return lesson, err
+// Update writes the lesson and, when its status changes, the change's charge and a
+// lesson_status_events row; a new scheduled_at is recorded as a move. It fails with
+// ErrStatusChanged if the lesson no longer has change.From.
+func (r *lessonRepository) Update(ctx context.Context, id string, req models.UpdateLessonRequest, change models.StatusChange) (models.Lesson, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	var previousAt time.Time
+	if err := tx.QueryRow(ctx,
+		`SELECT status, scheduled_at FROM lessons WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&previous, &previousAt); err != nil {
+		return models.Lesson{}, err
+	}
+	if previous != change.From {
+		return models.Lesson{}, ErrStatusChanged
+	}
+
+	lesson, err := scanLesson(tx.QueryRow(ctx,
+		`UPDATE lessons l SET scheduled_at=$1, duration_minutes=$2, status=$3, notes=$4,
+		        charge = CASE WHEN status <> $3 THEN $5 ELSE charge END,
+		        original_scheduled_at = CASE WHEN scheduled_at <> $1 THEN COALESCE(original_scheduled_at, scheduled_at)
+		                                     ELSE original_scheduled_at END,
+		        makeup_owed = COALESCE($7, makeup_owed),
+		        homework = COALESCE($8, homework)
+		 WHERE id=$6
+		 RETURNING `+lessonColumns,
+		req.ScheduledAt, req.DurationMinutes, req.Status, req.Notes, change.Charge, id, req.MakeupOwed, req.Homework))
+	if err != nil {
+		return models.Lesson{}, makeupError(err)
+	}
+	if !lesson.ScheduledAt.Equal(previousAt) {
+		if err := insertMoveEvent(ctx, tx, id, previous, previousAt, lesson.ScheduledAt, change.Actor, ""); err != nil {
+			return models.Lesson{}, err
+		}
+	}
+	if lesson.Status != previous {
+		if err := insertStatusEvent(ctx, tx, id, &previous, lesson.Status, lesson.Charge, change.Actor, change.Reason); err != nil {
+			return models.Lesson{}, err
+		}
+	}
+	return lesson, tx.Commit(ctx)
 }
 
 func (r *lessonRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM lessons WHERE id = $1`, id)
-	return err
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Remember the slot as an EXDATE so extending or regenerating the series won't bring it back.
+	if _, err := tx.Exec(ctx,
+		`UPDATE lesson_series s SET exdates = array_append(s.exdates, l.scheduled_at)
+		 FROM lessons l
+		 WHERE l.id = $1 AND s.id = l.series_id`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM lessons WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *lessonRepository) DeleteByCourse(ctx context.Context, courseID string, tutorID string) error {
@@ -140,87 +226,39 @@ func (r *lessonRepository) DeleteByCourse(ctx context.Context, courseID string,
 	return err
 }
 
-func (r *lessonRepository) DeleteSeries(ctx context.Context, seriesID string, tutorID string, fromDate *string) error {
-	args := []interface{}{seriesID, tutorID}
-	fromClause := ""
-	if fromDate != nil {
-		fromClause = fmt.Sprintf("AND lessons.scheduled_at >= $%d::timestamptz", len(args)+1)
-		args = append(args, *fromDate)
-	}
-
-	query := fmt.Sprintf(`
-		DELETE FROM lessons
-		USING courses
-		WHERE lessons.series_id = $1
-		  AND lessons.course_id = courses.id
-		  AND courses.tutor_id = $2
-		  %s`, fromClause)
-
-	_, err := r.pool.Exec(ctx, query, args...)
-	return err
-}
-
-func (r *lessonRepository) UpdateSeries(ctx context.Context, seriesID string, tutorID string, req models.UpdateSeriesRequest) error {
-	setParts := []string{}
-	args := []interface{}{seriesID, tutorID}
-
-	if req.NewTime != nil {
-		idx := len(args) + 1
-		setParts = append(setParts, fmt.Sprintf("scheduled_at = date_trunc('day', lessons.scheduled_at) + $%d::interval", idx))
-		args = append(args, *req.NewTime)
-	}
-	if req.DurationMinutes != nil {
-		idx := len(args) + 1
-		setParts = append(setParts, fmt.Sprintf("duration_minutes = $%d", idx))
-		args = append(args, *req.DurationMinutes)
-	}
-	if req.Notes != nil {
-		idx := len(args) + 1
-		setParts = append(setParts, fmt.Sprintf("notes = $%d", idx))
-		args = append(args, *req.Notes)
-	}
-
-	if len(setParts) == 0 {
-		return nil
-	}
-
-	fromClause := ""
-	if req.FromDate != nil {
-		idx := len(args) + 1
-		fromClause = fmt.Sprintf("AND lessons.scheduled_at >= $%d::timestamptz", idx)
-		args = append(args, *req.FromDate)
-	}
-
-	query := fmt.Sprintf(`
-		UPDATE lessons
-		SET %s
-		FROM courses
-		WHERE lessons.series_id = $1
-		  AND lessons.course_id = courses.id
-		  AND courses.tutor_id = $2
-		  %s`,
-		strings.Join(setParts, ", "), fromClause)
-
-	_, err := r.pool.Exec(ctx, query, args...)
-	return err
-}
-
-func (r *lessonRepository) GetCalendar(ctx context.Context, tutorID string, from string, to string) ([]models.CalendarLesson, error) {
-	rows, err := r.pool.Query(ctx,
-		`SELECT l.id, l.course_id, l.scheduled_at, l.duration_minutes, l.status, l.notes,
+// calendarColumns are read from calendarJoins.
+const calendarColumns = `l.id, l.course_id, l.scheduled_at, l.duration_minutes, l.status, l.notes,
 		        c.subject,
 		        CASE WHEN c.student_id IS NOT NULL
 		             THEN CASE WHEN s.last_name = '' THEN s.first_name ELSE s.first_name || ' ' || s.last_name END
 		             ELSE NULL
 		        END AS student_name,
 		        (c.student_id IS NULL) AS is_group,
-		        l.series_id
-		 FROM lessons l
+		        l.series_id,
+		        to_char(l.scheduled_at AT TIME ZONE t.timezone, 'YYYY-MM-DD') AS local_date,
+		        l.original_scheduled_at, l.makeup_for`
+
+const calendarJoins = `lessons l
 		 JOIN courses c ON c.id = l.course_id
-		 LEFT JOIN students s ON s.id = c.student_id
+		 JOIN tutors t ON t.id = c.tutor_id
+		 LEFT JOIN students s ON s.id = c.student_id`
+
+func scanCalendarLesson(row pgx.Row) (models.CalendarLesson, error) {
+	var cl models.CalendarLesson
+	err := row.Scan(&cl.ID, &cl.CourseID, &cl.ScheduledAt, &cl.DurationMinutes,
+		&cl.Status, &cl.Notes, &cl.Subject, &cl.StudentName, &cl.IsGroup, &cl.SeriesID, &cl.LocalDate, &cl.OriginalScheduledAt, &cl.MakeupFor)
+	return cl, err
+}
+
+// GetCalendar accepts from/to either as RFC3339 instants or as plain dates (YYYY-MM-DD);
+// plain dates are midnights in the tutor's timezone. local_date is the lesson's day in that zone.
+func (r *lessonRepository) GetCalendar(ctx context.Context, tutorID string, from string, to string) ([]models.CalendarLesson, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+calendarColumns+`
+		 FROM `+calendarJoins+`
 		 WHERE c.tutor_id = $1
-		   AND l.scheduled_at >= $2::timestamptz
-		   AND l.scheduled_at < $3::timestamptz
+		   AND l.scheduled_at >= `+localBound("$2")+`
+		   AND l.scheduled_at < `+localBound("$3")+`
 		 ORDER BY l.scheduled_at`,
 		tutorID, from, to)
 	if err != nil {
@@ -230,9 +268,8 @@ func (r *lessonRepository) GetCalendar(ctx context.Context, tutorID string, from
 
 	var lessons []models.CalendarLesson
 	for rows.Next() {
-		var cl models.CalendarLesson
-		if err := rows.Scan(&cl.ID, &cl.CourseID, &cl.ScheduledAt, &cl.DurationMinutes,
-			&cl.Status, &cl.Notes, &cl.Subject, &cl.StudentName, &cl.IsGroup, &cl.SeriesID); err != nil {
+		cl, err := scanCalendarLesson(rows)
+		if err != nil {
 			return nil, err
 		}
 		lessons = append(lessons, cl)
@@ -240,11 +277,27 @@ func (r *lessonRepository) GetCalendar(ctx context.Context, tutorID string, from
 	return lessons, rows.Err()
 }
 
+// localBound turns a text parameter into a timestamptz, reading a bare date as
+// midnight in the tutor's zone (expects tutors aliased as t).
+func localBound(param string) string {
+	return `CASE WHEN ` + param + `::text ~ '^\d{4}-\d{2}-\d{2}$'
+		             THEN ` + param + `::text::timestamp AT TIME ZONE t.timezone
+		             ELSE ` + param + `::text::timestamptz END`
+}
+
+// AutoComplete compares absolute instants, so lessons end at the same moment whatever
+// the tutor's zone is; no per-tutor conversion is needed here.
 func (r *lessonRepository) AutoComplete(ctx context.Context) (int64, error) {
 	result, err := r.pool.Exec(ctx,
-		`UPDATE lessons SET status = 'completed'
-		 WHERE status = 'scheduled'
-		   AND scheduled_at + duration_minutes * interval '1 minute' < NOW()`)
+		`WITH completed AS (
+		     UPDATE lessons SET status = 'completed'
+		     WHERE status = 'scheduled'
+		       AND scheduled_at + duration_minutes * interval '1 minute' < NOW()
+		     RETURNING id
+		 )
+		 INSERT INTO lesson_status_events (lesson_id, from_status, to_status, actor_type, reason)
+		 SELECT id, 'scheduled', 'completed', $1, 'lesson ended'
+		 FROM completed`, models.ActorSystem)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrStatusChanged means the lesson left the status a transition was checked
+// against before the update could be written.
+var ErrStatusChanged = errors.New("lesson status changed")
+
+// insertStatusEvent records a lesson status transition inside the caller's transaction.
+func insertStatusEvent(ctx context.Context, tx pgx.Tx, lessonID string, from *string, to string, charge *string, actor models.Actor, reason string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO lesson_status_events (lesson_id, from_status, to_status, charge, actor_type, actor_id, reason)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		lessonID, from, to, charge, actor.Type, actor.ID, reason)
+	return err
+}
+
+// insertMoveEvent records that a lesson moved from one slot to another; the status
+// is repeated on both sides.
+func insertMoveEvent(ctx context.Context, tx pgx.Tx, lessonID string, status string, from, to time.Time, actor models.Actor, reason string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO lesson_status_events (lesson_id, from_status, to_status, actor_type, actor_id, reason, previous_scheduled_at, scheduled_at)
+		 VALUES ($1, $2, $2, $3, $4, $5, $6, $7)`,
+		lessonID, status, actor.Type, actor.ID, reason, from, to)
+	return err
+}
+
+func (r *lessonRepository) GetStatusEvents(ctx context.Context, lessonID string) ([]models.LessonStatusEvent, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, lesson_id, from_status, to_status, charge, reason, actor_type, actor_id, created_at,
+		        previous_scheduled_at, scheduled_at
+		 FROM lesson_status_events
+		 WHERE lesson_id = $1
+		 ORDER BY created_at, id`, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.LessonStatusEvent{}
+	for rows.Next() {
+		var e models.LessonStatusEvent
+		if err := rows.Scan(&e.ID, &e.LessonID, &e.FromStatus, &e.ToStatus, &e.Charge, &e.Reason, &e.ActorType, &e.ActorID, &e.CreatedAt,
+			&e.PreviousScheduledAt, &e.ScheduledAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrMakeupExists is returned when a lesson already has a makeup that isn't cancelled.
+var ErrMakeupExists = errors.New("makeup exists")
+
+// makeupError turns a violation of the one-makeup-per-lesson index into ErrMakeupExists.
+func makeupError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_lessons_makeup_for" {
+		return ErrMakeupExists
+	}
+	return err
+}
+
+type MakeupRepository interface {
+	GetForCourse(ctx context.Context, courseID string) (models.MakeupCredits, error)
+	GetForStudent(ctx context.Context, studentID string, tutorID string) ([]models.MakeupCredits, error)
+}
+
+type makeupRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMakeupRepository(pool *pgxpool.Pool) MakeupRepository {
+	return &makeupRepository{pool: pool}
+}
+
+// makeupCreditsQuery counts, per course c, the lessons o that owe a makeup which
+// hasn't taken place yet; the caller appends the WHERE clause.
+const makeupCreditsQuery = `SELECT c.id,
+	       COUNT(o.id),
+	       COUNT(o.id) FILTER (WHERE EXISTS (
+	           SELECT 1 FROM lessons m WHERE m.makeup_for = o.id AND m.status IN ('scheduled', 'pending')))
+	FROM courses c
+	LEFT JOIN lessons o ON o.course_id = c.id
+	     AND o.makeup_owed AND o.status IN ('cancelled', 'missed')
+	     AND NOT EXISTS (SELECT 1 FROM lessons m WHERE m.makeup_for = o.id AND m.status IN ('completed', 'missed'))
+	`
+
+func (r *makeupRepository) GetForCourse(ctx context.Context, courseID string) (models.MakeupCredits, error) {
+	var mc models.MakeupCredits
+	err := r.pool.QueryRow(ctx,
+		makeupCreditsQuery+`WHERE c.id = $1 GROUP BY c.id`, courseID,
+	).Scan(&mc.CourseID, &mc.Owed, &mc.Scheduled)
+	return mc, err
+}
+
+func (r *makeupRepository) GetForStudent(ctx context.Context, studentID string, tutorID string) ([]models.MakeupCredits, error) {
+	rows, err := r.pool.Query(ctx,
+		makeupCreditsQuery+`WHERE c.student_id = $1 AND c.tutor_id = $2
+		 GROUP BY c.id
+		 ORDER BY c.started_at`, studentID, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []models.MakeupCredits{}
+	for rows.Next() {
+		var mc models.MakeupCredits
+		if err := rows.Scan(&mc.CourseID, &mc.Owed, &mc.Scheduled); err != nil {
+			return nil, err
+		}
+		credits = append(credits, mc)
+	}
+	return credits, rows.Err()
+}
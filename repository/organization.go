@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrAlreadyMember is returned when inviting a tutor who is already a member of
+// the organization, or invited to it.
+var ErrAlreadyMember = errors.New("tutor is already a member")
+
+// ErrIndividualCourse is returned when assigning a one-student course on its
+// own: it goes with its student.
+var ErrIndividualCourse = errors.New("course has a single student")
+
+// ErrOpenInvoices is returned when assigning courses that have invoices not yet
+// paid.
+var ErrOpenInvoices = errors.New("course has open invoices")
+
+// ErrInGroupCourse is returned when assigning a student who is still enrolled in
+// group courses of their tutor.
+var ErrInGroupCourse = errors.New("student is in group courses")
+
+// OrganizationRepository stores schools and their members. Who may do what in
+// a school is the service's to check; reads across members are scoped by the
+// organization, never by the caller.
+type OrganizationRepository interface {
+	Create(ctx context.Context, tutorID string, req models.CreateOrganizationRequest) (models.Organization, error)
+	GetAll(ctx context.Context, tutorID string) ([]models.Organization, error)
+	GetMembership(ctx context.Context, id string, tutorID string) (models.Organization, error)
+	Update(ctx context.Context, id string, req models.UpdateOrganizationRequest) error
+	Delete(ctx context.Context, id string) error
+	GetMembers(ctx context.Context, id string) ([]models.OrganizationMember, error)
+	AddMember(ctx context.Context, id string, email string, role string) (models.OrganizationMember, error)
+	UpdateMember(ctx context.Context, id string, tutorID string, role string) (models.OrganizationMember, error)
+	RemoveMember(ctx context.Context, id string, tutorID string) error
+	Join(ctx context.Context, id string, tutorID string) error
+	GetCalendar(ctx context.Context, id string, from string, to string) ([]models.OrganizationLesson, error)
+	AssignStudent(ctx context.Context, id string, studentID string, tutorID string) error
+	AssignCourse(ctx context.Context, id string, courseID string, tutorID string) error
+}
+
+type organizationRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewOrganizationRepository(conn *pgxpool.Pool) OrganizationRepository {
+	return &organizationRepository{conn: conn}
+}
+
+const organizationColumns = `o.id, o.name, o.created_at, m.role, m.joined_at`
+
+func scanOrganization(row pgx.Row) (models.Organization, error) {
+	var o models.Organization
+	err := row.Scan(&o.ID, &o.Name, &o.CreatedAt, &o.Role, &o.JoinedAt)
+	return o, err
+}
+
+const memberColumns = `m.tutor_id, t.first_name, t.last_name, t.email, m.role, m.invited_at, m.joined_at`
+
+func scanMember(row pgx.Row) (models.OrganizationMember, error) {
+	var m models.OrganizationMember
+	err := row.Scan(&m.TutorID, &m.FirstName, &m.LastName, &m.Email, &m.Role, &m.InvitedAt, &m.JoinedAt)
+	return m, err
+}
+
+// joinedMember holds for the tutors who have joined organization $1.
+const joinedMember = `IN (SELECT tutor_id FROM organization_members
+		 WHERE organization_id = $1 AND joined_at IS NOT NULL)`
+
+// Create makes the tutor the owner of a new organization.
+func (r *organizationRepository) Create(ctx context.Context, tutorID string, req models.CreateOrganizationRequest) (models.Organization, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Organization{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	org := models.Organization{Role: models.OrgRoleOwner}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO organizations (name) VALUES ($1) RETURNING id, name, created_at`, req.Name,
+	).Scan(&org.ID, &org.Name, &org.CreatedAt); err != nil {
+		return models.Organization{}, err
+	}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO organization_members (organization_id, tutor_id, role, joined_at)
+		 VALUES ($1, $2, $3, NOW())
+		 RETURNING joined_at`, org.ID, tutorID, models.OrgRoleOwner,
+	).Scan(&org.JoinedAt); err != nil {
+		return models.Organization{}, err
+	}
+	return org, tx.Commit(ctx)
+}
+
+// GetAll lists the tutor's organizations and the invitations they have.
+func (r *organizationRepository) GetAll(ctx context.Context, tutorID string) ([]models.Organization, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+organizationColumns+`
+		 FROM organizations o
+		 JOIN organization_members m ON m.organization_id = o.id
+		 WHERE m.tutor_id = $1
+		 ORDER BY o.name`, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []models.Organization{}
+	for rows.Next() {
+		o, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, o)
+	}
+	return orgs, rows.Err()
+}
+
+// GetMembership gives the organization as the tutor sees it; pgx.ErrNoRows if
+// they are neither a member nor invited.
+func (r *organizationRepository) GetMembership(ctx context.Context, id string, tutorID string) (models.Organization, error) {
+	return scanOrganization(r.conn.QueryRow(ctx,
+		`SELECT `+organizationColumns+`
+		 FROM organizations o
+		 JOIN organization_members m ON m.organization_id = o.id
+		 WHERE o.id = $1 AND m.tutor_id = $2`, id, tutorID))
+}
+
+func (r *organizationRepository) Update(ctx context.Context, id string, req models.UpdateOrganizationRequest) error {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE organizations SET name = $2 WHERE id = $1`, id, req.Name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes the organization and its memberships; the members' students,
+// courses and payments stay theirs.
+func (r *organizationRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.conn.Exec(ctx, `DELETE FROM organizations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *organizationRepository) GetMembers(ctx context.Context, id string) ([]models.OrganizationMember, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+memberColumns+`
+		 FROM organization_members m
+		 JOIN tutors t ON t.id = m.tutor_id
+		 WHERE m.organization_id = $1
+		 ORDER BY m.joined_at IS NULL, t.first_name, t.last_name`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.OrganizationMember{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddMember invites the tutor with the email. pgx.ErrNoRows if there is no such
+// tutor, ErrAlreadyMember if they are a member or invited already.
+func (r *organizationRepository) AddMember(ctx context.Context, id string, email string, role string) (models.OrganizationMember, error) {
+	var tutorID string
+	if err := r.conn.QueryRow(ctx,
+		`SELECT id FROM tutors WHERE email = $1`, email,
+	).Scan(&tutorID); err != nil {
+		return models.OrganizationMember{}, err
+	}
+
+	member, err := scanMember(r.conn.QueryRow(ctx,
+		`WITH m AS (
+		     INSERT INTO organization_members (organization_id, tutor_id, role)
+		     VALUES ($1, $2, $3)
+		     ON CONFLICT DO NOTHING
+		     RETURNING *
+		 )
+		 SELECT `+memberColumns+` FROM m JOIN tutors t ON t.id = m.tutor_id`,
+		id, tutorID, role))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.OrganizationMember{}, ErrAlreadyMember
+	}
+	return member, err
+}
+
+func (r *organizationRepository) UpdateMember(ctx context.Context, id string, tutorID string, role string) (models.OrganizationMember, error) {
+	return scanMember(r.conn.QueryRow(ctx,
+		`WITH m AS (
+		     UPDATE organization_members SET role = $3
+		     WHERE organization_id = $1 AND tutor_id = $2
+		     RETURNING *
+		 )
+		 SELECT `+memberColumns+` FROM m JOIN tutors t ON t.id = m.tutor_id`,
+		id, tutorID, role))
+}
+
+// RemoveMember removes a member or withdraws an invitation.
+func (r *organizationRepository) RemoveMember(ctx context.Context, id string, tutorID string) error {
+	tag, err := r.conn.Exec(ctx,
+		`DELETE FROM organization_members WHERE organization_id = $1 AND tutor_id = $2`, id, tutorID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Join accepts the tutor's invitation; pgx.ErrNoRows if they have none pending.
+func (r *organizationRepository) Join(ctx context.Context, id string, tutorID string) error {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE organization_members SET joined_at = NOW()
+		 WHERE organization_id = $1 AND tutor_id = $2 AND joined_at IS NULL`, id, tutorID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetCalendar lists the lessons of all who have joined, like the tutor calendar:
+// plain dates, and local_date, are in each lesson's tutor's timezone.
+func (r *organizationRepository) GetCalendar(ctx context.Context, id string, from string, to string) ([]models.OrganizationLesson, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+calendarColumns+`,
+		        t.id, CASE WHEN t.last_name = '' THEN t.first_name ELSE t.first_name || ' ' || t.last_name END
+		 FROM `+calendarJoins+`
+		 WHERE c.tutor_id `+joinedMember+`
+		   AND l.scheduled_at >= `+localBound("$2")+`
+		   AND l.scheduled_at < `+localBound("$3")+`
+		 ORDER BY l.scheduled_at`,
+		id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lessons := []models.OrganizationLesson{}
+	for rows.Next() {
+		var ol models.OrganizationLesson
+		cl := &ol.CalendarLesson
+		if err := rows.Scan(&cl.ID, &cl.CourseID, &cl.ScheduledAt, &cl.DurationMinutes,
+			&cl.Status, &cl.Notes, &cl.Subject, &cl.StudentName, &cl.IsGroup, &cl.SeriesID, &cl.LocalDate, &cl.OriginalScheduledAt, &cl.MakeupFor,
+			&ol.TutorID, &ol.TutorName); err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, ol)
+	}
+	return lessons, rows.Err()
+}
+
+// AssignStudent hands a student of a member over to another tutor, with the
+// student's individual courses.
+// pgx.ErrNoRows if no member has the student, ErrOpenInvoices if one of the
+// courses has invoices not yet paid. Group courses stay with their tutors, so a
+// student still enrolled in one of the old tutor's is ErrInGroupCourse.
+func (r *organizationRepository) AssignStudent(ctx context.Context, id string, studentID string, tutorID string) error {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	if err := tx.QueryRow(ctx,
+		`SELECT tutor_id FROM students
+		 WHERE id = $2 AND tutor_id `+joinedMember+`
+		 FOR UPDATE`, id, studentID,
+	).Scan(&previous); err != nil {
+		return err
+	}
+	var grouped bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS (
+		     SELECT 1 FROM course_enrollments e JOIN courses c ON c.id = e.course_id
+		     WHERE e.student_id = $1 AND c.tutor_id = $2
+		 )`, studentID, previous,
+	).Scan(&grouped); err != nil {
+		return err
+	}
+	if grouped {
+		return ErrInGroupCourse
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE students SET tutor_id = $2 WHERE id = $1`, studentID, tutorID); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM courses WHERE student_id = $1 AND tutor_id = $2 FOR UPDATE`, studentID, previous)
+	if err != nil {
+		return err
+	}
+	courseIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	if err := moveCourses(ctx, tx, courseIDs, tutorID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// AssignCourse hands a group course of a member over to another tutor; the
+// students stay whose they are. pgx.ErrNoRows
+// if no member has the course, ErrIndividualCourse if it is not a group's,
+// ErrOpenInvoices if it has invoices not yet paid.
+func (r *organizationRepository) AssignCourse(ctx context.Context, id string, courseID string, tutorID string) error {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var studentID *string
+	if err := tx.QueryRow(ctx,
+		`SELECT student_id FROM courses
+		 WHERE id = $2 AND tutor_id `+joinedMember+`
+		 FOR UPDATE`, id, courseID,
+	).Scan(&studentID); err != nil {
+		return err
+	}
+	if studentID != nil {
+		return ErrIndividualCourse
+	}
+	if err := moveCourses(ctx, tx, []string{courseID}, tutorID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// moveCourses gives the courses to the tutor. Payments recorded so far, with
+// their package sales and ledger entries, stay the income of the tutor who took
+// them; the balance counts them by course all the same, and what is paid from
+// now on is the new tutor's. Paid invoices stay with the tutor who issued them,
+// numbered in their series; open ones would be left billing for a course that
+// is no longer theirs, so they stop the move.
+//
+// The old tutor's booking links to the courses are deleted, and the requests
+// still pending through them declined: their slots were checked against the old
+// tutor's calendar, and their links would go on booking into it.
+func moveCourses(ctx context.Context, tx pgx.Tx, courseIDs []string, tutorID string) error {
+	if len(courseIDs) == 0 {
+		return nil
+	}
+	var open bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM invoices WHERE course_id = ANY($1) AND status <> 'paid')`, courseIDs,
+	).Scan(&open); err != nil {
+		return err
+	}
+	if open {
+		return ErrOpenInvoices
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE booking_requests br SET status = 'declined'
+		 FROM lessons l
+		 WHERE l.id = br.lesson_id AND l.course_id = ANY($1) AND br.status = 'pending'`, courseIDs); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM lessons WHERE course_id = ANY($1) AND status = 'pending'`, courseIDs); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM booking_links WHERE course_id = ANY($1)`, courseIDs); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx,
+		`UPDATE courses SET tutor_id = $2 WHERE id = ANY($1)`, courseIDs, tutorID)
+	return err
+}
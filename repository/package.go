@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PackageRepository interface {
+	Create(ctx context.Context, tutorID string, req models.CreatePackageRequest) (models.Package, error)
+	GetAll(ctx context.Context, tutorID string) ([]models.Package, error)
+	GetByID(ctx context.Context, id string, tutorID string) (models.Package, error)
+	Update(ctx context.Context, id string, tutorID string, req models.UpdatePackageRequest) (models.Package, error)
+	Delete(ctx context.Context, id string, tutorID string) error
+	Sell(ctx context.Context, pkg models.Package, req models.CreatePaymentRequest, startsAt time.Time, expiresAt *time.Time) (models.PackageSale, error)
+
+	GetCredits(ctx context.Context, courseID string) ([]models.LessonCredits, error)
+	GetCharges(ctx context.Context, courseID string) ([]models.LessonCharge, error)
+	GetStudentCharges(ctx context.Context, courseID string) ([]models.LessonCharge, error)
+}
+
+type packageRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewPackageRepository(conn *pgxpool.Pool) PackageRepository {
+	return &packageRepository{conn: conn}
+}
+
+const packageColumns = `id, name, kind, lessons_count, price, currency, valid_days, created_at`
+
+func scanPackage(row pgx.Row) (models.Package, error) {
+	var p models.Package
+	err := row.Scan(&p.ID, &p.Name, &p.Kind, &p.LessonsCount, &p.Price, &p.Currency, &p.ValidDays, &p.CreatedAt)
+	return p, err
+}
+
+// Create takes the tutor's home currency unless the request names one.
+func (r *packageRepository) Create(ctx context.Context, tutorID string, req models.CreatePackageRequest) (models.Package, error) {
+	return scanPackage(r.conn.QueryRow(ctx,
+		`INSERT INTO packages (tutor_id, name, kind, lessons_count, price, currency, valid_days)
+		 VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), (SELECT currency FROM tutors WHERE id = $1)), $7)
+		 RETURNING `+packageColumns,
+		tutorID, req.Name, req.Kind, req.LessonsCount, req.Price, req.Currency, req.ValidDays))
+}
+
+func (r *packageRepository) GetAll(ctx context.Context, tutorID string) ([]models.Package, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+packageColumns+` FROM packages WHERE tutor_id = $1 ORDER BY kind, price`, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packages := []models.Package{}
+	for rows.Next() {
+		p, err := scanPackage(rows)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, p)
+	}
+	return packages, rows.Err()
+}
+
+func (r *packageRepository) GetByID(ctx context.Context, id string, tutorID string) (models.Package, error) {
+	return scanPackage(r.conn.QueryRow(ctx,
+		`SELECT `+packageColumns+` FROM packages WHERE id = $1 AND tutor_id = $2`, id, tutorID))
+}
+
+func (r *packageRepository) Update(ctx context.Context, id string, tutorID string, req models.UpdatePackageRequest) (models.Package, error) {
+	return scanPackage(r.conn.QueryRow(ctx,
+		`UPDATE packages SET name = $1, lessons_count = $2, price = $3, currency = COALESCE(NULLIF($4, ''), currency), valid_days = $5
+		 WHERE id = $6 AND tutor_id = $7
+		 RETURNING `+packageColumns,
+		req.Name, req.LessonsCount, req.Price, req.Currency, req.ValidDays, id, tutorID))
+}
+
+// Delete removes the package; pgx.ErrNoRows if there is none. Credits already
+// sold keep its name.
+func (r *packageRepository) Delete(ctx context.Context, id string, tutorID string) error {
+	tag, err := r.conn.Exec(ctx,
+		`DELETE FROM packages WHERE id = $1 AND tutor_id = $2`, id, tutorID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Sell records the payment and the credits it gives in one transaction.
+func (r *packageRepository) Sell(ctx context.Context, pkg models.Package, req models.CreatePaymentRequest, startsAt time.Time, expiresAt *time.Time) (models.PackageSale, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.PackageSale{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	paymentID, err := createPayment(ctx, tx, req)
+	if err != nil {
+		return models.PackageSale{}, err
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO lesson_credits (payment_id, package_id, name, kind, starts_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		paymentID, pkg.ID, pkg.Name, pkg.Kind, startsAt, expiresAt); err != nil {
+		return models.PackageSale{}, err
+	}
+	payment, err := scanPayment(tx.QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_states WHERE id = $1`, paymentID))
+	if err != nil {
+		return models.PackageSale{}, err
+	}
+	sale := models.PackageSale{
+		Payment: payment,
+		Credits: models.LessonCredits{
+			PaymentID: paymentID, PackageID: &pkg.ID, Name: pkg.Name, Kind: pkg.Kind, StudentID: payment.StudentID,
+			LessonsCount: payment.LessonsCount, LessonsRemaining: float64(payment.LessonsCount),
+			StartsAt: startsAt, ExpiresAt: expiresAt,
+		},
+	}
+	return sale, tx.Commit(ctx)
+}
+
+// GetCredits lists the course's sold packages and subscription months. A voided
+// payment gives no lessons, a refunded one what was not given back.
+func (r *packageRepository) GetCredits(ctx context.Context, courseID string) ([]models.LessonCredits, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT p.id, lc.package_id, lc.name, lc.kind, p.student_id,
+		        CASE WHEN p.voided THEN 0 ELSE p.lessons_count - p.lessons_refunded END,
+		        lc.starts_at, lc.expires_at
+		 FROM lesson_credits lc
+		 JOIN payment_states p ON p.id = lc.payment_id
+		 WHERE p.course_id = $1
+		 ORDER BY lc.starts_at`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []models.LessonCredits{}
+	for rows.Next() {
+		var c models.LessonCredits
+		if err := rows.Scan(&c.PaymentID, &c.PackageID, &c.Name, &c.Kind, &c.StudentID, &c.LessonsCount, &c.StartsAt, &c.ExpiresAt); err != nil {
+			return nil, err
+		}
+		credits = append(credits, c)
+	}
+	return credits, rows.Err()
+}
+
+func scanCharges(rows pgx.Rows) ([]models.LessonCharge, error) {
+	defer rows.Close()
+	charges := []models.LessonCharge{}
+	for rows.Next() {
+		var c models.LessonCharge
+		if err := rows.Scan(&c.At, &c.Share, &c.StudentID); err != nil {
+			return nil, err
+		}
+		charges = append(charges, c)
+	}
+	return charges, rows.Err()
+}
+
+// GetCharges lists the course's charged lessons by chargedShare, oldest first.
+func (r *packageRepository) GetCharges(ctx context.Context, courseID string) ([]models.LessonCharge, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT scheduled_at, share, NULL::uuid FROM (
+		     SELECT l.scheduled_at, (`+chargedShare+`)::float8 AS share
+		     FROM lessons l
+		     WHERE l.course_id = $1
+		 ) c
+		 WHERE share > 0
+		 ORDER BY scheduled_at`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return scanCharges(rows)
+}
+
+// GetStudentCharges lists what each member of a group course is charged for by
+// attendedShare, oldest first.
+func (r *packageRepository) GetStudentCharges(ctx context.Context, courseID string) ([]models.LessonCharge, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT scheduled_at, share, student_id FROM (
+		     SELECT l.scheduled_at, a.student_id, (`+attendedShare+`)::float8 AS share
+		     FROM lesson_attendances a
+		     JOIN lessons l ON l.id = a.lesson_id
+		     WHERE l.course_id = $1
+		 ) c
+		 WHERE share > 0
+		 ORDER BY scheduled_at`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return scanCharges(rows)
+}
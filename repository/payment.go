@@ -4,16 +4,24 @@ import (
 	"context"
 	"tutorgo/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PaymentRepository interface {
 	Create(ctx context.Context, req models.CreatePaymentRequest) (models.Payment, error)
+	GetByID(ctx context.Context, id string, tutorID string) (models.Payment, error)
 	GetByCourse(ctx context.Context, courseID string, p models.Pagination) ([]models.Payment, int, error)
 	GetAllByTutor(ctx context.Context, tutorID string, limit int) ([]models.Payment, error)
 	GetAllByTutorPaged(ctx context.Context, tutorID string, p models.Pagination) ([]models.Payment, int, error)
 	GetBalance(ctx context.Context, courseID string) (models.CourseBalance, error)
-	GetMonthlyIncome(ctx context.Context, tutorID string) (float64, error)
+	GetStudentBalances(ctx context.Context, courseID string) ([]models.StudentBalance, error)
+	GetMonthlyIncome(ctx context.Context, tutorID string) (models.MonthlyIncome, error)
+
+	Correct(ctx context.Context, current models.Payment, req models.UpdatePaymentRequest) (models.Payment, error)
+	Void(ctx context.Context, current models.Payment, reason string) (models.Payment, error)
+	Refund(ctx context.Context, current models.Payment, req models.RefundPaymentRequest) (models.Payment, error)
+	GetEntries(ctx context.Context, paymentID string) ([]models.PaymentEntry, error)
 }
 
 type paymentRepository struct {
@@ -24,15 +32,68 @@ func NewPaymentRepository(conn *pgxpool.Pool) PaymentRepository {
 	return &paymentRepository{conn: conn}
 }
 
+// paymentColumns are read from payment_states, which folds each payment's ledger
+// into its current state.
+const paymentColumns = `id, course_id, subject, student_id, amount, currency, lessons_count, paid_at, refunded, lessons_refunded, voided`
+
+func scanPayment(row pgx.Row) (models.Payment, error) {
+	var p models.Payment
+	err := row.Scan(&p.ID, &p.CourseID, &p.Subject, &p.StudentID, &p.Amount, &p.Currency, &p.LessonsCount, &p.PaidAt,
+		&p.Refunded, &p.LessonsRefunded, &p.Voided)
+	return p, err
+}
+
+func scanPayments(rows pgx.Rows) ([]models.Payment, error) {
+	defer rows.Close()
+	payments := []models.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// Create records the payment and its first ledger entry. The payment takes the
+// course's currency unless the request names one.
 func (r *paymentRepository) Create(ctx context.Context, req models.CreatePaymentRequest) (models.Payment, error) {
-	var payment models.Payment
-	err := r.conn.QueryRow(ctx,
-		`INSERT INTO payments (course_id, amount, lessons_count, paid_at)
-		 VALUES ($1, $2, $3, $4)
-		 RETURNING id, course_id, amount, lessons_count, paid_at`,
-		req.CourseID, req.Amount, req.LessonsCount, req.PaidAt,
-	).Scan(&payment.ID, &payment.CourseID, &payment.Amount, &payment.LessonsCount, &payment.PaidAt)
-	return payment, err
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := createPayment(ctx, tx, req)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	payment, err := scanPayment(tx.QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_states WHERE id = $1`, id))
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return payment, tx.Commit(ctx)
+}
+
+func createPayment(ctx context.Context, tx pgx.Tx, req models.CreatePaymentRequest) (string, error) {
+	var id string
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO payments (tutor_id, course_id, subject, student_id, currency)
+		 SELECT c.tutor_id, c.id, c.subject, $2, COALESCE(NULLIF($3, ''), c.currency)
+		 FROM courses c WHERE c.id = $1
+		 RETURNING id`,
+		req.CourseID, req.StudentID, req.Currency,
+	).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, insertEntry(ctx, tx, id, models.EntryPayment, req.Amount, req.LessonsCount, req.PaidAt, "")
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id string, tutorID string) (models.Payment, error) {
+	return scanPayment(r.conn.QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_states WHERE id = $1 AND tutor_id = $2`, id, tutorID))
 }
 
 func (r *paymentRepository) GetByCourse(ctx context.Context, courseID string, p models.Pagination) ([]models.Payment, int, error) {
@@ -44,115 +105,194 @@ func (r *paymentRepository) GetByCourse(ctx context.Context, courseID string, p
 	}
 
 	rows, err := r.conn.Query(ctx,
-		`SELECT id, course_id, amount, lessons_count, paid_at
-		 FROM payments WHERE course_id = $1
+		`SELECT `+paymentColumns+`
+		 FROM payment_states WHERE course_id = $1
 		 ORDER BY paid_at DESC
 		 LIMIT $2 OFFSET $3`,
 		courseID, p.Limit, p.Offset())
 	if err != nil {
 		return nil, 0, err
 	}
-	defer rows.Close()
-
-	payments := []models.Payment{}
-	for rows.Next() {
-		var payment models.Payment
-		if err := rows.Scan(&payment.ID, &payment.CourseID, &payment.Amount, &payment.LessonsCount, &payment.PaidAt); err != nil {
-			return nil, 0, err
-		}
-		payments = append(payments, payment)
-	}
-	return payments, total, rows.Err()
+	payments, err := scanPayments(rows)
+	return payments, total, err
 }
 
+// GetAllByTutor includes payments whose course has since been deleted.
 func (r *paymentRepository) GetAllByTutor(ctx context.Context, tutorID string, limit int) ([]models.Payment, error) {
 	rows, err := r.conn.Query(ctx,
-		`SELECT p.id, p.course_id, p.amount, p.lessons_count, p.paid_at
-		 FROM payments p
-		 JOIN courses c ON c.id = p.course_id
-		 WHERE c.tutor_id = $1
-		 ORDER BY p.paid_at DESC
+		`SELECT `+paymentColumns+`
+		 FROM payment_states
+		 WHERE tutor_id = $1
+		 ORDER BY paid_at DESC
 		 LIMIT $2`, tutorID, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var payments []models.Payment
-	for rows.Next() {
-		var p models.Payment
-		if err := rows.Scan(&p.ID, &p.CourseID, &p.Amount, &p.LessonsCount, &p.PaidAt); err != nil {
-			return nil, err
-		}
-		payments = append(payments, p)
-	}
-	return payments, rows.Err()
+	return scanPayments(rows)
 }
 
 func (r *paymentRepository) GetAllByTutorPaged(ctx context.Context, tutorID string, p models.Pagination) ([]models.Payment, int, error) {
 	var total int
 	if err := r.conn.QueryRow(ctx,
-		`SELECT COUNT(*) FROM payments p
-		 JOIN courses c ON c.id = p.course_id
-		 WHERE c.tutor_id = $1`, tutorID,
+		`SELECT COUNT(*) FROM payments WHERE tutor_id = $1`, tutorID,
 	).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	rows, err := r.conn.Query(ctx,
-		`SELECT p.id, p.course_id, p.amount, p.lessons_count, p.paid_at
-		 FROM payments p
-		 JOIN courses c ON c.id = p.course_id
-		 WHERE c.tutor_id = $1
-		 ORDER BY p.paid_at DESC
+		`SELECT `+paymentColumns+`
+		 FROM payment_states
+		 WHERE tutor_id = $1
+		 ORDER BY paid_at DESC
 		 LIMIT $2 OFFSET $3`,
 		tutorID, p.Limit, p.Offset())
 	if err != nil {
 		return nil, 0, err
 	}
+	payments, err := scanPayments(rows)
+	return payments, total, err
+}
+
+// chargedShare is the part of lesson l that is billed: completed lessons in full,
+// missed and cancelled ones by the charge fixed when they got that status. Rows
+// from before cancellation policies have no charge: a no-show was billed, a
+// cancellation was free. Once a makeup has taken place it is billed instead of
+// the lesson it made up for.
+const chargedShare = `CASE
+	WHEN EXISTS (SELECT 1 FROM lessons m WHERE m.makeup_for = l.id AND m.status IN ('completed', 'missed')) THEN 0
+	WHEN l.status = 'completed' THEN 1
+	WHEN l.status IN ('missed', 'cancelled') THEN
+		CASE COALESCE(l.charge, CASE l.status WHEN 'missed' THEN 'full' ELSE 'free' END)
+			WHEN 'full' THEN 1
+			WHEN 'half' THEN 0.5
+			ELSE 0
+		END
+	ELSE 0
+END`
+
+// GetMonthlyIncome sums the ledger entries and charged lessons of the current
+// calendar month in the tutor's timezone, per currency. A refund counts in the
+// month it was made; a correction or void in the month of the payment it
+// undoes. Converting them into the home currency is left to the caller.
+func (r *paymentRepository) GetMonthlyIncome(ctx context.Context, tutorID string) (models.MonthlyIncome, error) {
+	income := models.MonthlyIncome{ByCurrency: []models.CurrencyIncome{}}
+	if err := r.conn.QueryRow(ctx,
+		`SELECT currency FROM tutors WHERE id = $1`, tutorID,
+	).Scan(&income.Currency); err != nil {
+		return models.MonthlyIncome{}, err
+	}
+
+	rows, err := r.conn.Query(ctx,
+		`WITH month AS (
+		     SELECT date_trunc('month', NOW() AT TIME ZONE t.timezone) AT TIME ZONE t.timezone AS start,
+		            (date_trunc('month', NOW() AT TIME ZONE t.timezone) + interval '1 month') AT TIME ZONE t.timezone AS "end"
+		     FROM tutors t WHERE t.id = $1
+		 ),
+		 received AS (
+		     SELECT p.currency, SUM(e.amount) AS total
+		     FROM payment_entries e
+		     JOIN payments p ON p.id = e.payment_id
+		     CROSS JOIN month
+		     WHERE p.tutor_id = $1
+		       AND e.effective_at >= month.start AND e.effective_at < month."end"
+		     GROUP BY p.currency
+		 ),
+		 earned AS (
+		     SELECT c.currency, ROUND(SUM(c.price_per_lesson * `+chargedShare+`), 2) AS earned
+		     FROM lessons l
+		     JOIN courses c ON c.id = l.course_id
+		     CROSS JOIN month
+		     WHERE c.tutor_id = $1
+		       AND l.scheduled_at >= month.start AND l.scheduled_at < month."end"
+		     GROUP BY c.currency
+		 )
+		 SELECT COALESCE(r.currency, e.currency) AS currency, COALESCE(r.total, 0), COALESCE(e.earned, 0)
+		 FROM received r
+		 FULL JOIN earned e ON e.currency = r.currency
+		 ORDER BY currency`,
+		tutorID)
+	if err != nil {
+		return models.MonthlyIncome{}, err
+	}
 	defer rows.Close()
 
-	payments := []models.Payment{}
 	for rows.Next() {
-		var payment models.Payment
-		if err := rows.Scan(&payment.ID, &payment.CourseID, &payment.Amount, &payment.LessonsCount, &payment.PaidAt); err != nil {
-			return nil, 0, err
+		var ci models.CurrencyIncome
+		if err := rows.Scan(&ci.Currency, &ci.Total, &ci.Earned); err != nil {
+			return models.MonthlyIncome{}, err
 		}
-		payments = append(payments, payment)
+		income.ByCurrency = append(income.ByCurrency, ci)
 	}
-	return payments, total, rows.Err()
-}
-
-func (r *paymentRepository) GetMonthlyIncome(ctx context.Context, tutorID string) (float64, error) {
-	var total float64
-	err := r.conn.QueryRow(ctx,
-		`SELECT COALESCE(SUM(p.amount), 0)
-		 FROM payments p
-		 JOIN courses c ON c.id = p.course_id
-		 WHERE c.tutor_id = $1
-		   AND p.paid_at >= date_trunc('month', NOW())
-		   AND p.paid_at <  date_trunc('month', NOW()) + interval '1 month'`,
-		tutorID,
-	).Scan(&total)
-	return total, err
+	return income, rows.Err()
 }
 
 func (r *paymentRepository) GetBalance(ctx context.Context, courseID string) (models.CourseBalance, error) {
-	var paid, completed int
+	var paid int
+	var charged float64
 	err := r.conn.QueryRow(ctx,
 		`SELECT
-			COALESCE((SELECT SUM(lessons_count) FROM payments WHERE course_id = $1), 0),
-			COUNT(id) FILTER (WHERE status IN ('completed', 'missed'))
-		FROM lessons
-		WHERE course_id = $1`,
+			COALESCE((SELECT SUM(e.lessons_count) FROM payment_entries e
+			          JOIN payments p ON p.id = e.payment_id
+			          WHERE p.course_id = $1), 0),
+			COALESCE(SUM(`+chargedShare+`), 0)::float8
+		FROM lessons l
+		WHERE l.course_id = $1`,
 		courseID,
-	).Scan(&paid, &completed)
+	).Scan(&paid, &charged)
 	if err != nil {
 		return models.CourseBalance{}, err
 	}
 	return models.CourseBalance{
 		LessonsPaid:      paid,
-		LessonsCompleted: completed,
-		LessonsRemaining: paid - completed,
+		LessonsCompleted: charged,
+		LessonsRemaining: float64(paid) - charged,
 	}, nil
 }
+
+// attendedShare is the part of group lesson l that student attendance row a is
+// billed for: present in full, absent by the charge fixed when it was recorded.
+// Cancelled lessons and ones that have been made up cost nothing.
+const attendedShare = `CASE
+	WHEN l.status = 'cancelled' THEN 0
+	WHEN EXISTS (SELECT 1 FROM lessons m WHERE m.makeup_for = l.id AND m.status IN ('completed', 'missed')) THEN 0
+	WHEN a.status = 'present' THEN 1
+	ELSE
+		CASE a.charge
+			WHEN 'full' THEN 1
+			WHEN 'half' THEN 0.5
+			ELSE 0
+		END
+END`
+
+// GetStudentBalances computes a balance for every student enrolled in the course.
+func (r *paymentRepository) GetStudentBalances(ctx context.Context, courseID string) ([]models.StudentBalance, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT e.student_id, s.first_name, s.last_name,
+		        COALESCE((SELECT SUM(pe.lessons_count) FROM payment_entries pe
+		                  JOIN payments p ON p.id = pe.payment_id
+		                  WHERE p.course_id = e.course_id AND p.student_id = e.student_id), 0),
+		        COALESCE((SELECT SUM(`+attendedShare+`)
+		                  FROM lesson_attendances a
+		                  JOIN lessons l ON l.id = a.lesson_id
+		                  WHERE l.course_id = e.course_id AND a.student_id = e.student_id), 0)::float8
+		 FROM course_enrollments e
+		 JOIN students s ON s.id = e.student_id
+		 WHERE e.course_id = $1
+		 ORDER BY s.first_name, s.last_name`,
+		courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []models.StudentBalance{}
+	for rows.Next() {
+		var b models.StudentBalance
+		if err := rows.Scan(&b.StudentID, &b.FirstName, &b.LastName, &b.LessonsPaid, &b.LessonsCompleted); err != nil {
+			return nil, err
+		}
+		b.LessonsRemaining = float64(b.LessonsPaid) - b.LessonsCompleted
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
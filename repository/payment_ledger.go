@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPaymentChanged means another correction, void or refund was written after
+// the payment was read and checked.
+var ErrPaymentChanged = errors.New("payment changed")
+
+const entryColumns = `id, payment_id, kind, amount, lessons_count, effective_at, reason, created_at`
+
+func insertEntry(ctx context.Context, tx pgx.Tx, paymentID string, kind string, amount money.Amount, lessons int, at time.Time, reason string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO payment_entries (payment_id, kind, amount, lessons_count, effective_at, reason)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		paymentID, kind, amount, lessons, at, reason)
+	return err
+}
+
+func samePaymentState(a, b models.Payment) bool {
+	return a.Amount == b.Amount && a.LessonsCount == b.LessonsCount && a.PaidAt.Equal(b.PaidAt) &&
+		a.Refunded == b.Refunded && a.LessonsRefunded == b.LessonsRefunded && a.Voided == b.Voided
+}
+
+// amendPayment locks the payment, makes sure it is still in the state the caller
+// checked (ErrPaymentChanged otherwise), lets write add the entries and returns
+// the new state.
+func (r *paymentRepository) amendPayment(ctx context.Context, current models.Payment, write func(tx pgx.Tx) error) (models.Payment, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err := tx.QueryRow(ctx,
+		`SELECT id FROM payments WHERE id = $1 FOR UPDATE`, current.ID,
+	).Scan(&id); err != nil {
+		return models.Payment{}, err
+	}
+	state, err := scanPayment(tx.QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_states WHERE id = $1`, id))
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if !samePaymentState(state, current) {
+		return models.Payment{}, ErrPaymentChanged
+	}
+	if err := write(tx); err != nil {
+		return models.Payment{}, err
+	}
+	payment, err := scanPayment(tx.QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_states WHERE id = $1`, id))
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return payment, tx.Commit(ctx)
+}
+
+// Correct reverses the payment's last posting, at its date, and posts the
+// corrected values.
+func (r *paymentRepository) Correct(ctx context.Context, current models.Payment, req models.UpdatePaymentRequest) (models.Payment, error) {
+	return r.amendPayment(ctx, current, func(tx pgx.Tx) error {
+		if err := insertEntry(ctx, tx, current.ID, models.EntryReversal, -current.Amount, -current.LessonsCount, current.PaidAt, req.Reason); err != nil {
+			return err
+		}
+		return insertEntry(ctx, tx, current.ID, models.EntryPayment, req.Amount, req.LessonsCount, req.PaidAt, req.Reason)
+	})
+}
+
+// Void reverses the payment's last posting, at its date.
+func (r *paymentRepository) Void(ctx context.Context, current models.Payment, reason string) (models.Payment, error) {
+	return r.amendPayment(ctx, current, func(tx pgx.Tx) error {
+		return insertEntry(ctx, tx, current.ID, models.EntryReversal, -current.Amount, -current.LessonsCount, current.PaidAt, reason)
+	})
+}
+
+// Refund records money and lessons given back, at the refund's own date.
+func (r *paymentRepository) Refund(ctx context.Context, current models.Payment, req models.RefundPaymentRequest) (models.Payment, error) {
+	return r.amendPayment(ctx, current, func(tx pgx.Tx) error {
+		return insertEntry(ctx, tx, current.ID, models.EntryRefund, -req.Amount, -req.LessonsCount, *req.RefundedAt, req.Reason)
+	})
+}
+
+func (r *paymentRepository) GetEntries(ctx context.Context, paymentID string) ([]models.PaymentEntry, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+entryColumns+` FROM payment_entries WHERE payment_id = $1 ORDER BY seq`, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.PaymentEntry{}
+	for rows.Next() {
+		var e models.PaymentEntry
+		if err := rows.Scan(&e.ID, &e.PaymentID, &e.Kind, &e.Amount, &e.LessonsCount, &e.EffectiveAt, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrEmailTaken is returned when inviting an email that has already joined the
+// portal for this student.
+var ErrEmailTaken = errors.New("email already has a portal account for the student")
+
+// PortalRepository reads for the portal by student rather than by tutor; who
+// may see which student is settled by the account signed in.
+type PortalRepository interface {
+	CreateAccount(ctx context.Context, studentID string, req models.InvitePortalRequest) (models.PortalAccount, error)
+	GetAccounts(ctx context.Context, studentID string) ([]models.PortalAccount, error)
+	GetAccount(ctx context.Context, id string) (models.PortalAccount, error)
+	GetLogins(ctx context.Context, email string) ([]models.PortalLogin, error)
+	DeleteAccount(ctx context.Context, id string, studentID string) error
+	Accept(ctx context.Context, id string, passwordHash string) error
+	IsActive(ctx context.Context, id string) (bool, error)
+	GetProfile(ctx context.Context, id string) (models.PortalProfile, error)
+	GetTimezone(ctx context.Context, studentID string) (string, error)
+	GetCourses(ctx context.Context, studentID string) ([]models.Course, error)
+	GetLessons(ctx context.Context, studentID string, from time.Time, to time.Time) ([]models.PortalLesson, error)
+	GetHomework(ctx context.Context, studentID string, limit int) ([]models.PortalLesson, error)
+}
+
+type portalRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewPortalRepository(conn *pgxpool.Pool) PortalRepository {
+	return &portalRepository{conn: conn}
+}
+
+const portalAccountColumns = `a.id, a.student_id, a.role, a.name, a.email, a.invited_at, a.joined_at`
+
+func scanPortalAccount(row pgx.Row, dest ...any) (models.PortalAccount, error) {
+	var a models.PortalAccount
+	err := row.Scan(append([]any{&a.ID, &a.StudentID, &a.Role, &a.Name, &a.Email, &a.InvitedAt, &a.JoinedAt}, dest...)...)
+	return a, err
+}
+
+// studentCourse holds for the courses of student $1: their individual ones and
+// the groups they are enrolled in.
+const studentCourse = `(c.student_id = $1
+		OR EXISTS (SELECT 1 FROM course_enrollments e WHERE e.course_id = c.id AND e.student_id = $1))`
+
+const portalLessonColumns = `l.id, l.course_id, c.subject, l.scheduled_at, l.duration_minutes, l.status,
+	c.student_id IS NULL, l.homework`
+
+func scanPortalLesson(row pgx.Row) (models.PortalLesson, error) {
+	var l models.PortalLesson
+	err := row.Scan(&l.ID, &l.CourseID, &l.Subject, &l.ScheduledAt, &l.DurationMinutes, &l.Status, &l.IsGroup, &l.Homework)
+	return l, err
+}
+
+// CreateAccount stores an invitation, or returns the pending one of the student
+// for the email so that inviting again resends it. It fails with ErrEmailTaken
+// if the email has joined for the student already; accounts of the email for
+// other students don't matter.
+func (r *portalRepository) CreateAccount(ctx context.Context, studentID string, req models.InvitePortalRequest) (models.PortalAccount, error) {
+	account, err := scanPortalAccount(r.conn.QueryRow(ctx,
+		`INSERT INTO portal_accounts AS a (student_id, role, name, email)
+		 VALUES ($1, $2, $3, LOWER($4))
+		 ON CONFLICT (email, student_id) DO UPDATE SET role = EXCLUDED.role, name = EXCLUDED.name, invited_at = NOW()
+		 WHERE a.joined_at IS NULL
+		 RETURNING `+portalAccountColumns,
+		studentID, req.Role, req.Name, req.Email))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PortalAccount{}, ErrEmailTaken
+	}
+	return account, err
+}
+
+func (r *portalRepository) GetAccounts(ctx context.Context, studentID string) ([]models.PortalAccount, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+portalAccountColumns+` FROM portal_accounts a
+		 WHERE a.student_id = $1
+		 ORDER BY a.invited_at`, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.PortalAccount{}
+	for rows.Next() {
+		a, err := scanPortalAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *portalRepository) GetAccount(ctx context.Context, id string) (models.PortalAccount, error) {
+	return scanPortalAccount(r.conn.QueryRow(ctx,
+		`SELECT `+portalAccountColumns+` FROM portal_accounts a WHERE a.id = $1`, id))
+}
+
+// GetLogins lists the joined accounts of an email, one per student, with their
+// password hashes.
+func (r *portalRepository) GetLogins(ctx context.Context, email string) ([]models.PortalLogin, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+portalAccountColumns+`, s.first_name, s.last_name, a.password_hash
+		 FROM portal_accounts a
+		 JOIN students s ON s.id = a.student_id
+		 WHERE a.email = LOWER($1) AND a.password_hash IS NOT NULL
+		 ORDER BY s.first_name, s.last_name`, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logins := []models.PortalLogin{}
+	for rows.Next() {
+		var l models.PortalLogin
+		l.Account, err = scanPortalAccount(rows, &l.StudentFirstName, &l.StudentLastName, &l.PasswordHash)
+		if err != nil {
+			return nil, err
+		}
+		logins = append(logins, l)
+	}
+	return logins, rows.Err()
+}
+
+// DeleteAccount revokes the account; pgx.ErrNoRows if the student has none with
+// the id.
+func (r *portalRepository) DeleteAccount(ctx context.Context, id string, studentID string) error {
+	tag, err := r.conn.Exec(ctx,
+		`DELETE FROM portal_accounts WHERE id = $1 AND student_id = $2`, id, studentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Accept sets the password of an invited account; pgx.ErrNoRows if there is no
+// such account or it has joined already.
+func (r *portalRepository) Accept(ctx context.Context, id string, passwordHash string) error {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE portal_accounts SET password_hash = $2, joined_at = NOW()
+		 WHERE id = $1 AND password_hash IS NULL`, id, passwordHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// IsActive tells whether the account still exists: the tutor revokes one by
+// deleting it.
+func (r *portalRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	err := r.conn.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM portal_accounts WHERE id = $1 AND joined_at IS NOT NULL)`, id,
+	).Scan(&active)
+	return active, err
+}
+
+func (r *portalRepository) GetProfile(ctx context.Context, id string) (models.PortalProfile, error) {
+	var p models.PortalProfile
+	account, err := scanPortalAccount(r.conn.QueryRow(ctx,
+		`SELECT `+portalAccountColumns+`, s.first_name, s.last_name, t.first_name, t.last_name,
+		        COALESCE(s.timezone, t.timezone)
+		 FROM portal_accounts a
+		 JOIN students s ON s.id = a.student_id
+		 JOIN tutors t ON t.id = s.tutor_id
+		 WHERE a.id = $1`, id),
+		&p.StudentFirstName, &p.StudentLastName, &p.TutorFirstName, &p.TutorLastName, &p.Timezone)
+	p.Account = account
+	return p, err
+}
+
+// GetTimezone is the student's timezone, or their tutor's when they have none.
+func (r *portalRepository) GetTimezone(ctx context.Context, studentID string) (string, error) {
+	var tz string
+	err := r.conn.QueryRow(ctx,
+		`SELECT COALESCE(s.timezone, t.timezone)
+		 FROM students s JOIN tutors t ON t.id = s.tutor_id
+		 WHERE s.id = $1`, studentID,
+	).Scan(&tz)
+	return tz, err
+}
+
+func (r *portalRepository) GetCourses(ctx context.Context, studentID string) ([]models.Course, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT c.id, c.student_id, c.tutor_id, c.subject, c.price_per_lesson, c.currency, c.started_at, c.ended_at
+		 FROM courses c
+		 WHERE `+studentCourse+`
+		 ORDER BY c.started_at DESC`, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []models.Course{}
+	for rows.Next() {
+		var course models.Course
+		if err := rows.Scan(&course.ID, &course.StudentID, &course.TutorID, &course.Subject, &course.PricePerLesson, &course.Currency, &course.StartedAt, &course.EndedAt); err != nil {
+			return nil, err
+		}
+		courses = append(courses, course)
+	}
+	return courses, rows.Err()
+}
+
+// GetLessons lists the student's lessons starting in [from, to).
+func (r *portalRepository) GetLessons(ctx context.Context, studentID string, from time.Time, to time.Time) ([]models.PortalLesson, error) {
+	return r.lessons(ctx,
+		`SELECT `+portalLessonColumns+`
+		 FROM lessons l
+		 JOIN courses c ON c.id = l.course_id
+		 WHERE `+studentCourse+` AND l.scheduled_at >= $2 AND l.scheduled_at < $3
+		 ORDER BY l.scheduled_at`, studentID, from, to)
+}
+
+// GetHomework lists the student's latest lessons with homework, newest first.
+func (r *portalRepository) GetHomework(ctx context.Context, studentID string, limit int) ([]models.PortalLesson, error) {
+	return r.lessons(ctx,
+		`SELECT `+portalLessonColumns+`
+		 FROM lessons l
+		 JOIN courses c ON c.id = l.course_id
+		 WHERE `+studentCourse+` AND l.homework <> '' AND l.status <> 'cancelled'
+		 ORDER BY l.scheduled_at DESC
+		 LIMIT $2`, studentID, limit)
+}
+
+func (r *portalRepository) lessons(ctx context.Context, sql string, args ...any) ([]models.PortalLesson, error) {
+	rows, err := r.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lessons := []models.PortalLesson{}
+	for rows.Next() {
+		l, err := scanPortalLesson(rows)
+		if err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, rows.Err()
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RescheduleRepository interface {
+	Move(ctx context.Context, lessonID string, to time.Time, reason string, actor models.Actor) (models.Reschedule, error)
+	Propose(ctx context.Context, lessonID string, token string, to time.Time, reason string, force bool) (models.Reschedule, error)
+	Withdraw(ctx context.Context, lessonID string) error
+	GetByLesson(ctx context.Context, lessonID string) ([]models.Reschedule, error)
+	GetPublic(ctx context.Context, token string) (models.PublicReschedule, error)
+	Accept(ctx context.Context, token string) (models.Reschedule, error)
+	Decline(ctx context.Context, token string) error
+}
+
+type rescheduleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRescheduleRepository(pool *pgxpool.Pool) RescheduleRepository {
+	return &rescheduleRepository{pool: pool}
+}
+
+const rescheduleColumns = `id, lesson_id, token, original_at, proposed_at, reason, status, force, created_at, resolved_at`
+
+func scanReschedule(row pgx.Row) (models.Reschedule, error) {
+	var rs models.Reschedule
+	err := row.Scan(&rs.ID, &rs.LessonID, &rs.Token, &rs.OriginalAt, &rs.ProposedAt, &rs.Reason, &rs.Status, &rs.Force, &rs.CreatedAt, &rs.ResolvedAt)
+	return rs, err
+}
+
+// lockScheduled locks the lesson and returns its slot. A lesson that is no longer
+// scheduled can't be moved: ErrStatusChanged.
+func lockScheduled(ctx context.Context, tx pgx.Tx, lessonID string) (time.Time, error) {
+	var status string
+	var at time.Time
+	if err := tx.QueryRow(ctx,
+		`SELECT status, scheduled_at FROM lessons WHERE id = $1 FOR UPDATE`, lessonID,
+	).Scan(&status, &at); err != nil {
+		return time.Time{}, err
+	}
+	if status != "scheduled" {
+		return time.Time{}, ErrStatusChanged
+	}
+	return at, nil
+}
+
+// withdrawOpen closes the lesson's open proposal, if it has one.
+func withdrawOpen(ctx context.Context, tx pgx.Tx, lessonID string) (int64, error) {
+	tag, err := tx.Exec(ctx,
+		`UPDATE lesson_reschedules SET status = 'withdrawn', resolved_at = NOW()
+		 WHERE lesson_id = $1 AND status = 'proposed'`, lessonID)
+	return tag.RowsAffected(), err
+}
+
+// moveLesson puts the lesson in its new slot, keeping the first slot it ever had,
+// and records the move on its timeline.
+func moveLesson(ctx context.Context, tx pgx.Tx, lessonID string, from, to time.Time, actor models.Actor, reason string) error {
+	if _, err := tx.Exec(ctx,
+		`UPDATE lessons SET scheduled_at = $2, original_scheduled_at = COALESCE(original_scheduled_at, scheduled_at)
+		 WHERE id = $1`, lessonID, to); err != nil {
+		return err
+	}
+	return insertMoveEvent(ctx, tx, lessonID, "scheduled", from, to, actor, reason)
+}
+
+// Move reschedules the lesson right away and withdraws any open proposal.
+func (r *rescheduleRepository) Move(ctx context.Context, lessonID string, to time.Time, reason string, actor models.Actor) (models.Reschedule, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	from, err := lockScheduled(ctx, tx, lessonID)
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	if _, err := withdrawOpen(ctx, tx, lessonID); err != nil {
+		return models.Reschedule{}, err
+	}
+	if err := moveLesson(ctx, tx, lessonID, from, to, actor, reason); err != nil {
+		return models.Reschedule{}, err
+	}
+	rs, err := scanReschedule(tx.QueryRow(ctx,
+		`INSERT INTO lesson_reschedules (lesson_id, original_at, proposed_at, reason, status, resolved_at)
+		 VALUES ($1, $2, $3, $4, 'applied', NOW())
+		 RETURNING `+rescheduleColumns,
+		lessonID, from, to, reason))
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	return rs, tx.Commit(ctx)
+}
+
+// Propose opens a proposal for the student, replacing the lesson's open one.
+func (r *rescheduleRepository) Propose(ctx context.Context, lessonID string, token string, to time.Time, reason string, force bool) (models.Reschedule, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	from, err := lockScheduled(ctx, tx, lessonID)
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	if _, err := withdrawOpen(ctx, tx, lessonID); err != nil {
+		return models.Reschedule{}, err
+	}
+	rs, err := scanReschedule(tx.QueryRow(ctx,
+		`INSERT INTO lesson_reschedules (lesson_id, token, original_at, proposed_at, reason, status, force)
+		 VALUES ($1, $2, $3, $4, $5, 'proposed', $6)
+		 RETURNING `+rescheduleColumns,
+		lessonID, token, from, to, reason, force))
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	return rs, tx.Commit(ctx)
+}
+
+// Withdraw closes the lesson's open proposal; pgx.ErrNoRows if there is none.
+func (r *rescheduleRepository) Withdraw(ctx context.Context, lessonID string) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	n, err := withdrawOpen(ctx, tx, lessonID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pgx.ErrNoRows
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *rescheduleRepository) GetByLesson(ctx context.Context, lessonID string) ([]models.Reschedule, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+rescheduleColumns+` FROM lesson_reschedules WHERE lesson_id = $1 ORDER BY created_at`, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reschedules := []models.Reschedule{}
+	for rows.Next() {
+		rs, err := scanReschedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		reschedules = append(reschedules, rs)
+	}
+	return reschedules, rows.Err()
+}
+
+func (r *rescheduleRepository) GetPublic(ctx context.Context, token string) (models.PublicReschedule, error) {
+	var p models.PublicReschedule
+	err := r.pool.QueryRow(ctx,
+		`SELECT c.subject, t.first_name, t.timezone, l.duration_minutes,
+		        rs.original_at, rs.proposed_at, rs.reason, rs.status,
+		        rs.id, rs.lesson_id, t.id
+		 FROM lesson_reschedules rs
+		 JOIN lessons l ON l.id = rs.lesson_id
+		 JOIN courses c ON c.id = l.course_id
+		 JOIN tutors t ON t.id = c.tutor_id
+		 WHERE rs.token = $1`, token,
+	).Scan(&p.Subject, &p.TutorName, &p.Timezone, &p.DurationMinutes,
+		&p.OriginalAt, &p.ProposedAt, &p.Reason, &p.Status,
+		&p.ID, &p.LessonID, &p.TutorID)
+	return p, err
+}
+
+// Accept moves the lesson to the proposed slot. The proposal must still be open and
+// the lesson scheduled where the proposal found it (ErrStatusChanged otherwise), and
+// the slot must still be free (ErrSlotTaken) unless the tutor forced the proposal;
+// moves for the same tutor are serialized with bookings.
+func (r *rescheduleRepository) Accept(ctx context.Context, token string) (models.Reschedule, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var tutorID string
+	if err := tx.QueryRow(ctx,
+		`SELECT c.tutor_id FROM lesson_reschedules rs
+		 JOIN lessons l ON l.id = rs.lesson_id
+		 JOIN courses c ON c.id = l.course_id
+		 WHERE rs.token = $1`, token,
+	).Scan(&tutorID); err != nil {
+		return models.Reschedule{}, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, tutorID); err != nil {
+		return models.Reschedule{}, err
+	}
+	rs, err := scanReschedule(tx.QueryRow(ctx,
+		`SELECT `+rescheduleColumns+` FROM lesson_reschedules
+		 WHERE token = $1 AND status = 'proposed' FOR UPDATE`, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Closed while we waited for the lock.
+		return models.Reschedule{}, ErrStatusChanged
+	}
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	from, err := lockScheduled(ctx, tx, rs.LessonID)
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	if !from.Equal(rs.OriginalAt) {
+		return models.Reschedule{}, ErrStatusChanged
+	}
+
+	var taken bool
+	if !rs.Force {
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (
+			     SELECT 1 FROM lessons l JOIN courses c ON c.id = l.course_id
+			     WHERE c.tutor_id = $1 AND l.id <> $2 AND l.status <> 'cancelled' AND NOT `+heldByExpiredBooking+`
+			       AND l.scheduled_at < $3::timestamptz + make_interval(mins => lm.duration_minutes)
+			       AND $3::timestamptz < l.scheduled_at + make_interval(mins => l.duration_minutes)
+			 ) OR EXISTS (
+			     SELECT 1 FROM tasks t
+			     WHERE t.tutor_id = $1 AND NOT t.done
+			       AND t.scheduled_at < $3::timestamptz + make_interval(mins => lm.duration_minutes)
+			       AND $3::timestamptz < t.scheduled_at + make_interval(mins => t.duration_minutes)
+			 )
+			 FROM lessons lm WHERE lm.id = $2`,
+			tutorID, rs.LessonID, rs.ProposedAt,
+		).Scan(&taken); err != nil {
+			return models.Reschedule{}, err
+		}
+		if taken {
+			return models.Reschedule{}, ErrSlotTaken
+		}
+	}
+
+	student := models.Actor{Type: models.ActorStudent}
+	if err := moveLesson(ctx, tx, rs.LessonID, from, rs.ProposedAt, student, rs.Reason); err != nil {
+		return models.Reschedule{}, err
+	}
+	if err := tx.QueryRow(ctx,
+		`UPDATE lesson_reschedules SET status = 'accepted', resolved_at = NOW()
+		 WHERE id = $1
+		 RETURNING status, resolved_at`, rs.ID,
+	).Scan(&rs.Status, &rs.ResolvedAt); err != nil {
+		return models.Reschedule{}, err
+	}
+	return rs, tx.Commit(ctx)
+}
+
+// Decline closes an open proposal; the lesson keeps its slot.
+func (r *rescheduleRepository) Decline(ctx context.Context, token string) error {
+	var id string
+	return r.pool.QueryRow(ctx,
+		`UPDATE lesson_reschedules SET status = 'declined', resolved_at = NOW()
+		 WHERE token = $1 AND status = 'proposed'
+		 RETURNING id`, token,
+	).Scan(&id)
+}
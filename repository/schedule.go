@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ScheduleRepository interface {
+	FindConflicts(ctx context.Context, tutorID string, slots []models.TimeSlot, exclude models.ConflictExclusion) ([]models.OccurrenceConflicts, error)
+	GetBusy(ctx context.Context, tutorID string, from time.Time, to time.Time) ([]models.TimeSlot, error)
+}
+
+type scheduleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewScheduleRepository(pool *pgxpool.Pool) ScheduleRepository {
+	return &scheduleRepository{pool: pool}
+}
+
+// FindConflicts returns, for every slot that overlaps something, the tutor's lessons
+// and open tasks it collides with. Intervals are half-open, so back-to-back items
+// don't conflict; cancelled lessons, lessons of expired booking requests and
+// finished tasks don't occupy time.
+func (r *scheduleRepository) FindConflicts(ctx context.Context, tutorID string, slots []models.TimeSlot, exclude models.ConflictExclusion) ([]models.OccurrenceConflicts, error) {
+	if len(slots) == 0 {
+		return nil, nil
+	}
+	starts := make([]time.Time, len(slots))
+	minutes := make([]int32, len(slots))
+	for i, s := range slots {
+		starts[i] = s.ScheduledAt
+		minutes[i] = int32(s.DurationMinutes)
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`WITH slots AS (
+		     SELECT s.idx, s.starts_at, s.starts_at + make_interval(mins => s.minutes) AS ends_at
+		     FROM unnest($2::timestamptz[], $3::int[]) WITH ORDINALITY AS s(starts_at, minutes, idx)
+		 )
+		 SELECT s.idx, 'lesson', l.id, c.subject, l.scheduled_at, l.duration_minutes
+		 FROM slots s
+		 JOIN lessons l ON l.scheduled_at < s.ends_at
+		               AND s.starts_at < l.scheduled_at + make_interval(mins => l.duration_minutes)
+		 JOIN courses c ON c.id = l.course_id
+		 WHERE c.tutor_id = $1 AND l.status <> 'cancelled' AND NOT `+heldByExpiredBooking+`
+		   AND l.id::text <> $4 AND l.series_id::text IS DISTINCT FROM $5
+		 UNION ALL
+		 SELECT s.idx, 'task', t.id, t.title, t.scheduled_at, t.duration_minutes
+		 FROM slots s
+		 JOIN tasks t ON t.scheduled_at < s.ends_at
+		             AND s.starts_at < t.scheduled_at + make_interval(mins => t.duration_minutes)
+		 WHERE t.tutor_id = $1 AND NOT t.done AND t.id::text <> $6
+		 ORDER BY 1, 5`,
+		tutorID, starts, minutes, exclude.LessonID, exclude.SeriesID, exclude.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.OccurrenceConflicts
+	last := int64(0)
+	for rows.Next() {
+		var idx int64
+		var c models.ScheduleConflict
+		if err := rows.Scan(&idx, &c.Kind, &c.ID, &c.Title, &c.ScheduledAt, &c.DurationMinutes); err != nil {
+			return nil, err
+		}
+		if idx != last {
+			slot := slots[idx-1]
+			result = append(result, models.OccurrenceConflicts{
+				ScheduledAt:     slot.ScheduledAt,
+				DurationMinutes: slot.DurationMinutes,
+			})
+			last = idx
+		}
+		result[len(result)-1].Conflicts = append(result[len(result)-1].Conflicts, c)
+	}
+	return result, rows.Err()
+}
+
+// GetBusy returns the time taken by lessons and open tasks overlapping [from, to),
+// ordered by start. Like in FindConflicts, expired booking requests take none.
+func (r *scheduleRepository) GetBusy(ctx context.Context, tutorID string, from time.Time, to time.Time) ([]models.TimeSlot, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT l.scheduled_at, l.duration_minutes
+		 FROM lessons l
+		 JOIN courses c ON c.id = l.course_id
+		 WHERE c.tutor_id = $1 AND l.status <> 'cancelled' AND NOT `+heldByExpiredBooking+`
+		   AND l.scheduled_at < $3 AND l.scheduled_at + make_interval(mins => l.duration_minutes) > $2
+		 UNION ALL
+		 SELECT t.scheduled_at, t.duration_minutes
+		 FROM tasks t
+		 WHERE t.tutor_id = $1 AND NOT t.done
+		   AND t.scheduled_at < $3 AND t.scheduled_at + make_interval(mins => t.duration_minutes) > $2
+		 ORDER BY 1`,
+		tutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var busy []models.TimeSlot
+	for rows.Next() {
+		var s models.TimeSlot
+		if err := rows.Scan(&s.ScheduledAt, &s.DurationMinutes); err != nil {
+			return nil, err
+		}
+		busy = append(busy, s)
+	}
+	return busy, rows.Err()
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, series models.LessonSeries, occurrences []time.Time) (models.LessonSeries, []models.Lesson, error)
+	GetByIDForTutor(ctx context.Context, id string, tutorID string) (models.LessonSeries, error)
+	GetLessons(ctx context.Context, id string) ([]models.Lesson, error)
+	Append(ctx context.Context, series models.LessonSeries, occurrences []time.Time) ([]models.Lesson, error)
+	UpdateInPlace(ctx context.Context, series models.LessonSeries, from time.Time, req models.UpdateSeriesRequest) error
+	Regenerate(ctx context.Context, series models.LessonSeries, from time.Time, occurrences []time.Time) error
+	Truncate(ctx context.Context, series models.LessonSeries, from time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type seriesRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSeriesRepository(pool *pgxpool.Pool) SeriesRepository {
+	return &seriesRepository{pool: pool}
+}
+
+const seriesColumns = `id, course_id, rrule, dtstart, timezone, duration_minutes, notes, exdates, generated_until`
+
+func scanSeries(row pgx.Row) (models.LessonSeries, error) {
+	var s models.LessonSeries
+	err := row.Scan(&s.ID, &s.CourseID, &s.RRule, &s.DTStart, &s.Timezone, &s.DurationMinutes, &s.Notes, &s.ExDates, &s.GeneratedUntil)
+	return s, err
+}
+
+func (r *seriesRepository) Create(ctx context.Context, series models.LessonSeries, occurrences []time.Time) (models.LessonSeries, []models.Lesson, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.LessonSeries{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := insertSeriesRow(ctx, tx, series)
+	if err != nil {
+		return models.LessonSeries{}, nil, err
+	}
+
+	lessons, err := insertSeriesLessons(ctx, tx, created, occurrences)
+	if err != nil {
+		return models.LessonSeries{}, nil, err
+	}
+	return created, lessons, tx.Commit(ctx)
+}
+
+func (r *seriesRepository) GetByIDForTutor(ctx context.Context, id string, tutorID string) (models.LessonSeries, error) {
+	return scanSeries(r.pool.QueryRow(ctx,
+		`SELECT s.id, s.course_id, s.rrule, s.dtstart, s.timezone, s.duration_minutes, s.notes, s.exdates, s.generated_until
+		 FROM lesson_series s
+		 JOIN courses c ON c.id = s.course_id
+		 WHERE s.id = $1 AND c.tutor_id = $2`, id, tutorID,
+	))
+}
+
+func (r *seriesRepository) GetLessons(ctx context.Context, id string) ([]models.Lesson, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, course_id, scheduled_at, duration_minutes, status, notes, series_id
+		 FROM lessons WHERE series_id = $1 ORDER BY scheduled_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lessons := []models.Lesson{}
+	for rows.Next() {
+		var l models.Lesson
+		if err := rows.Scan(&l.ID, &l.CourseID, &l.ScheduledAt, &l.DurationMinutes, &l.Status, &l.Notes, &l.SeriesID); err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, rows.Err()
+}
+
+// Append materializes occurrences past the current horizon and moves
+// generated_until to series.GeneratedUntil.
+func (r *seriesRepository) Append(ctx context.Context, series models.LessonSeries, occurrences []time.Time) ([]models.Lesson, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateSeriesRow(ctx, tx, series); err != nil {
+		return nil, err
+	}
+	lessons, err := insertSeriesLessons(ctx, tx, series, occurrences)
+	if err != nil {
+		return nil, err
+	}
+	return lessons, tx.Commit(ctx)
+}
+
+// UpdateInPlace rewrites time, duration and notes of the series lessons starting at from,
+// keeping lesson IDs (and therefore call links and attendance) intact. The new time of day
+// is applied to each lesson's calendar day in the series timezone.
+func (r *seriesRepository) UpdateInPlace(ctx context.Context, series models.LessonSeries, from time.Time, req models.UpdateSeriesRequest) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateSeriesRow(ctx, tx, series); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE lessons SET
+		     scheduled_at = CASE WHEN $3::text IS NULL THEN scheduled_at
+		                         ELSE (date_trunc('day', scheduled_at AT TIME ZONE $4) + $3::text::interval) AT TIME ZONE $4
+		                    END,
+		     duration_minutes = COALESCE($5, duration_minutes),
+		     notes = COALESCE($6, notes)
+		 WHERE series_id = $1 AND scheduled_at >= $2`,
+		series.ID, from, req.NewTime, series.Timezone, req.DurationMinutes, req.Notes)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Regenerate replaces the still-scheduled lessons starting at from with a fresh expansion.
+// Completed, cancelled and missed lessons are history and are left untouched.
+func (r *seriesRepository) Regenerate(ctx context.Context, series models.LessonSeries, from time.Time, occurrences []time.Time) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateSeriesRow(ctx, tx, series); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM lessons
+		 WHERE series_id = $1 AND scheduled_at >= $2 AND status = 'scheduled'`,
+		series.ID, from); err != nil {
+		return err
+	}
+	if _, err := insertSeriesLessons(ctx, tx, series, occurrences); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Truncate ends the series before from: the caller has already bounded the rule,
+// this persists it and removes the lessons that no longer belong to the series.
+func (r *seriesRepository) Truncate(ctx context.Context, series models.LessonSeries, from time.Time) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateSeriesRow(ctx, tx, series); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM lessons WHERE series_id = $1 AND scheduled_at >= $2`,
+		series.ID, from); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *seriesRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM lessons WHERE series_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM lesson_series WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertSeriesRow(ctx context.Context, tx pgx.Tx, s models.LessonSeries) (models.LessonSeries, error) {
+	return scanSeries(tx.QueryRow(ctx,
+		`INSERT INTO lesson_series (course_id, rrule, dtstart, timezone, duration_minutes, notes, exdates, generated_until)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING `+seriesColumns,
+		s.CourseID, s.RRule, s.DTStart, s.Timezone, s.DurationMinutes, s.Notes,
+		exdatesOrEmpty(s.ExDates), s.GeneratedUntil,
+	))
+}
+
+func updateSeriesRow(ctx context.Context, tx pgx.Tx, s models.LessonSeries) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE lesson_series
+		 SET rrule=$1, dtstart=$2, timezone=$3, duration_minutes=$4, notes=$5, exdates=$6, generated_until=$7
+		 WHERE id=$8`,
+		s.RRule, s.DTStart, s.Timezone, s.DurationMinutes, s.Notes, exdatesOrEmpty(s.ExDates), s.GeneratedUntil, s.ID)
+	return err
+}
+
+// insertSeriesLessons skips occurrences that already have a lesson at the same instant,
+// so a regeneration never duplicates lessons that were kept as history.
+func insertSeriesLessons(ctx context.Context, tx pgx.Tx, s models.LessonSeries, occurrences []time.Time) ([]models.Lesson, error) {
+	lessons := []models.Lesson{}
+	if len(occurrences) == 0 {
+		return lessons, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, at := range occurrences {
+		batch.Queue(
+			`INSERT INTO lessons (course_id, scheduled_at, duration_minutes, notes, series_id)
+			 SELECT $1, $2, $3, $4, $5
+			 WHERE NOT EXISTS (SELECT 1 FROM lessons WHERE series_id = $5 AND scheduled_at = $2)
+			 RETURNING id, course_id, scheduled_at, duration_minutes, status, notes, series_id`,
+			s.CourseID, at, s.DurationMinutes, s.Notes, s.ID,
+		)
+	}
+	br := tx.SendBatch(ctx, batch)
+	for range occurrences {
+		var l models.Lesson
+		err := br.QueryRow().Scan(&l.ID, &l.CourseID, &l.ScheduledAt, &l.DurationMinutes, &l.Status, &l.Notes, &l.SeriesID)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			br.Close()
+			return nil, err
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, br.Close()
+}
+
+func exdatesOrEmpty(exdates []time.Time) []time.Time {
+	if exdates == nil {
+		return []time.Time{}
+	}
+	return exdates
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, tutorID string, refreshHash string, meta models.SessionMeta, expiresAt time.Time) (models.Session, error)
+	Rotate(ctx context.Context, oldHash string, newHash string, meta models.SessionMeta, expiresAt time.Time) (models.Session, error)
+	RevokeReused(ctx context.Context, oldHash string) (bool, error)
+	GetActive(ctx context.Context, tutorID string) ([]models.Session, error)
+	Revoke(ctx context.Context, id string, tutorID string) error
+	RevokeOthers(ctx context.Context, tutorID string, keepID string) error
+	IsActive(ctx context.Context, id string) (bool, error)
+}
+
+type sessionRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewSessionRepository(conn *pgxpool.Pool) SessionRepository {
+	return &sessionRepository{conn: conn}
+}
+
+const sessionColumns = `id, tutor_id, user_agent, ip, created_at, last_used_at, expires_at`
+
+// sessionActive holds for sessions neither revoked nor expired.
+const sessionActive = `revoked_at IS NULL AND expires_at > NOW()`
+
+func scanSession(row pgx.Row) (models.Session, error) {
+	var s models.Session
+	err := row.Scan(&s.ID, &s.TutorID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+	return s, err
+}
+
+func (r *sessionRepository) Create(ctx context.Context, tutorID string, refreshHash string, meta models.SessionMeta, expiresAt time.Time) (models.Session, error) {
+	return scanSession(r.conn.QueryRow(ctx,
+		`INSERT INTO sessions (tutor_id, refresh_hash, user_agent, ip, expires_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING `+sessionColumns,
+		tutorID, refreshHash, meta.UserAgent, meta.IP, expiresAt))
+}
+
+// Rotate replaces the refresh token of the active session holding oldHash;
+// pgx.ErrNoRows if there is none.
+func (r *sessionRepository) Rotate(ctx context.Context, oldHash string, newHash string, meta models.SessionMeta, expiresAt time.Time) (models.Session, error) {
+	return scanSession(r.conn.QueryRow(ctx,
+		`UPDATE sessions
+		 SET refresh_hash = $2, previous_hash = $1, user_agent = $3, ip = $4, last_used_at = NOW(), expires_at = $5
+		 WHERE refresh_hash = $1 AND `+sessionActive+`
+		 RETURNING `+sessionColumns,
+		oldHash, newHash, meta.UserAgent, meta.IP, expiresAt))
+}
+
+// RevokeReused revokes the session whose refresh token oldHash was, if any.
+func (r *sessionRepository) RevokeReused(ctx context.Context, oldHash string) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE sessions SET revoked_at = NOW()
+		 WHERE previous_hash = $1 AND revoked_at IS NULL`, oldHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *sessionRepository) GetActive(ctx context.Context, tutorID string) ([]models.Session, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+sessionColumns+` FROM sessions
+		 WHERE tutor_id = $1 AND `+sessionActive+`
+		 ORDER BY last_used_at DESC`, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// Revoke signs the session out; pgx.ErrNoRows if the tutor has no such active
+// session.
+func (r *sessionRepository) Revoke(ctx context.Context, id string, tutorID string) error {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE sessions SET revoked_at = NOW()
+		 WHERE id = $1 AND tutor_id = $2 AND `+sessionActive, id, tutorID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeOthers(ctx context.Context, tutorID string, keepID string) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE sessions SET revoked_at = NOW()
+		 WHERE tutor_id = $1 AND id <> $2 AND `+sessionActive, tutorID, keepID)
+	return err
+}
+
+func (r *sessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	err := r.conn.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND `+sessionActive+`)`, id,
+	).Scan(&active)
+	return active, err
+}
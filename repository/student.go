@@ -4,6 +4,7 @@ import (
 	"context"
 	"tutorgo/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -23,38 +24,47 @@ func NewStudentRepository(conn *pgxpool.Pool) StudentRepository {
 	return &studentRepository{conn: conn}
 }
 
+const studentColumns = `id, tutor_id, first_name, last_name, phone, email, notes, active, timezone`
+
+// studentFilter picks the tutor's ($1) students matching the search ($2) of the
+// list; exports share it so they list the same students.
+const studentFilter = `tutor_id = $1
+		   AND ($2 = '' OR first_name ILIKE '%' || $2 || '%'
+		                 OR last_name  ILIKE '%' || $2 || '%'
+		                 OR email      ILIKE '%' || $2 || '%')`
+
+const studentOrder = `first_name, last_name`
+
+func scanStudent(row pgx.Row) (models.Student, error) {
+	var s models.Student
+	err := row.Scan(&s.ID, &s.TutorID, &s.FirstName, &s.LastName, &s.Phone, &s.Email, &s.Notes, &s.Active, &s.Timezone)
+	return s, err
+}
+
 func (r *studentRepository) Create(ctx context.Context, req models.CreateStudentRequest, tutorID string) (models.Student, error) {
 	var student models.Student
 	err := r.conn.QueryRow(ctx,
-		`INSERT INTO students (tutor_id, first_name, last_name, phone, email, notes)
-		 VALUES ($1, $2, $3, $4, $5, $6)
-		 RETURNING id, tutor_id, first_name, last_name, phone, email, notes, active`,
-		tutorID, req.FirstName, req.LastName, req.Phone, req.Email, req.Notes,
-	).Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active)
+		`INSERT INTO students (tutor_id, first_name, last_name, phone, email, notes, timezone)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id, tutor_id, first_name, last_name, phone, email, notes, active, timezone`,
+		tutorID, req.FirstName, req.LastName, req.Phone, req.Email, req.Notes, req.Timezone,
+	).Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active, &student.Timezone)
 	return student, err
 }
 
 func (r *studentRepository) GetAll(ctx context.Context, tutorID string, p models.Pagination) ([]models.Student, int, error) {
 	var total int
 	if err := r.conn.QueryRow(ctx,
-		`SELECT COUNT(*) FROM students
-		 WHERE tutor_id = $1
-		   AND ($2 = '' OR first_name ILIKE '%' || $2 || '%'
-		                 OR last_name  ILIKE '%' || $2 || '%'
-		                 OR email      ILIKE '%' || $2 || '%')`,
+		`SELECT COUNT(*) FROM students WHERE `+studentFilter,
 		tutorID, p.Search,
 	).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	rows, err := r.conn.Query(ctx,
-		`SELECT id, tutor_id, first_name, last_name, phone, email, notes, active
-		 FROM students
-		 WHERE tutor_id = $1
-		   AND ($2 = '' OR first_name ILIKE '%' || $2 || '%'
-		                 OR last_name  ILIKE '%' || $2 || '%'
-		                 OR email      ILIKE '%' || $2 || '%')
-		 ORDER BY first_name, last_name
+		`SELECT `+studentColumns+` FROM students
+		 WHERE `+studentFilter+`
+		 ORDER BY `+studentOrder+`
 		 LIMIT $3 OFFSET $4`,
 		tutorID, p.Search, p.Limit, p.Offset())
 	if err != nil {
@@ -64,8 +74,8 @@ func (r *studentRepository) GetAll(ctx context.Context, tutorID string, p models
 
 	students := []models.Student{}
 	for rows.Next() {
-		var student models.Student
-		if err := rows.Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active); err != nil {
+		student, err := scanStudent(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		students = append(students, student)
@@ -76,20 +86,20 @@ func (r *studentRepository) GetAll(ctx context.Context, tutorID string, p models
 func (r *studentRepository) GetByID(ctx context.Context, id string, tutorID string) (models.Student, error) {
 	var student models.Student
 	err := r.conn.QueryRow(ctx,
-		`SELECT id, tutor_id, first_name, last_name, phone, email, notes, active
+		`SELECT id, tutor_id, first_name, last_name, phone, email, notes, active, timezone
 		 FROM students WHERE id = $1 AND tutor_id = $2`, id, tutorID,
-	).Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active)
+	).Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active, &student.Timezone)
 	return student, err
 }
 
 func (r *studentRepository) Update(ctx context.Context, id string, tutorID string, req models.UpdateStudentRequest) (models.Student, error) {
 	var student models.Student
 	err := r.conn.QueryRow(ctx,
-		`UPDATE students SET first_name=$1, last_name=$2, phone=$3, email=$4, notes=$5
-		 WHERE id=$6 AND tutor_id=$7
-		 RETURNING id, tutor_id, first_name, last_name, phone, email, notes, active`,
-		req.FirstName, req.LastName, req.Phone, req.Email, req.Notes, id, tutorID,
-	).Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active)
+		`UPDATE students SET first_name=$1, last_name=$2, phone=$3, email=$4, notes=$5, timezone=$6
+		 WHERE id=$7 AND tutor_id=$8
+		 RETURNING id, tutor_id, first_name, last_name, phone, email, notes, active, timezone`,
+		req.FirstName, req.LastName, req.Phone, req.Email, req.Notes, req.Timezone, id, tutorID,
+	).Scan(&student.ID, &student.TutorID, &student.FirstName, &student.LastName, &student.Phone, &student.Email, &student.Notes, &student.Active, &student.Timezone)
 	return student, err
 }
 
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StudentImportRepository interface {
+	GetEmails(ctx context.Context, tutorID string, emails []string) ([]string, error)
+	Import(ctx context.Context, tutorID string, rows []models.StudentImportRow) error
+}
+
+type studentImportRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewStudentImportRepository(conn *pgxpool.Pool) StudentImportRepository {
+	return &studentImportRepository{conn: conn}
+}
+
+// GetEmails returns those of emails, lowercased, that the tutor's students
+// already have.
+func (r *studentImportRepository) GetEmails(ctx context.Context, tutorID string, emails []string) ([]string, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT DISTINCT lower(email) FROM students
+		 WHERE tutor_id = $1 AND lower(email) = ANY($2::text[])`, tutorID, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		found = append(found, email)
+	}
+	return found, rows.Err()
+}
+
+// Import creates the students, and the courses of the rows that have one, in one
+// transaction. Courses take the tutor's home currency unless the row names one.
+func (r *studentImportRepository) Import(ctx context.Context, tutorID string, rows []models.StudentImportRow) error {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, row := range rows {
+		st := row.Student
+		if row.Course == nil {
+			batch.Queue(
+				`INSERT INTO students (tutor_id, first_name, last_name, phone, email, notes, timezone)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				tutorID, st.FirstName, st.LastName, st.Phone, st.Email, st.Notes, st.Timezone,
+			)
+			continue
+		}
+		c := row.Course
+		batch.Queue(
+			`WITH s AS (
+			     INSERT INTO students (tutor_id, first_name, last_name, phone, email, notes, timezone)
+			     VALUES ($1, $2, $3, $4, $5, $6, $7)
+			     RETURNING id
+			 )
+			 INSERT INTO courses (student_id, tutor_id, subject, price_per_lesson, currency, started_at, ended_at)
+			 SELECT s.id, $1, $8, $9, COALESCE(NULLIF($10, ''), (SELECT currency FROM tutors WHERE id = $1)), $11, $12
+			 FROM s`,
+			tutorID, st.FirstName, st.LastName, st.Phone, st.Email, st.Notes, st.Timezone,
+			c.Subject, c.PricePerLesson, c.Currency, c.StartedAt, c.EndedAt,
+		)
+	}
+	br := tx.SendBatch(ctx, batch)
+	for range rows {
+		if _, err := br.Exec(); err != nil {
+			br.Close()
+			return err
+		}
+	}
+	if err := br.Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"tutorgo/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,6 +18,7 @@ type TutorRepository interface {
 	Delete(ctx context.Context, id string) error
 	GetPasswordHash(ctx context.Context, id string) (string, error)
 	UpdatePassword(ctx context.Context, id string, hash string) error
+	GetTimezone(ctx context.Context, id string) (string, error)
 }
 type tutorRepository struct {
 	conn *pgxpool.Pool
@@ -25,20 +27,29 @@ type tutorRepository struct {
 func NewTutorRepository(conn *pgxpool.Pool) TutorRepository {
 	return &tutorRepository{conn: conn}
 }
+
+const tutorColumns = `id, email, email_verified_at IS NOT NULL, first_name, last_name, phone, timezone, currency,
+	legal_name, tax_id, address, bank_name, bank_account, bank_code`
+
+func scanTutor(row pgx.Row) (models.Tutor, error) {
+	var t models.Tutor
+	q := &t.Requisites
+	err := row.Scan(&t.ID, &t.Email, &t.EmailVerified, &t.FirstName, &t.LastName, &t.Phone, &t.Timezone, &t.Currency,
+		&q.LegalName, &q.TaxID, &q.Address, &q.BankName, &q.BankAccount, &q.BankCode)
+	return t, err
+}
+
 func (r *tutorRepository) Create(ctx context.Context, req models.CreateTutorRequest, passwordHash string) (models.Tutor, error) {
-	var tutor models.Tutor
-	err := r.conn.QueryRow(ctx,
-		`INSERT INTO tutors (email, password_hash, first_name, last_name, phone)
-		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING id, email, first_name, last_name, phone`,
-		req.Email, passwordHash, req.FirstName, req.LastName, req.Phone,
-	).Scan(&tutor.ID, &tutor.Email, &tutor.FirstName, &tutor.LastName, &tutor.Phone)
-	return tutor, err
+	return scanTutor(r.conn.QueryRow(ctx,
+		`INSERT INTO tutors (email, password_hash, first_name, last_name, phone, timezone, currency)
+		 VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'UTC'), COALESCE(NULLIF($7, ''), 'KZT'))
+		 RETURNING `+tutorColumns,
+		req.Email, passwordHash, req.FirstName, req.LastName, req.Phone, req.Timezone, req.Currency))
 }
 
 func (r *tutorRepository) GetAll(ctx context.Context) ([]models.Tutor, error) {
 	rows, err := r.conn.Query(ctx,
-		`SELECT id, email, first_name, last_name, phone FROM tutors`)
+		`SELECT `+tutorColumns+` FROM tutors`)
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +57,7 @@ func (r *tutorRepository) GetAll(ctx context.Context) ([]models.Tutor, error) {
 
 	var tutors []models.Tutor
 	for rows.Next() {
-		var tutor models.Tutor
-		err := rows.Scan(&tutor.ID, &tutor.Email, &tutor.FirstName, &tutor.LastName, &tutor.Phone)
+		tutor, err := scanTutor(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -57,11 +67,8 @@ func (r *tutorRepository) GetAll(ctx context.Context) ([]models.Tutor, error) {
 }
 
 func (r *tutorRepository) GetByID(ctx context.Context, id string) (models.Tutor, error) {
-	var tutor models.Tutor
-	err := r.conn.QueryRow(ctx,
-		`SELECT id, email, first_name, last_name, phone FROM tutors WHERE id = $1`, id,
-	).Scan(&tutor.ID, &tutor.Email, &tutor.FirstName, &tutor.LastName, &tutor.Phone)
-	return tutor, err
+	return scanTutor(r.conn.QueryRow(ctx,
+		`SELECT `+tutorColumns+` FROM tutors WHERE id = $1`, id))
 }
 
 func (r *tutorRepository) GetByEmail(ctx context.Context, email string) (string, string, error) {
@@ -81,14 +88,42 @@ func (r *tutorRepository) GetByPhone(ctx context.Context, phone string) (string,
 }
 
 func (r *tutorRepository) Update(ctx context.Context, id string, req models.UpdateTutorRequest) (models.Tutor, error) {
-	var tutor models.Tutor
-	err := r.conn.QueryRow(ctx,
-		`UPDATE tutors SET email=$1, first_name=$2, last_name=$3, phone=$4
-		 WHERE id=$5
-		 RETURNING id, email, first_name, last_name, phone`,
-		req.Email, req.FirstName, req.LastName, req.Phone, id,
-	).Scan(&tutor.ID, &tutor.Email, &tutor.FirstName, &tutor.LastName, &tutor.Phone)
-	return tutor, err
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.Tutor{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	tutor, err := scanTutor(tx.QueryRow(ctx,
+		`UPDATE tutors SET email=$1, email_verified_at=CASE WHEN email = $1 THEN email_verified_at END, first_name=$2, last_name=$3, phone=$4, timezone=COALESCE(NULLIF($5, ''), timezone),
+		     currency=COALESCE(NULLIF($6, ''), currency)
+		 WHERE id=$7
+		 RETURNING `+tutorColumns,
+		req.Email, req.FirstName, req.LastName, req.Phone, req.Timezone, req.Currency, id))
+	if err != nil {
+		return models.Tutor{}, err
+	}
+
+	// Series created from explicit dates have no wall-clock anchor of their own,
+	// so their "HH:MM" edits follow the tutor's zone.
+	if _, err := tx.Exec(ctx,
+		`UPDATE lesson_series s SET timezone = $1
+		 FROM courses c
+		 WHERE c.id = s.course_id AND c.tutor_id = $2 AND s.rrule IS NULL`,
+		tutor.Timezone, id); err != nil {
+		return models.Tutor{}, err
+	}
+
+	if q := req.Requisites; q != nil {
+		if _, err := tx.Exec(ctx,
+			`UPDATE tutors SET legal_name=$1, tax_id=$2, address=$3, bank_name=$4, bank_account=$5, bank_code=$6
+			 WHERE id=$7`,
+			q.LegalName, q.TaxID, q.Address, q.BankName, q.BankAccount, q.BankCode, id); err != nil {
+			return models.Tutor{}, err
+		}
+		tutor.Requisites = *q
+	}
+	return tutor, tx.Commit(ctx)
 }
 
 func (r *tutorRepository) Delete(ctx context.Context, id string) error {
@@ -110,3 +145,11 @@ func (r *tutorRepository) UpdatePassword(ctx context.Context, id string, hash st
 		`UPDATE tutors SET password_hash = $1 WHERE id = $2`, hash, id)
 	return err
 }
+
+func (r *tutorRepository) GetTimezone(ctx context.Context, id string) (string, error) {
+	var tz string
+	err := r.conn.QueryRow(ctx,
+		`SELECT timezone FROM tutors WHERE id = $1`, id,
+	).Scan(&tz)
+	return tz, err
+}
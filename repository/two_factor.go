@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tutorgo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrNoTutor is returned for a tutor id that has no account.
+	ErrNoTutor = errors.New("tutor not found")
+	// ErrTwoFactorOn is returned when starting enrollment after it is complete.
+	ErrTwoFactorOn = errors.New("two-factor authentication is on")
+	// ErrNoEnrollment is returned when completing an enrollment that isn't under way.
+	ErrNoEnrollment = errors.New("no two-factor enrollment under way")
+)
+
+type TwoFactorRepository interface {
+	GetState(ctx context.Context, tutorID string) (models.TwoFactorState, error)
+	CountRecoveryCodes(ctx context.Context, tutorID string) (int, error)
+	SetSecret(ctx context.Context, tutorID string, secret string) error
+	Enable(ctx context.Context, tutorID string, step int64, codeHashes []string) error
+	UseStep(ctx context.Context, tutorID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, tutorID string, codeHash string) (bool, error)
+	Disable(ctx context.Context, tutorID string) error
+}
+
+type twoFactorRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(conn *pgxpool.Pool) TwoFactorRepository {
+	return &twoFactorRepository{conn: conn}
+}
+
+func (r *twoFactorRepository) GetState(ctx context.Context, tutorID string) (models.TwoFactorState, error) {
+	var s models.TwoFactorState
+	err := r.conn.QueryRow(ctx,
+		`SELECT email, COALESCE(totp_secret, ''), totp_enabled_at IS NOT NULL
+		 FROM tutors WHERE id = $1`, tutorID,
+	).Scan(&s.Email, &s.Secret, &s.Enabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.TwoFactorState{}, ErrNoTutor
+	}
+	return s, err
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, tutorID string) (int, error) {
+	var n int
+	err := r.conn.QueryRow(ctx,
+		`SELECT COUNT(*) FROM recovery_codes WHERE tutor_id = $1 AND used_at IS NULL`, tutorID,
+	).Scan(&n)
+	return n, err
+}
+
+// SetSecret starts enrolling with a new secret; ErrTwoFactorOn once enrollment
+// is complete.
+func (r *twoFactorRepository) SetSecret(ctx context.Context, tutorID string, secret string) error {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE tutors SET totp_secret = $2, totp_last_step = NULL
+		 WHERE id = $1 AND totp_enabled_at IS NULL`, tutorID, secret)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTwoFactorOn
+	}
+	return nil
+}
+
+// Enable completes enrollment with the step of the code that confirmed it and
+// replaces the recovery codes; ErrNoEnrollment if it isn't under way.
+func (r *twoFactorRepository) Enable(ctx context.Context, tutorID string, step int64, codeHashes []string) error {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE tutors SET totp_enabled_at = NOW(), totp_last_step = $2
+		 WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`, tutorID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoEnrollment
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM recovery_codes WHERE tutor_id = $1`, tutorID); err != nil {
+		return err
+	}
+	batch := &pgx.Batch{}
+	for _, hash := range codeHashes {
+		batch.Queue(`INSERT INTO recovery_codes (tutor_id, code_hash) VALUES ($1, $2)`, tutorID, hash)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UseStep records that a code of step was accepted, unless one of it or a later
+// step already was.
+func (r *twoFactorRepository) UseStep(ctx context.Context, tutorID string, step int64) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE tutors SET totp_last_step = $2
+		 WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)`, tutorID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UseRecoveryCode spends the unused recovery code with the hash, if there is one.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, tutorID string, codeHash string) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE recovery_codes SET used_at = NOW()
+		 WHERE tutor_id = $1 AND code_hash = $2 AND used_at IS NULL`, tutorID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *twoFactorRepository) Disable(ctx context.Context, tutorID string) error {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE tutors SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+		 WHERE id = $1`, tutorID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM recovery_codes WHERE tutor_id = $1`, tutorID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -3,11 +3,14 @@ package router
 import (
 	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	"tutorgo/config"
 	"tutorgo/handlers"
+	"tutorgo/mail"
 	"tutorgo/middleware"
+	"tutorgo/pdf"
 	"tutorgo/repository"
 	"tutorgo/service"
 
@@ -27,20 +30,63 @@ func Setup(pool *pgxpool.Pool, log *slog.Logger, cfg *config.Config) *gin.Engine
 	enrollmentRepo := repository.NewEnrollmentRepository(pool)
 	attendanceRepo := repository.NewAttendanceRepository(pool)
 	taskRepo := repository.NewTaskRepository(pool)
+	seriesRepo := repository.NewSeriesRepository(pool)
+	scheduleRepo := repository.NewScheduleRepository(pool)
+	availabilityRepo := repository.NewAvailabilityRepository(pool)
+	bookingRepo := repository.NewBookingRepository(pool)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(pool)
+	calendarImportRepo := repository.NewCalendarImportRepository(pool)
+	policyRepo := repository.NewCancellationPolicyRepository(pool)
+	rescheduleRepo := repository.NewRescheduleRepository(pool)
+	makeupRepo := repository.NewMakeupRepository(pool)
+	exchangeRateRepo := repository.NewExchangeRateRepository(pool)
+	invoiceRepo := repository.NewInvoiceRepository(pool)
+	packageRepo := repository.NewPackageRepository(pool)
+	analyticsRepo := repository.NewAnalyticsRepository(pool)
+	exportRepo := repository.NewExportRepository(pool)
+	studentImportRepo := repository.NewStudentImportRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+	accountRepo := repository.NewAccountRepository(pool)
+	twoFactorRepo := repository.NewTwoFactorRepository(pool)
+	portalRepo := repository.NewPortalRepository(pool)
+	organizationRepo := repository.NewOrganizationRepository(pool)
 
 	// Services
 	tutorService := service.NewTutorService(tutorRepo)
+	sessionService := service.NewSessionService(sessionRepo)
+	mailSender := mailer(cfg, log)
+	accountService := service.NewAccountService(accountRepo, tutorRepo, mailSender, cfg.JWTSecret, cfg.AppURL)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo)
 	studentService := service.NewStudentService(studentRepo)
 	courseService := service.NewCourseService(courseRepo, studentRepo, lessonRepo)
-	paymentService := service.NewPaymentService(paymentRepo, courseRepo)
-	lessonService := service.NewLessonService(lessonRepo, courseRepo)
+	paymentService := service.NewPaymentService(paymentRepo, courseRepo, enrollmentRepo, exchangeRateRepo, packageRepo)
+	lessonService := service.NewLessonService(lessonRepo, courseRepo, scheduleRepo, availabilityRepo, tutorRepo, policyRepo)
 	enrollmentService := service.NewEnrollmentService(enrollmentRepo, courseRepo, studentRepo)
-	attendanceService := service.NewAttendanceService(attendanceRepo, lessonRepo, courseRepo)
-	taskService := service.NewTaskService(taskRepo)
+	attendanceService := service.NewAttendanceService(attendanceRepo, lessonRepo, courseRepo, policyRepo)
+	taskService := service.NewTaskService(taskRepo, scheduleRepo)
+	seriesService := service.NewSeriesService(seriesRepo, courseRepo, tutorRepo, scheduleRepo)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, scheduleRepo, tutorRepo)
+	bookingService := service.NewBookingService(bookingRepo, courseRepo, availabilityRepo, scheduleRepo)
+	calendarFeedService := service.NewCalendarFeedService(calendarFeedRepo, lessonRepo, taskRepo)
+	calendarImportService := service.NewCalendarImportService(calendarImportRepo, courseRepo, studentRepo, tutorRepo, scheduleRepo)
+	policyService := service.NewCancellationPolicyService(policyRepo, courseRepo)
+	rescheduleService := service.NewRescheduleService(rescheduleRepo, lessonRepo, scheduleRepo, availabilityRepo, tutorRepo)
+	makeupService := service.NewMakeupService(makeupRepo, courseRepo, studentRepo)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo, tutorRepo)
+	invoiceService := service.NewInvoiceService(invoiceRepo, tutorRepo, courseRepo, studentRepo, enrollmentRepo, paymentRepo, invoiceFont(cfg.InvoiceFont, log))
+	packageService := service.NewPackageService(packageRepo, courseRepo, enrollmentRepo)
+	analyticsService := service.NewAnalyticsService(analyticsRepo)
+	exportService := service.NewExportService(exportRepo, courseRepo, tutorRepo)
+	studentImportService := service.NewStudentImportService(studentImportRepo, tutorRepo)
+	portalService := service.NewPortalService(portalRepo, studentRepo, paymentRepo, packageRepo, mailSender, cfg.JWTSecret, cfg.AppURL)
+	organizationService := service.NewOrganizationService(organizationRepo, paymentRepo, exchangeRateRepo)
 
 	// Handlers
 	tutorHandler := handlers.NewTutorHandler(tutorService, log)
-	authHandler := handlers.NewAuthHandler(tutorService, log, cfg.JWTSecret)
+	authHandler := handlers.NewAuthHandler(tutorService, sessionService, accountService, twoFactorService, log, cfg.JWTSecret)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, tutorService, log)
+	accountHandler := handlers.NewAccountHandler(accountService, log)
+	sessionHandler := handlers.NewSessionHandler(sessionService, log)
 	studentHandler := handlers.NewStudentHandler(studentService, log)
 	courseHandler := handlers.NewCourseHandler(courseService, log)
 	paymentHandler := handlers.NewPaymentHandler(paymentService, log)
@@ -48,6 +94,22 @@ func Setup(pool *pgxpool.Pool, log *slog.Logger, cfg *config.Config) *gin.Engine
 	enrollmentHandler := handlers.NewEnrollmentHandler(enrollmentService, log)
 	attendanceHandler := handlers.NewAttendanceHandler(attendanceService, log)
 	taskHandler := handlers.NewTaskHandler(taskService, log)
+	seriesHandler := handlers.NewSeriesHandler(seriesService, log)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService, log)
+	bookingHandler := handlers.NewBookingHandler(bookingService, log)
+	calendarFeedHandler := handlers.NewCalendarFeedHandler(calendarFeedService, log)
+	calendarImportHandler := handlers.NewCalendarImportHandler(calendarImportService, log)
+	policyHandler := handlers.NewCancellationPolicyHandler(policyService, log)
+	rescheduleHandler := handlers.NewRescheduleHandler(rescheduleService, log)
+	makeupHandler := handlers.NewMakeupHandler(makeupService, log)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService, log)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService, log)
+	packageHandler := handlers.NewPackageHandler(packageService, log)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService, log)
+	exportHandler := handlers.NewExportHandler(exportService, log)
+	studentImportHandler := handlers.NewStudentImportHandler(studentImportService, log)
+	portalHandler := handlers.NewPortalHandler(portalService, log, cfg.JWTSecret)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService, log)
 	callHandler := handlers.NewCallHandler(lessonService, log, cfg.LiveKitURL, cfg.LiveKitAPIKey, cfg.LiveKitAPISecret)
 
 	r := gin.New()
@@ -72,35 +134,117 @@ func Setup(pool *pgxpool.Pool, log *slog.Logger, cfg *config.Config) *gin.Engine
 	authLimiter := middleware.RateLimit(rate.Every(12*time.Second), 3)
 	r.POST("/auth/register", authLimiter, authHandler.Register)
 	r.POST("/auth/login", authLimiter, authHandler.Login)
+	r.POST("/auth/login/2fa", authLimiter, authHandler.LoginTwoFactor)
+	r.POST("/auth/refresh", middleware.RateLimit(rate.Every(time.Second), 10), authHandler.Refresh)
+	r.POST("/auth/verify-email", authLimiter, accountHandler.VerifyEmail)
+	passwordResetLimiter := middleware.RateLimit(rate.Every(time.Minute), 3)
+	r.POST("/auth/forgot-password", passwordResetLimiter, accountHandler.ForgotPassword)
+	r.POST("/auth/reset-password", passwordResetLimiter, accountHandler.ResetPassword)
 	r.GET("/public/lessons/:id/guest-token", middleware.RateLimit(rate.Every(3*time.Second), 5), callHandler.GetGuestToken)
+	bookingViewLimiter := middleware.RateLimit(rate.Every(2*time.Second), 10)
+	r.GET("/public/booking/:token", bookingViewLimiter, bookingHandler.GetPublicLink)
+	r.GET("/public/booking/:token/slots", bookingViewLimiter, bookingHandler.GetPublicSlots)
+	r.POST("/public/booking/:token", middleware.RateLimit(rate.Every(time.Minute), 3), bookingHandler.Book)
+	r.GET("/public/reschedules/:token", bookingViewLimiter, rescheduleHandler.GetPublic)
+	rescheduleReplyLimiter := middleware.RateLimit(rate.Every(10*time.Second), 3)
+	r.POST("/public/reschedules/:token/accept", rescheduleReplyLimiter, rescheduleHandler.Accept)
+	r.POST("/public/reschedules/:token/decline", rescheduleReplyLimiter, rescheduleHandler.Decline)
+	r.GET("/public/calendar/:token", middleware.RateLimit(rate.Every(10*time.Second), 5), calendarFeedHandler.Feed)
+	r.POST("/portal/login", authLimiter, portalHandler.Login)
+	r.POST("/portal/invites/accept", authLimiter, portalHandler.AcceptInvite)
+
+	// Portal routes: students and parents, read-only
+	portal := r.Group("/portal")
+	portal.Use(middleware.Portal(cfg.JWTSecret, portalService))
+	{
+		portal.GET("/me", portalHandler.GetProfile)
+		portal.GET("/lessons", portalHandler.GetLessons)
+		portal.GET("/balance", portalHandler.GetBalances)
+		portal.GET("/homework", portalHandler.GetHomework)
+	}
 
 	// Protected routes
 	auth := r.Group("/")
-	auth.Use(middleware.Auth(cfg.JWTSecret))
+	auth.Use(middleware.Auth(cfg.JWTSecret, sessionService))
 	{
+		auth.POST("/auth/logout", authHandler.Logout)
+		auth.POST("/auth/verify-email/send", middleware.RateLimit(rate.Every(time.Minute), 3), accountHandler.SendVerification)
+		twoFactorLimiter := middleware.RateLimit(rate.Every(12*time.Second), 5)
+		auth.GET("/auth/2fa", twoFactorHandler.GetStatus)
+		auth.POST("/auth/2fa/setup", twoFactorLimiter, twoFactorHandler.Setup)
+		auth.POST("/auth/2fa/enable", twoFactorLimiter, twoFactorHandler.Enable)
+		auth.POST("/auth/2fa/disable", twoFactorLimiter, twoFactorHandler.Disable)
+		auth.GET("/sessions", sessionHandler.GetAll)
+		auth.DELETE("/sessions", sessionHandler.DeleteOthers)
+		auth.DELETE("/sessions/:id", sessionHandler.Delete)
+
 		auth.GET("/tutors/:id", tutorHandler.GetByID)
 		auth.PUT("/tutors/:id", tutorHandler.Update)
 		auth.PUT("/tutors/:id/password", tutorHandler.ChangePassword)
 		auth.DELETE("/tutors/:id", tutorHandler.Delete)
+		auth.GET("/tutors/:id/calendar-feed", calendarFeedHandler.Get)
+		auth.POST("/tutors/:id/calendar-feed/rotate", calendarFeedHandler.Rotate)
+		auth.DELETE("/tutors/:id/calendar-feed", calendarFeedHandler.Revoke)
 
 		auth.GET("/students", studentHandler.GetAll)
 		auth.POST("/students", studentHandler.Create)
+		auth.POST("/students/import", studentImportHandler.Import)
 		auth.GET("/students/:id", studentHandler.GetByID)
 		auth.PUT("/students/:id", studentHandler.Update)
 		auth.DELETE("/students/:id", studentHandler.Delete)
 		auth.GET("/students/:id/courses", courseHandler.GetByStudent)
+		auth.GET("/students/:id/makeup-credits", makeupHandler.GetForStudent)
+		auth.GET("/students/:id/portal-accounts", portalHandler.GetAccounts)
+		auth.POST("/students/:id/portal-accounts", middleware.RateLimit(rate.Every(time.Minute), 5), portalHandler.Invite)
+		auth.DELETE("/students/:id/portal-accounts/:accountId", portalHandler.DeleteAccount)
 
 		auth.GET("/courses", courseHandler.GetAll)
 		auth.POST("/courses", courseHandler.Create)
 		auth.GET("/courses/:id", courseHandler.GetByID)
 		auth.PUT("/courses/:id", courseHandler.Update)
 		auth.DELETE("/courses/:id", courseHandler.Delete)
+		auth.GET("/courses/:id/cancellation-policy", policyHandler.GetForCourse)
+		auth.PUT("/courses/:id/cancellation-policy", policyHandler.SetForCourse)
+		auth.DELETE("/courses/:id/cancellation-policy", policyHandler.ClearForCourse)
+		auth.GET("/courses/:id/makeup-credits", makeupHandler.GetForCourse)
+		auth.GET("/cancellation-policy", policyHandler.GetForTutor)
+		auth.PUT("/cancellation-policy", policyHandler.SetForTutor)
 
 		auth.GET("/payments", paymentHandler.GetAll)
 		auth.POST("/payments", paymentHandler.Create)
 		auth.GET("/payments/recent", paymentHandler.GetRecent)
 		auth.GET("/payments/balance", paymentHandler.GetBalance)
 		auth.GET("/payments/monthly-income", paymentHandler.GetMonthlyIncome)
+		auth.PUT("/payments/:id", paymentHandler.Update)
+		auth.POST("/payments/:id/void", paymentHandler.Void)
+		auth.POST("/payments/:id/refunds", paymentHandler.Refund)
+		auth.GET("/payments/:id/entries", paymentHandler.GetEntries)
+
+		auth.GET("/analytics/income", analyticsHandler.GetIncome)
+		auth.GET("/analytics/workload", analyticsHandler.GetWorkload)
+
+		auth.GET("/exports/students", exportHandler.Students)
+		auth.GET("/exports/courses", exportHandler.Courses)
+		auth.GET("/exports/lessons", exportHandler.Lessons)
+		auth.GET("/exports/payments", exportHandler.Payments)
+
+		auth.GET("/exchange-rates", exchangeRateHandler.GetAll)
+		auth.PUT("/exchange-rates/:currency", exchangeRateHandler.Set)
+		auth.DELETE("/exchange-rates/:currency", exchangeRateHandler.Delete)
+
+		auth.GET("/invoices", invoiceHandler.GetAll)
+		auth.POST("/invoices", invoiceHandler.Create)
+		auth.GET("/invoices/:id", invoiceHandler.GetByID)
+		auth.DELETE("/invoices/:id", invoiceHandler.Delete)
+		auth.POST("/invoices/:id/send", invoiceHandler.Send)
+		auth.POST("/invoices/:id/pay", invoiceHandler.Pay)
+		auth.GET("/invoices/:id/pdf", invoiceHandler.PDF)
+
+		auth.GET("/packages", packageHandler.GetAll)
+		auth.POST("/packages", packageHandler.Create)
+		auth.PUT("/packages/:id", packageHandler.Update)
+		auth.DELETE("/packages/:id", packageHandler.Delete)
+		auth.POST("/packages/:id/sell", packageHandler.Sell)
 
 		auth.GET("/lessons", lessonHandler.GetByCourse)
 		auth.POST("/lessons", lessonHandler.Create)
@@ -109,12 +253,22 @@ func Setup(pool *pgxpool.Pool, log *slog.Logger, cfg *config.Config) *gin.Engine
 		auth.GET("/lessons/:id", lessonHandler.GetByID)
 		auth.PUT("/lessons/:id", lessonHandler.Update)
 		auth.DELETE("/lessons/:id", lessonHandler.Delete)
-		auth.DELETE("/lessons/series/:seriesId", lessonHandler.DeleteSeries)
-		auth.PATCH("/lessons/series/:seriesId", lessonHandler.UpdateSeries)
+		auth.GET("/lessons/:id/status-events", lessonHandler.GetStatusEvents)
+		auth.POST("/lessons/:id/reschedule", rescheduleHandler.Reschedule)
+		auth.DELETE("/lessons/:id/reschedule", rescheduleHandler.Withdraw)
+		auth.GET("/lessons/:id/reschedules", rescheduleHandler.GetByLesson)
+		auth.POST("/lessons/series", seriesHandler.Create)
+		auth.GET("/lessons/series/:seriesId", seriesHandler.GetByID)
+		auth.POST("/lessons/series/:seriesId/extend", seriesHandler.Extend)
+		auth.DELETE("/lessons/series/:seriesId", seriesHandler.Delete)
+		auth.PATCH("/lessons/series/:seriesId", seriesHandler.Update)
 
 		auth.GET("/calendar", lessonHandler.GetCalendar)
+		auth.POST("/calendar/imports", calendarImportHandler.Preview)
+		auth.POST("/calendar/imports/:id/commit", calendarImportHandler.Commit)
 
 		auth.GET("/courses/:id/enrollments", enrollmentHandler.GetByCourse)
+		auth.GET("/courses/:id/enrollments/balances", paymentHandler.GetStudentBalances)
 		auth.POST("/courses/:id/enrollments", enrollmentHandler.Add)
 		auth.DELETE("/courses/:id/enrollments/:studentId", enrollmentHandler.Remove)
 
@@ -128,7 +282,71 @@ func Setup(pool *pgxpool.Pool, log *slog.Logger, cfg *config.Config) *gin.Engine
 		auth.PATCH("/tasks/:id/done", taskHandler.ToggleDone)
 
 		auth.POST("/lessons/:id/room-token", callHandler.GetToken)
+
+		auth.GET("/booking-links", bookingHandler.GetLinks)
+		auth.POST("/booking-links", bookingHandler.CreateLink)
+		auth.DELETE("/booking-links/:id", bookingHandler.DeleteLink)
+		auth.GET("/booking-requests", bookingHandler.GetRequests)
+		auth.POST("/lessons/:id/confirm", bookingHandler.Confirm)
+		auth.POST("/lessons/:id/decline", bookingHandler.Decline)
+
+		auth.GET("/availability", availabilityHandler.GetFreeSlots)
+		auth.GET("/availability/hours", availabilityHandler.GetWorkingHours)
+		auth.PUT("/availability/hours", availabilityHandler.SetWorkingHours)
+		auth.GET("/availability/overrides", availabilityHandler.GetOverrides)
+		auth.POST("/availability/overrides", availabilityHandler.CreateOverride)
+		auth.DELETE("/availability/overrides/:id", availabilityHandler.DeleteOverride)
+		auth.GET("/availability/blackouts", availabilityHandler.GetBlackouts)
+		auth.POST("/availability/blackouts", availabilityHandler.CreateBlackout)
+		auth.DELETE("/availability/blackouts/:id", availabilityHandler.DeleteBlackout)
+
+		auth.GET("/organizations", organizationHandler.GetAll)
+		auth.POST("/organizations", organizationHandler.Create)
+		auth.GET("/organizations/:id", organizationHandler.GetByID)
+		auth.PUT("/organizations/:id", organizationHandler.Update)
+		auth.DELETE("/organizations/:id", organizationHandler.Delete)
+		auth.POST("/organizations/:id/join", organizationHandler.Join)
+		auth.GET("/organizations/:id/members", organizationHandler.GetMembers)
+		auth.POST("/organizations/:id/members", organizationHandler.InviteMember)
+		auth.PUT("/organizations/:id/members/:tutorId", organizationHandler.UpdateMember)
+		auth.DELETE("/organizations/:id/members/:tutorId", organizationHandler.RemoveMember)
+		auth.GET("/organizations/:id/calendar", organizationHandler.GetCalendar)
+		auth.GET("/organizations/:id/income", organizationHandler.GetIncome)
+		auth.POST("/organizations/:id/students/:studentId/assign", organizationHandler.AssignStudent)
+		auth.POST("/organizations/:id/courses/:courseId/assign", organizationHandler.AssignCourse)
 	}
 
 	return r
 }
+
+// mailer sends through the configured SMTP server, or only logs mail when there
+// is none.
+func mailer(cfg *config.Config, log *slog.Logger) mail.Sender {
+	if cfg.SMTPHost == "" {
+		log.Warn("SMTP_HOST is not set, mail will only be logged")
+		return mail.NewLog(log)
+	}
+	sender, err := mail.NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	if err != nil {
+		log.Error("Invalid mail settings, mail will only be logged", slog.String("error", err.Error()))
+		return mail.NewLog(log)
+	}
+	return sender
+}
+
+// invoiceFont loads the TrueType font for invoice PDFs. Without one, or when it
+// can't be used, invoices fall back to Helvetica.
+func invoiceFont(path string, log *slog.Logger) pdf.Font {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var font *pdf.TrueType
+		if font, err = pdf.ParseTrueType(data); err == nil {
+			return font
+		}
+	}
+	log.Warn("Invoice font not loaded, using Helvetica", slog.String("path", path), slog.String("error", err.Error()))
+	return nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"tutorgo/mail"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type AccountService interface {
+	SendVerification(ctx context.Context, tutorID string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, passwordHash string) error
+}
+
+type accountService struct {
+	repo      repository.AccountRepository
+	tutorRepo repository.TutorRepository
+	mailer    mail.Sender
+	signer    linkSigner
+	appURL    string
+}
+
+// NewAccountService signs tokens with secret and mails links to pages of the app
+// at appURL.
+func NewAccountService(repo repository.AccountRepository, tutorRepo repository.TutorRepository, mailer mail.Sender, secret string, appURL string) AccountService {
+	return &accountService{
+		repo:      repo,
+		tutorRepo: tutorRepo,
+		mailer:    mailer,
+		signer:    linkSigner{secret: []byte(secret)},
+		appURL:    strings.TrimSuffix(appURL, "/"),
+	}
+}
+
+// How long the mailed links work.
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+var errInvalidLink = fmt.Errorf("the link is invalid or has expired: %w", ErrBadRequest)
+
+// SendVerification mails the tutor a link to verify their current email.
+func (s *accountService) SendVerification(ctx context.Context, tutorID string) error {
+	tutor, err := s.tutorRepo.GetByID(ctx, tutorID)
+	if err != nil {
+		return fmt.Errorf("tutor: %w", ErrNotFound)
+	}
+	if tutor.EmailVerified {
+		return fmt.Errorf("the email is already verified: %w", ErrConflict)
+	}
+	link, err := s.link(ctx, tutorID, models.TokenVerifyEmail, tutor.Email, verifyEmailTTL, "/verify-email")
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      tutor.Email,
+		Subject: "Confirm your email for TutorGo",
+		Body: "Hello " + tutor.FirstName + ",\n\n" +
+			"Please confirm that this is your email by following the link below:\n\n" +
+			link + "\n\n" +
+			"The link works for 48 hours. If you didn't sign up for TutorGo, ignore this email.\n",
+	})
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	id, ok := s.signer.parse(models.TokenVerifyEmail, token)
+	if !ok {
+		return errInvalidLink
+	}
+	if _, err := s.repo.VerifyEmail(ctx, id); err != nil {
+		return errInvalidLink
+	}
+	return nil
+}
+
+// ForgotPassword mails a password reset link to the tutor with the email, if
+// there is one. Whether there is is not told, so the form can't be used to find
+// out who has an account.
+func (s *accountService) ForgotPassword(ctx context.Context, email string) error {
+	tutorID, _, err := s.tutorRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+	link, err := s.link(ctx, tutorID, models.TokenResetPassword, email, resetPasswordTTL, "/reset-password")
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Reset your TutorGo password",
+		Body: "Hello,\n\n" +
+			"Someone asked to reset the password of your TutorGo account. To choose a new one, follow the link below:\n\n" +
+			link + "\n\n" +
+			"The link works for an hour, once. If it wasn't you, ignore this email; your password stays the same.\n",
+	})
+}
+
+// ResetPassword sets a new password and signs every device out.
+func (s *accountService) ResetPassword(ctx context.Context, token string, passwordHash string) error {
+	id, ok := s.signer.parse(models.TokenResetPassword, token)
+	if !ok {
+		return errInvalidLink
+	}
+	if _, err := s.repo.ResetPassword(ctx, id, passwordHash); err != nil {
+		return errInvalidLink
+	}
+	return nil
+}
+
+// link stores a token and returns the app page at path that takes it.
+func (s *accountService) link(ctx context.Context, tutorID string, purpose string, email string, ttl time.Duration, path string) (string, error) {
+	expiresAt := time.Now().Add(ttl)
+	id, err := s.repo.CreateToken(ctx, tutorID, purpose, email, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	return s.appURL + path + "?token=" + url.QueryEscape(s.signer.sign(purpose, id, expiresAt)), nil
+}
@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+	"tutorgo/mail"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountRepo struct {
+	mock.Mock
+}
+
+func (m *mockAccountRepo) CreateToken(ctx context.Context, tutorID string, purpose string, email string, expiresAt time.Time) (string, error) {
+	args := m.Called(ctx, tutorID, purpose, email, expiresAt)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockAccountRepo) VerifyEmail(ctx context.Context, tokenID string) (string, error) {
+	args := m.Called(ctx, tokenID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockAccountRepo) ResetPassword(ctx context.Context, tokenID string, passwordHash string) (string, error) {
+	args := m.Called(ctx, tokenID, passwordHash)
+	return args.String(0), args.Error(1)
+}
+
+// outbox keeps what is sent instead of sending it.
+type outbox []mail.Message
+
+func (o *outbox) Send(_ context.Context, msg mail.Message) error {
+	*o = append(*o, msg)
+	return nil
+}
+
+var linkToken = regexp.MustCompile(`https://app\.example\.com/[a-z/-]+\?token=(\S+)`)
+
+// mailedToken is the token of the link in the message.
+func mailedToken(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	m := linkToken.FindStringSubmatch(msg.Body)
+	require.NotNil(t, m, "no link in %q", msg.Body)
+	return m[1]
+}
+
+func newAccountService(repo *mockAccountRepo, tutorRepo *mockTutorRepo, sent *outbox) service.AccountService {
+	return service.NewAccountService(repo, tutorRepo, sent, "test-secret", "https://app.example.com/")
+}
+
+func TestForgotPassword_MailsWorkingLink(t *testing.T) {
+	repo := new(mockAccountRepo)
+	tutorRepo := new(mockTutorRepo)
+	sent := new(outbox)
+	svc := newAccountService(repo, tutorRepo, sent)
+
+	tutorRepo.On("GetByEmail", mock.Anything, "anna@example.com").Return(tutorID, "hash", nil)
+	repo.On("CreateToken", mock.Anything, tutorID, models.TokenResetPassword, "anna@example.com", mock.Anything).
+		Return("token-1", nil)
+	repo.On("ResetPassword", mock.Anything, "token-1", "new-hash").Return(tutorID, nil)
+
+	require.NoError(t, svc.ForgotPassword(context.Background(), "anna@example.com"))
+	require.Len(t, *sent, 1)
+	assert.Equal(t, "anna@example.com", (*sent)[0].To)
+
+	err := svc.ResetPassword(context.Background(), mailedToken(t, (*sent)[0]), "new-hash")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestForgotPassword_UnknownEmailSendsNothing(t *testing.T) {
+	repo := new(mockAccountRepo)
+	tutorRepo := new(mockTutorRepo)
+	sent := new(outbox)
+	svc := newAccountService(repo, tutorRepo, sent)
+
+	tutorRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return("", "", errors.New("no rows"))
+
+	err := svc.ForgotPassword(context.Background(), "nobody@example.com")
+
+	assert.NoError(t, err)
+	assert.Empty(t, *sent)
+	repo.AssertNotCalled(t, "CreateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResetPassword_RejectsOtherTokens(t *testing.T) {
+	repo := new(mockAccountRepo)
+	tutorRepo := new(mockTutorRepo)
+	sent := new(outbox)
+	svc := newAccountService(repo, tutorRepo, sent)
+
+	tutorRepo.On("GetByID", mock.Anything, tutorID).Return(models.Tutor{ID: tutorID, Email: "anna@example.com"}, nil)
+	repo.On("CreateToken", mock.Anything, tutorID, models.TokenVerifyEmail, "anna@example.com", mock.Anything).
+		Return("token-1", nil)
+	require.NoError(t, svc.SendVerification(context.Background(), tutorID))
+	verifyToken := mailedToken(t, (*sent)[0])
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"verification token", verifyToken},
+		{"tampered id", "token-2" + verifyToken[len("token-1"):]},
+		{"garbage", "not-a-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.ResetPassword(context.Background(), tt.token, "new-hash")
+
+			assert.ErrorIs(t, err, service.ErrBadRequest)
+		})
+	}
+	repo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerifyEmail_UsedToken(t *testing.T) {
+	repo := new(mockAccountRepo)
+	tutorRepo := new(mockTutorRepo)
+	sent := new(outbox)
+	svc := newAccountService(repo, tutorRepo, sent)
+
+	tutorRepo.On("GetByID", mock.Anything, tutorID).Return(models.Tutor{ID: tutorID, Email: "anna@example.com"}, nil)
+	repo.On("CreateToken", mock.Anything, tutorID, models.TokenVerifyEmail, "anna@example.com", mock.Anything).
+		Return("token-1", nil)
+	repo.On("VerifyEmail", mock.Anything, "token-1").Return("", errors.New("no rows"))
+	require.NoError(t, svc.SendVerification(context.Background(), tutorID))
+
+	err := svc.VerifyEmail(context.Background(), mailedToken(t, (*sent)[0]))
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestSendVerification_AlreadyVerified(t *testing.T) {
+	repo := new(mockAccountRepo)
+	tutorRepo := new(mockTutorRepo)
+	sent := new(outbox)
+	svc := newAccountService(repo, tutorRepo, sent)
+
+	tutorRepo.On("GetByID", mock.Anything, tutorID).Return(models.Tutor{ID: tutorID, EmailVerified: true}, nil)
+
+	err := svc.SendVerification(context.Background(), tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	assert.Empty(t, *sent)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type AnalyticsService interface {
+	GetIncome(ctx context.Context, tutorID string, from string, to string, bucket string) (models.IncomeAnalytics, error)
+	GetWorkload(ctx context.Context, tutorID string, from string, to string) (models.Workload, error)
+}
+
+type analyticsService struct {
+	repo repository.AnalyticsRepository
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{repo: repo}
+}
+
+// maxIncomeBuckets keeps a report to a year of days or some thirty years of
+// months.
+const maxIncomeBuckets = 366
+
+// GetIncome reports the income between the dates from and to, both included,
+// bucketed by day, week or month (the default).
+func (s *analyticsService) GetIncome(ctx context.Context, tutorID string, from string, to string, bucket string) (models.IncomeAnalytics, error) {
+	start, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return models.IncomeAnalytics{}, fmt.Errorf("from must be a date: %w", ErrBadRequest)
+	}
+	end, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return models.IncomeAnalytics{}, fmt.Errorf("to must be a date: %w", ErrBadRequest)
+	}
+	if end.Before(start) {
+		return models.IncomeAnalytics{}, fmt.Errorf("to is before from: %w", ErrBadRequest)
+	}
+	if bucket == "" {
+		bucket = models.BucketMonth
+	}
+	n, err := bucketCount(start, end, bucket)
+	if err != nil {
+		return models.IncomeAnalytics{}, err
+	}
+	if n > maxIncomeBuckets {
+		return models.IncomeAnalytics{}, fmt.Errorf("the period has more than %d %ss: %w", maxIncomeBuckets, bucket, ErrBadRequest)
+	}
+	return s.repo.GetIncome(ctx, tutorID, from, to, bucket)
+}
+
+// bucketCount counts the buckets from the one start falls in to the one end does.
+func bucketCount(start, end time.Time, bucket string) (int, error) {
+	days := int(end.Sub(start).Hours() / 24)
+	switch bucket {
+	case models.BucketDay:
+		return days + 1, nil
+	case models.BucketWeek:
+		// Go counts weekdays from Sunday, weeks start on Monday.
+		sinceMonday := (int(start.Weekday()) + 6) % 7
+		return (sinceMonday+days)/7 + 1, nil
+	case models.BucketMonth:
+		return (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()) + 1, nil
+	default:
+		return 0, fmt.Errorf("bucket must be day, week or month: %w", ErrBadRequest)
+	}
+}
+
+// maxWorkloadDays keeps a workload report to a year.
+const maxWorkloadDays = 366
+
+// daysPerMonth is the length of the average Gregorian month.
+const daysPerMonth = 365.2425 / 12
+
+// GetWorkload reports the lessons between the dates from and to, both included,
+// and compares them with the period of the same length just before.
+func (s *analyticsService) GetWorkload(ctx context.Context, tutorID string, from string, to string) (models.Workload, error) {
+	start, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return models.Workload{}, fmt.Errorf("from must be a date: %w", ErrBadRequest)
+	}
+	end, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return models.Workload{}, fmt.Errorf("to must be a date: %w", ErrBadRequest)
+	}
+	if end.Before(start) {
+		return models.Workload{}, fmt.Errorf("to is before from: %w", ErrBadRequest)
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days > maxWorkloadDays {
+		return models.Workload{}, fmt.Errorf("the period is longer than %d days: %w", maxWorkloadDays, ErrBadRequest)
+	}
+
+	// The repository reads the end of a period as exclusive.
+	w, err := s.repo.GetWorkload(ctx, tutorID, from, end.AddDate(0, 0, 1).Format(time.DateOnly))
+	if err != nil {
+		return models.Workload{}, err
+	}
+	prevStart := start.AddDate(0, 0, -days)
+	w.Previous, err = s.repo.GetWorkloadSummary(ctx, tutorID, prevStart.Format(time.DateOnly), from)
+	if err != nil {
+		return models.Workload{}, err
+	}
+
+	months := float64(days) / daysPerMonth
+	w.Summary.From, w.Summary.To = from, to
+	w.Previous.From, w.Previous.To = prevStart.Format(time.DateOnly), start.AddDate(0, 0, -1).Format(time.DateOnly)
+	fillWorkloadRates(&w.Summary, months)
+	fillWorkloadRates(&w.Previous, months)
+	for i := range w.Students {
+		sw := &w.Students[i]
+		sw.CancellationRate = ratio(float64(sw.Cancelled), float64(sw.Lessons))
+		sw.NoShowRate = ratio(float64(sw.Missed), float64(sw.Completed+sw.Missed))
+		sw.LessonsPerMonth = round2(float64(sw.Completed) / months)
+	}
+	return w, nil
+}
+
+func fillWorkloadRates(ws *models.WorkloadSummary, months float64) {
+	ws.CancellationRate = ratio(float64(ws.Cancelled), float64(ws.Lessons))
+	ws.NoShowRate = ratio(float64(ws.Missed), float64(ws.Completed+ws.Missed))
+	if ws.Students > 0 {
+		ws.LessonsPerStudentMonth = round2(float64(ws.StudentLessons) / float64(ws.Students) / months)
+	}
+}
+
+// ratio is part/whole to four places, 0 when there is no whole.
+func ratio(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(part/whole*10000) / 10000
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
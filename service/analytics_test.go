@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAnalyticsRepo struct {
+	mock.Mock
+}
+
+func (m *mockAnalyticsRepo) GetIncome(ctx context.Context, tutorID string, from string, to string, bucket string) (models.IncomeAnalytics, error) {
+	args := m.Called(ctx, tutorID, from, to, bucket)
+	return args.Get(0).(models.IncomeAnalytics), args.Error(1)
+}
+
+func TestAnalyticsGetIncome_DefaultsToMonths(t *testing.T) {
+	repo := new(mockAnalyticsRepo)
+	svc := service.NewAnalyticsService(repo)
+
+	repo.On("GetIncome", mock.Anything, tutorID, "2025-01-01", "2025-12-31", models.BucketMonth).
+		Return(models.IncomeAnalytics{Bucket: models.BucketMonth}, nil)
+
+	income, err := svc.GetIncome(context.Background(), tutorID, "2025-01-01", "2025-12-31", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.BucketMonth, income.Bucket)
+	repo.AssertExpectations(t)
+}
+
+func TestAnalyticsGetIncome_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name, from, to, bucket string
+	}{
+		{"from not a date", "2025-13-01", "2025-12-31", ""},
+		{"to before from", "2025-06-01", "2025-05-31", ""},
+		{"unknown bucket", "2025-01-01", "2025-01-31", "quarter"},
+		{"too many days", "2024-01-01", "2025-01-01", models.BucketDay},
+		{"too many weeks", "2017-01-01", "2025-01-01", models.BucketWeek},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockAnalyticsRepo)
+			svc := service.NewAnalyticsService(repo)
+
+			_, err := svc.GetIncome(context.Background(), tutorID, tt.from, tt.to, tt.bucket)
+
+			assert.ErrorIs(t, err, service.ErrBadRequest)
+			repo.AssertNotCalled(t, "GetIncome", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAnalyticsGetIncome_LeapYearOfDays(t *testing.T) {
+	repo := new(mockAnalyticsRepo)
+	svc := service.NewAnalyticsService(repo)
+
+	repo.On("GetIncome", mock.Anything, tutorID, "2024-01-01", "2024-12-31", models.BucketDay).
+		Return(models.IncomeAnalytics{}, nil)
+
+	_, err := svc.GetIncome(context.Background(), tutorID, "2024-01-01", "2024-12-31", models.BucketDay)
+
+	assert.NoError(t, err)
+}
+
+func (m *mockAnalyticsRepo) GetWorkload(ctx context.Context, tutorID string, from string, to string) (models.Workload, error) {
+	args := m.Called(ctx, tutorID, from, to)
+	return args.Get(0).(models.Workload), args.Error(1)
+}
+
+func (m *mockAnalyticsRepo) GetWorkloadSummary(ctx context.Context, tutorID string, from string, to string) (models.WorkloadSummary, error) {
+	args := m.Called(ctx, tutorID, from, to)
+	return args.Get(0).(models.WorkloadSummary), args.Error(1)
+}
+
+func TestAnalyticsGetWorkload_ComparesWithPreviousPeriod(t *testing.T) {
+	repo := new(mockAnalyticsRepo)
+	svc := service.NewAnalyticsService(repo)
+
+	repo.On("GetWorkload", mock.Anything, tutorID, "2025-03-01", "2025-04-01").Return(models.Workload{
+		Summary: models.WorkloadSummary{Lessons: 20, Completed: 14, Cancelled: 4, Missed: 2, Students: 4, StudentLessons: 14},
+		Students: []models.StudentWorkload{
+			{StudentID: "student-uuid-2", Lessons: 10, Completed: 6, Cancelled: 2, Missed: 2},
+		},
+	}, nil)
+	repo.On("GetWorkloadSummary", mock.Anything, tutorID, "2025-01-29", "2025-03-01").Return(models.WorkloadSummary{
+		Lessons: 10, Completed: 10, Students: 2, StudentLessons: 10,
+	}, nil)
+
+	w, err := svc.GetWorkload(context.Background(), tutorID, "2025-03-01", "2025-03-31")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-03-31", w.Summary.To)
+	assert.Equal(t, 0.2, w.Summary.CancellationRate)
+	assert.Equal(t, 0.125, w.Summary.NoShowRate)
+	assert.Equal(t, 3.44, w.Summary.LessonsPerStudentMonth)
+	assert.Equal(t, "2025-01-29", w.Previous.From)
+	assert.Equal(t, "2025-02-28", w.Previous.To)
+	assert.Zero(t, w.Previous.CancellationRate)
+	assert.Equal(t, 4.91, w.Previous.LessonsPerStudentMonth)
+	assert.Equal(t, 0.25, w.Students[0].NoShowRate)
+	assert.Equal(t, 5.89, w.Students[0].LessonsPerMonth)
+	repo.AssertExpectations(t)
+}
+
+func TestAnalyticsGetWorkload_NoLessons(t *testing.T) {
+	repo := new(mockAnalyticsRepo)
+	svc := service.NewAnalyticsService(repo)
+
+	repo.On("GetWorkload", mock.Anything, tutorID, "2025-03-01", "2025-03-08").Return(models.Workload{}, nil)
+	repo.On("GetWorkloadSummary", mock.Anything, tutorID, "2025-02-22", "2025-03-01").Return(models.WorkloadSummary{}, nil)
+
+	w, err := svc.GetWorkload(context.Background(), tutorID, "2025-03-01", "2025-03-07")
+
+	assert.NoError(t, err)
+	assert.Zero(t, w.Summary.NoShowRate)
+	assert.Zero(t, w.Summary.LessonsPerStudentMonth)
+}
+
+func TestAnalyticsGetWorkload_TooLong(t *testing.T) {
+	repo := new(mockAnalyticsRepo)
+	svc := service.NewAnalyticsService(repo)
+
+	_, err := svc.GetWorkload(context.Background(), tutorID, "2024-01-01", "2025-01-01")
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
@@ -16,10 +16,11 @@ type attendanceService struct {
 	repo       repository.AttendanceRepository
 	lessonRepo repository.LessonRepository
 	courseRepo repository.CourseRepository
+	policyRepo repository.CancellationPolicyRepository
 }
 
-func NewAttendanceService(repo repository.AttendanceRepository, lessonRepo repository.LessonRepository, courseRepo repository.CourseRepository) AttendanceService {
-	return &attendanceService{repo: repo, lessonRepo: lessonRepo, courseRepo: courseRepo}
+func NewAttendanceService(repo repository.AttendanceRepository, lessonRepo repository.LessonRepository, courseRepo repository.CourseRepository, policyRepo repository.CancellationPolicyRepository) AttendanceService {
+	return &attendanceService{repo: repo, lessonRepo: lessonRepo, courseRepo: courseRepo, policyRepo: policyRepo}
 }
 
 func (s *attendanceService) Update(ctx context.Context, lessonID string, req models.UpdateAttendanceRequest, tutorID string) error {
@@ -35,7 +36,23 @@ func (s *attendanceService) Update(ctx context.Context, lessonID string, req mod
 		return fmt.Errorf("attendance for individual courses: %w", ErrForbidden)
 	}
 
-	return s.repo.Upsert(ctx, lessonID, req.Attendances)
+	policy, err := s.policyRepo.GetForCourse(ctx, lesson.CourseID)
+	if err != nil {
+		return err
+	}
+	// An absence is charged like a no-show unless the entry says otherwise.
+	entries := make([]models.AttendanceEntry, len(req.Attendances))
+	for i, e := range req.Attendances {
+		switch {
+		case e.Status == "present":
+			e.Charge = nil
+		case e.Charge == nil:
+			charge := policy.NoShowCharge
+			e.Charge = &charge
+		}
+		entries[i] = e
+	}
+	return s.repo.Upsert(ctx, lessonID, entries)
 }
 
 func (s *attendanceService) GetByLesson(ctx context.Context, lessonID string, tutorID string) ([]models.LessonAttendance, error) {
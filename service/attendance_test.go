@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAttendanceRepo struct {
+	mock.Mock
+}
+
+func (m *mockAttendanceRepo) Upsert(ctx context.Context, lessonID string, entries []models.AttendanceEntry) error {
+	return m.Called(ctx, lessonID, entries).Error(0)
+}
+
+func (m *mockAttendanceRepo) GetByLesson(ctx context.Context, lessonID string) ([]models.LessonAttendance, error) {
+	args := m.Called(ctx, lessonID)
+	return args.Get(0).([]models.LessonAttendance), args.Error(1)
+}
+
+func TestAttendanceUpdate_AbsenceChargedAsNoShow(t *testing.T) {
+	repo := new(mockAttendanceRepo)
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := service.NewAttendanceService(repo, lessonRepo, courseRepo, policyOf(strictPolicy))
+
+	free := models.ChargeFree
+	full := models.ChargeFull
+	req := models.UpdateAttendanceRequest{Attendances: []models.AttendanceEntry{
+		{StudentID: "student-1", Status: "present", Charge: &full},
+		{StudentID: "student-2", Status: "absent"},
+		{StudentID: "student-3", Status: "absent", Charge: &free},
+	}}
+	want := []models.AttendanceEntry{
+		{StudentID: "student-1", Status: "present"},
+		{StudentID: "student-2", Status: "absent", Charge: &full},
+		{StudentID: "student-3", Status: "absent", Charge: &free},
+	}
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{ID: courseID, TutorID: tutorID}, nil)
+	repo.On("Upsert", mock.Anything, lessonID, want).Return(nil)
+
+	err := svc.Update(context.Background(), lessonID, req, tutorID)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestAttendanceUpdate_IndividualCourse(t *testing.T) {
+	repo := new(mockAttendanceRepo)
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := service.NewAttendanceService(repo, lessonRepo, courseRepo, defaultPolicy())
+
+	studentID := "student-1"
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{ID: courseID, StudentID: &studentID}, nil)
+
+	err := svc.Update(context.Background(), lessonID, models.UpdateAttendanceRequest{}, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything, mock.Anything)
+}
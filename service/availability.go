@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+// maxAvailabilityRange bounds a single free-slot query.
+const maxAvailabilityRange = 62 * 24 * time.Hour
+
+type AvailabilityService interface {
+	GetFreeSlots(ctx context.Context, tutorID string, from string, to string) ([]models.FreeSlot, error)
+	GetWorkingHours(ctx context.Context, tutorID string) ([]models.WorkingHours, error)
+	SetWorkingHours(ctx context.Context, tutorID string, req models.SetWorkingHoursRequest) ([]models.WorkingHours, error)
+	GetOverrides(ctx context.Context, tutorID string, from string, to string) ([]models.AvailabilityOverride, error)
+	CreateOverride(ctx context.Context, tutorID string, req models.CreateOverrideRequest) (models.AvailabilityOverride, error)
+	DeleteOverride(ctx context.Context, id string, tutorID string) error
+	GetBlackouts(ctx context.Context, tutorID string, from string, to string) ([]models.Blackout, error)
+	CreateBlackout(ctx context.Context, tutorID string, req models.CreateBlackoutRequest) (models.Blackout, error)
+	DeleteBlackout(ctx context.Context, id string, tutorID string) error
+}
+
+type availabilityService struct {
+	repo         repository.AvailabilityRepository
+	scheduleRepo repository.ScheduleRepository
+	tutorRepo    repository.TutorRepository
+}
+
+func NewAvailabilityService(repo repository.AvailabilityRepository, scheduleRepo repository.ScheduleRepository, tutorRepo repository.TutorRepository) AvailabilityService {
+	return &availabilityService{repo: repo, scheduleRepo: scheduleRepo, tutorRepo: tutorRepo}
+}
+
+// GetFreeSlots returns the working time in [from, to) not taken by lessons, tasks or
+// blackouts; a tutor without working hours works all day. Bare dates are midnights
+// in the tutor's timezone.
+func (s *availabilityService) GetFreeSlots(ctx context.Context, tutorID string, from string, to string) ([]models.FreeSlot, error) {
+	tz, err := s.tutorRepo.GetTimezone(ctx, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := parseRange(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	if end.Sub(start) > maxAvailabilityRange {
+		return nil, fmt.Errorf("range is longer than 62 days: %w", ErrBadRequest)
+	}
+
+	free, err := freeIntervals(ctx, s.repo, s.scheduleRepo, tutorID, loc, start, end)
+	if err != nil {
+		return nil, err
+	}
+	slots := []models.FreeSlot{}
+	for _, f := range free {
+		slots = append(slots, models.FreeSlot{Start: f.start, End: f.end})
+	}
+	return slots, nil
+}
+
+func (s *availabilityService) GetWorkingHours(ctx context.Context, tutorID string) ([]models.WorkingHours, error) {
+	return s.repo.GetWorkingHours(ctx, tutorID)
+}
+
+func (s *availabilityService) SetWorkingHours(ctx context.Context, tutorID string, req models.SetWorkingHoursRequest) ([]models.WorkingHours, error) {
+	for _, h := range req.Hours {
+		if h.Start >= h.End {
+			return nil, fmt.Errorf("working hours must end after they start: %w", ErrBadRequest)
+		}
+	}
+	return s.repo.ReplaceWorkingHours(ctx, tutorID, req.Hours)
+}
+
+func (s *availabilityService) GetOverrides(ctx context.Context, tutorID string, from string, to string) ([]models.AvailabilityOverride, error) {
+	for _, v := range []string{from, to} {
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return nil, fmt.Errorf("from and to must be YYYY-MM-DD: %w", ErrBadRequest)
+		}
+	}
+	return s.repo.GetOverrides(ctx, tutorID, from, to)
+}
+
+func (s *availabilityService) CreateOverride(ctx context.Context, tutorID string, req models.CreateOverrideRequest) (models.AvailabilityOverride, error) {
+	if req.Start >= req.End {
+		return models.AvailabilityOverride{}, fmt.Errorf("override must end after it starts: %w", ErrBadRequest)
+	}
+	return s.repo.CreateOverride(ctx, tutorID, req)
+}
+
+func (s *availabilityService) DeleteOverride(ctx context.Context, id string, tutorID string) error {
+	if err := s.repo.DeleteOverride(ctx, id, tutorID); err != nil {
+		return fmt.Errorf("override: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// GetBlackouts lists the blackouts overlapping [from, to). Bare dates are
+// midnights in the tutor's timezone.
+func (s *availabilityService) GetBlackouts(ctx context.Context, tutorID string, from string, to string) ([]models.Blackout, error) {
+	tz, err := s.tutorRepo.GetTimezone(ctx, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := parseRange(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetBlackouts(ctx, tutorID, start, end)
+}
+
+func (s *availabilityService) CreateBlackout(ctx context.Context, tutorID string, req models.CreateBlackoutRequest) (models.Blackout, error) {
+	return s.repo.CreateBlackout(ctx, tutorID, req)
+}
+
+func (s *availabilityService) DeleteBlackout(ctx context.Context, id string, tutorID string) error {
+	if err := s.repo.DeleteBlackout(ctx, id, tutorID); err != nil {
+		return fmt.Errorf("blackout: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// availability is a tutor's working-hours setup loaded for a range of days.
+type availability struct {
+	loc       *time.Location
+	hours     []models.WorkingHours
+	overrides map[string][]models.AvailabilityOverride // by date
+	blackouts []models.Blackout
+}
+
+func loadAvailability(ctx context.Context, repo repository.AvailabilityRepository, tutorID string, loc *time.Location, from, to time.Time) (availability, error) {
+	av := availability{loc: loc, overrides: map[string][]models.AvailabilityOverride{}}
+	var err error
+	if av.hours, err = repo.GetWorkingHours(ctx, tutorID); err != nil {
+		return availability{}, err
+	}
+	// One day of slack on both sides covers intervals that cross the range edges.
+	overrides, err := repo.GetOverrides(ctx, tutorID,
+		from.In(loc).AddDate(0, 0, -1).Format(time.DateOnly), to.In(loc).AddDate(0, 0, 1).Format(time.DateOnly))
+	if err != nil {
+		return availability{}, err
+	}
+	for _, o := range overrides {
+		av.overrides[o.Date] = append(av.overrides[o.Date], o)
+	}
+	if av.blackouts, err = repo.GetBlackouts(ctx, tutorID, from, to); err != nil {
+		return availability{}, err
+	}
+	return av, nil
+}
+
+// freeIntervals is the working time in [from, to) minus lessons, open tasks and blackouts.
+func freeIntervals(ctx context.Context, repo repository.AvailabilityRepository, scheduleRepo repository.ScheduleRepository, tutorID string, loc *time.Location, from, to time.Time) ([]interval, error) {
+	av, err := loadAvailability(ctx, repo, tutorID, loc, from, to)
+	if err != nil {
+		return nil, err
+	}
+	busy, err := scheduleRepo.GetBusy(ctx, tutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	taken := make([]interval, 0, len(busy)+len(av.blackouts))
+	for _, b := range busy {
+		taken = append(taken, interval{b.ScheduledAt, b.ScheduledAt.Add(time.Duration(b.DurationMinutes) * time.Minute)})
+	}
+	for _, b := range av.blackouts {
+		taken = append(taken, interval{b.StartsAt, b.EndsAt})
+	}
+	return subtractIntervals(av.workingIntervals(from, to), taken), nil
+}
+
+// workingIntervals expands the template and overrides into instants within [from, to).
+// Intervals that meet, such as whole days back to back, are joined into one.
+func (a availability) workingIntervals(from, to time.Time) []interval {
+	var out []interval
+	first := from.In(a.loc)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, a.loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, w := range a.dayIntervals(day) {
+			if w.start.Before(from) {
+				w.start = from
+			}
+			if w.end.After(to) {
+				w.end = to
+			}
+			switch {
+			case !w.start.Before(w.end):
+			case len(out) > 0 && out[len(out)-1].end.Equal(w.start):
+				out[len(out)-1].end = w.end
+			default:
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// dayIntervals is the working time of one day: its overrides if it has any, else
+// the weekly template. A tutor without a template works all day, for free slots,
+// bookings and warnings alike, and overrides change single days on top of that.
+func (a availability) dayIntervals(day time.Time) []interval {
+	wallClock := func(hhmm string) time.Time {
+		t, _ := time.Parse("15:04", hhmm)
+		return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, a.loc)
+	}
+	var out []interval
+	if overrides, ok := a.overrides[day.Format(time.DateOnly)]; ok {
+		for _, o := range overrides {
+			out = append(out, interval{wallClock(o.Start), wallClock(o.End)})
+		}
+		return out
+	}
+	if len(a.hours) == 0 {
+		return []interval{{day, day.AddDate(0, 0, 1)}}
+	}
+	for _, h := range a.hours {
+		if time.Weekday(h.Weekday) == day.Weekday() {
+			out = append(out, interval{wallClock(h.Start), wallClock(h.End)})
+		}
+	}
+	return out
+}
+
+// warningsFor reports how a slot sits against the working hours and blackouts.
+func (a availability) warningsFor(slot models.TimeSlot) []string {
+	end := slot.ScheduledAt.Add(time.Duration(slot.DurationMinutes) * time.Minute)
+	var warnings []string
+	inside := false
+	for _, w := range a.workingIntervals(slot.ScheduledAt.AddDate(0, 0, -1), end.AddDate(0, 0, 1)) {
+		if !slot.ScheduledAt.Before(w.start) && !end.After(w.end) {
+			inside = true
+			break
+		}
+	}
+	if !inside {
+		warnings = append(warnings, models.WarningOutsideWorkingHours)
+	}
+	for _, b := range a.blackouts {
+		if slot.ScheduledAt.Before(b.EndsAt) && b.StartsAt.Before(end) {
+			warnings = append(warnings, models.WarningDuringBlackout)
+			break
+		}
+	}
+	return warnings
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+// subtractIntervals removes taken from free. free must be sorted and non-overlapping.
+func subtractIntervals(free, taken []interval) []interval {
+	sort.Slice(taken, func(i, j int) bool { return taken[i].start.Before(taken[j].start) })
+	var out []interval
+	for _, f := range free {
+		cur := f.start
+		for _, t := range taken {
+			if !t.end.After(cur) || !t.start.Before(f.end) {
+				continue
+			}
+			if t.start.After(cur) {
+				out = append(out, interval{cur, t.start})
+			}
+			if t.end.After(cur) {
+				cur = t.end
+			}
+		}
+		if cur.Before(f.end) {
+			out = append(out, interval{cur, f.end})
+		}
+	}
+	return out
+}
+
+// parseRange reads a from/to pair of dates or date-times in loc.
+func parseRange(from, to string, loc *time.Location) (time.Time, time.Time, error) {
+	parse := func(v string) (time.Time, error) {
+		if t, err := time.ParseInLocation(time.DateOnly, v, loc); err == nil {
+			return t, nil
+		}
+		return parseLocalTime(v, loc)
+	}
+	start, err := parse(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("from: %w", ErrBadRequest)
+	}
+	end, err := parse(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("to: %w", ErrBadRequest)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must be after from: %w", ErrBadRequest)
+	}
+	return start, end, nil
+}
+
+// slotWarnings checks each slot against the tutor's working hours and blackouts.
+func slotWarnings(ctx context.Context, repo repository.AvailabilityRepository, tutorRepo repository.TutorRepository, tutorID string, slots []models.TimeSlot) ([][]string, error) {
+	if len(slots) == 0 {
+		return nil, nil
+	}
+	tz, err := tutorRepo.GetTimezone(ctx, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	from, to := slots[0].ScheduledAt, slots[0].ScheduledAt
+	for _, s := range slots {
+		end := s.ScheduledAt.Add(time.Duration(s.DurationMinutes) * time.Minute)
+		if s.ScheduledAt.Before(from) {
+			from = s.ScheduledAt
+		}
+		if end.After(to) {
+			to = end
+		}
+	}
+	av, err := loadAvailability(ctx, repo, tutorID, loc, from, to)
+	if err != nil {
+		return nil, err
+	}
+	warnings := make([][]string, len(slots))
+	for i, s := range slots {
+		warnings[i] = av.warningsFor(s)
+	}
+	return warnings, nil
+}
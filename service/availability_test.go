@@ -0,0 +1,225 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAvailabilityRepo struct {
+	mock.Mock
+}
+
+func (m *mockAvailabilityRepo) GetWorkingHours(ctx context.Context, tutorID string) ([]models.WorkingHours, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.WorkingHours), args.Error(1)
+}
+
+func (m *mockAvailabilityRepo) ReplaceWorkingHours(ctx context.Context, tutorID string, hours []models.WorkingHoursInput) ([]models.WorkingHours, error) {
+	args := m.Called(ctx, tutorID, hours)
+	return args.Get(0).([]models.WorkingHours), args.Error(1)
+}
+
+func (m *mockAvailabilityRepo) GetOverrides(ctx context.Context, tutorID string, fromDate string, toDate string) ([]models.AvailabilityOverride, error) {
+	args := m.Called(ctx, tutorID, fromDate, toDate)
+	return args.Get(0).([]models.AvailabilityOverride), args.Error(1)
+}
+
+func (m *mockAvailabilityRepo) CreateOverride(ctx context.Context, tutorID string, req models.CreateOverrideRequest) (models.AvailabilityOverride, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.AvailabilityOverride), args.Error(1)
+}
+
+func (m *mockAvailabilityRepo) DeleteOverride(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockAvailabilityRepo) GetBlackouts(ctx context.Context, tutorID string, from time.Time, to time.Time) ([]models.Blackout, error) {
+	args := m.Called(ctx, tutorID, from, to)
+	return args.Get(0).([]models.Blackout), args.Error(1)
+}
+
+func (m *mockAvailabilityRepo) CreateBlackout(ctx context.Context, tutorID string, req models.CreateBlackoutRequest) (models.Blackout, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.Blackout), args.Error(1)
+}
+
+func (m *mockAvailabilityRepo) DeleteBlackout(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+// availabilityOf serves a fixed setup to every query.
+func availabilityOf(hours []models.WorkingHours, overrides []models.AvailabilityOverride, blackouts []models.Blackout) *mockAvailabilityRepo {
+	m := new(mockAvailabilityRepo)
+	m.On("GetWorkingHours", mock.Anything, mock.Anything).Return(hours, nil).Maybe()
+	m.On("GetOverrides", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(overrides, nil).Maybe()
+	m.On("GetBlackouts", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blackouts, nil).Maybe()
+	return m
+}
+
+func noWorkingHours() *mockAvailabilityRepo {
+	return availabilityOf([]models.WorkingHours{}, []models.AvailabilityOverride{}, []models.Blackout{})
+}
+
+func tutorIn(tz string) *mockTutorRepo {
+	m := new(mockTutorRepo)
+	m.On("GetTimezone", mock.Anything, mock.Anything).Return(tz, nil).Maybe()
+	return m
+}
+
+func utcTutor() *mockTutorRepo {
+	return tutorIn("UTC")
+}
+
+var mondayMorning = []models.WorkingHours{{ID: "wh-1", Weekday: int(time.Monday), Start: "09:00", End: "13:00"}}
+
+// GetFreeSlots
+
+func TestAvailabilityGetFreeSlots_SubtractsBusyAndBlackouts(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	at := func(h, m int) time.Time { return time.Date(2026, time.May, 4, h, m, 0, 0, berlin) }
+
+	scheduleRepo := new(mockScheduleRepo)
+	repo := availabilityOf(mondayMorning, []models.AvailabilityOverride{}, []models.Blackout{
+		{ID: "b-1", StartsAt: at(12, 30), EndsAt: at(14, 0)},
+	})
+	svc := service.NewAvailabilityService(repo, scheduleRepo, tutorIn("Europe/Berlin"))
+
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, at(0, 0), at(24, 0)).
+		Return([]models.TimeSlot{{ScheduledAt: at(10, 0), DurationMinutes: 60}}, nil)
+
+	slots, err := svc.GetFreeSlots(context.Background(), tutorID, "2026-05-04", "2026-05-05")
+
+	assert.NoError(t, err)
+	assert.Len(t, slots, 2)
+	assert.True(t, slots[0].Start.Equal(at(9, 0)) && slots[0].End.Equal(at(10, 0)))
+	assert.True(t, slots[1].Start.Equal(at(11, 0)) && slots[1].End.Equal(at(12, 30)))
+}
+
+func TestAvailabilityGetFreeSlots_OverrideReplacesTemplate(t *testing.T) {
+	scheduleRepo := new(mockScheduleRepo)
+	repo := availabilityOf(mondayMorning, []models.AvailabilityOverride{
+		{ID: "o-1", Date: "2026-05-04", Start: "15:00", End: "16:00"},
+	}, []models.Blackout{})
+	svc := service.NewAvailabilityService(repo, scheduleRepo, utcTutor())
+
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, mock.Anything, mock.Anything).Return([]models.TimeSlot(nil), nil)
+
+	slots, err := svc.GetFreeSlots(context.Background(), tutorID, "2026-05-04", "2026-05-12")
+
+	// The override wins on May 4; the template applies again on Monday May 11.
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FreeSlot{
+		{Start: time.Date(2026, time.May, 4, 15, 0, 0, 0, time.UTC), End: time.Date(2026, time.May, 4, 16, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2026, time.May, 11, 9, 0, 0, 0, time.UTC), End: time.Date(2026, time.May, 11, 13, 0, 0, 0, time.UTC)},
+	}, slots)
+}
+
+func TestAvailabilityGetFreeSlots_NoWorkingHoursIsAllDay(t *testing.T) {
+	scheduleRepo := new(mockScheduleRepo)
+	svc := service.NewAvailabilityService(noWorkingHours(), scheduleRepo, utcTutor())
+	at := func(h int) time.Time { return time.Date(2026, time.May, 4, h, 0, 0, 0, time.UTC) }
+
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, at(0), at(24)).
+		Return([]models.TimeSlot{{ScheduledAt: at(10), DurationMinutes: 60}}, nil)
+
+	slots, err := svc.GetFreeSlots(context.Background(), tutorID, "2026-05-04", "2026-05-05")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FreeSlot{{Start: at(0), End: at(10)}, {Start: at(11), End: at(24)}}, slots)
+}
+
+func TestAvailabilityGetFreeSlots_RangeTooLong(t *testing.T) {
+	svc := service.NewAvailabilityService(noWorkingHours(), new(mockScheduleRepo), utcTutor())
+
+	_, err := svc.GetFreeSlots(context.Background(), tutorID, "2026-01-01", "2026-06-01")
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+// GetBlackouts
+
+func TestAvailabilityGetBlackouts_DatesInTutorTimezone(t *testing.T) {
+	repo := new(mockAvailabilityRepo)
+	svc := service.NewAvailabilityService(repo, new(mockScheduleRepo), tutorIn("Europe/Berlin"))
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	repo.On("GetBlackouts", mock.Anything, tutorID,
+		time.Date(2026, time.May, 1, 0, 0, 0, 0, berlin), time.Date(2026, time.June, 1, 0, 0, 0, 0, berlin)).
+		Return([]models.Blackout{}, nil)
+
+	_, err := svc.GetBlackouts(context.Background(), tutorID, "2026-05-01", "2026-06-01")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// SetWorkingHours
+
+func TestAvailabilitySetWorkingHours_EndBeforeStart(t *testing.T) {
+	repo := new(mockAvailabilityRepo)
+	svc := service.NewAvailabilityService(repo, new(mockScheduleRepo), utcTutor())
+
+	_, err := svc.SetWorkingHours(context.Background(), tutorID, models.SetWorkingHoursRequest{
+		Hours: []models.WorkingHoursInput{{Weekday: 1, Start: "18:00", End: "09:00"}},
+	})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "ReplaceWorkingHours")
+}
+
+// Lesson warnings
+
+func TestLessonCreate_WarnsOutsideWorkingHours(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	// createLessonReq is on Friday May 1, the template only covers Mondays.
+	svc := service.NewLessonService(lessonRepo, courseRepo, freeSchedule(),
+		availabilityOf(mondayMorning, []models.AvailabilityOverride{}, []models.Blackout{}), utcTutor(), defaultPolicy())
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("Create", mock.Anything, createLessonReq).Return(expectedLesson, nil)
+
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.WarningOutsideWorkingHours}, lesson.Warnings)
+}
+
+func TestLessonCreate_InsideWorkingHours(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	fridays := []models.WorkingHours{{ID: "wh-2", Weekday: int(time.Friday), Start: "08:00", End: "12:00"}}
+	svc := service.NewLessonService(lessonRepo, courseRepo, freeSchedule(),
+		availabilityOf(fridays, []models.AvailabilityOverride{}, []models.Blackout{}), utcTutor(), defaultPolicy())
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("Create", mock.Anything, createLessonReq).Return(expectedLesson, nil)
+
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, lesson.Warnings)
+}
+
+func TestLessonCreate_OverrideWithoutTemplateLeavesOtherDaysFree(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	// The override is on Thursday April 30; createLessonReq is the day after.
+	override := []models.AvailabilityOverride{{ID: "o-1", Date: "2026-04-30", Start: "15:00", End: "16:00"}}
+	svc := service.NewLessonService(lessonRepo, courseRepo, freeSchedule(),
+		availabilityOf([]models.WorkingHours{}, override, []models.Blackout{}), utcTutor(), defaultPolicy())
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("Create", mock.Anything, createLessonReq).Return(expectedLesson, nil)
+
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, lesson.Warnings)
+}
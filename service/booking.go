@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+// bookingSlotStep is the granularity of start times offered to visitors.
+const bookingSlotStep = 30 * time.Minute
+
+type BookingService interface {
+	CreateLink(ctx context.Context, tutorID string, req models.CreateBookingLinkRequest) (models.BookingLink, error)
+	GetLinks(ctx context.Context, tutorID string) ([]models.BookingLink, error)
+	DeleteLink(ctx context.Context, id string, tutorID string) error
+	GetRequests(ctx context.Context, tutorID string, status string) ([]models.BookingRequest, error)
+	Confirm(ctx context.Context, lessonID string, tutorID string) error
+	Decline(ctx context.Context, lessonID string, tutorID string) error
+
+	GetPublicLink(ctx context.Context, token string) (models.PublicBookingLink, error)
+	GetPublicSlots(ctx context.Context, token string) ([]models.FreeSlot, error)
+	Book(ctx context.Context, token string, req models.CreateBookingRequest) (models.BookingRequest, error)
+}
+
+type bookingService struct {
+	repo             repository.BookingRepository
+	courseRepo       repository.CourseRepository
+	availabilityRepo repository.AvailabilityRepository
+	scheduleRepo     repository.ScheduleRepository
+}
+
+func NewBookingService(repo repository.BookingRepository, courseRepo repository.CourseRepository, availabilityRepo repository.AvailabilityRepository, scheduleRepo repository.ScheduleRepository) BookingService {
+	return &bookingService{repo: repo, courseRepo: courseRepo, availabilityRepo: availabilityRepo, scheduleRepo: scheduleRepo}
+}
+
+func (s *bookingService) CreateLink(ctx context.Context, tutorID string, req models.CreateBookingLinkRequest) (models.BookingLink, error) {
+	if _, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID); err != nil {
+		return models.BookingLink{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	token, err := newToken()
+	if err != nil {
+		return models.BookingLink{}, err
+	}
+	return s.repo.CreateLink(ctx, tutorID, token, req)
+}
+
+func (s *bookingService) GetLinks(ctx context.Context, tutorID string) ([]models.BookingLink, error) {
+	return s.repo.GetLinks(ctx, tutorID)
+}
+
+func (s *bookingService) DeleteLink(ctx context.Context, id string, tutorID string) error {
+	if err := s.repo.DeleteLink(ctx, id, tutorID); err != nil {
+		return fmt.Errorf("booking link: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *bookingService) GetRequests(ctx context.Context, tutorID string, status string) ([]models.BookingRequest, error) {
+	switch status {
+	case "", "pending", "confirmed", "declined", "expired":
+	default:
+		return nil, fmt.Errorf("status must be one of: pending, confirmed, declined, expired: %w", ErrBadRequest)
+	}
+	return s.repo.GetRequests(ctx, tutorID, status)
+}
+
+func (s *bookingService) Confirm(ctx context.Context, lessonID string, tutorID string) error {
+	if err := s.repo.Confirm(ctx, lessonID, tutorID); err != nil {
+		return fmt.Errorf("pending lesson: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *bookingService) Decline(ctx context.Context, lessonID string, tutorID string) error {
+	if err := s.repo.Decline(ctx, lessonID, tutorID); err != nil {
+		return fmt.Errorf("pending lesson: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *bookingService) GetPublicLink(ctx context.Context, token string) (models.PublicBookingLink, error) {
+	link, err := s.repo.GetPublicLink(ctx, token)
+	if err != nil {
+		return models.PublicBookingLink{}, fmt.Errorf("booking link: %w", ErrNotFound)
+	}
+	return link, nil
+}
+
+// GetPublicSlots lists the start times a visitor can book, within the link's notice
+// period and horizon.
+func (s *bookingService) GetPublicSlots(ctx context.Context, token string) ([]models.FreeSlot, error) {
+	link, err := s.GetPublicLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(link.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	from, to := bookingWindow(link, time.Now())
+	free, err := freeIntervals(ctx, s.availabilityRepo, s.scheduleRepo, link.TutorID, loc, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	length := time.Duration(link.DurationMinutes) * time.Minute
+	slots := []models.FreeSlot{}
+	for _, f := range free {
+		start := f.start.Truncate(bookingSlotStep)
+		if start.Before(f.start) {
+			start = start.Add(bookingSlotStep)
+		}
+		for ; !start.Add(length).After(f.end); start = start.Add(bookingSlotStep) {
+			slots = append(slots, models.FreeSlot{Start: start, End: start.Add(length)})
+		}
+	}
+	return slots, nil
+}
+
+// Book requests a slot: it must still be free working time, and becomes a pending
+// lesson for the tutor to confirm or decline.
+func (s *bookingService) Book(ctx context.Context, token string, req models.CreateBookingRequest) (models.BookingRequest, error) {
+	link, err := s.GetPublicLink(ctx, token)
+	if err != nil {
+		return models.BookingRequest{}, err
+	}
+	loc, err := time.LoadLocation(link.Timezone)
+	if err != nil {
+		return models.BookingRequest{}, err
+	}
+	start := req.ScheduledAt
+	end := start.Add(time.Duration(link.DurationMinutes) * time.Minute)
+	from, to := bookingWindow(link, time.Now())
+	if start.Before(from) || end.After(to) {
+		return models.BookingRequest{}, fmt.Errorf("slot is outside the booking window: %w", ErrBadRequest)
+	}
+
+	free, err := freeIntervals(ctx, s.availabilityRepo, s.scheduleRepo, link.TutorID, loc, start, end)
+	if err != nil {
+		return models.BookingRequest{}, err
+	}
+	if len(free) != 1 || !free[0].start.Equal(start) || !free[0].end.Equal(end) {
+		return models.BookingRequest{}, fmt.Errorf("slot is not available: %w", ErrConflict)
+	}
+
+	booking, err := s.repo.CreatePending(ctx, link, req)
+	if errors.Is(err, repository.ErrSlotTaken) {
+		return models.BookingRequest{}, fmt.Errorf("slot is not available: %w", ErrConflict)
+	}
+	return booking, err
+}
+
+func bookingWindow(link models.PublicBookingLink, now time.Time) (time.Time, time.Time) {
+	return now.Add(time.Duration(link.MinNoticeHours) * time.Hour), now.AddDate(0, 0, link.HorizonDays)
+}
@@ -0,0 +1,238 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBookingRepo struct {
+	mock.Mock
+}
+
+func (m *mockBookingRepo) CreateLink(ctx context.Context, tutorID string, token string, req models.CreateBookingLinkRequest) (models.BookingLink, error) {
+	args := m.Called(ctx, tutorID, token, req)
+	return args.Get(0).(models.BookingLink), args.Error(1)
+}
+
+func (m *mockBookingRepo) GetLinks(ctx context.Context, tutorID string) ([]models.BookingLink, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.BookingLink), args.Error(1)
+}
+
+func (m *mockBookingRepo) DeleteLink(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockBookingRepo) GetPublicLink(ctx context.Context, token string) (models.PublicBookingLink, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(models.PublicBookingLink), args.Error(1)
+}
+
+func (m *mockBookingRepo) CreatePending(ctx context.Context, link models.PublicBookingLink, req models.CreateBookingRequest) (models.BookingRequest, error) {
+	args := m.Called(ctx, link, req)
+	return args.Get(0).(models.BookingRequest), args.Error(1)
+}
+
+func (m *mockBookingRepo) GetRequests(ctx context.Context, tutorID string, status string) ([]models.BookingRequest, error) {
+	args := m.Called(ctx, tutorID, status)
+	return args.Get(0).([]models.BookingRequest), args.Error(1)
+}
+
+func (m *mockBookingRepo) Confirm(ctx context.Context, lessonID string, tutorID string) error {
+	return m.Called(ctx, lessonID, tutorID).Error(0)
+}
+
+func (m *mockBookingRepo) Decline(ctx context.Context, lessonID string, tutorID string) error {
+	return m.Called(ctx, lessonID, tutorID).Error(0)
+}
+
+// fixtures
+
+var (
+	bookingToken = "booking-token"
+
+	publicLink = models.PublicBookingLink{
+		Title:           "Trial lesson",
+		Subject:         "Mathematics",
+		DurationMinutes: 60,
+		Timezone:        "UTC",
+		LinkID:          "link-uuid-1",
+		TutorID:         tutorID,
+		CourseID:        courseID,
+		MinNoticeHours:  0,
+		HorizonDays:     14,
+	}
+
+	everyDay = func() []models.WorkingHours {
+		hours := make([]models.WorkingHours, 7)
+		for d := range hours {
+			hours[d] = models.WorkingHours{Weekday: d, Start: "09:00", End: "12:00"}
+		}
+		return hours
+	}()
+)
+
+// nextDayAt is tomorrow at hh:00 UTC, always inside the booking window.
+func nextDayAt(hh int) time.Time {
+	y, m, d := time.Now().UTC().AddDate(0, 0, 1).Date()
+	return time.Date(y, m, d, hh, 0, 0, 0, time.UTC)
+}
+
+func newBookingSvc(repo *mockBookingRepo, scheduleRepo *mockScheduleRepo) service.BookingService {
+	availability := availabilityOf(everyDay, []models.AvailabilityOverride{}, []models.Blackout{})
+	return service.NewBookingService(repo, new(mockCourseRepo), availability, scheduleRepo)
+}
+
+// Book
+
+func TestBookingBook_CreatesPendingLesson(t *testing.T) {
+	repo := new(mockBookingRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newBookingSvc(repo, scheduleRepo)
+
+	req := models.CreateBookingRequest{ScheduledAt: nextDayAt(10), Name: "Aiya", Contact: "@aiya"}
+	repo.On("GetPublicLink", mock.Anything, bookingToken).Return(publicLink, nil)
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, mock.Anything, mock.Anything).Return([]models.TimeSlot(nil), nil)
+	repo.On("CreatePending", mock.Anything, publicLink, req).Return(models.BookingRequest{ID: "booking-uuid-1", Status: "pending"}, nil)
+
+	booking, err := svc.Book(context.Background(), bookingToken, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", booking.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestBookingBook_SlotBusy(t *testing.T) {
+	repo := new(mockBookingRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newBookingSvc(repo, scheduleRepo)
+
+	req := models.CreateBookingRequest{ScheduledAt: nextDayAt(10), Name: "Aiya", Contact: "@aiya"}
+	repo.On("GetPublicLink", mock.Anything, bookingToken).Return(publicLink, nil)
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, mock.Anything, mock.Anything).
+		Return([]models.TimeSlot{{ScheduledAt: nextDayAt(10).Add(30 * time.Minute), DurationMinutes: 30}}, nil)
+
+	_, err := svc.Book(context.Background(), bookingToken, req)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "CreatePending")
+}
+
+func TestBookingBook_OutsideWorkingHours(t *testing.T) {
+	repo := new(mockBookingRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newBookingSvc(repo, scheduleRepo)
+
+	req := models.CreateBookingRequest{ScheduledAt: nextDayAt(11).Add(30 * time.Minute), Name: "Aiya", Contact: "@aiya"}
+	repo.On("GetPublicLink", mock.Anything, bookingToken).Return(publicLink, nil)
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, mock.Anything, mock.Anything).Return([]models.TimeSlot(nil), nil)
+
+	_, err := svc.Book(context.Background(), bookingToken, req)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "CreatePending")
+}
+
+func TestBookingBook_TakenConcurrently(t *testing.T) {
+	repo := new(mockBookingRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newBookingSvc(repo, scheduleRepo)
+
+	req := models.CreateBookingRequest{ScheduledAt: nextDayAt(9), Name: "Aiya", Contact: "@aiya"}
+	repo.On("GetPublicLink", mock.Anything, bookingToken).Return(publicLink, nil)
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, mock.Anything, mock.Anything).Return([]models.TimeSlot(nil), nil)
+	repo.On("CreatePending", mock.Anything, publicLink, req).Return(models.BookingRequest{}, repository.ErrSlotTaken)
+
+	_, err := svc.Book(context.Background(), bookingToken, req)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestBookingBook_BeyondHorizon(t *testing.T) {
+	repo := new(mockBookingRepo)
+	svc := newBookingSvc(repo, new(mockScheduleRepo))
+
+	req := models.CreateBookingRequest{ScheduledAt: nextDayAt(10).AddDate(0, 1, 0), Name: "Aiya", Contact: "@aiya"}
+	repo.On("GetPublicLink", mock.Anything, bookingToken).Return(publicLink, nil)
+
+	_, err := svc.Book(context.Background(), bookingToken, req)
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestBookingBook_UnknownLink(t *testing.T) {
+	repo := new(mockBookingRepo)
+	svc := newBookingSvc(repo, new(mockScheduleRepo))
+
+	repo.On("GetPublicLink", mock.Anything, "nope").Return(models.PublicBookingLink{}, errors.New("no rows"))
+
+	_, err := svc.Book(context.Background(), "nope", models.CreateBookingRequest{})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// GetPublicSlots
+
+func TestBookingGetPublicSlots_SplitsFreeTime(t *testing.T) {
+	repo := new(mockBookingRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newBookingSvc(repo, scheduleRepo)
+
+	link := publicLink
+	link.HorizonDays = 2
+	repo.On("GetPublicLink", mock.Anything, bookingToken).Return(link, nil)
+	scheduleRepo.On("GetBusy", mock.Anything, tutorID, mock.Anything, mock.Anything).
+		Return([]models.TimeSlot{{ScheduledAt: nextDayAt(10), DurationMinutes: 60}}, nil)
+
+	slots, err := svc.GetPublicSlots(context.Background(), bookingToken)
+
+	assert.NoError(t, err)
+	var tomorrow []time.Time
+	for _, s := range slots {
+		if s.Start.UTC().Day() == nextDayAt(0).Day() {
+			tomorrow = append(tomorrow, s.Start)
+		}
+	}
+	// 09:00-12:00 minus 10:00-11:00 leaves room for 60-minute lessons at 09:00 and 11:00.
+	assert.Equal(t, []time.Time{nextDayAt(9), nextDayAt(11)}, tomorrow)
+}
+
+// Confirm / Decline
+
+func TestBookingConfirm_NotPending(t *testing.T) {
+	repo := new(mockBookingRepo)
+	svc := newBookingSvc(repo, new(mockScheduleRepo))
+
+	repo.On("Confirm", mock.Anything, lessonID, tutorID).Return(errors.New("no rows"))
+
+	err := svc.Confirm(context.Background(), lessonID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// GetRequests
+
+func TestBookingGetRequests_Statuses(t *testing.T) {
+	for _, status := range []string{"", "pending", "confirmed", "declined", "expired"} {
+		repo := new(mockBookingRepo)
+		svc := newBookingSvc(repo, new(mockScheduleRepo))
+		repo.On("GetRequests", mock.Anything, tutorID, status).Return([]models.BookingRequest{}, nil)
+
+		_, err := svc.GetRequests(context.Background(), tutorID, status)
+
+		assert.NoError(t, err, status)
+	}
+
+	repo := new(mockBookingRepo)
+	_, err := newBookingSvc(repo, new(mockScheduleRepo)).GetRequests(context.Background(), tutorID, "stale")
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "GetRequests", mock.Anything, mock.Anything, mock.Anything)
+}
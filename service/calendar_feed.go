@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"tutorgo/ics"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+const (
+	feedProdID = "-//TutorGo//Calendar Feed//EN"
+	// feedUIDDomain makes UIDs globally unique, as RFC 5545 recommends.
+	feedUIDDomain = "@tutorgo"
+	// feedPast is how much history the feed keeps; the future side follows the
+	// longest series horizon.
+	feedPast = 90 * 24 * time.Hour
+)
+
+type CalendarFeedService interface {
+	Get(ctx context.Context, tutorID string) (models.CalendarFeed, error)
+	Rotate(ctx context.Context, tutorID string) (models.CalendarFeed, error)
+	Revoke(ctx context.Context, tutorID string) error
+	Render(ctx context.Context, token string) ([]byte, error)
+}
+
+type calendarFeedService struct {
+	repo       repository.CalendarFeedRepository
+	lessonRepo repository.LessonRepository
+	taskRepo   repository.TaskRepository
+}
+
+func NewCalendarFeedService(repo repository.CalendarFeedRepository, lessonRepo repository.LessonRepository, taskRepo repository.TaskRepository) CalendarFeedService {
+	return &calendarFeedService{repo: repo, lessonRepo: lessonRepo, taskRepo: taskRepo}
+}
+
+func (s *calendarFeedService) Get(ctx context.Context, tutorID string) (models.CalendarFeed, error) {
+	token, err := s.repo.GetToken(ctx, tutorID)
+	if err != nil {
+		return models.CalendarFeed{}, fmt.Errorf("tutor: %w", ErrNotFound)
+	}
+	if token == nil {
+		return models.CalendarFeed{}, nil
+	}
+	return feedFor(*token), nil
+}
+
+// Rotate issues a new feed token, enabling the feed if it was off. Calendars
+// subscribed to the previous URL stop receiving updates.
+func (s *calendarFeedService) Rotate(ctx context.Context, tutorID string) (models.CalendarFeed, error) {
+	token, err := newToken()
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+	if err := s.repo.SetToken(ctx, tutorID, &token); err != nil {
+		return models.CalendarFeed{}, err
+	}
+	return feedFor(token), nil
+}
+
+func (s *calendarFeedService) Revoke(ctx context.Context, tutorID string) error {
+	return s.repo.SetToken(ctx, tutorID, nil)
+}
+
+// Render builds the ICS document for the feed token: lessons and tasks from
+// feedPast ago up to the series horizon.
+func (s *calendarFeedService) Render(ctx context.Context, token string) ([]byte, error) {
+	owner, err := s.repo.GetOwner(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("calendar feed: %w", ErrNotFound)
+	}
+	now := time.Now()
+	from := now.Add(-feedPast).Format(time.RFC3339)
+	to := now.Add(maxSeriesHorizon).Format(time.RFC3339)
+
+	lessons, err := s.lessonRepo.GetCalendar(ctx, owner.TutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.taskRepo.GetByRange(ctx, owner.TutorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := ics.Calendar{
+		ProdID:   feedProdID,
+		Name:     strings.TrimSpace("TutorGo · " + owner.FirstName + " " + owner.LastName),
+		Timezone: owner.Timezone,
+		Events:   make([]ics.Event, 0, len(lessons)+len(tasks)),
+	}
+	loc, err := time.LoadLocation(owner.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	seriesDays := map[string]bool{}
+	for _, l := range lessons {
+		cal.Events = append(cal.Events, ics.Event{
+			UID:         lessonUID(l, loc, seriesDays),
+			Start:       l.ScheduledAt,
+			End:         l.ScheduledAt.Add(time.Duration(l.DurationMinutes) * time.Minute),
+			Summary:     lessonSummary(l),
+			Description: lessonDescription(l, loc),
+			Status:      lessonEventStatus(l.Status),
+		})
+	}
+	for _, t := range tasks {
+		cal.Events = append(cal.Events, ics.Event{
+			UID:     "task-" + t.ID + feedUIDDomain,
+			Start:   t.ScheduledAt,
+			End:     t.ScheduledAt.Add(time.Duration(t.DurationMinutes) * time.Minute),
+			Summary: t.Title,
+			Status:  ics.StatusConfirmed,
+		})
+	}
+	return cal.Encode(now), nil
+}
+
+func feedFor(token string) models.CalendarFeed {
+	return models.CalendarFeed{Enabled: true, Token: token, Path: "/public/calendar/" + token + ".ics"}
+}
+
+// lessonUID keys series lessons by series and local day rather than by lesson
+// id: regenerating a series recreates its lessons, and the calendar event should
+// survive that. The day is the occurrence's own, from before any move, so that a
+// moved lesson keeps its event and doesn't take another's. A second lesson of the
+// same series on the same day falls back to its own id. seen tracks the keys
+// already handed out, in scheduled_at order.
+func lessonUID(l models.CalendarLesson, loc *time.Location, seen map[string]bool) string {
+	if l.SeriesID != nil {
+		day := strings.ReplaceAll(l.LocalDate, "-", "")
+		if l.OriginalScheduledAt != nil {
+			day = l.OriginalScheduledAt.In(loc).Format("20060102")
+		}
+		key := "series-" + *l.SeriesID + "-" + day
+		if !seen[key] {
+			seen[key] = true
+			return key + feedUIDDomain
+		}
+	}
+	return "lesson-" + l.ID + feedUIDDomain
+}
+
+func lessonSummary(l models.CalendarLesson) string {
+	summary := l.Subject
+	if l.StudentName != nil && *l.StudentName != "" {
+		summary += " — " + *l.StudentName
+	}
+	if l.MakeupFor != nil {
+		summary += " (makeup)"
+	}
+	return summary
+}
+
+// lessonDescription prefixes the notes of a moved lesson with the slot it was
+// moved from, in the tutor's zone.
+func lessonDescription(l models.CalendarLesson, loc *time.Location) string {
+	if l.OriginalScheduledAt == nil {
+		return l.Notes
+	}
+	moved := "Moved from " + l.OriginalScheduledAt.In(loc).Format("2006-01-02 15:04")
+	if l.Notes == "" {
+		return moved
+	}
+	return moved + "\n\n" + l.Notes
+}
+
+func lessonEventStatus(status string) string {
+	switch status {
+	case "cancelled":
+		return ics.StatusCancelled
+	case "pending":
+		return ics.StatusTentative
+	default:
+		return ics.StatusConfirmed
+	}
+}
@@ -0,0 +1,197 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCalendarFeedRepo struct {
+	mock.Mock
+}
+
+func (m *mockCalendarFeedRepo) GetToken(ctx context.Context, tutorID string) (*string, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).(*string), args.Error(1)
+}
+
+func (m *mockCalendarFeedRepo) SetToken(ctx context.Context, tutorID string, token *string) error {
+	return m.Called(ctx, tutorID, token).Error(0)
+}
+
+func (m *mockCalendarFeedRepo) GetOwner(ctx context.Context, token string) (models.FeedOwner, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(models.FeedOwner), args.Error(1)
+}
+
+type mockTaskRepo struct {
+	mock.Mock
+}
+
+func (m *mockTaskRepo) Create(ctx context.Context, tutorID string, req models.CreateTaskRequest) (models.Task, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *mockTaskRepo) GetByRange(ctx context.Context, tutorID, from, to string) ([]models.Task, error) {
+	args := m.Called(ctx, tutorID, from, to)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *mockTaskRepo) Update(ctx context.Context, id, tutorID string, req models.UpdateTaskRequest) (models.Task, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *mockTaskRepo) Delete(ctx context.Context, id, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockTaskRepo) ToggleDone(ctx context.Context, id, tutorID string) (models.Task, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+const feedToken = "feed-token"
+
+func renderFeed(t *testing.T, lessons []models.CalendarLesson, tasks []models.Task) string {
+	t.Helper()
+	repo := new(mockCalendarFeedRepo)
+	lessonRepo := new(mockLessonRepo)
+	taskRepo := new(mockTaskRepo)
+	svc := service.NewCalendarFeedService(repo, lessonRepo, taskRepo)
+
+	repo.On("GetOwner", mock.Anything, feedToken).
+		Return(models.FeedOwner{TutorID: tutorID, FirstName: "Anna", LastName: "Petrova", Timezone: "Europe/Moscow"}, nil)
+	lessonRepo.On("GetCalendar", mock.Anything, tutorID, mock.Anything, mock.Anything).Return(lessons, nil)
+	taskRepo.On("GetByRange", mock.Anything, tutorID, mock.Anything, mock.Anything).Return(tasks, nil)
+
+	body, err := svc.Render(context.Background(), feedToken)
+	require.NoError(t, err)
+	return strings.ReplaceAll(string(body), "\r\n ", "")
+}
+
+func TestCalendarFeedRender_LessonsAndTasks(t *testing.T) {
+	student := "Ivan Ivanov"
+	at := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	lessons := []models.CalendarLesson{
+		{ID: "l1", ScheduledAt: at, DurationMinutes: 60, Status: "scheduled", Subject: "Math", StudentName: &student, LocalDate: "2026-03-02"},
+		{ID: "l2", ScheduledAt: at.Add(24 * time.Hour), DurationMinutes: 45, Status: "cancelled", Subject: "Physics", IsGroup: true, LocalDate: "2026-03-03"},
+	}
+	tasks := []models.Task{{ID: "t1", Title: "Check homework", ScheduledAt: at.Add(2 * time.Hour), DurationMinutes: 30}}
+
+	out := renderFeed(t, lessons, tasks)
+
+	assert.Contains(t, out, "X-WR-CALNAME:TutorGo · Anna Petrova\r\n")
+	assert.Contains(t, out, "X-WR-TIMEZONE:Europe/Moscow\r\n")
+	assert.Contains(t, out, "UID:lesson-l1@tutorgo\r\nDTSTAMP:")
+	assert.Contains(t, out, "DTSTART:20260302T140000Z\r\nDTEND:20260302T150000Z\r\nSUMMARY:Math — Ivan Ivanov\r\nSTATUS:CONFIRMED\r\n")
+	assert.Contains(t, out, "SUMMARY:Physics\r\nSTATUS:CANCELLED\r\n")
+	assert.Contains(t, out, "UID:task-t1@tutorgo\r\n")
+	assert.Contains(t, out, "SUMMARY:Check homework\r\n")
+}
+
+func TestCalendarFeedRender_SeriesUIDsFollowLocalDay(t *testing.T) {
+	seriesID := "series-uuid-1"
+	at := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	lessons := []models.CalendarLesson{
+		{ID: "l1", ScheduledAt: at, DurationMinutes: 60, Status: "scheduled", Subject: "Math", SeriesID: &seriesID, LocalDate: "2026-03-02"},
+		{ID: "l2", ScheduledAt: at.Add(3 * time.Hour), DurationMinutes: 60, Status: "scheduled", Subject: "Math", SeriesID: &seriesID, LocalDate: "2026-03-02"},
+		{ID: "l3", ScheduledAt: at.Add(7 * 24 * time.Hour), DurationMinutes: 60, Status: "pending", Subject: "Math", SeriesID: &seriesID, LocalDate: "2026-03-09"},
+	}
+
+	out := renderFeed(t, lessons, []models.Task{})
+
+	assert.Contains(t, out, "UID:series-series-uuid-1-20260302@tutorgo\r\n")
+	assert.Contains(t, out, "UID:lesson-l2@tutorgo\r\n")
+	assert.Contains(t, out, "UID:series-series-uuid-1-20260309@tutorgo\r\n")
+	assert.Contains(t, out, "STATUS:TENTATIVE\r\n")
+}
+
+func TestCalendarFeedRender_MovedOccurrenceKeepsItsUID(t *testing.T) {
+	seriesID := "series-uuid-1"
+	monday := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	// The Monday lesson is moved to the next Monday, where the series has one too.
+	lessons := []models.CalendarLesson{
+		{ID: "l2", ScheduledAt: monday.AddDate(0, 0, 7), DurationMinutes: 60, Status: "scheduled", Subject: "Math", SeriesID: &seriesID, LocalDate: "2026-03-09"},
+		{ID: "l1", ScheduledAt: monday.AddDate(0, 0, 7).Add(2 * time.Hour), DurationMinutes: 60, Status: "scheduled", Subject: "Math", SeriesID: &seriesID, LocalDate: "2026-03-09", OriginalScheduledAt: &monday},
+	}
+
+	out := renderFeed(t, lessons, []models.Task{})
+
+	assert.Contains(t, out, "UID:series-series-uuid-1-20260309@tutorgo\r\nDTSTAMP:")
+	assert.Contains(t, out, "UID:series-series-uuid-1-20260302@tutorgo\r\n")
+	assert.NotContains(t, out, "UID:lesson-")
+}
+
+func TestCalendarFeedRender_UnknownToken(t *testing.T) {
+	repo := new(mockCalendarFeedRepo)
+	svc := service.NewCalendarFeedService(repo, new(mockLessonRepo), new(mockTaskRepo))
+
+	repo.On("GetOwner", mock.Anything, "revoked").Return(models.FeedOwner{}, errors.New("no rows"))
+
+	_, err := svc.Render(context.Background(), "revoked")
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestCalendarFeedGet_Disabled(t *testing.T) {
+	repo := new(mockCalendarFeedRepo)
+	svc := service.NewCalendarFeedService(repo, new(mockLessonRepo), new(mockTaskRepo))
+
+	repo.On("GetToken", mock.Anything, tutorID).Return((*string)(nil), nil)
+
+	feed, err := svc.Get(context.Background(), tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.CalendarFeed{}, feed)
+}
+
+func TestCalendarFeedRotate_IssuesNewToken(t *testing.T) {
+	repo := new(mockCalendarFeedRepo)
+	svc := service.NewCalendarFeedService(repo, new(mockLessonRepo), new(mockTaskRepo))
+
+	repo.On("SetToken", mock.Anything, tutorID, mock.AnythingOfType("*string")).Return(nil).Twice()
+
+	first, err := svc.Rotate(context.Background(), tutorID)
+	require.NoError(t, err)
+	second, err := svc.Rotate(context.Background(), tutorID)
+	require.NoError(t, err)
+
+	assert.True(t, first.Enabled)
+	assert.NotEqual(t, first.Token, second.Token)
+	assert.Equal(t, "/public/calendar/"+second.Token+".ics", second.Path)
+	repo.AssertExpectations(t)
+}
+
+func TestCalendarFeedRender_MovedLessonNamesOriginalSlot(t *testing.T) {
+	original := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	lessons := []models.CalendarLesson{
+		{ID: "l1", ScheduledAt: original.Add(48 * time.Hour), DurationMinutes: 60, Status: "scheduled", Subject: "Math",
+			Notes: "bring the workbook", LocalDate: "2026-03-04", OriginalScheduledAt: &original},
+	}
+
+	out := renderFeed(t, lessons, []models.Task{})
+
+	assert.Contains(t, out, `DESCRIPTION:Moved from 2026-03-02 17:00\n\nbring the workbook`)
+}
+
+func TestCalendarFeedRender_MakeupLessonIsMarked(t *testing.T) {
+	original := "l0"
+	at := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	lessons := []models.CalendarLesson{
+		{ID: "l1", ScheduledAt: at, DurationMinutes: 60, Status: "scheduled", Subject: "Math", LocalDate: "2026-03-02", MakeupFor: &original},
+	}
+
+	out := renderFeed(t, lessons, []models.Task{})
+
+	assert.Contains(t, out, "SUMMARY:Math (makeup)\r\n")
+}
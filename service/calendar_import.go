@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"tutorgo/ics"
+	"tutorgo/models"
+	"tutorgo/recurrence"
+	"tutorgo/repository"
+)
+
+// maxImportNotes matches the notes limit of lesson requests.
+const maxImportNotes = 500
+
+type CalendarImportService interface {
+	Preview(ctx context.Context, tutorID string, file io.Reader) (models.CalendarImport, error)
+	Commit(ctx context.Context, id string, tutorID string, req models.CommitImportRequest, opts models.ScheduleOptions) (models.ImportResult, error)
+}
+
+type calendarImportService struct {
+	repo         repository.CalendarImportRepository
+	courseRepo   repository.CourseRepository
+	studentRepo  repository.StudentRepository
+	tutorRepo    repository.TutorRepository
+	scheduleRepo repository.ScheduleRepository
+}
+
+func NewCalendarImportService(repo repository.CalendarImportRepository, courseRepo repository.CourseRepository, studentRepo repository.StudentRepository, tutorRepo repository.TutorRepository, scheduleRepo repository.ScheduleRepository) CalendarImportService {
+	return &calendarImportService{repo: repo, courseRepo: courseRepo, studentRepo: studentRepo, tutorRepo: tutorRepo, scheduleRepo: scheduleRepo}
+}
+
+// Preview parses an .ics file and stores what importing it would do. Only upcoming
+// lessons are imported: past ones would be auto-completed and billed.
+func (s *calendarImportService) Preview(ctx context.Context, tutorID string, file io.Reader) (models.CalendarImport, error) {
+	tz, err := s.tutorRepo.GetTimezone(ctx, tutorID)
+	if err != nil {
+		return models.CalendarImport{}, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return models.CalendarImport{}, err
+	}
+	parsed, err := ics.Parse(file, loc)
+	if err != nil {
+		return models.CalendarImport{}, fmt.Errorf("%s: %w", err.Error(), ErrBadRequest)
+	}
+
+	events := importEvents(parsed, loc, time.Now())
+	if err := s.markImported(ctx, tutorID, events); err != nil {
+		return models.CalendarImport{}, err
+	}
+	imp, err := s.repo.Create(ctx, tutorID, events)
+	if err != nil {
+		return models.CalendarImport{}, err
+	}
+	imp.Titles, err = s.titles(ctx, tutorID, events)
+	return imp, err
+}
+
+// Commit imports the previewed events whose titles are mapped, creating the new
+// courses the mappings ask for. UIDs imported before are skipped, so committing
+// the same file twice creates nothing the second time.
+func (s *calendarImportService) Commit(ctx context.Context, id string, tutorID string, req models.CommitImportRequest, opts models.ScheduleOptions) (models.ImportResult, error) {
+	imp, err := s.repo.Get(ctx, id, tutorID)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("import: %w", ErrNotFound)
+	}
+	mappings := map[string]models.ImportMapping{}
+	for _, m := range req.Mappings {
+		if _, dup := mappings[m.Title]; dup {
+			return models.ImportResult{}, fmt.Errorf("title %q is mapped twice: %w", m.Title, ErrBadRequest)
+		}
+		if (m.CourseID == nil) == (m.NewCourse == nil) {
+			return models.ImportResult{}, fmt.Errorf("title %q needs exactly one of course_id and new_course: %w", m.Title, ErrBadRequest)
+		}
+		if m.CourseID != nil {
+			if _, err := s.courseRepo.GetByID(ctx, *m.CourseID, tutorID); err != nil {
+				return models.ImportResult{}, fmt.Errorf("course: %w", ErrNotFound)
+			}
+		} else if m.NewCourse.StudentID != nil {
+			if _, err := s.studentRepo.GetByID(ctx, *m.NewCourse.StudentID, tutorID); err != nil {
+				return models.ImportResult{}, fmt.Errorf("student: %w", ErrNotFound)
+			}
+		}
+		mappings[m.Title] = m
+	}
+
+	// Re-evaluate against the current time and import state: the preview may be
+	// hours old, and the same file may have been committed from another preview.
+	events := imp.Events
+	for i, e := range events {
+		if e.Skip == models.ImportSkipAlreadyImported || e.Skip == models.ImportSkipPast {
+			events[i].Skip = ""
+		}
+	}
+	events = reskipImportEvents(events, time.Now())
+	if err := s.markImported(ctx, tutorID, events); err != nil {
+		return models.ImportResult{}, err
+	}
+
+	var plan models.ImportPlan
+	skipped := []models.ImportSkipped{}
+	newCourse := map[string]bool{}
+	var slots []models.TimeSlot
+	for _, e := range events {
+		m, mapped := mappings[e.Title]
+		if e.Skip == "" && !mapped {
+			e.Skip = models.ImportSkipUnmapped
+		}
+		if e.Skip != "" {
+			skipped = append(skipped, models.ImportSkipped{UID: e.UID, Title: e.Title, Reason: e.Skip})
+			continue
+		}
+		item := models.ImportItem{
+			UID:             e.UID,
+			Title:           e.Title,
+			ScheduledAt:     e.ScheduledAt,
+			DurationMinutes: e.DurationMinutes,
+			Notes:           e.Notes,
+		}
+		if m.CourseID != nil {
+			item.CourseID = *m.CourseID
+		} else if !newCourse[e.Title] {
+			newCourse[e.Title] = true
+			plan.NewCourses = append(plan.NewCourses, models.ImportNewCourse{Title: e.Title, Course: *m.NewCourse})
+		}
+		if e.RRule != nil {
+			series, occurrences, err := importSeries(e, time.Now())
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			item.Series, item.Occurrences = &series, occurrences
+			slots = append(slots, slotsAt(occurrences, e.DurationMinutes)...)
+		} else {
+			slots = append(slots, models.TimeSlot{ScheduledAt: e.ScheduledAt, DurationMinutes: e.DurationMinutes})
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{}, opts); !ok {
+		return models.ImportResult{}, err
+	}
+	result, err := s.repo.Commit(ctx, id, tutorID, plan)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	result.Skipped = append(skipped, result.Skipped...)
+	return result, nil
+}
+
+func (s *calendarImportService) markImported(ctx context.Context, tutorID string, events []models.ImportEvent) error {
+	uids := make([]string, len(events))
+	for i, e := range events {
+		uids[i] = e.UID
+	}
+	imported, err := s.repo.ImportedUIDs(ctx, tutorID, uids)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		if events[i].Skip == "" && imported[events[i].UID] {
+			events[i].Skip = models.ImportSkipAlreadyImported
+		}
+	}
+	return nil
+}
+
+// titles lists the titles of importable events with a suggested course each.
+func (s *calendarImportService) titles(ctx context.Context, tutorID string, events []models.ImportEvent) ([]models.ImportTitle, error) {
+	counts := map[string]int{}
+	var names []string
+	for _, e := range events {
+		if e.Skip != "" {
+			continue
+		}
+		if counts[e.Title] == 0 {
+			names = append(names, e.Title)
+		}
+		counts[e.Title]++
+	}
+	sort.Strings(names)
+	matches, err := s.repo.MatchCourses(ctx, tutorID, names)
+	if err != nil {
+		return nil, err
+	}
+	titles := make([]models.ImportTitle, 0, len(names))
+	for _, name := range names {
+		t := models.ImportTitle{Title: name, Events: counts[name]}
+		if id, ok := matches[name]; ok {
+			t.CourseID = &id
+		}
+		titles = append(titles, t)
+	}
+	return titles, nil
+}
+
+// importEvents converts parsed VEVENTs. An edited instance of a recurring event
+// (RECURRENCE-ID) is excluded from its series and imported on its own, unless it
+// was cancelled.
+func importEvents(parsed []ics.ParsedEvent, loc *time.Location, now time.Time) []models.ImportEvent {
+	overridden := map[string][]time.Time{}
+	for _, p := range parsed {
+		if p.RecurrenceID != nil {
+			overridden[p.UID] = append(overridden[p.UID], *p.RecurrenceID)
+		}
+	}
+
+	events := make([]models.ImportEvent, 0, len(parsed))
+	for _, p := range parsed {
+		e := models.ImportEvent{
+			UID:             p.UID,
+			Title:           strings.TrimSpace(p.Summary),
+			Notes:           truncateRunes(p.Description, maxImportNotes),
+			ScheduledAt:     p.Start,
+			DurationMinutes: int(p.End.Sub(p.Start) / time.Minute),
+			Timezone:        p.TZID,
+			Problem:         p.Problem,
+		}
+		if e.Timezone == "" {
+			e.Timezone = loc.String()
+		}
+		if p.RecurrenceID != nil {
+			e.UID += "/" + p.RecurrenceID.UTC().Format("20060102T150405Z")
+		}
+		switch {
+		case p.UID == "":
+			e.Skip, e.Problem = models.ImportSkipInvalid, "event has no UID"
+		case e.Problem != "":
+			e.Skip = models.ImportSkipInvalid
+		case p.AllDay:
+			e.Skip = models.ImportSkipAllDay
+		case p.Status == ics.StatusCancelled:
+			e.Skip = models.ImportSkipCancelled
+		case e.Title == "":
+			e.Skip, e.Problem = models.ImportSkipInvalid, "event has no title"
+		case e.DurationMinutes <= 0:
+			e.Skip, e.Problem = models.ImportSkipInvalid, "event has no duration"
+		}
+		if e.Skip == "" && p.RRule != "" && p.RecurrenceID == nil {
+			rule, err := recurrence.Parse(p.RRule)
+			if err != nil {
+				e.Skip, e.Problem = models.ImportSkipUnsupportedRule, err.Error()
+			} else {
+				ruleStr := rule.String()
+				e.RRule = &ruleStr
+				e.ExDates = append(append([]time.Time{}, p.ExDates...), overridden[p.UID]...)
+			}
+		}
+		events = append(events, e)
+	}
+	return reskipImportEvents(events, now)
+}
+
+// reskipImportEvents marks events without upcoming lessons as past and counts
+// the lessons of the others. Events already skipped are left alone.
+func reskipImportEvents(events []models.ImportEvent, now time.Time) []models.ImportEvent {
+	for i, e := range events {
+		if e.Skip != "" {
+			continue
+		}
+		e.Occurrences = 0
+		if e.RRule != nil {
+			if _, occurrences, err := importSeries(e, now); err == nil {
+				e.Occurrences = len(occurrences)
+			}
+		} else if e.ScheduledAt.After(now) {
+			e.Occurrences = 1
+		}
+		if e.Occurrences == 0 {
+			e.Skip = models.ImportSkipPast
+		}
+		events[i] = e
+	}
+	return events
+}
+
+// importSeries builds the series for a recurring event and its occurrences from
+// now on, over the same horizon as a series created through the API.
+func importSeries(e models.ImportEvent, now time.Time) (models.LessonSeries, []time.Time, error) {
+	rule, err := recurrence.Parse(*e.RRule)
+	if err != nil {
+		return models.LessonSeries{}, nil, err
+	}
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return models.LessonSeries{}, nil, err
+	}
+	series := models.LessonSeries{
+		RRule:           e.RRule,
+		DTStart:         e.ScheduledAt.In(loc),
+		Timezone:        e.Timezone,
+		DurationMinutes: e.DurationMinutes,
+		Notes:           e.Notes,
+		ExDates:         e.ExDates,
+		GeneratedUntil:  horizonFor(rule, e.ScheduledAt),
+	}
+	from := now
+	if series.DTStart.After(from) {
+		from = series.DTStart
+	}
+	occurrences, err := expandSeries(rule, series, from, series.GeneratedUntil)
+	if err != nil {
+		return models.LessonSeries{}, nil, err
+	}
+	return series, occurrences, nil
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
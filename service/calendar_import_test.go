@@ -0,0 +1,281 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCalendarImportRepo struct {
+	mock.Mock
+}
+
+func (m *mockCalendarImportRepo) Create(ctx context.Context, tutorID string, events []models.ImportEvent) (models.CalendarImport, error) {
+	args := m.Called(ctx, tutorID, events)
+	return args.Get(0).(models.CalendarImport), args.Error(1)
+}
+
+func (m *mockCalendarImportRepo) Get(ctx context.Context, id string, tutorID string) (models.CalendarImport, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.CalendarImport), args.Error(1)
+}
+
+func (m *mockCalendarImportRepo) ImportedUIDs(ctx context.Context, tutorID string, uids []string) (map[string]bool, error) {
+	args := m.Called(ctx, tutorID, uids)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *mockCalendarImportRepo) MatchCourses(ctx context.Context, tutorID string, titles []string) (map[string]string, error) {
+	args := m.Called(ctx, tutorID, titles)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *mockCalendarImportRepo) Commit(ctx context.Context, id string, tutorID string, plan models.ImportPlan) (models.ImportResult, error) {
+	args := m.Called(ctx, id, tutorID, plan)
+	return args.Get(0).(models.ImportResult), args.Error(1)
+}
+
+const importID = "import-uuid-1"
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func icsFile(events ...[]string) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0"}
+	for _, e := range events {
+		lines = append(lines, "BEGIN:VEVENT")
+		lines = append(lines, e...)
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	return strings.Join(lines, "\r\n")
+}
+
+func newImportSvc(repo *mockCalendarImportRepo, courseRepo *mockCourseRepo, scheduleRepo *mockScheduleRepo) service.CalendarImportService {
+	tutorRepo := new(mockTutorRepo)
+	tutorRepo.On("GetTimezone", mock.Anything, tutorID).Return("UTC", nil).Maybe()
+	return service.NewCalendarImportService(repo, courseRepo, new(mockStudentRepo), tutorRepo, scheduleRepo)
+}
+
+// previewEvents runs Preview and returns the events it stored, keyed by UID.
+func previewEvents(t *testing.T, repo *mockCalendarImportRepo, file string) (models.CalendarImport, map[string]models.ImportEvent) {
+	t.Helper()
+	svc := newImportSvc(repo, new(mockCourseRepo), freeSchedule())
+	var stored []models.ImportEvent
+	repo.On("Create", mock.Anything, tutorID, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]models.ImportEvent) }).
+		Return(models.CalendarImport{ID: importID}, nil)
+
+	imp, err := svc.Preview(context.Background(), tutorID, strings.NewReader(file))
+	require.NoError(t, err)
+	byUID := map[string]models.ImportEvent{}
+	for _, e := range stored {
+		byUID[e.UID] = e
+	}
+	return imp, byUID
+}
+
+// Preview
+
+func TestCalendarImportPreview_ClassifiesEvents(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	soon := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	longAgo := time.Now().AddDate(0, -2, 0).Truncate(time.Hour)
+	repo.On("ImportedUIDs", mock.Anything, tutorID, mock.Anything).Return(map[string]bool{}, nil)
+	repo.On("MatchCourses", mock.Anything, tutorID, []string{"Math", "Physics"}).Return(map[string]string{"Math": courseID}, nil)
+
+	imp, events := previewEvents(t, repo, icsFile(
+		[]string{"UID:single", "SUMMARY:Math", "DTSTART:" + icsTime(soon), "DURATION:PT1H"},
+		[]string{"UID:weekly", "SUMMARY:Physics", "DTSTART:" + icsTime(longAgo), "DURATION:PT45M", "RRULE:FREQ=WEEKLY"},
+		[]string{"UID:past", "SUMMARY:Math", "DTSTART:" + icsTime(longAgo), "DURATION:PT1H"},
+		[]string{"UID:allday", "SUMMARY:Holiday", "DTSTART;VALUE=DATE:" + soon.Format("20060102")},
+		[]string{"UID:cancelled", "SUMMARY:Math", "DTSTART:" + icsTime(soon), "DURATION:PT1H", "STATUS:CANCELLED"},
+		[]string{"UID:yearly", "SUMMARY:Exam", "DTSTART:" + icsTime(soon), "DURATION:PT1H", "RRULE:FREQ=YEARLY"},
+	))
+
+	assert.Equal(t, "", events["single"].Skip)
+	assert.Equal(t, 1, events["single"].Occurrences)
+	assert.Equal(t, 60, events["single"].DurationMinutes)
+	assert.Equal(t, "", events["weekly"].Skip)
+	assert.Equal(t, "FREQ=WEEKLY", *events["weekly"].RRule)
+	assert.Greater(t, events["weekly"].Occurrences, 10)
+	assert.Equal(t, models.ImportSkipPast, events["past"].Skip)
+	assert.Equal(t, models.ImportSkipAllDay, events["allday"].Skip)
+	assert.Equal(t, models.ImportSkipCancelled, events["cancelled"].Skip)
+	assert.Equal(t, models.ImportSkipUnsupportedRule, events["yearly"].Skip)
+	assert.NotEmpty(t, events["yearly"].Problem)
+
+	require.Len(t, imp.Titles, 2)
+	assert.Equal(t, "Math", imp.Titles[0].Title)
+	assert.Equal(t, courseID, *imp.Titles[0].CourseID)
+	assert.Nil(t, imp.Titles[1].CourseID)
+}
+
+func TestCalendarImportPreview_OverrideLeavesSeriesAndImportsAlone(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	moved := start.Add(7 * 24 * time.Hour)
+	repo.On("ImportedUIDs", mock.Anything, tutorID, mock.Anything).Return(map[string]bool{}, nil)
+	repo.On("MatchCourses", mock.Anything, tutorID, mock.Anything).Return(map[string]string{}, nil)
+
+	_, events := previewEvents(t, repo, icsFile(
+		[]string{"UID:weekly", "SUMMARY:Math", "DTSTART:" + icsTime(start), "DURATION:PT1H", "RRULE:FREQ=WEEKLY;COUNT=4"},
+		[]string{"UID:weekly", "RECURRENCE-ID:" + icsTime(moved), "SUMMARY:Math", "DTSTART:" + icsTime(moved.Add(2*time.Hour)), "DURATION:PT1H"},
+	))
+
+	series := events["weekly"]
+	assert.Equal(t, 3, series.Occurrences)
+	require.Len(t, series.ExDates, 1)
+	assert.True(t, series.ExDates[0].Equal(moved))
+	override := events["weekly/"+icsTime(moved)]
+	assert.Equal(t, "", override.Skip)
+	assert.True(t, override.ScheduledAt.Equal(moved.Add(2*time.Hour)))
+}
+
+func TestCalendarImportPreview_MarksAlreadyImported(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	soon := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	repo.On("ImportedUIDs", mock.Anything, tutorID, []string{"single"}).Return(map[string]bool{"single": true}, nil)
+	repo.On("MatchCourses", mock.Anything, tutorID, []string(nil)).Return(map[string]string{}, nil)
+
+	imp, events := previewEvents(t, repo, icsFile(
+		[]string{"UID:single", "SUMMARY:Math", "DTSTART:" + icsTime(soon), "DURATION:PT1H"},
+	))
+
+	assert.Equal(t, models.ImportSkipAlreadyImported, events["single"].Skip)
+	assert.Empty(t, imp.Titles)
+}
+
+func TestCalendarImportPreview_NotICS(t *testing.T) {
+	svc := newImportSvc(new(mockCalendarImportRepo), new(mockCourseRepo), freeSchedule())
+
+	_, err := svc.Preview(context.Background(), tutorID, strings.NewReader("name,email\n"))
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+// Commit
+
+func storedImport(now time.Time) models.CalendarImport {
+	rule := "FREQ=WEEKLY;COUNT=3"
+	return models.CalendarImport{ID: importID, Events: []models.ImportEvent{
+		{UID: "single", Title: "Math", ScheduledAt: now.Add(48 * time.Hour), DurationMinutes: 60, Timezone: "UTC", Occurrences: 1},
+		{UID: "weekly", Title: "Physics", ScheduledAt: now.Add(24 * time.Hour), DurationMinutes: 45, Timezone: "UTC", RRule: &rule, Occurrences: 3},
+		{UID: "other", Title: "Chess", ScheduledAt: now.Add(72 * time.Hour), DurationMinutes: 30, Timezone: "UTC", Occurrences: 1},
+		{UID: "allday", Title: "Holiday", Skip: models.ImportSkipAllDay},
+	}}
+}
+
+func TestCalendarImportCommit_WritesMappedEvents(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newImportSvc(repo, courseRepo, freeSchedule())
+	now := time.Now().Truncate(time.Hour)
+	existing := courseID
+	newCourse := &models.CreateCourseRequest{Subject: "Physics", PricePerLesson: money.FromUnits(1500), StartedAt: now}
+
+	repo.On("Get", mock.Anything, importID, tutorID).Return(storedImport(now), nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{ID: courseID}, nil)
+	repo.On("ImportedUIDs", mock.Anything, tutorID, mock.Anything).Return(map[string]bool{}, nil)
+	var plan models.ImportPlan
+	repo.On("Commit", mock.Anything, importID, tutorID, mock.Anything).
+		Run(func(args mock.Arguments) { plan = args.Get(3).(models.ImportPlan) }).
+		Return(models.ImportResult{CoursesCreated: 1, LessonsCreated: 4, SeriesCreated: 1, Skipped: []models.ImportSkipped{}}, nil)
+
+	result, err := svc.Commit(context.Background(), importID, tutorID, models.CommitImportRequest{Mappings: []models.ImportMapping{
+		{Title: "Math", CourseID: &existing},
+		{Title: "Physics", NewCourse: newCourse},
+	}}, models.ScheduleOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.LessonsCreated)
+	assert.ElementsMatch(t, []models.ImportSkipped{
+		{UID: "other", Title: "Chess", Reason: models.ImportSkipUnmapped},
+		{UID: "allday", Title: "Holiday", Reason: models.ImportSkipAllDay},
+	}, result.Skipped)
+
+	require.Len(t, plan.NewCourses, 1)
+	assert.Equal(t, "Physics", plan.NewCourses[0].Title)
+	require.Len(t, plan.Items, 2)
+	assert.Equal(t, courseID, plan.Items[0].CourseID)
+	assert.Nil(t, plan.Items[0].Series)
+	assert.Equal(t, "", plan.Items[1].CourseID)
+	require.NotNil(t, plan.Items[1].Series)
+	assert.Len(t, plan.Items[1].Occurrences, 3)
+}
+
+func TestCalendarImportCommit_SkipsAlreadyImported(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newImportSvc(repo, courseRepo, freeSchedule())
+	now := time.Now().Truncate(time.Hour)
+	existing := courseID
+
+	repo.On("Get", mock.Anything, importID, tutorID).Return(storedImport(now), nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{ID: courseID}, nil)
+	repo.On("ImportedUIDs", mock.Anything, tutorID, mock.Anything).Return(map[string]bool{"single": true}, nil)
+	repo.On("Commit", mock.Anything, importID, tutorID, models.ImportPlan{}).
+		Return(models.ImportResult{Skipped: []models.ImportSkipped{}}, nil)
+
+	result, err := svc.Commit(context.Background(), importID, tutorID, models.CommitImportRequest{Mappings: []models.ImportMapping{
+		{Title: "Math", CourseID: &existing},
+	}}, models.ScheduleOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.LessonsCreated)
+	assert.Contains(t, result.Skipped, models.ImportSkipped{UID: "single", Title: "Math", Reason: models.ImportSkipAlreadyImported})
+}
+
+func TestCalendarImportCommit_Conflict(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	courseRepo := new(mockCourseRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newImportSvc(repo, courseRepo, scheduleRepo)
+	now := time.Now().Truncate(time.Hour)
+	existing := courseID
+
+	repo.On("Get", mock.Anything, importID, tutorID).Return(storedImport(now), nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{ID: courseID}, nil)
+	repo.On("ImportedUIDs", mock.Anything, tutorID, mock.Anything).Return(map[string]bool{}, nil)
+	scheduleRepo.On("FindConflicts", mock.Anything, tutorID, mock.Anything, models.ConflictExclusion{}).
+		Return([]models.OccurrenceConflicts{{ScheduledAt: now.Add(48 * time.Hour), DurationMinutes: 60}}, nil)
+
+	_, err := svc.Commit(context.Background(), importID, tutorID, models.CommitImportRequest{Mappings: []models.ImportMapping{
+		{Title: "Math", CourseID: &existing},
+	}}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "Commit")
+}
+
+func TestCalendarImportCommit_MappingNeedsExactlyOneTarget(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	svc := newImportSvc(repo, new(mockCourseRepo), freeSchedule())
+	repo.On("Get", mock.Anything, importID, tutorID).Return(storedImport(time.Now()), nil)
+
+	_, err := svc.Commit(context.Background(), importID, tutorID, models.CommitImportRequest{Mappings: []models.ImportMapping{
+		{Title: "Math"},
+	}}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestCalendarImportCommit_NotFound(t *testing.T) {
+	repo := new(mockCalendarImportRepo)
+	svc := newImportSvc(repo, new(mockCourseRepo), freeSchedule())
+	repo.On("Get", mock.Anything, "missing", tutorID).Return(models.CalendarImport{}, errors.New("no rows"))
+
+	_, err := svc.Commit(context.Background(), "missing", tutorID, models.CommitImportRequest{}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
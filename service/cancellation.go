@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type CancellationPolicyService interface {
+	GetForTutor(ctx context.Context, tutorID string) (models.CancellationPolicy, error)
+	SetForTutor(ctx context.Context, tutorID string, req models.SetCancellationPolicyRequest) (models.CancellationPolicy, error)
+	GetForCourse(ctx context.Context, courseID string, tutorID string) (models.CoursePolicy, error)
+	SetForCourse(ctx context.Context, courseID string, tutorID string, req models.SetCancellationPolicyRequest) (models.CoursePolicy, error)
+	ClearForCourse(ctx context.Context, courseID string, tutorID string) error
+}
+
+type cancellationPolicyService struct {
+	repo       repository.CancellationPolicyRepository
+	courseRepo repository.CourseRepository
+}
+
+func NewCancellationPolicyService(repo repository.CancellationPolicyRepository, courseRepo repository.CourseRepository) CancellationPolicyService {
+	return &cancellationPolicyService{repo: repo, courseRepo: courseRepo}
+}
+
+func (s *cancellationPolicyService) GetForTutor(ctx context.Context, tutorID string) (models.CancellationPolicy, error) {
+	return s.repo.GetForTutor(ctx, tutorID)
+}
+
+func (s *cancellationPolicyService) SetForTutor(ctx context.Context, tutorID string, req models.SetCancellationPolicyRequest) (models.CancellationPolicy, error) {
+	return s.repo.SetForTutor(ctx, tutorID, req)
+}
+
+func (s *cancellationPolicyService) GetForCourse(ctx context.Context, courseID string, tutorID string) (models.CoursePolicy, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID, tutorID); err != nil {
+		return models.CoursePolicy{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	return s.repo.GetForCourse(ctx, courseID)
+}
+
+func (s *cancellationPolicyService) SetForCourse(ctx context.Context, courseID string, tutorID string, req models.SetCancellationPolicyRequest) (models.CoursePolicy, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID, tutorID); err != nil {
+		return models.CoursePolicy{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	if err := s.repo.SetForCourse(ctx, courseID, &req); err != nil {
+		return models.CoursePolicy{}, err
+	}
+	return s.repo.GetForCourse(ctx, courseID)
+}
+
+// ClearForCourse drops the override; the course follows the tutor's policy again.
+func (s *cancellationPolicyService) ClearForCourse(ctx context.Context, courseID string, tutorID string) error {
+	if _, err := s.courseRepo.GetByID(ctx, courseID, tutorID); err != nil {
+		return fmt.Errorf("course: %w", ErrNotFound)
+	}
+	return s.repo.SetForCourse(ctx, courseID, nil)
+}
+
+// chargeFor is what a lesson starting at scheduledAt costs when it is moved to
+// status at now. Cancelling before the cutoff is free, later it costs the late
+// cancel charge; a no-show costs the no-show charge. Other statuses carry no
+// charge (completed lessons are always billed in full).
+func chargeFor(status string, scheduledAt, now time.Time, policy models.CancellationPolicy) *string {
+	var charge string
+	switch status {
+	case "cancelled":
+		charge = models.ChargeFree
+		if now.After(scheduledAt.Add(-time.Duration(policy.CutoffHours) * time.Hour)) {
+			charge = policy.LateCancelCharge
+		}
+	case "missed":
+		charge = policy.NoShowCharge
+	default:
+		return nil
+	}
+	return &charge
+}
@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPolicyRepo struct {
+	mock.Mock
+}
+
+func (m *mockPolicyRepo) GetForTutor(ctx context.Context, tutorID string) (models.CancellationPolicy, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).(models.CancellationPolicy), args.Error(1)
+}
+
+func (m *mockPolicyRepo) SetForTutor(ctx context.Context, tutorID string, req models.SetCancellationPolicyRequest) (models.CancellationPolicy, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.CancellationPolicy), args.Error(1)
+}
+
+func (m *mockPolicyRepo) GetForCourse(ctx context.Context, courseID string) (models.CoursePolicy, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).(models.CoursePolicy), args.Error(1)
+}
+
+func (m *mockPolicyRepo) SetForCourse(ctx context.Context, courseID string, req *models.SetCancellationPolicyRequest) error {
+	return m.Called(ctx, courseID, req).Error(0)
+}
+
+// policyOf serves p as the policy of every course.
+func policyOf(p models.CancellationPolicy) *mockPolicyRepo {
+	m := new(mockPolicyRepo)
+	m.On("GetForCourse", mock.Anything, mock.Anything).Return(models.CoursePolicy{CancellationPolicy: p, Inherited: true}, nil).Maybe()
+	return m
+}
+
+// defaultPolicy matches the column defaults: cancelling is free, a no-show is charged.
+func defaultPolicy() *mockPolicyRepo {
+	return policyOf(models.CancellationPolicy{CutoffHours: 24, LateCancelCharge: models.ChargeFree, NoShowCharge: models.ChargeFull})
+}
+
+var strictPolicy = models.CancellationPolicy{CutoffHours: 24, LateCancelCharge: models.ChargeHalf, NoShowCharge: models.ChargeFull}
+
+// updateStatus moves a lesson starting at `at` to status under policy and
+// returns the charge handed to the repository.
+func updateStatus(t *testing.T, at time.Time, status string, policy models.CancellationPolicy) *string {
+	t.Helper()
+	lessonRepo := new(mockLessonRepo)
+	svc := service.NewLessonService(lessonRepo, new(mockCourseRepo), freeSchedule(), noWorkingHours(), utcTutor(), policyOf(policy))
+
+	current := expectedLesson
+	current.ScheduledAt = at
+	req := models.UpdateLessonRequest{ScheduledAt: at, DurationMinutes: 60, Status: status}
+	var charge *string
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(current, nil)
+	lessonRepo.On("Update", mock.Anything, lessonID, req, mock.Anything).
+		Run(func(args mock.Arguments) { charge = args.Get(3).(models.StatusChange).Charge }).
+		Return(models.Lesson{ID: lessonID, Status: status}, nil)
+
+	_, err := svc.Update(context.Background(), lessonID, req, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	lessonRepo.AssertExpectations(t)
+	return charge
+}
+
+func TestLessonUpdate_LateCancelIsCharged(t *testing.T) {
+	charge := updateStatus(t, time.Now().Add(3*time.Hour), "cancelled", strictPolicy)
+
+	if assert.NotNil(t, charge) {
+		assert.Equal(t, models.ChargeHalf, *charge)
+	}
+}
+
+func TestLessonUpdate_EarlyCancelIsFree(t *testing.T) {
+	charge := updateStatus(t, time.Now().Add(48*time.Hour), "cancelled", strictPolicy)
+
+	if assert.NotNil(t, charge) {
+		assert.Equal(t, models.ChargeFree, *charge)
+	}
+}
+
+func TestLessonUpdate_NoShowUsesPolicy(t *testing.T) {
+	lenient := models.CancellationPolicy{CutoffHours: 12, LateCancelCharge: models.ChargeFree, NoShowCharge: models.ChargeHalf}
+
+	charge := updateStatus(t, time.Now().Add(-2*time.Hour), "missed", lenient)
+
+	if assert.NotNil(t, charge) {
+		assert.Equal(t, models.ChargeHalf, *charge)
+	}
+}
+
+func TestLessonUpdate_CompletedHasNoCharge(t *testing.T) {
+	charge := updateStatus(t, time.Now().Add(-2*time.Hour), "completed", strictPolicy)
+
+	assert.Nil(t, charge)
+}
+
+// Course policy
+
+func TestCancellationPolicySetForCourse_Success(t *testing.T) {
+	repo := new(mockPolicyRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := service.NewCancellationPolicyService(repo, courseRepo)
+	req := models.SetCancellationPolicyRequest{CutoffHours: 12, LateCancelCharge: models.ChargeFull, NoShowCharge: models.ChargeFull}
+	expected := models.CoursePolicy{CancellationPolicy: models.CancellationPolicy{CutoffHours: 12, LateCancelCharge: models.ChargeFull, NoShowCharge: models.ChargeFull}}
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	repo.On("SetForCourse", mock.Anything, courseID, &req).Return(nil)
+	repo.On("GetForCourse", mock.Anything, courseID).Return(expected, nil)
+
+	policy, err := svc.SetForCourse(context.Background(), courseID, tutorID, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, policy)
+	repo.AssertExpectations(t)
+}
+
+func TestCancellationPolicyClearForCourse_NotFound(t *testing.T) {
+	repo := new(mockPolicyRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := service.NewCancellationPolicyService(repo, courseRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{}, errors.New("not found"))
+
+	err := svc.ClearForCourse(context.Background(), courseID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "SetForCourse")
+}
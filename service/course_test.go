@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 	"tutorgo/models"
+	"tutorgo/money"
 	"tutorgo/service"
 
 	"github.com/stretchr/testify/assert"
@@ -47,14 +48,14 @@ var (
 	courseReq = models.CreateCourseRequest{
 		StudentID:      studentUUID,
 		Subject:        "Mathematics",
-		PricePerLesson: 5000,
+		PricePerLesson: money.FromUnits(5000),
 		StartedAt:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 		EndedAt:        endedAt,
 	}
 
 	updateCourseReq = models.UpdateCourseRequest{
 		Subject:        "Physics",
-		PricePerLesson: 6000,
+		PricePerLesson: money.FromUnits(6000),
 		StartedAt:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 		EndedAt:        endedAt,
 	}
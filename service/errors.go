@@ -1,10 +1,29 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"tutorgo/models"
+)
 
 var (
-	ErrNotFound   = errors.New("not found")
-	ErrForbidden  = errors.New("forbidden")
-	ErrConflict   = errors.New("conflict")
-	ErrBadRequest = errors.New("bad request")
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
 )
+
+// ConflictError reports the requested slots that overlap the tutor's existing
+// lessons or tasks. It matches ErrConflict.
+type ConflictError struct {
+	Occurrences []models.OccurrenceConflicts
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("schedule conflict: %d of the requested slots overlap", len(e.Occurrences))
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
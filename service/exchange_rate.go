@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type ExchangeRateService interface {
+	GetAll(ctx context.Context, tutorID string) ([]models.ExchangeRate, error)
+	Set(ctx context.Context, tutorID string, currency string, req models.SetExchangeRateRequest) (models.ExchangeRate, error)
+	Delete(ctx context.Context, tutorID string, currency string) error
+}
+
+type exchangeRateService struct {
+	repo      repository.ExchangeRateRepository
+	tutorRepo repository.TutorRepository
+}
+
+func NewExchangeRateService(repo repository.ExchangeRateRepository, tutorRepo repository.TutorRepository) ExchangeRateService {
+	return &exchangeRateService{repo: repo, tutorRepo: tutorRepo}
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// normalizeCurrency upper-cases a currency code taken from the URL.
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(currency)
+	if !currencyCode.MatchString(currency) {
+		return "", fmt.Errorf("currency must be an ISO 4217 code: %w", ErrBadRequest)
+	}
+	return currency, nil
+}
+
+func (s *exchangeRateService) GetAll(ctx context.Context, tutorID string) ([]models.ExchangeRate, error) {
+	return s.repo.GetAll(ctx, tutorID)
+}
+
+// Set stores how many units of the home currency one unit of currency buys. The
+// home currency itself always converts at 1.
+func (s *exchangeRateService) Set(ctx context.Context, tutorID string, currency string, req models.SetExchangeRateRequest) (models.ExchangeRate, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	if req.Rate.Sign() <= 0 {
+		return models.ExchangeRate{}, fmt.Errorf("rate must be positive: %w", ErrBadRequest)
+	}
+	tutor, err := s.tutorRepo.GetByID(ctx, tutorID)
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("tutor: %w", ErrNotFound)
+	}
+	if currency == tutor.Currency {
+		return models.ExchangeRate{}, fmt.Errorf("%s is the home currency: %w", currency, ErrBadRequest)
+	}
+	return s.repo.Set(ctx, tutorID, currency, req.Rate)
+}
+
+func (s *exchangeRateService) Delete(ctx context.Context, tutorID string, currency string) error {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tutorID, currency); err != nil {
+		return fmt.Errorf("exchange rate: %w", ErrNotFound)
+	}
+	return nil
+}
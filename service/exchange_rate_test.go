@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockExchangeRateRepo struct {
+	mock.Mock
+}
+
+func (m *mockExchangeRateRepo) GetAll(ctx context.Context, tutorID string) ([]models.ExchangeRate, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.ExchangeRate), args.Error(1)
+}
+
+func (m *mockExchangeRateRepo) Set(ctx context.Context, tutorID string, currency string, rate money.Rate) (models.ExchangeRate, error) {
+	args := m.Called(ctx, tutorID, currency, rate)
+	return args.Get(0).(models.ExchangeRate), args.Error(1)
+}
+
+func (m *mockExchangeRateRepo) Delete(ctx context.Context, tutorID string, currency string) error {
+	return m.Called(ctx, tutorID, currency).Error(0)
+}
+
+func homeIn(currency string) *mockTutorRepo {
+	m := new(mockTutorRepo)
+	m.On("GetByID", mock.Anything, tutorID).Return(models.Tutor{ID: tutorID, Currency: currency}, nil)
+	return m
+}
+
+func TestExchangeRateSet_Success(t *testing.T) {
+	repo := new(mockExchangeRateRepo)
+	svc := service.NewExchangeRateService(repo, homeIn("KZT"))
+
+	rate := mustRate(t, "520.25")
+	want := models.ExchangeRate{Currency: "EUR", Rate: rate}
+	repo.On("Set", mock.Anything, tutorID, "EUR", rate).Return(want, nil)
+
+	got, err := svc.Set(context.Background(), tutorID, "eur", models.SetExchangeRateRequest{Rate: rate})
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	repo.AssertExpectations(t)
+}
+
+func TestExchangeRateSet_HomeCurrency(t *testing.T) {
+	repo := new(mockExchangeRateRepo)
+	svc := service.NewExchangeRateService(repo, homeIn("KZT"))
+
+	_, err := svc.Set(context.Background(), tutorID, "KZT", models.SetExchangeRateRequest{Rate: mustRate(t, "1")})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExchangeRateSet_Invalid(t *testing.T) {
+	repo := new(mockExchangeRateRepo)
+	svc := service.NewExchangeRateService(repo, homeIn("KZT"))
+
+	_, err := svc.Set(context.Background(), tutorID, "EU", models.SetExchangeRateRequest{Rate: mustRate(t, "1")})
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+
+	_, err = svc.Set(context.Background(), tutorID, "EUR", models.SetExchangeRateRequest{})
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+
+	repo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExchangeRateDelete_NotFound(t *testing.T) {
+	repo := new(mockExchangeRateRepo)
+	svc := service.NewExchangeRateService(repo, homeIn("KZT"))
+
+	repo.On("Delete", mock.Anything, tutorID, "USD").Return(errors.New("no rows"))
+
+	err := svc.Delete(context.Background(), tutorID, "usd")
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/sheet"
+)
+
+// ExportService writes the tutor's students, courses, lessons and payments to w
+// as a spreadsheet, row by row as they are read. Filters are checked before
+// anything is written, and nothing is written until the first row is read, so an
+// error before that leaves w untouched.
+type ExportService interface {
+	Students(ctx context.Context, tutorID string, search string, opts models.ExportOptions, w io.Writer) error
+	Courses(ctx context.Context, tutorID string, search string, opts models.ExportOptions, w io.Writer) error
+	Lessons(ctx context.Context, tutorID string, filter models.LessonExportFilter, opts models.ExportOptions, w io.Writer) error
+	Payments(ctx context.Context, tutorID string, opts models.ExportOptions, w io.Writer) error
+}
+
+type exportService struct {
+	repo       repository.ExportRepository
+	courseRepo repository.CourseRepository
+	tutorRepo  repository.TutorRepository
+}
+
+func NewExportService(repo repository.ExportRepository, courseRepo repository.CourseRepository, tutorRepo repository.TutorRepository) ExportService {
+	return &exportService{repo: repo, courseRepo: courseRepo, tutorRepo: tutorRepo}
+}
+
+// exportLabels are the sheet names, column headers and values of exports.
+type exportLabels struct {
+	students, courses, lessons, payments string
+	studentColumns, courseColumns        []any
+	lessonColumns, paymentColumns        []any
+	group, yes, no                       string
+	statuses                             map[string]string
+}
+
+var exportLabelsRU = exportLabels{
+	students: "Ученики", courses: "Курсы", lessons: "Занятия", payments: "Оплаты",
+	studentColumns: []any{"Имя", "Фамилия", "Телефон", "Email", "Активен", "Часовой пояс", "Заметки"},
+	courseColumns:  []any{"Предмет", "Ученик", "Цена занятия", "Валюта", "Начало", "Окончание"},
+	lessonColumns:  []any{"Дата и время", "Длительность, мин", "Предмет", "Ученик", "Статус", "Заметки"},
+	paymentColumns: []any{"Дата", "Предмет", "Ученик", "Сумма", "Валюта", "Занятий", "Возвращено", "Занятий возвращено", "Аннулирован"},
+	group:          "Группа", yes: "да", no: "нет",
+	statuses: map[string]string{
+		"pending": "ожидает подтверждения", "scheduled": "запланировано", "completed": "проведено",
+		"cancelled": "отменено", "missed": "пропущено",
+	},
+}
+
+var exportLabelsEN = exportLabels{
+	students: "Students", courses: "Courses", lessons: "Lessons", payments: "Payments",
+	studentColumns: []any{"First name", "Last name", "Phone", "Email", "Active", "Timezone", "Notes"},
+	courseColumns:  []any{"Subject", "Student", "Price per lesson", "Currency", "Started", "Ended"},
+	lessonColumns:  []any{"Date and time", "Duration, min", "Subject", "Student", "Status", "Notes"},
+	paymentColumns: []any{"Paid at", "Subject", "Student", "Amount", "Currency", "Lessons", "Refunded", "Lessons refunded", "Voided"},
+	group:          "Group", yes: "yes", no: "no",
+	statuses: map[string]string{
+		"pending": "pending", "scheduled": "scheduled", "completed": "completed",
+		"cancelled": "cancelled", "missed": "missed",
+	},
+}
+
+func (l exportLabels) yesNo(v bool) string {
+	if v {
+		return l.yes
+	}
+	return l.no
+}
+
+// student names a course's student, or says it is a group course.
+func (l exportLabels) student(name *string) string {
+	if name == nil {
+		return l.group
+	}
+	return *name
+}
+
+func (l exportLabels) status(status string) string {
+	if s, ok := l.statuses[status]; ok {
+		return s
+	}
+	return status
+}
+
+// exportTable writes its header row along with the first row, or on close if
+// there are none.
+type exportTable struct {
+	w       sheet.Writer
+	header  []any
+	started bool
+}
+
+// exportLabelsFor gives the labels in lang, English by default.
+func exportLabelsFor(lang string) exportLabels {
+	if lang == "ru" {
+		return exportLabelsRU
+	}
+	return exportLabelsEN
+}
+
+// newExportTable opens a sheet in format on w, CSV by default.
+func newExportTable(format string, w io.Writer, name string, header []any) (*exportTable, error) {
+	var sw sheet.Writer
+	switch format {
+	case "", models.ExportCSV:
+		sw = sheet.NewCSV(w)
+	case models.ExportXLSX:
+		sw = sheet.NewXLSX(w, name)
+	default:
+		return nil, fmt.Errorf("format must be csv or xlsx: %w", ErrBadRequest)
+	}
+	return &exportTable{w: sw, header: header}, nil
+}
+
+func (t *exportTable) row(cells ...any) error {
+	if !t.started {
+		t.started = true
+		if err := t.w.WriteRow(t.header...); err != nil {
+			return err
+		}
+	}
+	return t.w.WriteRow(cells...)
+}
+
+func (t *exportTable) close() error {
+	if !t.started {
+		t.started = true
+		if err := t.w.WriteRow(t.header...); err != nil {
+			return err
+		}
+	}
+	return t.w.Close()
+}
+
+func optional(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func (s *exportService) location(ctx context.Context, tutorID string) (*time.Location, error) {
+	tz, err := s.tutorRepo.GetTimezone(ctx, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// Students exports the students the list finds by search.
+func (s *exportService) Students(ctx context.Context, tutorID string, search string, opts models.ExportOptions, w io.Writer) error {
+	labels := exportLabelsFor(opts.Lang)
+	t, err := newExportTable(opts.Format, w, labels.students, labels.studentColumns)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Students(ctx, tutorID, search, func(st models.Student) error {
+		return t.row(st.FirstName, st.LastName, st.Phone, st.Email, labels.yesNo(st.Active), optional(st.Timezone), st.Notes)
+	}); err != nil {
+		return err
+	}
+	return t.close()
+}
+
+// Courses exports the courses the list finds by search.
+func (s *exportService) Courses(ctx context.Context, tutorID string, search string, opts models.ExportOptions, w io.Writer) error {
+	labels := exportLabelsFor(opts.Lang)
+	t, err := newExportTable(opts.Format, w, labels.courses, labels.courseColumns)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Courses(ctx, tutorID, search, func(c models.CourseRow) error {
+		var ended any
+		if c.EndedAt != nil {
+			ended = sheet.Date(*c.EndedAt)
+		}
+		return t.row(c.Subject, labels.student(c.StudentName), sheet.Decimal(c.PricePerLesson.String()), c.Currency,
+			sheet.Date(c.StartedAt), ended)
+	}); err != nil {
+		return err
+	}
+	return t.close()
+}
+
+// Lessons exports the lessons from the date filter.From to filter.To, both
+// included, with their times in the tutor's timezone.
+func (s *exportService) Lessons(ctx context.Context, tutorID string, filter models.LessonExportFilter, opts models.ExportOptions, w io.Writer) error {
+	start, err := time.Parse(time.DateOnly, filter.From)
+	if err != nil {
+		return fmt.Errorf("from must be a date: %w", ErrBadRequest)
+	}
+	end, err := time.Parse(time.DateOnly, filter.To)
+	if err != nil {
+		return fmt.Errorf("to must be a date: %w", ErrBadRequest)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("to is before from: %w", ErrBadRequest)
+	}
+	if _, ok := exportLabelsEN.statuses[filter.Status]; filter.Status != "" && !ok {
+		return fmt.Errorf("status must be pending, scheduled, completed, cancelled or missed: %w", ErrBadRequest)
+	}
+	if filter.CourseID != nil {
+		if _, err := s.courseRepo.GetByID(ctx, *filter.CourseID, tutorID); err != nil {
+			return fmt.Errorf("course: %w", ErrNotFound)
+		}
+	}
+	labels := exportLabelsFor(opts.Lang)
+	t, err := newExportTable(opts.Format, w, labels.lessons, labels.lessonColumns)
+	if err != nil {
+		return err
+	}
+	loc, err := s.location(ctx, tutorID)
+	if err != nil {
+		return err
+	}
+
+	filter.To = end.AddDate(0, 0, 1).Format(time.DateOnly)
+	if err := s.repo.Lessons(ctx, tutorID, filter, func(l models.CalendarLesson) error {
+		return t.row(l.ScheduledAt.In(loc), l.DurationMinutes, l.Subject, labels.student(l.StudentName),
+			labels.status(l.Status), l.Notes)
+	}); err != nil {
+		return err
+	}
+	return t.close()
+}
+
+// Payments exports all the tutor's payments, newest first, with what has been
+// refunded and whether they were voided.
+func (s *exportService) Payments(ctx context.Context, tutorID string, opts models.ExportOptions, w io.Writer) error {
+	labels := exportLabelsFor(opts.Lang)
+	t, err := newExportTable(opts.Format, w, labels.payments, labels.paymentColumns)
+	if err != nil {
+		return err
+	}
+	loc, err := s.location(ctx, tutorID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Payments(ctx, tutorID, func(p models.PaymentRow) error {
+		return t.row(p.PaidAt.In(loc), p.Subject, optional(p.StudentName), sheet.Decimal(p.Amount.String()), p.Currency,
+			p.LessonsCount, sheet.Decimal(p.Refunded.String()), p.LessonsRefunded, labels.yesNo(p.Voided))
+	}); err != nil {
+		return err
+	}
+	return t.close()
+}
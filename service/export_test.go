@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockExportRepo hands each the rows it is given, then returns its error.
+type mockExportRepo struct {
+	mock.Mock
+}
+
+func feed[T any](args mock.Arguments, each func(T) error) error {
+	for _, row := range args.Get(0).([]T) {
+		if err := each(row); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockExportRepo) Students(ctx context.Context, tutorID string, search string, each func(models.Student) error) error {
+	return feed(m.Called(ctx, tutorID, search), each)
+}
+
+func (m *mockExportRepo) Courses(ctx context.Context, tutorID string, search string, each func(models.CourseRow) error) error {
+	return feed(m.Called(ctx, tutorID, search), each)
+}
+
+func (m *mockExportRepo) Lessons(ctx context.Context, tutorID string, filter models.LessonExportFilter, each func(models.CalendarLesson) error) error {
+	return feed(m.Called(ctx, tutorID, filter), each)
+}
+
+func (m *mockExportRepo) Payments(ctx context.Context, tutorID string, each func(models.PaymentRow) error) error {
+	return feed(m.Called(ctx, tutorID), each)
+}
+
+func csvLines(buf *bytes.Buffer) []string {
+	return strings.Split(strings.TrimSuffix(strings.TrimPrefix(buf.String(), "\ufeff"), "\n"), "\n")
+}
+
+func TestExportStudents_CSVInRussian(t *testing.T) {
+	repo := new(mockExportRepo)
+	svc := service.NewExportService(repo, new(mockCourseRepo), utcTutor())
+
+	repo.On("Students", mock.Anything, tutorID, "iv").Return([]models.Student{
+		{FirstName: "Ivan", LastName: "Petrov", Email: "ivan@example.com", Active: true, Notes: "likes, commas"},
+	}, nil)
+
+	var buf bytes.Buffer
+	err := svc.Students(context.Background(), tutorID, "iv", models.ExportOptions{Lang: "ru"}, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Имя,Фамилия,Телефон,Email,Активен,Часовой пояс,Заметки",
+		`Ivan,Petrov,,ivan@example.com,да,,"likes, commas"`,
+	}, csvLines(&buf))
+}
+
+func TestExportLessons_DatesIncludedInTutorTimezone(t *testing.T) {
+	repo := new(mockExportRepo)
+	svc := service.NewExportService(repo, new(mockCourseRepo), tutorIn("Europe/Moscow"))
+
+	repo.On("Lessons", mock.Anything, tutorID, models.LessonExportFilter{From: "2026-03-01", To: "2026-04-01", Status: "completed"}).
+		Return([]models.CalendarLesson{
+			{ScheduledAt: time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC), DurationMinutes: 60, Subject: "Math", Status: "completed"},
+		}, nil)
+
+	var buf bytes.Buffer
+	filter := models.LessonExportFilter{From: "2026-03-01", To: "2026-03-31", Status: "completed"}
+	err := svc.Lessons(context.Background(), tutorID, filter, models.ExportOptions{}, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Date and time,\"Duration, min\",Subject,Student,Status,Notes",
+		"2026-03-02 17:00,60,Math,Group,completed,",
+	}, csvLines(&buf))
+	repo.AssertExpectations(t)
+}
+
+func TestExportLessons_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.LessonExportFilter
+		opts   models.ExportOptions
+	}{
+		{"from not a date", models.LessonExportFilter{From: "2026-02-30", To: "2026-03-31"}, models.ExportOptions{}},
+		{"to before from", models.LessonExportFilter{From: "2026-03-31", To: "2026-03-01"}, models.ExportOptions{}},
+		{"unknown status", models.LessonExportFilter{From: "2026-03-01", To: "2026-03-31", Status: "done"}, models.ExportOptions{}},
+		{"unknown format", models.LessonExportFilter{From: "2026-03-01", To: "2026-03-31"}, models.ExportOptions{Format: "ods"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockExportRepo)
+			svc := service.NewExportService(repo, new(mockCourseRepo), utcTutor())
+
+			var buf bytes.Buffer
+			err := svc.Lessons(context.Background(), tutorID, tt.filter, tt.opts, &buf)
+
+			assert.ErrorIs(t, err, service.ErrBadRequest)
+			assert.Zero(t, buf.Len())
+			repo.AssertNotCalled(t, "Lessons", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestExportLessons_OtherTutorsCourse(t *testing.T) {
+	repo := new(mockExportRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := service.NewExportService(repo, courseRepo, utcTutor())
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{}, errors.New("no rows"))
+
+	filter := models.LessonExportFilter{From: "2026-03-01", To: "2026-03-31", CourseID: &courseID}
+	err := svc.Lessons(context.Background(), tutorID, filter, models.ExportOptions{}, new(bytes.Buffer))
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "Lessons", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportPayments_NoRowsStillHasHeader(t *testing.T) {
+	repo := new(mockExportRepo)
+	svc := service.NewExportService(repo, new(mockCourseRepo), utcTutor())
+
+	repo.On("Payments", mock.Anything, tutorID).Return([]models.PaymentRow{}, nil)
+
+	var buf bytes.Buffer
+	err := svc.Payments(context.Background(), tutorID, models.ExportOptions{}, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Paid at,Subject,Student,Amount,Currency,Lessons,Refunded,Lessons refunded,Voided"}, csvLines(&buf))
+}
+
+func TestExportPayments_QueryFailsBeforeWriting(t *testing.T) {
+	repo := new(mockExportRepo)
+	svc := service.NewExportService(repo, new(mockCourseRepo), utcTutor())
+
+	repo.On("Payments", mock.Anything, tutorID).Return([]models.PaymentRow{}, errors.New("db down"))
+
+	var buf bytes.Buffer
+	err := svc.Payments(context.Background(), tutorID, models.ExportOptions{Format: models.ExportXLSX}, &buf)
+
+	assert.Error(t, err)
+	assert.Zero(t, buf.Len())
+}
+
+func TestExportPayments_Amounts(t *testing.T) {
+	repo := new(mockExportRepo)
+	svc := service.NewExportService(repo, new(mockCourseRepo), utcTutor())
+
+	name := "Ivan Petrov"
+	repo.On("Payments", mock.Anything, tutorID).Return([]models.PaymentRow{{
+		Payment: models.Payment{
+			Subject: "Math", Amount: money.Amount(1234550), Currency: "RUB", LessonsCount: 8,
+			PaidAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC), Refunded: money.Amount(15000), LessonsRefunded: 1,
+		},
+		StudentName: &name,
+	}}, nil)
+
+	var buf bytes.Buffer
+	err := svc.Payments(context.Background(), tutorID, models.ExportOptions{}, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-01 09:30,Math,Ivan Petrov,12345.50,RUB,8,150.00,1,no", csvLines(&buf)[1])
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/pdf"
+	"tutorgo/repository"
+)
+
+// invoiceTerm is how long after issue an invoice falls due by default.
+const invoiceTerm = 14 * 24 * time.Hour
+
+type InvoiceService interface {
+	Create(ctx context.Context, req models.CreateInvoiceRequest, tutorID string) (models.Invoice, error)
+	GetByID(ctx context.Context, id string, tutorID string) (models.Invoice, error)
+	GetAll(ctx context.Context, tutorID string, f models.InvoiceFilter, p models.Pagination) ([]models.Invoice, int, error)
+	Delete(ctx context.Context, id string, tutorID string) error
+	Send(ctx context.Context, id string, tutorID string) (models.Invoice, error)
+	Pay(ctx context.Context, id string, tutorID string, req models.PayInvoiceRequest) (models.Invoice, error)
+	RenderPDF(ctx context.Context, id string, tutorID string) ([]byte, string, error)
+}
+
+type invoiceService struct {
+	repo           repository.InvoiceRepository
+	tutorRepo      repository.TutorRepository
+	courseRepo     repository.CourseRepository
+	studentRepo    repository.StudentRepository
+	enrollmentRepo repository.EnrollmentRepository
+	paymentRepo    repository.PaymentRepository
+	font           pdf.Font
+}
+
+// NewInvoiceService renders PDFs in font; nil means Helvetica, which prints
+// Cyrillic transliterated.
+func NewInvoiceService(repo repository.InvoiceRepository, tutorRepo repository.TutorRepository, courseRepo repository.CourseRepository, studentRepo repository.StudentRepository, enrollmentRepo repository.EnrollmentRepository, paymentRepo repository.PaymentRepository, font pdf.Font) InvoiceService {
+	return &invoiceService{
+		repo: repo, tutorRepo: tutorRepo, courseRepo: courseRepo, studentRepo: studentRepo,
+		enrollmentRepo: enrollmentRepo, paymentRepo: paymentRepo, font: font,
+	}
+}
+
+// invoiceError maps the repository's races on an invoice.
+func invoiceError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrInvoiceChanged):
+		return fmt.Errorf("invoice was changed meanwhile: %w", ErrConflict)
+	case errors.Is(err, repository.ErrAlreadyInvoiced):
+		return fmt.Errorf("already billed or settled by another invoice: %w", ErrConflict)
+	}
+	return err
+}
+
+// Create drafts an invoice for the course's charged lessons in a period or for a
+// payment package.
+func (s *invoiceService) Create(ctx context.Context, req models.CreateInvoiceRequest, tutorID string) (models.Invoice, error) {
+	tutor, err := s.tutorRepo.GetByID(ctx, tutorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	invoice := models.Invoice{BillTo: req.BillTo, Notes: req.Notes, IssueDate: req.IssueDate, DueDate: req.DueDate}
+	switch req.Source {
+	case models.InvoiceFromLessons:
+		err = s.billLessons(ctx, &invoice, req, tutorID)
+	case models.InvoiceFromPayment:
+		err = s.billPayment(ctx, &invoice, req.PaymentID, tutorID)
+	default:
+		err = fmt.Errorf("source: %w", ErrBadRequest)
+	}
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	if invoice.IssueDate == "" {
+		loc, err := time.LoadLocation(tutor.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		invoice.IssueDate = time.Now().In(loc).Format(time.DateOnly)
+	}
+	if invoice.DueDate == "" {
+		issued, _ := time.Parse(time.DateOnly, invoice.IssueDate)
+		invoice.DueDate = issued.Add(invoiceTerm).Format(time.DateOnly)
+	}
+	if invoice.DueDate < invoice.IssueDate {
+		return models.Invoice{}, fmt.Errorf("due_date is before issue_date: %w", ErrBadRequest)
+	}
+	if invoice.BillTo == "" && invoice.StudentID != nil {
+		if student, err := s.studentRepo.GetByID(ctx, *invoice.StudentID, tutorID); err == nil {
+			invoice.BillTo = strings.TrimSpace(student.FirstName + " " + student.LastName)
+		}
+	}
+	for _, it := range invoice.Items {
+		invoice.Total += it.Amount
+	}
+
+	created, err := s.repo.Create(ctx, tutorID, invoice)
+	return created, invoiceError(err)
+}
+
+// billLessons fills the invoice from the course's unbilled charged lessons. A
+// group course bills one member, by their attendance.
+func (s *invoiceService) billLessons(ctx context.Context, invoice *models.Invoice, req models.CreateInvoiceRequest, tutorID string) error {
+	course, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID)
+	if err != nil {
+		return fmt.Errorf("course: %w", ErrNotFound)
+	}
+	studentID, err := courseStudent(ctx, s.enrollmentRepo, course, req.StudentID, true)
+	if err != nil {
+		return err
+	}
+	if req.To < req.From {
+		return fmt.Errorf("to is before from: %w", ErrBadRequest)
+	}
+
+	items, err := s.repo.GetBillableLessons(ctx, course.ID, studentID, req.From, req.To)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no unbilled charged lessons in the period: %w", ErrBadRequest)
+	}
+	invoice.CourseID = &course.ID
+	invoice.StudentID = &studentID
+	invoice.Currency = course.Currency
+	invoice.Items = items
+	return nil
+}
+
+// billPayment fills the invoice with one line for what is left of the payment
+// after refunds.
+func (s *invoiceService) billPayment(ctx context.Context, invoice *models.Invoice, paymentID string, tutorID string) error {
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID, tutorID)
+	if err != nil {
+		return fmt.Errorf("payment: %w", ErrNotFound)
+	}
+	if payment.Voided {
+		return fmt.Errorf("payment is voided: %w", ErrConflict)
+	}
+	amount := payment.Amount - payment.Refunded
+	if amount <= 0 {
+		return fmt.Errorf("payment is refunded in full: %w", ErrConflict)
+	}
+
+	studentID := payment.StudentID
+	if studentID == nil && payment.CourseID != nil {
+		if course, err := s.courseRepo.GetByID(ctx, *payment.CourseID, tutorID); err == nil {
+			studentID = course.StudentID
+		}
+	}
+	// Priced per lesson when the package divides evenly, as one sum otherwise.
+	quantity, unitPrice := 1, amount
+	if lessons := payment.LessonsCount - payment.LessonsRefunded; lessons > 0 && amount%money.Amount(lessons) == 0 {
+		quantity, unitPrice = lessons, amount/money.Amount(lessons)
+	}
+	invoice.CourseID = payment.CourseID
+	invoice.StudentID = studentID
+	invoice.Currency = payment.Currency
+	invoice.Items = []models.InvoiceItem{{
+		Description: payment.Subject + ", " + payment.PaidAt.Format("02.01.2006"),
+		Quantity:    float64(quantity),
+		UnitPrice:   unitPrice,
+		Amount:      amount,
+		PaymentID:   &payment.ID,
+	}}
+	return nil
+}
+
+func (s *invoiceService) GetByID(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	invoice, err := s.repo.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("invoice: %w", ErrNotFound)
+	}
+	return invoice, nil
+}
+
+func (s *invoiceService) GetAll(ctx context.Context, tutorID string, f models.InvoiceFilter, p models.Pagination) ([]models.Invoice, int, error) {
+	switch f.Status {
+	case "", models.InvoiceDraft, models.InvoiceSent, models.InvoicePaid, models.InvoiceOverdue:
+	default:
+		return nil, 0, fmt.Errorf("status must be one of draft, sent, paid, overdue: %w", ErrBadRequest)
+	}
+	return s.repo.GetAll(ctx, tutorID, f, p)
+}
+
+// Delete discards a draft. Sent invoices have a number and stay on record.
+func (s *invoiceService) Delete(ctx context.Context, id string, tutorID string) error {
+	invoice, err := s.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return err
+	}
+	if invoice.Status != models.InvoiceDraft {
+		return fmt.Errorf("only drafts can be deleted: %w", ErrConflict)
+	}
+	return invoiceError(s.repo.Delete(ctx, id, tutorID))
+}
+
+// Send gives a draft the tutor's next invoice number.
+func (s *invoiceService) Send(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	invoice, err := s.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if invoice.Status != models.InvoiceDraft {
+		return models.Invoice{}, fmt.Errorf("invoice is already sent: %w", ErrConflict)
+	}
+	sent, err := s.repo.Send(ctx, id, tutorID)
+	return sent, invoiceError(err)
+}
+
+// Pay marks a sent or overdue invoice paid and links the payment that settles it:
+// the one named, the one the invoice bills, or a new payment for the total on the
+// invoice's course.
+func (s *invoiceService) Pay(ctx context.Context, id string, tutorID string, req models.PayInvoiceRequest) (models.Invoice, error) {
+	invoice, err := s.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	switch invoice.Status {
+	case models.InvoiceDraft:
+		return models.Invoice{}, fmt.Errorf("invoice must be sent first: %w", ErrConflict)
+	case models.InvoicePaid:
+		return models.Invoice{}, fmt.Errorf("invoice is already paid: %w", ErrConflict)
+	}
+	paidAt := time.Now()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	paymentID := req.PaymentID
+	if paymentID == nil {
+		for _, it := range invoice.Items {
+			if it.PaymentID != nil {
+				paymentID = it.PaymentID
+			}
+		}
+	}
+	if paymentID != nil {
+		payment, err := s.paymentRepo.GetByID(ctx, *paymentID, tutorID)
+		if err != nil {
+			return models.Invoice{}, fmt.Errorf("payment: %w", ErrNotFound)
+		}
+		if payment.Voided {
+			return models.Invoice{}, fmt.Errorf("payment is voided: %w", ErrConflict)
+		}
+		if payment.Currency != invoice.Currency {
+			return models.Invoice{}, fmt.Errorf("payment is in %s, the invoice in %s: %w", payment.Currency, invoice.Currency, ErrBadRequest)
+		}
+		if req.PaidAt == nil {
+			paidAt = payment.PaidAt
+		}
+		paid, err := s.repo.Pay(ctx, id, tutorID, payment.ID, paidAt)
+		return paid, invoiceError(err)
+	}
+
+	if invoice.CourseID == nil {
+		return models.Invoice{}, fmt.Errorf("the invoice's course was deleted, payment_id is required: %w", ErrBadRequest)
+	}
+	course, err := s.courseRepo.GetByID(ctx, *invoice.CourseID, tutorID)
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	payment := models.CreatePaymentRequest{
+		CourseID:     course.ID,
+		Amount:       invoice.Total,
+		Currency:     invoice.Currency,
+		LessonsCount: invoicedLessons(invoice),
+		PaidAt:       paidAt,
+	}
+	if course.StudentID == nil {
+		payment.StudentID = invoice.StudentID
+	}
+	paid, err := s.repo.PayWithNewPayment(ctx, id, tutorID, payment)
+	return paid, invoiceError(err)
+}
+
+// invoicedLessons is how many lessons a payment for the invoice buys: the billed
+// shares of its lessons, rounded, and at least one.
+func invoicedLessons(invoice models.Invoice) int {
+	var n float64
+	for _, it := range invoice.Items {
+		if it.LessonID != nil {
+			n += it.Quantity
+		}
+	}
+	return max(int(math.Round(n)), 1)
+}
+
+// RenderPDF returns the invoice as a PDF and a file name for it.
+func (s *invoiceService) RenderPDF(ctx context.Context, id string, tutorID string) ([]byte, string, error) {
+	invoice, err := s.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return nil, "", err
+	}
+	tutor, err := s.tutorRepo.GetByID(ctx, tutorID)
+	if err != nil {
+		return nil, "", err
+	}
+	name := "invoice-draft.pdf"
+	if invoice.Number != nil {
+		name = fmt.Sprintf("invoice-%d.pdf", *invoice.Number)
+	}
+	return renderInvoice(s.font, tutor, invoice), name, nil
+}
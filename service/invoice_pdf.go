@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"tutorgo/models"
+	"tutorgo/pdf"
+)
+
+// invoiceLabels are the fixed texts of an invoice PDF.
+type invoiceLabels struct {
+	title, draft, issued, due, status, from, billTo string
+	taxID, bank, account, bankCode                  string
+	item, description, quantity, unitPrice, amount  string
+	total, notes                                    string
+	statuses                                        map[string]string
+}
+
+var invoiceLabelsRU = invoiceLabels{
+	title: "Счёт № %d", draft: "Черновик счёта", issued: "Дата", due: "Оплатить до", status: "Статус",
+	from: "Исполнитель", billTo: "Плательщик",
+	taxID: "ИИН/БИН", bank: "Банк", account: "Счёт", bankCode: "БИК",
+	item: "№", description: "Наименование", quantity: "Кол-во", unitPrice: "Цена", amount: "Сумма",
+	total: "Итого", notes: "Примечание",
+	statuses: map[string]string{
+		models.InvoiceDraft: "черновик", models.InvoiceSent: "выставлен",
+		models.InvoicePaid: "оплачен", models.InvoiceOverdue: "просрочен",
+	},
+}
+
+var invoiceLabelsEN = invoiceLabels{
+	title: "Invoice No. %d", draft: "Draft invoice", issued: "Date", due: "Due", status: "Status",
+	from: "From", billTo: "Bill to",
+	taxID: "Tax ID", bank: "Bank", account: "Account", bankCode: "Bank code",
+	item: "#", description: "Description", quantity: "Qty", unitPrice: "Price", amount: "Amount",
+	total: "Total", notes: "Notes",
+	statuses: map[string]string{
+		models.InvoiceDraft: "draft", models.InvoiceSent: "sent",
+		models.InvoicePaid: "paid", models.InvoiceOverdue: "overdue",
+	},
+}
+
+// Layout of an invoice page, in points.
+const (
+	invoiceMargin = 50.0
+	invoiceRight  = pdf.PageWidth - invoiceMargin
+	invoiceBottom = 70.0
+	invoiceLine   = 16.0
+	invoiceText   = 10.0
+)
+
+// Right edges of the numeric columns of the items table.
+const (
+	colQuantity  = 360.0
+	colUnitPrice = 450.0
+)
+
+// renderInvoice lays the invoice out on A4 pages. The labels are Russian when the
+// font has Cyrillic, English otherwise.
+func renderInvoice(font pdf.Font, tutor models.Tutor, invoice models.Invoice) []byte {
+	doc := pdf.New(font)
+	labels := invoiceLabelsEN
+	if doc.Font().Has('С') {
+		labels = invoiceLabelsRU
+	}
+	r := &invoiceRenderer{doc: doc, labels: labels}
+	r.newPage()
+
+	title := labels.draft
+	if invoice.Number != nil {
+		title = fmt.Sprintf(labels.title, *invoice.Number)
+	}
+	r.page.Text(invoiceMargin, r.y, 18, title)
+	r.y -= 2 * invoiceLine
+	top := r.y
+	r.rightLine(labels.issued + ": " + invoice.IssueDate)
+	r.rightLine(labels.due + ": " + invoice.DueDate)
+	r.rightLine(labels.status + ": " + labels.statuses[invoice.Status])
+
+	r.y = top
+	q := tutor.Requisites
+	seller := q.LegalName
+	if seller == "" {
+		seller = strings.TrimSpace(tutor.FirstName + " " + tutor.LastName)
+	}
+	r.heading(labels.from)
+	r.line(seller)
+	r.field(labels.taxID, q.TaxID)
+	r.line(q.Address)
+	r.line(strings.TrimSpace(tutor.Email + "  " + tutor.Phone))
+	r.field(labels.bank, q.BankName)
+	r.field(labels.account, q.BankAccount)
+	r.field(labels.bankCode, q.BankCode)
+	r.y -= invoiceLine / 2
+	r.heading(labels.billTo)
+	r.line(invoice.BillTo)
+	r.y -= invoiceLine
+
+	r.tableHeader()
+	for i, it := range invoice.Items {
+		if r.y < invoiceBottom {
+			r.newPage()
+			r.tableHeader()
+		}
+		r.page.Text(invoiceMargin, r.y, invoiceText, strconv.Itoa(i+1))
+		r.page.Text(invoiceMargin+25, r.y, invoiceText, r.fit(it.Description, colQuantity-60-invoiceMargin-25))
+		r.page.TextRight(colQuantity, r.y, invoiceText, strconv.FormatFloat(it.Quantity, 'f', -1, 64))
+		r.page.TextRight(colUnitPrice, r.y, invoiceText, it.UnitPrice.String())
+		r.page.TextRight(invoiceRight, r.y, invoiceText, it.Amount.String())
+		r.y -= invoiceLine
+	}
+	r.page.Line(invoiceMargin, r.y+invoiceLine-4, invoiceRight, r.y+invoiceLine-4, 0.5)
+	r.y -= 4
+	r.page.TextRight(invoiceRight, r.y, 12, labels.total+": "+invoice.Total.String()+" "+invoice.Currency)
+	r.y -= 2 * invoiceLine
+
+	if invoice.Notes != "" {
+		r.heading(labels.notes)
+		for _, l := range strings.Split(invoice.Notes, "\n") {
+			r.line(r.fit(l, invoiceRight-invoiceMargin))
+		}
+	}
+	return doc.Bytes()
+}
+
+type invoiceRenderer struct {
+	doc    *pdf.Document
+	page   *pdf.Page
+	labels invoiceLabels
+	y      float64
+}
+
+func (r *invoiceRenderer) newPage() {
+	r.page = r.doc.AddPage()
+	r.y = pdf.PageHeight - invoiceMargin - 18
+}
+
+// line writes s on the next line, starting a page when this one is full; empty
+// lines are skipped.
+func (r *invoiceRenderer) line(s string) {
+	if s == "" {
+		return
+	}
+	if r.y < invoiceBottom {
+		r.newPage()
+	}
+	r.page.Text(invoiceMargin, r.y, invoiceText, s)
+	r.y -= invoiceLine
+}
+
+func (r *invoiceRenderer) field(label, value string) {
+	if value != "" {
+		r.line(label + ": " + value)
+	}
+}
+
+func (r *invoiceRenderer) heading(s string) {
+	r.page.Gray(0.4)
+	r.line(s)
+	r.page.Gray(0)
+}
+
+func (r *invoiceRenderer) rightLine(s string) {
+	r.page.TextRight(invoiceRight, r.y, invoiceText, s)
+	r.y -= invoiceLine
+}
+
+func (r *invoiceRenderer) tableHeader() {
+	l := r.labels
+	r.page.Gray(0.4)
+	r.page.Text(invoiceMargin, r.y, invoiceText, l.item)
+	r.page.Text(invoiceMargin+25, r.y, invoiceText, l.description)
+	r.page.TextRight(colQuantity, r.y, invoiceText, l.quantity)
+	r.page.TextRight(colUnitPrice, r.y, invoiceText, l.unitPrice)
+	r.page.TextRight(invoiceRight, r.y, invoiceText, l.amount)
+	r.page.Gray(0)
+	r.page.Line(invoiceMargin, r.y-5, invoiceRight, r.y-5, 0.5)
+	r.y -= invoiceLine + 4
+}
+
+// fit shortens s with "..." to at most width points.
+func (r *invoiceRenderer) fit(s string, width float64) string {
+	if r.doc.TextWidth(s, invoiceText) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && r.doc.TextWidth(string(runes)+"...", invoiceText) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
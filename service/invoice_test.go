@@ -0,0 +1,344 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockInvoiceRepo struct {
+	mock.Mock
+}
+
+func (m *mockInvoiceRepo) Create(ctx context.Context, tutorID string, invoice models.Invoice) (models.Invoice, error) {
+	args := m.Called(ctx, tutorID, invoice)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceRepo) GetByID(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceRepo) GetAll(ctx context.Context, tutorID string, f models.InvoiceFilter, p models.Pagination) ([]models.Invoice, int, error) {
+	args := m.Called(ctx, tutorID, f, p)
+	return args.Get(0).([]models.Invoice), args.Int(1), args.Error(2)
+}
+
+func (m *mockInvoiceRepo) Delete(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockInvoiceRepo) Send(ctx context.Context, id string, tutorID string) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceRepo) Pay(ctx context.Context, id string, tutorID string, paymentID string, paidAt time.Time) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID, paymentID, paidAt)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceRepo) PayWithNewPayment(ctx context.Context, id string, tutorID string, req models.CreatePaymentRequest) (models.Invoice, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Invoice), args.Error(1)
+}
+
+func (m *mockInvoiceRepo) GetBillableLessons(ctx context.Context, courseID string, studentID string, from string, to string) ([]models.InvoiceItem, error) {
+	args := m.Called(ctx, courseID, studentID, from, to)
+	return args.Get(0).([]models.InvoiceItem), args.Error(1)
+}
+
+type invoiceMocks struct {
+	repo        *mockInvoiceRepo
+	courses     *mockCourseRepo
+	students    *mockStudentRepo
+	enrollments *mockEnrollmentRepo
+	payments    *mockPaymentRepo
+}
+
+func newInvoiceSvc() (service.InvoiceService, invoiceMocks) {
+	m := invoiceMocks{new(mockInvoiceRepo), new(mockCourseRepo), new(mockStudentRepo), new(mockEnrollmentRepo), new(mockPaymentRepo)}
+	tutors := new(mockTutorRepo)
+	tutors.On("GetByID", mock.Anything, tutorID).Return(models.Tutor{
+		ID: tutorID, FirstName: "Anna", LastName: "Ivanova", Email: "anna@example.com", Timezone: "Asia/Almaty", Currency: "KZT",
+		Requisites: models.Requisites{TaxID: "900101300123", BankAccount: "KZ12345"},
+	}, nil)
+	return service.NewInvoiceService(m.repo, tutors, m.courses, m.students, m.enrollments, m.payments, nil), m
+}
+
+var (
+	invoiceStudentID = "student-uuid-1"
+	invoiceCourse    = models.Course{ID: courseID, TutorID: tutorID, StudentID: &invoiceStudentID, Subject: "Math", Currency: "KZT", PricePerLesson: money.FromUnits(5000)}
+	lessonIDs        = []string{"lesson-uuid-1", "lesson-uuid-2"}
+	billableLessons  = []models.InvoiceItem{
+		{Description: "Math, 02.03.2026", Quantity: 1, UnitPrice: money.FromUnits(5000), Amount: money.FromUnits(5000), LessonID: &lessonIDs[0], StudentID: &invoiceStudentID},
+		{Description: "Math, 04.03.2026", Quantity: 0.5, UnitPrice: money.FromUnits(5000), Amount: money.FromUnits(2500), LessonID: &lessonIDs[1], StudentID: &invoiceStudentID},
+	}
+	lessonsInvoiceReq = models.CreateInvoiceRequest{Source: models.InvoiceFromLessons, CourseID: courseID, From: "2026-03-01", To: "2026-03-31"}
+)
+
+func invoiceIn(status string) models.Invoice {
+	inv := models.Invoice{ID: "invoice-uuid-1", Status: status, CourseID: &courseID, StudentID: &invoiceStudentID,
+		Currency: "KZT", IssueDate: "2026-04-01", DueDate: "2026-04-15", Total: money.FromUnits(7500), Items: billableLessons}
+	if status != models.InvoiceDraft {
+		n := 7
+		inv.Number = &n
+	}
+	return inv
+}
+
+// Create
+
+func TestInvoiceCreate_FromLessons(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(invoiceCourse, nil)
+	m.repo.On("GetBillableLessons", mock.Anything, courseID, invoiceStudentID, "2026-03-01", "2026-03-31").Return(billableLessons, nil)
+	m.students.On("GetByID", mock.Anything, invoiceStudentID, tutorID).Return(models.Student{FirstName: "Ivan", LastName: "Petrov"}, nil)
+	req := lessonsInvoiceReq
+	req.IssueDate = "2026-04-01"
+
+	want := models.Invoice{
+		CourseID: &invoiceCourse.ID, StudentID: &invoiceStudentID, Currency: "KZT", BillTo: "Ivan Petrov",
+		IssueDate: "2026-04-01", DueDate: "2026-04-15", Total: money.FromUnits(7500), Items: billableLessons,
+	}
+	m.repo.On("Create", mock.Anything, tutorID, want).Return(want, nil)
+
+	got, err := svc.Create(context.Background(), req, tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	m.repo.AssertExpectations(t)
+}
+
+func TestInvoiceCreate_GroupNeedsStudent(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	group := invoiceCourse
+	group.StudentID = nil
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(group, nil)
+
+	_, err := svc.Create(context.Background(), lessonsInvoiceReq, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	m.repo.AssertNotCalled(t, "GetBillableLessons", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvoiceCreate_GroupMember(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	group := invoiceCourse
+	group.StudentID = nil
+	member := "student-uuid-2"
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(group, nil)
+	m.enrollments.On("GetByCourse", mock.Anything, courseID).Return([]models.CourseEnrollment{{StudentID: member}}, nil)
+	m.repo.On("GetBillableLessons", mock.Anything, courseID, member, "2026-03-01", "2026-03-31").Return([]models.InvoiceItem{}, nil)
+	req := lessonsInvoiceReq
+	req.StudentID = &member
+
+	_, err := svc.Create(context.Background(), req, tutorID)
+
+	// Nothing to bill: the member's lessons are all billed or free.
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	m.repo.AssertExpectations(t)
+	m.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvoiceCreate_AlreadyInvoiced(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(invoiceCourse, nil)
+	m.repo.On("GetBillableLessons", mock.Anything, courseID, invoiceStudentID, mock.Anything, mock.Anything).Return(billableLessons, nil)
+	m.students.On("GetByID", mock.Anything, invoiceStudentID, tutorID).Return(models.Student{FirstName: "Ivan"}, nil)
+	m.repo.On("Create", mock.Anything, tutorID, mock.Anything).Return(models.Invoice{}, repository.ErrAlreadyInvoiced)
+
+	_, err := svc.Create(context.Background(), lessonsInvoiceReq, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestInvoiceCreate_DueBeforeIssue(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(invoiceCourse, nil)
+	m.repo.On("GetBillableLessons", mock.Anything, courseID, invoiceStudentID, mock.Anything, mock.Anything).Return(billableLessons, nil)
+	req := lessonsInvoiceReq
+	req.IssueDate, req.DueDate = "2026-04-10", "2026-04-01"
+
+	_, err := svc.Create(context.Background(), req, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestInvoiceCreate_FromPayment(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	payment := expectedPayment
+	payment.Subject, payment.Currency = "Math", "KZT"
+	payment.Refunded, payment.LessonsRefunded = money.FromUnits(1000), 2
+	m.payments.On("GetByID", mock.Anything, payment.ID, tutorID).Return(payment, nil)
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(invoiceCourse, nil)
+	m.repo.On("Create", mock.Anything, tutorID, mock.Anything).Return(models.Invoice{}, nil)
+	req := models.CreateInvoiceRequest{Source: models.InvoiceFromPayment, PaymentID: payment.ID, BillTo: "Petrov family", IssueDate: "2026-04-01"}
+
+	_, err := svc.Create(context.Background(), req, tutorID)
+
+	assert.NoError(t, err)
+	created := m.repo.Calls[0].Arguments.Get(2).(models.Invoice)
+	assert.Equal(t, "Petrov family", created.BillTo)
+	assert.Equal(t, &invoiceStudentID, created.StudentID)
+	assert.Equal(t, money.FromUnits(4000), created.Total)
+	// 4000 left for 10 lessons: 400 each.
+	assert.Equal(t, []models.InvoiceItem{{
+		Description: "Math, 11.09.2001", Quantity: 10, UnitPrice: money.FromUnits(400), Amount: money.FromUnits(4000), PaymentID: &payment.ID,
+	}}, created.Items)
+}
+
+func TestInvoiceCreate_FromVoidedPayment(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	payment := expectedPayment
+	payment.Voided = true
+	m.payments.On("GetByID", mock.Anything, payment.ID, tutorID).Return(payment, nil)
+
+	_, err := svc.Create(context.Background(), models.CreateInvoiceRequest{Source: models.InvoiceFromPayment, PaymentID: payment.ID}, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+// GetAll
+
+func TestInvoiceGetAll_BadStatus(t *testing.T) {
+	svc, m := newInvoiceSvc()
+
+	_, _, err := svc.GetAll(context.Background(), tutorID, models.InvoiceFilter{Status: "lost"}, models.Pagination{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	m.repo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Send and Delete
+
+func TestInvoiceSend_Success(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceDraft), nil)
+	m.repo.On("Send", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceSent), nil)
+
+	got, err := svc.Send(context.Background(), "invoice-uuid-1", tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, *got.Number)
+}
+
+func TestInvoiceSend_AlreadySent(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceOverdue), nil)
+
+	_, err := svc.Send(context.Background(), "invoice-uuid-1", tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	m.repo.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvoiceSend_ChangedMeanwhile(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceDraft), nil)
+	m.repo.On("Send", mock.Anything, "invoice-uuid-1", tutorID).Return(models.Invoice{}, repository.ErrInvoiceChanged)
+
+	_, err := svc.Send(context.Background(), "invoice-uuid-1", tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestInvoiceDelete_Sent(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceSent), nil)
+
+	err := svc.Delete(context.Background(), "invoice-uuid-1", tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	m.repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvoiceDelete_NotFound(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-9", tutorID).Return(models.Invoice{}, errors.New("no rows"))
+
+	err := svc.Delete(context.Background(), "invoice-uuid-9", tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// Pay
+
+func TestInvoicePay_Draft(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceDraft), nil)
+
+	_, err := svc.Pay(context.Background(), "invoice-uuid-1", tutorID, models.PayInvoiceRequest{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestInvoicePay_RecordsPayment(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceOverdue), nil)
+	m.courses.On("GetByID", mock.Anything, courseID, tutorID).Return(invoiceCourse, nil)
+	paidAt := time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC)
+	// One and a half lessons billed round to two paid.
+	want := models.CreatePaymentRequest{CourseID: courseID, Amount: money.FromUnits(7500), Currency: "KZT", LessonsCount: 2, PaidAt: paidAt}
+	m.repo.On("PayWithNewPayment", mock.Anything, "invoice-uuid-1", tutorID, want).Return(invoiceIn(models.InvoicePaid), nil)
+
+	_, err := svc.Pay(context.Background(), "invoice-uuid-1", tutorID, models.PayInvoiceRequest{PaidAt: &paidAt})
+
+	assert.NoError(t, err)
+	m.repo.AssertExpectations(t)
+}
+
+func TestInvoicePay_WithPayment(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceSent), nil)
+	payment := expectedPayment
+	payment.Currency = "KZT"
+	m.payments.On("GetByID", mock.Anything, payment.ID, tutorID).Return(payment, nil)
+	m.repo.On("Pay", mock.Anything, "invoice-uuid-1", tutorID, payment.ID, payment.PaidAt).Return(invoiceIn(models.InvoicePaid), nil)
+
+	_, err := svc.Pay(context.Background(), "invoice-uuid-1", tutorID, models.PayInvoiceRequest{PaymentID: &payment.ID})
+
+	assert.NoError(t, err)
+	m.repo.AssertExpectations(t)
+}
+
+func TestInvoicePay_PaymentInOtherCurrency(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceSent), nil)
+	payment := expectedPayment
+	payment.Currency = "EUR"
+	m.payments.On("GetByID", mock.Anything, payment.ID, tutorID).Return(payment, nil)
+
+	_, err := svc.Pay(context.Background(), "invoice-uuid-1", tutorID, models.PayInvoiceRequest{PaymentID: &payment.ID})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	m.repo.AssertNotCalled(t, "Pay", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// RenderPDF
+
+func TestInvoiceRenderPDF(t *testing.T) {
+	svc, m := newInvoiceSvc()
+	m.repo.On("GetByID", mock.Anything, "invoice-uuid-1", tutorID).Return(invoiceIn(models.InvoiceSent), nil)
+
+	body, name, err := svc.RenderPDF(context.Background(), "invoice-uuid-1", tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "invoice-7.pdf", name)
+	out := string(body)
+	assert.True(t, strings.HasPrefix(out, "%PDF-"))
+	// Helvetica has no Cyrillic, so the labels are English.
+	assert.Contains(t, out, "(Invoice No. 7)")
+	assert.Contains(t, out, "(Tax ID: 900101300123)")
+	assert.Contains(t, out, "(Total: 7500.00 KZT)")
+}
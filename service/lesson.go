@@ -2,48 +2,104 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 	"tutorgo/models"
 	"tutorgo/repository"
 )
 
 type LessonService interface {
-	Create(ctx context.Context, req models.CreateLessonRequest, tutorID string) (models.Lesson, error)
-	CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest, tutorID string) ([]models.Lesson, error)
+	Create(ctx context.Context, req models.CreateLessonRequest, tutorID string, opts models.ScheduleOptions) (models.Lesson, error)
+	CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest, tutorID string, opts models.ScheduleOptions) ([]models.Lesson, error)
 	GetByCourse(ctx context.Context, courseID string, tutorID string) ([]models.Lesson, error)
 	GetByID(ctx context.Context, id string, tutorID string) (models.Lesson, error)
-	Update(ctx context.Context, id string, req models.UpdateLessonRequest, tutorID string) (models.Lesson, error)
+	Update(ctx context.Context, id string, req models.UpdateLessonRequest, tutorID string, opts models.ScheduleOptions) (models.Lesson, error)
 	Delete(ctx context.Context, id string, tutorID string) error
 	DeleteByCourse(ctx context.Context, courseID string, tutorID string) error
-	DeleteSeries(ctx context.Context, seriesID string, tutorID string, fromDate *string) error
-	UpdateSeries(ctx context.Context, seriesID string, tutorID string, req models.UpdateSeriesRequest) error
 	GetCalendar(ctx context.Context, tutorID string, from string, to string) ([]models.CalendarLesson, error)
 	ExistsPublic(ctx context.Context, id string) error
+	GetStatusEvents(ctx context.Context, id string, tutorID string) ([]models.LessonStatusEvent, error)
 }
 
 type lessonService struct {
-	repo       repository.LessonRepository
-	courseRepo repository.CourseRepository
+	repo             repository.LessonRepository
+	courseRepo       repository.CourseRepository
+	scheduleRepo     repository.ScheduleRepository
+	availabilityRepo repository.AvailabilityRepository
+	tutorRepo        repository.TutorRepository
+	policyRepo       repository.CancellationPolicyRepository
 }
 
-func NewLessonService(repo repository.LessonRepository, courseRepo repository.CourseRepository) LessonService {
-	return &lessonService{repo: repo, courseRepo: courseRepo}
+func NewLessonService(repo repository.LessonRepository, courseRepo repository.CourseRepository, scheduleRepo repository.ScheduleRepository, availabilityRepo repository.AvailabilityRepository, tutorRepo repository.TutorRepository, policyRepo repository.CancellationPolicyRepository) LessonService {
+	return &lessonService{repo: repo, courseRepo: courseRepo, scheduleRepo: scheduleRepo, availabilityRepo: availabilityRepo, tutorRepo: tutorRepo, policyRepo: policyRepo}
 }
 
-func (s *lessonService) Create(ctx context.Context, req models.CreateLessonRequest, tutorID string) (models.Lesson, error) {
+func (s *lessonService) Create(ctx context.Context, req models.CreateLessonRequest, tutorID string, opts models.ScheduleOptions) (models.Lesson, error) {
 	_, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID)
 	if err != nil {
 		return models.Lesson{}, fmt.Errorf("course: %w", ErrNotFound)
 	}
-	return s.repo.Create(ctx, req)
+	if req.MakeupFor != nil {
+		original, err := s.repo.GetByIDForTutor(ctx, *req.MakeupFor, tutorID)
+		if err != nil {
+			return models.Lesson{}, fmt.Errorf("makeup_for lesson: %w", ErrNotFound)
+		}
+		if err := checkMakeup(original, req.CourseID); err != nil {
+			return models.Lesson{}, err
+		}
+	}
+	slots := slotsAt([]time.Time{req.ScheduledAt}, req.DurationMinutes)
+	warnings, err := slotWarnings(ctx, s.availabilityRepo, s.tutorRepo, tutorID, slots)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{}, opts); !ok {
+		if err != nil {
+			return models.Lesson{}, err
+		}
+		// Dry run: nothing to store, but the warnings are worth showing.
+		return models.Lesson{Warnings: warnings[0]}, nil
+	}
+	lesson, err := s.repo.Create(ctx, req)
+	if errors.Is(err, repository.ErrMakeupExists) {
+		return models.Lesson{}, errMakeupExists
+	}
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	lesson.Warnings = warnings[0]
+	return lesson, nil
 }
 
-func (s *lessonService) CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest, tutorID string) ([]models.Lesson, error) {
+func (s *lessonService) CreateBulk(ctx context.Context, req models.CreateBulkLessonRequest, tutorID string, opts models.ScheduleOptions) ([]models.Lesson, error) {
 	_, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID)
 	if err != nil {
 		return nil, fmt.Errorf("course: %w", ErrNotFound)
 	}
-	return s.repo.CreateBulk(ctx, req)
+	times := make([]time.Time, len(req.ScheduledAts))
+	for i, v := range req.ScheduledAts {
+		if times[i], err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("scheduled_ats: %w", ErrBadRequest)
+		}
+	}
+	slots := slotsAt(times, req.DurationMinutes)
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{}, opts); !ok {
+		return nil, err
+	}
+	warnings, err := slotWarnings(ctx, s.availabilityRepo, s.tutorRepo, tutorID, slots)
+	if err != nil {
+		return nil, err
+	}
+	lessons, err := s.repo.CreateBulk(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// The repository returns lessons in request order.
+	for i := range lessons {
+		lessons[i].Warnings = warnings[i]
+	}
+	return lessons, nil
 }
 
 func (s *lessonService) GetByCourse(ctx context.Context, courseID string, tutorID string) ([]models.Lesson, error) {
@@ -62,12 +118,56 @@ func (s *lessonService) GetByID(ctx context.Context, id string, tutorID string)
 	return lesson, nil
 }
 
-func (s *lessonService) Update(ctx context.Context, id string, req models.UpdateLessonRequest, tutorID string) (models.Lesson, error) {
-	_, err := s.repo.GetByIDForTutor(ctx, id, tutorID)
+func (s *lessonService) Update(ctx context.Context, id string, req models.UpdateLessonRequest, tutorID string, opts models.ScheduleOptions) (models.Lesson, error) {
+	current, err := s.repo.GetByIDForTutor(ctx, id, tutorID)
 	if err != nil {
 		return models.Lesson{}, fmt.Errorf("lesson: %w", ErrNotFound)
 	}
-	return s.repo.Update(ctx, id, req)
+	if req.Status == "" {
+		req.Status = current.Status
+	}
+	if err := checkTransition(current.Status, req.Status); err != nil {
+		return models.Lesson{}, err
+	}
+	if req.MakeupOwed != nil && *req.MakeupOwed && req.Status != "cancelled" && req.Status != "missed" {
+		return models.Lesson{}, fmt.Errorf("only cancelled or missed lessons can owe a makeup: %w", ErrBadRequest)
+	}
+	// Only a lesson that is still going to happen can collide with anything.
+	if req.Status == "scheduled" || req.Status == "pending" {
+		slots := slotsAt([]time.Time{req.ScheduledAt}, req.DurationMinutes)
+		if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{LessonID: id}, opts); !ok {
+			return models.Lesson{}, err
+		}
+	} else if opts.DryRun {
+		return models.Lesson{}, nil
+	}
+
+	change := models.StatusChange{From: current.Status, Actor: models.TutorActor(tutorID), Reason: req.Reason}
+	if req.Status != current.Status {
+		policy, err := s.policyRepo.GetForCourse(ctx, current.CourseID)
+		if err != nil {
+			return models.Lesson{}, err
+		}
+		// The cutoff counts from the slot the lesson had when it was cancelled.
+		change.Charge = chargeFor(req.Status, current.ScheduledAt, time.Now(), policy.CancellationPolicy)
+	}
+	lesson, err := s.repo.Update(ctx, id, req, change)
+	switch {
+	case errors.Is(err, repository.ErrStatusChanged):
+		return models.Lesson{}, fmt.Errorf("lesson status was changed concurrently: %w", ErrConflict)
+	case errors.Is(err, repository.ErrMakeupExists):
+		// Restoring a cancelled makeup whose original has been made up since.
+		return models.Lesson{}, errMakeupExists
+	}
+	return lesson, err
+}
+
+// GetStatusEvents returns the lesson's status timeline, oldest first.
+func (s *lessonService) GetStatusEvents(ctx context.Context, id string, tutorID string) ([]models.LessonStatusEvent, error) {
+	if _, err := s.repo.GetByIDForTutor(ctx, id, tutorID); err != nil {
+		return nil, fmt.Errorf("lesson: %w", ErrNotFound)
+	}
+	return s.repo.GetStatusEvents(ctx, id)
 }
 
 func (s *lessonService) Delete(ctx context.Context, id string, tutorID string) error {
@@ -86,17 +186,6 @@ func (s *lessonService) DeleteByCourse(ctx context.Context, courseID string, tut
 	return s.repo.DeleteByCourse(ctx, courseID, tutorID)
 }
 
-func (s *lessonService) DeleteSeries(ctx context.Context, seriesID string, tutorID string, fromDate *string) error {
-	return s.repo.DeleteSeries(ctx, seriesID, tutorID, fromDate)
-}
-
-func (s *lessonService) UpdateSeries(ctx context.Context, seriesID string, tutorID string, req models.UpdateSeriesRequest) error {
-	if req.NewTime == nil && req.DurationMinutes == nil && req.Notes == nil {
-		return fmt.Errorf("update requires at least one field: %w", ErrBadRequest)
-	}
-	return s.repo.UpdateSeries(ctx, seriesID, tutorID, req)
-}
-
 func (s *lessonService) GetCalendar(ctx context.Context, tutorID string, from string, to string) ([]models.CalendarLesson, error) {
 	return s.repo.GetCalendar(ctx, tutorID, from, to)
 }
@@ -0,0 +1,28 @@
+package service
+
+import "fmt"
+
+// lessonTransitions lists the statuses a tutor may move a lesson to. Pending
+// lessons leave that state only through the booking confirm and decline
+// endpoints; a cancelled lesson can be restored, and a past lesson's outcome
+// can be corrected between completed and missed.
+var lessonTransitions = map[string][]string{
+	"scheduled": {"completed", "cancelled", "missed"},
+	"completed": {"missed", "cancelled"},
+	"missed":    {"completed", "cancelled"},
+	"cancelled": {"scheduled"},
+}
+
+// checkTransition reports whether a lesson may go from one status to another.
+// Keeping the status is always allowed.
+func checkTransition(from, to string) error {
+	if from == to {
+		return nil
+	}
+	for _, allowed := range lessonTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change lesson status from %s to %s: %w", from, to, ErrConflict)
+}
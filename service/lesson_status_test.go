@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func lessonWithStatus(status string) models.Lesson {
+	l := expectedLesson
+	l.Status = status
+	return l
+}
+
+func TestLessonUpdate_ForbiddenTransition(t *testing.T) {
+	cases := []struct{ from, to string }{
+		{"completed", "scheduled"},
+		{"missed", "scheduled"},
+		{"cancelled", "completed"},
+		{"pending", "completed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			lessonRepo := new(mockLessonRepo)
+			svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+			req := models.UpdateLessonRequest{ScheduledAt: scheduledAt, DurationMinutes: 60, Status: tc.to}
+			lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lessonWithStatus(tc.from), nil)
+
+			_, err := svc.Update(context.Background(), lessonID, req, tutorID, models.ScheduleOptions{})
+
+			assert.ErrorIs(t, err, service.ErrConflict)
+			lessonRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestLessonUpdate_SameStatusIsAllowed(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+	req := models.UpdateLessonRequest{ScheduledAt: scheduledAt, DurationMinutes: 60, Notes: "moved notes"}
+	want := req
+	want.Status = "completed"
+	change := models.StatusChange{From: "completed", Actor: models.TutorActor(tutorID)}
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lessonWithStatus("completed"), nil)
+	lessonRepo.On("Update", mock.Anything, lessonID, want, change).Return(lessonWithStatus("completed"), nil)
+
+	_, err := svc.Update(context.Background(), lessonID, req, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	lessonRepo.AssertExpectations(t)
+}
+
+func TestLessonUpdate_RestoreCancelledRecordsReason(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+	req := models.UpdateLessonRequest{ScheduledAt: scheduledAt, DurationMinutes: 60, Status: "scheduled", Reason: "student rebooked"}
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lessonWithStatus("cancelled"), nil)
+	lessonRepo.On("Update", mock.Anything, lessonID, req, mock.MatchedBy(func(c models.StatusChange) bool {
+		return c.From == "cancelled" && c.Reason == "student rebooked" && c.Actor.Type == models.ActorTutor
+	})).Return(lessonWithStatus("scheduled"), nil)
+
+	_, err := svc.Update(context.Background(), lessonID, req, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	lessonRepo.AssertExpectations(t)
+}
+
+func TestLessonUpdate_ConcurrentStatusChange(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
+	lessonRepo.On("Update", mock.Anything, lessonID, updateLessonReq, scheduledToCompleted).Return(models.Lesson{}, repository.ErrStatusChanged)
+
+	_, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestLessonGetStatusEvents(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+	events := []models.LessonStatusEvent{{ID: "event-1", LessonID: lessonID, ToStatus: "completed", ActorType: models.ActorSystem}}
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
+	lessonRepo.On("GetStatusEvents", mock.Anything, lessonID).Return(events, nil)
+
+	got, err := svc.GetStatusEvents(context.Background(), lessonID, tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, events, got)
+}
+
+func TestLessonGetStatusEvents_NotFound(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(models.Lesson{}, errors.New("no rows"))
+
+	_, err := svc.GetStatusEvents(context.Background(), lessonID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	lessonRepo.AssertNotCalled(t, "GetStatusEvents", mock.Anything, mock.Anything)
+}
@@ -38,8 +38,8 @@ func (m *mockLessonRepo) GetByIDForTutor(ctx context.Context, id string, tutorID
 	return args.Get(0).(models.Lesson), args.Error(1)
 }
 
-func (m *mockLessonRepo) Update(ctx context.Context, id string, req models.UpdateLessonRequest) (models.Lesson, error) {
-	args := m.Called(ctx, id, req)
+func (m *mockLessonRepo) Update(ctx context.Context, id string, req models.UpdateLessonRequest, change models.StatusChange) (models.Lesson, error) {
+	args := m.Called(ctx, id, req, change)
 	return args.Get(0).(models.Lesson), args.Error(1)
 }
 
@@ -63,20 +63,38 @@ func (m *mockLessonRepo) AutoComplete(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *mockLessonRepo) GetStatusEvents(ctx context.Context, lessonID string) ([]models.LessonStatusEvent, error) {
+	args := m.Called(ctx, lessonID)
+	return args.Get(0).([]models.LessonStatusEvent), args.Error(1)
+}
+
 func (m *mockLessonRepo) DeleteByCourse(ctx context.Context, courseID string, tutorID string) error {
 	return m.Called(ctx, courseID, tutorID).Error(0)
 }
 
-func (m *mockLessonRepo) DeleteSeries(ctx context.Context, seriesID string, tutorID string, fromDate *string) error {
-	return m.Called(ctx, seriesID, tutorID, fromDate).Error(0)
+func (m *mockLessonRepo) ExistsPublic(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+type mockScheduleRepo struct {
+	mock.Mock
 }
 
-func (m *mockLessonRepo) UpdateSeries(ctx context.Context, seriesID string, tutorID string, req models.UpdateSeriesRequest) error {
-	return m.Called(ctx, seriesID, tutorID, req).Error(0)
+func (m *mockScheduleRepo) FindConflicts(ctx context.Context, tutorID string, slots []models.TimeSlot, exclude models.ConflictExclusion) ([]models.OccurrenceConflicts, error) {
+	args := m.Called(ctx, tutorID, slots, exclude)
+	return args.Get(0).([]models.OccurrenceConflicts), args.Error(1)
 }
 
-func (m *mockLessonRepo) ExistsPublic(ctx context.Context, id string) error {
-	return m.Called(ctx, id).Error(0)
+func (m *mockScheduleRepo) GetBusy(ctx context.Context, tutorID string, from time.Time, to time.Time) ([]models.TimeSlot, error) {
+	args := m.Called(ctx, tutorID, from, to)
+	return args.Get(0).([]models.TimeSlot), args.Error(1)
+}
+
+// freeSchedule reports no conflicts for any slot.
+func freeSchedule() *mockScheduleRepo {
+	m := new(mockScheduleRepo)
+	m.On("FindConflicts", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.OccurrenceConflicts(nil), nil).Maybe()
+	return m
 }
 
 // fixtures
@@ -100,6 +118,8 @@ var (
 		Notes:           "done",
 	}
 
+	scheduledToCompleted = models.StatusChange{From: "scheduled", Actor: models.TutorActor(tutorID)}
+
 	expectedLesson = models.Lesson{
 		ID:              lessonID,
 		CourseID:        courseID,
@@ -111,7 +131,11 @@ var (
 )
 
 func newLessonSvc(lessonRepo *mockLessonRepo, courseRepo *mockCourseRepo) service.LessonService {
-	return service.NewLessonService(lessonRepo, courseRepo)
+	return newLessonSvcWithSchedule(lessonRepo, courseRepo, freeSchedule())
+}
+
+func newLessonSvcWithSchedule(lessonRepo *mockLessonRepo, courseRepo *mockCourseRepo, scheduleRepo *mockScheduleRepo) service.LessonService {
+	return service.NewLessonService(lessonRepo, courseRepo, scheduleRepo, noWorkingHours(), utcTutor(), defaultPolicy())
 }
 
 // Create
@@ -124,7 +148,7 @@ func TestLessonCreate_Success(t *testing.T) {
 	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
 	lessonRepo.On("Create", mock.Anything, createLessonReq).Return(expectedLesson, nil)
 
-	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID)
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedLesson, lesson)
@@ -139,7 +163,7 @@ func TestLessonCreate_CourseNotFound(t *testing.T) {
 
 	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{}, errors.New("not found"))
 
-	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID)
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
 
 	assert.ErrorIs(t, err, service.ErrNotFound)
 	assert.Empty(t, lesson)
@@ -155,7 +179,7 @@ func TestLessonCreate_RepoError(t *testing.T) {
 	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
 	lessonRepo.On("Create", mock.Anything, createLessonReq).Return(models.Lesson{}, errors.New("db error"))
 
-	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID)
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
 
 	assert.Error(t, err)
 	assert.Empty(t, lesson)
@@ -163,6 +187,140 @@ func TestLessonCreate_RepoError(t *testing.T) {
 	lessonRepo.AssertExpectations(t)
 }
 
+func TestLessonCreate_Conflict(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newLessonSvcWithSchedule(lessonRepo, courseRepo, scheduleRepo)
+
+	conflicts := []models.OccurrenceConflicts{{
+		ScheduledAt:     scheduledAt,
+		DurationMinutes: 60,
+		Conflicts:       []models.ScheduleConflict{{Kind: "task", ID: "task-uuid-1", Title: "Grade essays"}},
+	}}
+	slots := []models.TimeSlot{{ScheduledAt: scheduledAt, DurationMinutes: 60}}
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	scheduleRepo.On("FindConflicts", mock.Anything, tutorID, slots, models.ConflictExclusion{}).Return(conflicts, nil)
+
+	_, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{})
+
+	var conflictErr *service.ConflictError
+	assert.ErrorIs(t, err, service.ErrConflict)
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, conflicts, conflictErr.Occurrences)
+	lessonRepo.AssertNotCalled(t, "Create")
+}
+
+func TestLessonCreate_ForceSkipsCheck(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newLessonSvcWithSchedule(lessonRepo, courseRepo, scheduleRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("Create", mock.Anything, createLessonReq).Return(expectedLesson, nil)
+
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{Force: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLesson, lesson)
+	scheduleRepo.AssertNotCalled(t, "FindConflicts")
+}
+
+func TestLessonCreate_DryRunWritesNothing(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newLessonSvc(lessonRepo, courseRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+
+	lesson, err := svc.Create(context.Background(), createLessonReq, tutorID, models.ScheduleOptions{DryRun: true})
+
+	assert.NoError(t, err)
+	assert.Empty(t, lesson)
+	lessonRepo.AssertNotCalled(t, "Create")
+}
+
+// CreateBulk
+
+func TestLessonCreateBulk_ReportsEachOccurrence(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newLessonSvcWithSchedule(lessonRepo, courseRepo, scheduleRepo)
+
+	req := models.CreateBulkLessonRequest{
+		CourseID:        courseID,
+		ScheduledAts:    []string{"2026-05-01T10:00:00Z", "2026-05-08T10:00:00Z"},
+		DurationMinutes: 60,
+	}
+	second := time.Date(2026, time.May, 8, 10, 0, 0, 0, time.UTC)
+	conflicts := []models.OccurrenceConflicts{{
+		ScheduledAt:     second,
+		DurationMinutes: 60,
+		Conflicts:       []models.ScheduleConflict{{Kind: "lesson", ID: "lesson-uuid-2", Title: "Physics"}},
+	}}
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	scheduleRepo.On("FindConflicts", mock.Anything, tutorID, mock.MatchedBy(func(slots []models.TimeSlot) bool {
+		return len(slots) == 2 && slots[0].ScheduledAt.Equal(scheduledAt) && slots[1].ScheduledAt.Equal(second)
+	}), models.ConflictExclusion{}).Return(conflicts, nil)
+
+	_, err := svc.CreateBulk(context.Background(), req, tutorID, models.ScheduleOptions{DryRun: true})
+
+	var conflictErr *service.ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, conflicts, conflictErr.Occurrences)
+	lessonRepo.AssertNotCalled(t, "CreateBulk")
+}
+
+func TestLessonCreateBulk_OverlappingSlots(t *testing.T) {
+	for _, opts := range []models.ScheduleOptions{{}, {DryRun: true}} {
+		lessonRepo := new(mockLessonRepo)
+		courseRepo := new(mockCourseRepo)
+		scheduleRepo := new(mockScheduleRepo)
+		svc := newLessonSvcWithSchedule(lessonRepo, courseRepo, scheduleRepo)
+
+		// Listed out of order: the 10:00 lesson runs until 11:00, past the 10:30 start.
+		req := models.CreateBulkLessonRequest{
+			CourseID:        courseID,
+			ScheduledAts:    []string{"2026-05-01T10:30:00Z", "2026-05-01T10:00:00Z"},
+			DurationMinutes: 60,
+		}
+		courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+
+		_, err := svc.CreateBulk(context.Background(), req, tutorID, opts)
+
+		var conflictErr *service.ConflictError
+		if !assert.ErrorAs(t, err, &conflictErr, "dry run: %v", opts.DryRun) {
+			continue
+		}
+		assert.Equal(t, []models.OccurrenceConflicts{{
+			ScheduledAt:     scheduledAt.Add(30 * time.Minute),
+			DurationMinutes: 60,
+			Conflicts:       []models.ScheduleConflict{{Kind: "slot", ScheduledAt: scheduledAt, DurationMinutes: 60}},
+		}}, conflictErr.Occurrences)
+		scheduleRepo.AssertNotCalled(t, "FindConflicts")
+		lessonRepo.AssertNotCalled(t, "CreateBulk")
+	}
+}
+
+func TestLessonCreateBulk_InvalidDate(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newLessonSvc(lessonRepo, courseRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+
+	_, err := svc.CreateBulk(context.Background(), models.CreateBulkLessonRequest{
+		CourseID:        courseID,
+		ScheduledAts:    []string{"next monday"},
+		DurationMinutes: 60,
+	}, tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	lessonRepo.AssertNotCalled(t, "CreateBulk")
+}
+
 // GetByCourse
 
 func TestLessonGetByCourse_Success(t *testing.T) {
@@ -245,9 +403,9 @@ func TestLessonUpdate_Success(t *testing.T) {
 	}
 
 	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
-	lessonRepo.On("Update", mock.Anything, lessonID, updateLessonReq).Return(updated, nil)
+	lessonRepo.On("Update", mock.Anything, lessonID, updateLessonReq, scheduledToCompleted).Return(updated, nil)
 
-	lesson, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID)
+	lesson, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID, models.ScheduleOptions{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, updated, lesson)
@@ -261,7 +419,7 @@ func TestLessonUpdate_NotFound(t *testing.T) {
 
 	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(models.Lesson{}, errors.New("not found"))
 
-	lesson, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID)
+	lesson, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID, models.ScheduleOptions{})
 
 	assert.ErrorIs(t, err, service.ErrNotFound)
 	assert.Empty(t, lesson)
@@ -275,9 +433,9 @@ func TestLessonUpdate_RepoError(t *testing.T) {
 	svc := newLessonSvc(lessonRepo, courseRepo)
 
 	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
-	lessonRepo.On("Update", mock.Anything, lessonID, updateLessonReq).Return(models.Lesson{}, errors.New("db error"))
+	lessonRepo.On("Update", mock.Anything, lessonID, updateLessonReq, scheduledToCompleted).Return(models.Lesson{}, errors.New("db error"))
 
-	lesson, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID)
+	lesson, err := svc.Update(context.Background(), lessonID, updateLessonReq, tutorID, models.ScheduleOptions{})
 
 	assert.Error(t, err)
 	assert.Empty(t, lesson)
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+var errMakeupExists = fmt.Errorf("the lesson already has a makeup: %w", ErrConflict)
+
+type MakeupService interface {
+	GetForCourse(ctx context.Context, courseID string, tutorID string) (models.MakeupCredits, error)
+	GetForStudent(ctx context.Context, studentID string, tutorID string) ([]models.MakeupCredits, error)
+}
+
+type makeupService struct {
+	repo        repository.MakeupRepository
+	courseRepo  repository.CourseRepository
+	studentRepo repository.StudentRepository
+}
+
+func NewMakeupService(repo repository.MakeupRepository, courseRepo repository.CourseRepository, studentRepo repository.StudentRepository) MakeupService {
+	return &makeupService{repo: repo, courseRepo: courseRepo, studentRepo: studentRepo}
+}
+
+func (s *makeupService) GetForCourse(ctx context.Context, courseID string, tutorID string) (models.MakeupCredits, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID, tutorID); err != nil {
+		return models.MakeupCredits{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	return s.repo.GetForCourse(ctx, courseID)
+}
+
+// GetForStudent lists the credits of the student's individual courses.
+func (s *makeupService) GetForStudent(ctx context.Context, studentID string, tutorID string) ([]models.MakeupCredits, error) {
+	if _, err := s.studentRepo.GetByID(ctx, studentID, tutorID); err != nil {
+		return nil, fmt.Errorf("student: %w", ErrNotFound)
+	}
+	return s.repo.GetForStudent(ctx, studentID, tutorID)
+}
+
+// checkMakeup validates that original can get a makeup in courseID: it must be a
+// cancelled or missed lesson of the same course.
+func checkMakeup(original models.Lesson, courseID string) error {
+	if original.CourseID != courseID {
+		return fmt.Errorf("makeup_for must be a lesson of the same course: %w", ErrBadRequest)
+	}
+	if original.Status != "cancelled" && original.Status != "missed" {
+		return fmt.Errorf("only cancelled or missed lessons can be made up: %w", ErrConflict)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockMakeupRepo struct {
+	mock.Mock
+}
+
+func (m *mockMakeupRepo) GetForCourse(ctx context.Context, courseID string) (models.MakeupCredits, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).(models.MakeupCredits), args.Error(1)
+}
+
+func (m *mockMakeupRepo) GetForStudent(ctx context.Context, studentID string, tutorID string) ([]models.MakeupCredits, error) {
+	args := m.Called(ctx, studentID, tutorID)
+	return args.Get(0).([]models.MakeupCredits), args.Error(1)
+}
+
+const missedLessonID = "missed-lesson-uuid"
+
+func makeupReq() models.CreateLessonRequest {
+	req := createLessonReq
+	original := missedLessonID
+	req.MakeupFor = &original
+	return req
+}
+
+func missedLesson() models.Lesson {
+	l := expectedLesson
+	l.ID = missedLessonID
+	l.Status = "missed"
+	return l
+}
+
+func TestLessonCreate_Makeup(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newLessonSvc(lessonRepo, courseRepo)
+
+	req := makeupReq()
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("GetByIDForTutor", mock.Anything, missedLessonID, tutorID).Return(missedLesson(), nil)
+	lessonRepo.On("Create", mock.Anything, req).Return(expectedLesson, nil)
+
+	_, err := svc.Create(context.Background(), req, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	lessonRepo.AssertExpectations(t)
+}
+
+func TestLessonCreate_MakeupForScheduledLesson(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newLessonSvc(lessonRepo, courseRepo)
+
+	original := missedLesson()
+	original.Status = "scheduled"
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("GetByIDForTutor", mock.Anything, missedLessonID, tutorID).Return(original, nil)
+
+	_, err := svc.Create(context.Background(), makeupReq(), tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	lessonRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLessonCreate_MakeupFromAnotherCourse(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newLessonSvc(lessonRepo, courseRepo)
+
+	original := missedLesson()
+	original.CourseID = "other-course-uuid"
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("GetByIDForTutor", mock.Anything, missedLessonID, tutorID).Return(original, nil)
+
+	_, err := svc.Create(context.Background(), makeupReq(), tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestLessonCreate_MakeupAlreadyExists(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newLessonSvc(lessonRepo, courseRepo)
+
+	req := makeupReq()
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	lessonRepo.On("GetByIDForTutor", mock.Anything, missedLessonID, tutorID).Return(missedLesson(), nil)
+	lessonRepo.On("Create", mock.Anything, req).Return(models.Lesson{}, repository.ErrMakeupExists)
+
+	_, err := svc.Create(context.Background(), req, tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestLessonUpdate_MakeupOwedNeedsCancelledOrMissed(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newLessonSvc(lessonRepo, new(mockCourseRepo))
+
+	owed := true
+	req := updateLessonReq
+	req.MakeupOwed = &owed
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(expectedLesson, nil)
+
+	_, err := svc.Update(context.Background(), lessonID, req, tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	lessonRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMakeupGetForCourse(t *testing.T) {
+	repo := new(mockMakeupRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := service.NewMakeupService(repo, courseRepo, new(mockStudentRepo))
+
+	credits := models.MakeupCredits{CourseID: courseID, Owed: 2, Scheduled: 1}
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	repo.On("GetForCourse", mock.Anything, courseID).Return(credits, nil)
+
+	got, err := svc.GetForCourse(context.Background(), courseID, tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, credits, got)
+}
+
+func TestMakeupGetForStudent_NotFound(t *testing.T) {
+	repo := new(mockMakeupRepo)
+	studentRepo := new(mockStudentRepo)
+	svc := service.NewMakeupService(repo, new(mockCourseRepo), studentRepo)
+
+	studentRepo.On("GetByID", mock.Anything, "student-1", tutorID).Return(models.Student{}, errors.New("no rows"))
+
+	_, err := svc.GetForStudent(context.Background(), "student-1", tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "GetForStudent", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+// OrganizationService runs tutoring schools. Every method takes the calling
+// tutor and checks their role in the school; the per-tutor checks of the other
+// services are untouched, so a school only ever reads across its members
+// through here.
+type OrganizationService interface {
+	Create(ctx context.Context, tutorID string, req models.CreateOrganizationRequest) (models.Organization, error)
+	GetAll(ctx context.Context, tutorID string) ([]models.Organization, error)
+	GetByID(ctx context.Context, id string, tutorID string) (models.Organization, error)
+	Update(ctx context.Context, id string, tutorID string, req models.UpdateOrganizationRequest) (models.Organization, error)
+	Delete(ctx context.Context, id string, tutorID string) error
+
+	GetMembers(ctx context.Context, id string, tutorID string) ([]models.OrganizationMember, error)
+	InviteMember(ctx context.Context, id string, tutorID string, req models.InviteMemberRequest) (models.OrganizationMember, error)
+	UpdateMember(ctx context.Context, id string, tutorID string, memberID string, req models.UpdateMemberRequest) (models.OrganizationMember, error)
+	RemoveMember(ctx context.Context, id string, tutorID string, memberID string) error
+	Join(ctx context.Context, id string, tutorID string) error
+
+	GetCalendar(ctx context.Context, id string, tutorID string, from string, to string) ([]models.OrganizationLesson, error)
+	GetIncome(ctx context.Context, id string, tutorID string) (models.OrganizationIncome, error)
+	AssignStudent(ctx context.Context, id string, tutorID string, studentID string, req models.AssignTutorRequest) error
+	AssignCourse(ctx context.Context, id string, tutorID string, courseID string, req models.AssignTutorRequest) error
+}
+
+type organizationService struct {
+	repo        repository.OrganizationRepository
+	paymentRepo repository.PaymentRepository
+	rateRepo    repository.ExchangeRateRepository
+}
+
+func NewOrganizationService(repo repository.OrganizationRepository, paymentRepo repository.PaymentRepository, rateRepo repository.ExchangeRateRepository) OrganizationService {
+	return &organizationService{repo: repo, paymentRepo: paymentRepo, rateRepo: rateRepo}
+}
+
+// What each role may do besides seeing the school and its members.
+var (
+	orgManagers  = []string{models.OrgRoleOwner, models.OrgRoleAdmin}
+	orgCalendar  = []string{models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleTutor}
+	orgIncome    = []string{models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleAccountant}
+	orgTeachers  = []string{models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleTutor}
+	orgAllRoles  = []string{models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleTutor, models.OrgRoleAccountant}
+	orgOwnerOnly = []string{models.OrgRoleOwner}
+)
+
+// member gives the organization as the tutor sees it, if they have joined it in
+// one of roles. Invitees and outsiders are told it does not exist.
+func (s *organizationService) member(ctx context.Context, id string, tutorID string, roles []string) (models.Organization, error) {
+	org, err := s.repo.GetMembership(ctx, id, tutorID)
+	if err != nil || org.JoinedAt == nil {
+		return models.Organization{}, fmt.Errorf("organization: %w", ErrNotFound)
+	}
+	if !slices.Contains(roles, org.Role) {
+		return models.Organization{}, fmt.Errorf("your role in the organization does not allow this: %w", ErrForbidden)
+	}
+	return org, nil
+}
+
+// mayManage reports whether a member with role may give, change or take away
+// the role of another: owners over anyone, admins over tutors and accountants.
+func mayManage(role string, other string) bool {
+	return role == models.OrgRoleOwner ||
+		role == models.OrgRoleAdmin && (other == models.OrgRoleTutor || other == models.OrgRoleAccountant)
+}
+
+func (s *organizationService) Create(ctx context.Context, tutorID string, req models.CreateOrganizationRequest) (models.Organization, error) {
+	return s.repo.Create(ctx, tutorID, req)
+}
+
+// GetAll lists the tutor's organizations, invitations among them.
+func (s *organizationService) GetAll(ctx context.Context, tutorID string) ([]models.Organization, error) {
+	return s.repo.GetAll(ctx, tutorID)
+}
+
+// GetByID shows an organization to its members and to those invited to it.
+func (s *organizationService) GetByID(ctx context.Context, id string, tutorID string) (models.Organization, error) {
+	org, err := s.repo.GetMembership(ctx, id, tutorID)
+	if err != nil {
+		return models.Organization{}, fmt.Errorf("organization: %w", ErrNotFound)
+	}
+	return org, nil
+}
+
+func (s *organizationService) Update(ctx context.Context, id string, tutorID string, req models.UpdateOrganizationRequest) (models.Organization, error) {
+	org, err := s.member(ctx, id, tutorID, orgManagers)
+	if err != nil {
+		return models.Organization{}, err
+	}
+	if err := s.repo.Update(ctx, id, req); err != nil {
+		return models.Organization{}, fmt.Errorf("organization: %w", ErrNotFound)
+	}
+	org.Name = req.Name
+	return org, nil
+}
+
+// Delete dissolves the organization. What its members teach stays theirs.
+func (s *organizationService) Delete(ctx context.Context, id string, tutorID string) error {
+	if _, err := s.member(ctx, id, tutorID, orgOwnerOnly); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("organization: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *organizationService) GetMembers(ctx context.Context, id string, tutorID string) ([]models.OrganizationMember, error) {
+	if _, err := s.member(ctx, id, tutorID, orgAllRoles); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(ctx, id)
+}
+
+// InviteMember invites a tutor, by the email of their account, to join in a
+// role; nothing of theirs is shared until they accept.
+func (s *organizationService) InviteMember(ctx context.Context, id string, tutorID string, req models.InviteMemberRequest) (models.OrganizationMember, error) {
+	org, err := s.member(ctx, id, tutorID, orgManagers)
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+	if !mayManage(org.Role, req.Role) {
+		return models.OrganizationMember{}, fmt.Errorf("only owners can invite owners and admins: %w", ErrForbidden)
+	}
+	member, err := s.repo.AddMember(ctx, id, req.Email, req.Role)
+	if errors.Is(err, repository.ErrAlreadyMember) {
+		return models.OrganizationMember{}, fmt.Errorf("this tutor is already a member or invited: %w", ErrConflict)
+	}
+	if err != nil {
+		return models.OrganizationMember{}, fmt.Errorf("tutor with this email: %w", ErrNotFound)
+	}
+	return member, nil
+}
+
+// UpdateMember changes a member's role. The last owner can't be demoted.
+func (s *organizationService) UpdateMember(ctx context.Context, id string, tutorID string, memberID string, req models.UpdateMemberRequest) (models.OrganizationMember, error) {
+	org, err := s.member(ctx, id, tutorID, orgManagers)
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+	target, err := s.findMember(ctx, id, memberID)
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+	if !mayManage(org.Role, target.Role) || !mayManage(org.Role, req.Role) {
+		return models.OrganizationMember{}, fmt.Errorf("only owners can change owners and admins: %w", ErrForbidden)
+	}
+	if req.Role != models.OrgRoleOwner {
+		if err := s.keepOwner(ctx, id, target); err != nil {
+			return models.OrganizationMember{}, err
+		}
+	}
+	member, err := s.repo.UpdateMember(ctx, id, memberID, req.Role)
+	if err != nil {
+		return models.OrganizationMember{}, fmt.Errorf("member: %w", ErrNotFound)
+	}
+	return member, nil
+}
+
+// RemoveMember takes a member out of the organization, or withdraws their
+// invitation. Anyone may leave, or decline, on their own; the last owner can't.
+// Students and courses the member has stay theirs.
+func (s *organizationService) RemoveMember(ctx context.Context, id string, tutorID string, memberID string) error {
+	if memberID != tutorID {
+		org, err := s.member(ctx, id, tutorID, orgManagers)
+		if err != nil {
+			return err
+		}
+		target, err := s.findMember(ctx, id, memberID)
+		if err != nil {
+			return err
+		}
+		if !mayManage(org.Role, target.Role) {
+			return fmt.Errorf("only owners can remove owners and admins: %w", ErrForbidden)
+		}
+		if err := s.keepOwner(ctx, id, target); err != nil {
+			return err
+		}
+	} else {
+		self, err := s.findMember(ctx, id, tutorID)
+		if err != nil {
+			return err
+		}
+		if err := s.keepOwner(ctx, id, self); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.RemoveMember(ctx, id, memberID); err != nil {
+		return fmt.Errorf("member: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *organizationService) findMember(ctx context.Context, id string, memberID string) (models.OrganizationMember, error) {
+	members, err := s.repo.GetMembers(ctx, id)
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+	for _, m := range members {
+		if m.TutorID == memberID {
+			return m, nil
+		}
+	}
+	return models.OrganizationMember{}, fmt.Errorf("member: %w", ErrNotFound)
+}
+
+// keepOwner refuses to let member stop being an owner if they are the only one
+// who has joined.
+func (s *organizationService) keepOwner(ctx context.Context, id string, member models.OrganizationMember) error {
+	if member.Role != models.OrgRoleOwner || member.JoinedAt == nil {
+		return nil
+	}
+	members, err := s.repo.GetMembers(ctx, id)
+	if err != nil {
+		return err
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == models.OrgRoleOwner && m.JoinedAt != nil {
+			owners++
+		}
+	}
+	if owners < 2 {
+		return fmt.Errorf("the organization needs another owner first: %w", ErrConflict)
+	}
+	return nil
+}
+
+// Join accepts the tutor's invitation.
+func (s *organizationService) Join(ctx context.Context, id string, tutorID string) error {
+	if err := s.repo.Join(ctx, id, tutorID); err != nil {
+		return fmt.Errorf("invitation: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// GetCalendar lists the lessons of every member in [from, to).
+func (s *organizationService) GetCalendar(ctx context.Context, id string, tutorID string, from string, to string) ([]models.OrganizationLesson, error) {
+	if _, err := s.member(ctx, id, tutorID, orgCalendar); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCalendar(ctx, id, from, to)
+}
+
+// GetIncome gives each member's income this month as they see it themselves,
+// in their home currency at their exchange rates, and sums the school's by
+// currency.
+func (s *organizationService) GetIncome(ctx context.Context, id string, tutorID string) (models.OrganizationIncome, error) {
+	if _, err := s.member(ctx, id, tutorID, orgIncome); err != nil {
+		return models.OrganizationIncome{}, err
+	}
+	members, err := s.repo.GetMembers(ctx, id)
+	if err != nil {
+		return models.OrganizationIncome{}, err
+	}
+
+	result := models.OrganizationIncome{ByTutor: []models.TutorIncome{}, ByCurrency: []models.CurrencyIncome{}}
+	totals := map[string]int{}
+	for _, m := range members {
+		if m.JoinedAt == nil {
+			continue
+		}
+		income, err := s.paymentRepo.GetMonthlyIncome(ctx, m.TutorID)
+		if err != nil {
+			return models.OrganizationIncome{}, err
+		}
+		rates, err := s.rateRepo.GetAll(ctx, m.TutorID)
+		if err != nil {
+			return models.OrganizationIncome{}, err
+		}
+		result.ByTutor = append(result.ByTutor, models.TutorIncome{
+			TutorID: m.TutorID, FirstName: m.FirstName, LastName: m.LastName,
+			Income: convertIncome(income, rates),
+		})
+		for _, ci := range income.ByCurrency {
+			i, ok := totals[ci.Currency]
+			if !ok {
+				i = len(result.ByCurrency)
+				totals[ci.Currency] = i
+				result.ByCurrency = append(result.ByCurrency, models.CurrencyIncome{Currency: ci.Currency})
+			}
+			result.ByCurrency[i].Total += ci.Total
+			result.ByCurrency[i].Earned += ci.Earned
+		}
+	}
+	slices.SortFunc(result.ByCurrency, func(a, b models.CurrencyIncome) int {
+		return cmp.Compare(a.Currency, b.Currency)
+	})
+	return result, nil
+}
+
+// teacher checks that the tutor a student or course is handed to teaches in the
+// organization.
+func (s *organizationService) teacher(ctx context.Context, id string, tutorID string) error {
+	org, err := s.repo.GetMembership(ctx, id, tutorID)
+	if err != nil || org.JoinedAt == nil {
+		return fmt.Errorf("tutor is not a member of the organization: %w", ErrBadRequest)
+	}
+	if !slices.Contains(orgTeachers, org.Role) {
+		return fmt.Errorf("an accountant can't be given students: %w", ErrBadRequest)
+	}
+	return nil
+}
+
+// AssignStudent hands a member's student over to another member, with their
+// individual courses. Payments already recorded stay the income of the tutor
+// who took them. A student still in group courses can't be moved.
+func (s *organizationService) AssignStudent(ctx context.Context, id string, tutorID string, studentID string, req models.AssignTutorRequest) error {
+	if _, err := s.member(ctx, id, tutorID, orgManagers); err != nil {
+		return err
+	}
+	if err := s.teacher(ctx, id, req.TutorID); err != nil {
+		return err
+	}
+	return assignError(s.repo.AssignStudent(ctx, id, studentID, req.TutorID), "student")
+}
+
+// AssignCourse hands a member's group course over to another member; payments
+// already recorded stay with the tutor who took them. A one-student course is
+// moved with its student.
+func (s *organizationService) AssignCourse(ctx context.Context, id string, tutorID string, courseID string, req models.AssignTutorRequest) error {
+	if _, err := s.member(ctx, id, tutorID, orgManagers); err != nil {
+		return err
+	}
+	if err := s.teacher(ctx, id, req.TutorID); err != nil {
+		return err
+	}
+	return assignError(s.repo.AssignCourse(ctx, id, courseID, req.TutorID), "course")
+}
+
+// assignError maps why a student or course could not be handed over.
+func assignError(err error, what string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrIndividualCourse):
+		return fmt.Errorf("a one-student course goes with its student; assign the student instead: %w", ErrBadRequest)
+	case errors.Is(err, repository.ErrInGroupCourse):
+		return fmt.Errorf("take the student out of their group courses first: %w", ErrConflict)
+	case errors.Is(err, repository.ErrOpenInvoices):
+		return fmt.Errorf("settle or delete the course's unpaid invoices first: %w", ErrConflict)
+	default:
+		return fmt.Errorf("%s: %w", what, ErrNotFound)
+	}
+}
@@ -0,0 +1,351 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOrganizationRepo struct {
+	mock.Mock
+}
+
+func (m *mockOrganizationRepo) Create(ctx context.Context, tutorID string, req models.CreateOrganizationRequest) (models.Organization, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) GetAll(ctx context.Context, tutorID string) ([]models.Organization, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) GetMembership(ctx context.Context, id string, tutorID string) (models.Organization, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) Update(ctx context.Context, id string, req models.UpdateOrganizationRequest) error {
+	return m.Called(ctx, id, req).Error(0)
+}
+
+func (m *mockOrganizationRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockOrganizationRepo) GetMembers(ctx context.Context, id string) ([]models.OrganizationMember, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]models.OrganizationMember), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) AddMember(ctx context.Context, id string, email string, role string) (models.OrganizationMember, error) {
+	args := m.Called(ctx, id, email, role)
+	return args.Get(0).(models.OrganizationMember), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) UpdateMember(ctx context.Context, id string, tutorID string, role string) (models.OrganizationMember, error) {
+	args := m.Called(ctx, id, tutorID, role)
+	return args.Get(0).(models.OrganizationMember), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) RemoveMember(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockOrganizationRepo) Join(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockOrganizationRepo) GetCalendar(ctx context.Context, id string, from string, to string) ([]models.OrganizationLesson, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Get(0).([]models.OrganizationLesson), args.Error(1)
+}
+
+func (m *mockOrganizationRepo) AssignStudent(ctx context.Context, id string, studentID string, tutorID string) error {
+	return m.Called(ctx, id, studentID, tutorID).Error(0)
+}
+
+func (m *mockOrganizationRepo) AssignCourse(ctx context.Context, id string, courseID string, tutorID string) error {
+	return m.Called(ctx, id, courseID, tutorID).Error(0)
+}
+
+// fixtures
+
+const (
+	orgID       = "org-uuid-1"
+	colleagueID = "colleague-uuid-1"
+)
+
+var joined = func() *time.Time { t := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC); return &t }()
+
+// as makes tutor a member of the organization in role; a nil joinedAt makes
+// them only invited.
+func as(repo *mockOrganizationRepo, tutor string, role string, joinedAt *time.Time) {
+	repo.On("GetMembership", mock.Anything, orgID, tutor).
+		Return(models.Organization{ID: orgID, Name: "Bright School", Role: role, JoinedAt: joinedAt}, nil)
+}
+
+func newOrganizationSvc(repo *mockOrganizationRepo, payRepo *mockPaymentRepo, rateRepo *mockExchangeRateRepo) service.OrganizationService {
+	return service.NewOrganizationService(repo, payRepo, rateRepo)
+}
+
+func TestOrganizationGetCalendar_InviteeCantSee(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleTutor, nil)
+
+	_, err := svc.GetCalendar(context.Background(), orgID, tutorID, "2026-05-01", "2026-05-08")
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "GetCalendar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationGetCalendar_Tutor(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleTutor, joined)
+	repo.On("GetCalendar", mock.Anything, orgID, "2026-05-01", "2026-05-08").
+		Return([]models.OrganizationLesson{{TutorID: colleagueID}}, nil)
+
+	lessons, err := svc.GetCalendar(context.Background(), orgID, tutorID, "2026-05-01", "2026-05-08")
+
+	require.NoError(t, err)
+	assert.Len(t, lessons, 1)
+}
+
+func TestOrganizationGetIncome_ForbiddenForTutors(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleTutor, joined)
+
+	_, err := svc.GetIncome(context.Background(), orgID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestOrganizationGetIncome_PerTutorAndByCurrency(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	payRepo := new(mockPaymentRepo)
+	rateRepo := new(mockExchangeRateRepo)
+	svc := newOrganizationSvc(repo, payRepo, rateRepo)
+	as(repo, tutorID, models.OrgRoleAccountant, joined)
+	repo.On("GetMembers", mock.Anything, orgID).Return([]models.OrganizationMember{
+		{TutorID: colleagueID, FirstName: "Aigerim", Role: models.OrgRoleTutor, JoinedAt: joined},
+		{TutorID: tutorID, FirstName: "Dana", Role: models.OrgRoleAccountant, JoinedAt: joined},
+		{TutorID: "invitee-uuid", FirstName: "Erlan", Role: models.OrgRoleTutor},
+	}, nil)
+	payRepo.On("GetMonthlyIncome", mock.Anything, colleagueID).Return(models.MonthlyIncome{
+		Currency: "KZT",
+		ByCurrency: []models.CurrencyIncome{
+			{Currency: "EUR", Total: money.FromUnits(100), Earned: money.FromUnits(50)},
+			{Currency: "KZT", Total: money.FromUnits(20000), Earned: money.FromUnits(10000)},
+		},
+	}, nil)
+	payRepo.On("GetMonthlyIncome", mock.Anything, tutorID).Return(models.MonthlyIncome{
+		Currency: "EUR",
+		ByCurrency: []models.CurrencyIncome{
+			{Currency: "EUR", Total: money.FromUnits(30), Earned: money.FromUnits(30)},
+		},
+	}, nil)
+	rateRepo.On("GetAll", mock.Anything, colleagueID).Return([]models.ExchangeRate{
+		{Currency: "EUR", Rate: mustRate(t, "500")},
+	}, nil)
+	rateRepo.On("GetAll", mock.Anything, tutorID).Return([]models.ExchangeRate{}, nil)
+
+	income, err := svc.GetIncome(context.Background(), orgID, tutorID)
+
+	require.NoError(t, err)
+	require.Len(t, income.ByTutor, 2)
+	assert.Equal(t, colleagueID, income.ByTutor[0].TutorID)
+	assert.Equal(t, money.FromUnits(20000+50000), income.ByTutor[0].Income.Total)
+	assert.Equal(t, money.FromUnits(30), income.ByTutor[1].Income.Total)
+	assert.Equal(t, []models.CurrencyIncome{
+		{Currency: "EUR", Total: money.FromUnits(130), Earned: money.FromUnits(80)},
+		{Currency: "KZT", Total: money.FromUnits(20000), Earned: money.FromUnits(10000)},
+	}, income.ByCurrency)
+	payRepo.AssertNotCalled(t, "GetMonthlyIncome", mock.Anything, "invitee-uuid")
+}
+
+func TestOrganizationInviteMember_AdminCantInviteAdmin(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleAdmin, joined)
+
+	_, err := svc.InviteMember(context.Background(), orgID, tutorID, models.InviteMemberRequest{Email: "b@example.com", Role: models.OrgRoleAdmin})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationInviteMember_AlreadyMember(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleOwner, joined)
+	repo.On("AddMember", mock.Anything, orgID, "b@example.com", models.OrgRoleTutor).
+		Return(models.OrganizationMember{}, repository.ErrAlreadyMember)
+
+	_, err := svc.InviteMember(context.Background(), orgID, tutorID, models.InviteMemberRequest{Email: "b@example.com", Role: models.OrgRoleTutor})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestOrganizationInviteMember_UnknownEmail(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleAdmin, joined)
+	repo.On("AddMember", mock.Anything, orgID, "nobody@example.com", models.OrgRoleAccountant).
+		Return(models.OrganizationMember{}, errors.New("no rows"))
+
+	_, err := svc.InviteMember(context.Background(), orgID, tutorID, models.InviteMemberRequest{Email: "nobody@example.com", Role: models.OrgRoleAccountant})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestOrganizationRemoveMember_LastOwnerCantLeave(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	repo.On("GetMembers", mock.Anything, orgID).Return([]models.OrganizationMember{
+		{TutorID: tutorID, Role: models.OrgRoleOwner, JoinedAt: joined},
+		{TutorID: colleagueID, Role: models.OrgRoleOwner},
+	}, nil)
+
+	err := svc.RemoveMember(context.Background(), orgID, tutorID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationRemoveMember_DeclineInvitation(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	repo.On("GetMembers", mock.Anything, orgID).Return([]models.OrganizationMember{
+		{TutorID: colleagueID, Role: models.OrgRoleOwner, JoinedAt: joined},
+		{TutorID: tutorID, Role: models.OrgRoleTutor},
+	}, nil)
+	repo.On("RemoveMember", mock.Anything, orgID, tutorID).Return(nil)
+
+	err := svc.RemoveMember(context.Background(), orgID, tutorID, tutorID)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestOrganizationUpdateMember_AdminCantDemoteOwner(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleAdmin, joined)
+	repo.On("GetMembers", mock.Anything, orgID).Return([]models.OrganizationMember{
+		{TutorID: colleagueID, Role: models.OrgRoleOwner, JoinedAt: joined},
+		{TutorID: tutorID, Role: models.OrgRoleAdmin, JoinedAt: joined},
+	}, nil)
+
+	_, err := svc.UpdateMember(context.Background(), orgID, tutorID, colleagueID, models.UpdateMemberRequest{Role: models.OrgRoleTutor})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "UpdateMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationAssignCourse_ToTutor(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleAdmin, joined)
+	as(repo, colleagueID, models.OrgRoleTutor, joined)
+	repo.On("AssignCourse", mock.Anything, orgID, courseID, colleagueID).Return(nil)
+
+	err := svc.AssignCourse(context.Background(), orgID, tutorID, courseID, models.AssignTutorRequest{TutorID: colleagueID})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestOrganizationAssignCourse_NotToAccountantOrInvitee(t *testing.T) {
+	for name, target := range map[string]struct {
+		role     string
+		joinedAt *time.Time
+	}{
+		"accountant": {models.OrgRoleAccountant, joined},
+		"invitee":    {models.OrgRoleTutor, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := new(mockOrganizationRepo)
+			svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+			as(repo, tutorID, models.OrgRoleOwner, joined)
+			as(repo, colleagueID, target.role, target.joinedAt)
+
+			err := svc.AssignCourse(context.Background(), orgID, tutorID, courseID, models.AssignTutorRequest{TutorID: colleagueID})
+
+			assert.ErrorIs(t, err, service.ErrBadRequest)
+			repo.AssertNotCalled(t, "AssignCourse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOrganizationAssignStudent_TutorsCantAssign(t *testing.T) {
+	repo := new(mockOrganizationRepo)
+	svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+	as(repo, tutorID, models.OrgRoleTutor, joined)
+
+	err := svc.AssignStudent(context.Background(), orgID, tutorID, "student-uuid-1", models.AssignTutorRequest{TutorID: colleagueID})
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "AssignStudent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrganizationAssignCourse_RefusedMoves(t *testing.T) {
+	for name, tc := range map[string]struct {
+		repoErr error
+		want    error
+	}{
+		"one-student course": {repository.ErrIndividualCourse, service.ErrBadRequest},
+		"unpaid invoices":    {repository.ErrOpenInvoices, service.ErrConflict},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := new(mockOrganizationRepo)
+			svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+			as(repo, tutorID, models.OrgRoleOwner, joined)
+			as(repo, colleagueID, models.OrgRoleTutor, joined)
+			repo.On("AssignCourse", mock.Anything, orgID, courseID, colleagueID).Return(tc.repoErr)
+
+			err := svc.AssignCourse(context.Background(), orgID, tutorID, courseID, models.AssignTutorRequest{TutorID: colleagueID})
+
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestOrganizationAssignStudent_RepositoryOutcomes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		repoErr error
+		want    error
+	}{
+		"moved":              {nil, nil},
+		"still in a group":   {repository.ErrInGroupCourse, service.ErrConflict},
+		"unpaid invoices":    {repository.ErrOpenInvoices, service.ErrConflict},
+		"not a member's own": {errors.New("no rows"), service.ErrNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := new(mockOrganizationRepo)
+			svc := newOrganizationSvc(repo, new(mockPaymentRepo), new(mockExchangeRateRepo))
+			as(repo, tutorID, models.OrgRoleAdmin, joined)
+			as(repo, colleagueID, models.OrgRoleTutor, joined)
+			repo.On("AssignStudent", mock.Anything, orgID, "student-uuid-1", colleagueID).Return(tc.repoErr)
+
+			err := svc.AssignStudent(context.Background(), orgID, tutorID, "student-uuid-1", models.AssignTutorRequest{TutorID: colleagueID})
+
+			if tc.want == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.want)
+			}
+		})
+	}
+}
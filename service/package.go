@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type PackageService interface {
+	Create(ctx context.Context, tutorID string, req models.CreatePackageRequest) (models.Package, error)
+	GetAll(ctx context.Context, tutorID string) ([]models.Package, error)
+	Update(ctx context.Context, id string, tutorID string, req models.UpdatePackageRequest) (models.Package, error)
+	Delete(ctx context.Context, id string, tutorID string) error
+	Sell(ctx context.Context, id string, tutorID string, req models.SellPackageRequest) (models.PackageSale, error)
+}
+
+type packageService struct {
+	repo           repository.PackageRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+}
+
+func NewPackageService(repo repository.PackageRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository) PackageService {
+	return &packageService{repo: repo, courseRepo: courseRepo, enrollmentRepo: enrollmentRepo}
+}
+
+// Create adds a package or a subscription. A subscription's lessons are valid for
+// the month sold, so it takes no valid_days.
+func (s *packageService) Create(ctx context.Context, tutorID string, req models.CreatePackageRequest) (models.Package, error) {
+	if req.Kind == models.KindSubscription && req.ValidDays != nil {
+		return models.Package{}, fmt.Errorf("a subscription is valid for a month, not valid_days: %w", ErrBadRequest)
+	}
+	return s.repo.Create(ctx, tutorID, req)
+}
+
+func (s *packageService) GetAll(ctx context.Context, tutorID string) ([]models.Package, error) {
+	return s.repo.GetAll(ctx, tutorID)
+}
+
+// Update changes what the package gives from now on; sales already made keep
+// their lessons and validity.
+func (s *packageService) Update(ctx context.Context, id string, tutorID string, req models.UpdatePackageRequest) (models.Package, error) {
+	current, err := s.repo.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Package{}, fmt.Errorf("package: %w", ErrNotFound)
+	}
+	if current.Kind == models.KindSubscription && req.ValidDays != nil {
+		return models.Package{}, fmt.Errorf("a subscription is valid for a month, not valid_days: %w", ErrBadRequest)
+	}
+	return s.repo.Update(ctx, id, tutorID, req)
+}
+
+func (s *packageService) Delete(ctx context.Context, id string, tutorID string) error {
+	if err := s.repo.Delete(ctx, id, tutorID); err != nil {
+		return fmt.Errorf("package: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// Sell records a payment of the package's price on the course together with the
+// lessons it gives, valid from StartsAt (PaidAt by default) for the package's
+// valid_days or, for a subscription, for a month. A group course's packages are
+// bought by one member.
+func (s *packageService) Sell(ctx context.Context, id string, tutorID string, req models.SellPackageRequest) (models.PackageSale, error) {
+	pkg, err := s.repo.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.PackageSale{}, fmt.Errorf("package: %w", ErrNotFound)
+	}
+	course, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID)
+	if err != nil {
+		return models.PackageSale{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	if _, err := courseStudent(ctx, s.enrollmentRepo, course, req.StudentID, true); err != nil {
+		return models.PackageSale{}, err
+	}
+	if course.StudentID != nil {
+		// The course already says whose payment it is.
+		req.StudentID = nil
+	}
+
+	startsAt := req.PaidAt
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	var expiresAt *time.Time
+	switch {
+	case pkg.Kind == models.KindSubscription:
+		end := startsAt.AddDate(0, 1, 0)
+		expiresAt = &end
+	case pkg.ValidDays != nil:
+		end := startsAt.AddDate(0, 0, *pkg.ValidDays)
+		expiresAt = &end
+	}
+
+	payment := models.CreatePaymentRequest{
+		CourseID:     course.ID,
+		StudentID:    req.StudentID,
+		Amount:       pkg.Price,
+		Currency:     pkg.Currency,
+		LessonsCount: pkg.LessonsCount,
+		PaidAt:       req.PaidAt,
+	}
+	return s.repo.Sell(ctx, pkg, payment, startsAt, expiresAt)
+}
+
+// allocateCredits works out how the credits have gone by now. Charges are taken
+// in order, each from the same student's credits valid when the lesson took
+// place, the soonest to expire first; what no credits cover comes out of ad-hoc
+// payments. Credits still unused when their validity ended have expired.
+func allocateCredits(credits []models.LessonCredits, charges []models.LessonCharge, now time.Time) []models.LessonCredits {
+	out := make([]models.LessonCredits, len(credits))
+	copy(out, credits)
+	left := make([]float64, len(out))
+	for i, c := range out {
+		left[i] = float64(c.LessonsCount)
+	}
+
+	// Soonest expiry first, never-expiring last, then in the order sold.
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := out[order[a]].ExpiresAt, out[order[b]].ExpiresAt
+		switch {
+		case ea == nil:
+			return false
+		case eb == nil:
+			return true
+		default:
+			return ea.Before(*eb)
+		}
+	})
+
+	for _, ch := range charges {
+		share := ch.Share
+		for _, i := range order {
+			if share <= 0 {
+				break
+			}
+			c := out[i]
+			if left[i] <= 0 || !sameStudent(c.StudentID, ch.StudentID) ||
+				ch.At.Before(c.StartsAt) || (c.ExpiresAt != nil && !ch.At.Before(*c.ExpiresAt)) {
+				continue
+			}
+			used := min(left[i], share)
+			left[i] -= used
+			out[i].LessonsUsed += used
+			share -= used
+		}
+	}
+
+	for i, c := range out {
+		if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+			out[i].LessonsExpired = left[i]
+		} else {
+			out[i].LessonsRemaining = left[i]
+		}
+	}
+	return out
+}
+
+func sameStudent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,280 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPackageRepo struct {
+	mock.Mock
+}
+
+func (m *mockPackageRepo) Create(ctx context.Context, tutorID string, req models.CreatePackageRequest) (models.Package, error) {
+	args := m.Called(ctx, tutorID, req)
+	return args.Get(0).(models.Package), args.Error(1)
+}
+
+func (m *mockPackageRepo) GetAll(ctx context.Context, tutorID string) ([]models.Package, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.Package), args.Error(1)
+}
+
+func (m *mockPackageRepo) GetByID(ctx context.Context, id string, tutorID string) (models.Package, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Package), args.Error(1)
+}
+
+func (m *mockPackageRepo) Update(ctx context.Context, id string, tutorID string, req models.UpdatePackageRequest) (models.Package, error) {
+	args := m.Called(ctx, id, tutorID, req)
+	return args.Get(0).(models.Package), args.Error(1)
+}
+
+func (m *mockPackageRepo) Delete(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockPackageRepo) Sell(ctx context.Context, pkg models.Package, req models.CreatePaymentRequest, startsAt time.Time, expiresAt *time.Time) (models.PackageSale, error) {
+	args := m.Called(ctx, pkg, req, startsAt, expiresAt)
+	return args.Get(0).(models.PackageSale), args.Error(1)
+}
+
+func (m *mockPackageRepo) GetCredits(ctx context.Context, courseID string) ([]models.LessonCredits, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).([]models.LessonCredits), args.Error(1)
+}
+
+func (m *mockPackageRepo) GetCharges(ctx context.Context, courseID string) ([]models.LessonCharge, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).([]models.LessonCharge), args.Error(1)
+}
+
+func (m *mockPackageRepo) GetStudentCharges(ctx context.Context, courseID string) ([]models.LessonCharge, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).([]models.LessonCharge), args.Error(1)
+}
+
+// noPackages is a package repository for courses that were never sold one.
+func noPackages() *mockPackageRepo {
+	m := new(mockPackageRepo)
+	m.On("GetCredits", mock.Anything, mock.Anything).Return([]models.LessonCredits{}, nil).Maybe()
+	return m
+}
+
+const packageID = "package-uuid-1"
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2025, month, d, 10, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func newPackageSvc(repo *mockPackageRepo, courseRepo *mockCourseRepo, enrollmentRepo *mockEnrollmentRepo) service.PackageService {
+	return service.NewPackageService(repo, courseRepo, enrollmentRepo)
+}
+
+// Create
+
+func TestPackageCreate_SubscriptionWithValidDays(t *testing.T) {
+	repo := new(mockPackageRepo)
+	svc := newPackageSvc(repo, new(mockCourseRepo), new(mockEnrollmentRepo))
+
+	_, err := svc.Create(context.Background(), tutorID, models.CreatePackageRequest{
+		Name: "Monthly", Kind: models.KindSubscription, LessonsCount: 8, Price: money.FromUnits(40000), ValidDays: ptr(30),
+	})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPackageDelete_NotFound(t *testing.T) {
+	repo := new(mockPackageRepo)
+	svc := newPackageSvc(repo, new(mockCourseRepo), new(mockEnrollmentRepo))
+
+	repo.On("Delete", mock.Anything, packageID, tutorID).Return(errors.New("no rows"))
+
+	err := svc.Delete(context.Background(), packageID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// Sell
+
+func TestPackageSell_ValidDays(t *testing.T) {
+	repo := new(mockPackageRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newPackageSvc(repo, courseRepo, new(mockEnrollmentRepo))
+
+	own := "student-uuid-1"
+	course := expectedCourse
+	course.StudentID = &own
+	pkg := models.Package{ID: packageID, Name: "8 lessons", Kind: models.KindPackage, LessonsCount: 8,
+		Price: money.FromUnits(36000), Currency: "KZT", ValidDays: ptr(60)}
+	paidAt := day(time.March, 1)
+	repo.On("GetByID", mock.Anything, packageID, tutorID).Return(pkg, nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(course, nil)
+	payment := models.CreatePaymentRequest{CourseID: courseID, Amount: pkg.Price, Currency: "KZT", LessonsCount: 8, PaidAt: paidAt}
+	repo.On("Sell", mock.Anything, pkg, payment, paidAt, ptr(day(time.April, 30))).Return(models.PackageSale{}, nil)
+
+	_, err := svc.Sell(context.Background(), packageID, tutorID, models.SellPackageRequest{CourseID: courseID, StudentID: &own, PaidAt: paidAt})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestPackageSell_SubscriptionLastsAMonth(t *testing.T) {
+	repo := new(mockPackageRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newPackageSvc(repo, courseRepo, new(mockEnrollmentRepo))
+
+	own := "student-uuid-1"
+	course := expectedCourse
+	course.StudentID = &own
+	pkg := models.Package{ID: packageID, Name: "Monthly", Kind: models.KindSubscription, LessonsCount: 8,
+		Price: money.FromUnits(40000), Currency: "KZT"}
+	repo.On("GetByID", mock.Anything, packageID, tutorID).Return(pkg, nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(course, nil)
+	repo.On("Sell", mock.Anything, pkg, mock.Anything, day(time.February, 1), ptr(day(time.March, 1))).Return(models.PackageSale{}, nil)
+
+	_, err := svc.Sell(context.Background(), packageID, tutorID, models.SellPackageRequest{
+		CourseID: courseID, PaidAt: day(time.January, 28), StartsAt: ptr(day(time.February, 1)),
+	})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestPackageSell_GroupCourseNeedsStudent(t *testing.T) {
+	repo := new(mockPackageRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newPackageSvc(repo, courseRepo, new(mockEnrollmentRepo))
+
+	repo.On("GetByID", mock.Anything, packageID, tutorID).Return(models.Package{ID: packageID, Kind: models.KindPackage}, nil)
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(groupCourse, nil)
+
+	_, err := svc.Sell(context.Background(), packageID, tutorID, models.SellPackageRequest{CourseID: courseID, PaidAt: day(time.March, 1)})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "Sell", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPackageSell_PackageNotFound(t *testing.T) {
+	repo := new(mockPackageRepo)
+	svc := newPackageSvc(repo, new(mockCourseRepo), new(mockEnrollmentRepo))
+
+	repo.On("GetByID", mock.Anything, packageID, tutorID).Return(models.Package{}, errors.New("no rows"))
+
+	_, err := svc.Sell(context.Background(), packageID, tutorID, models.SellPackageRequest{CourseID: courseID, PaidAt: day(time.March, 1)})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// Balances with packages
+
+func TestPaymentGetBalance_PackageCredits(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	packageRepo := new(mockPackageRepo)
+	svc := service.NewPaymentService(payRepo, courseRepo, new(mockEnrollmentRepo), new(mockExchangeRateRepo), packageRepo)
+
+	own := "student-uuid-1"
+	course := expectedCourse
+	course.StudentID = &own
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(course, nil)
+	payRepo.On("GetBalance", mock.Anything, courseID).Return(models.CourseBalance{
+		LessonsPaid: 14, LessonsCompleted: 7.5, LessonsRemaining: 6.5,
+	}, nil)
+	// An expired package, one still valid and a package without expiry.
+	packageRepo.On("GetCredits", mock.Anything, courseID).Return([]models.LessonCredits{
+		{PaymentID: "p1", Name: "8 lessons", LessonsCount: 8, StartsAt: day(time.January, 1), ExpiresAt: ptr(day(time.March, 2))},
+		{PaymentID: "p2", Name: "Forever", LessonsCount: 2, StartsAt: day(time.February, 1)},
+		{PaymentID: "p3", Name: "Long", LessonsCount: 4, StartsAt: day(time.March, 1), ExpiresAt: ptr(time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC))},
+	}, nil)
+	packageRepo.On("GetCharges", mock.Anything, courseID).Return([]models.LessonCharge{
+		{At: day(time.January, 10), Share: 1},
+		{At: day(time.February, 10), Share: 1},
+		{At: day(time.February, 17), Share: 0.5},
+		{At: day(time.February, 24), Share: 1},
+		{At: day(time.March, 5), Share: 1},
+		{At: day(time.March, 12), Share: 1},
+		{At: day(time.March, 19), Share: 1},
+		{At: day(time.March, 26), Share: 1},
+	}, nil)
+
+	balance, err := svc.GetBalance(context.Background(), courseID, tutorID)
+
+	assert.NoError(t, err)
+	assert.Len(t, balance.Packages, 3)
+	// January and February come out of the expiring package, not the one that keeps.
+	assert.Equal(t, 3.5, balance.Packages[0].LessonsUsed)
+	assert.Equal(t, 4.5, balance.Packages[0].LessonsExpired)
+	assert.Zero(t, balance.Packages[0].LessonsRemaining)
+	// March takes the package that expires first before the one that never does.
+	assert.Equal(t, 4.0, balance.Packages[2].LessonsUsed)
+	assert.Zero(t, balance.Packages[2].LessonsRemaining)
+	assert.Equal(t, 0.0, balance.Packages[1].LessonsUsed)
+	assert.Equal(t, 2.0, balance.Packages[1].LessonsRemaining)
+	assert.Equal(t, 4.5, balance.LessonsExpired)
+	assert.Equal(t, 2.0, balance.LessonsRemaining)
+	packageRepo.AssertNotCalled(t, "GetStudentCharges", mock.Anything, mock.Anything)
+}
+
+func TestPaymentGetBalance_NoPackagesSkipsCharges(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	packageRepo := noPackages()
+	svc := service.NewPaymentService(payRepo, courseRepo, new(mockEnrollmentRepo), new(mockExchangeRateRepo), packageRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	payRepo.On("GetBalance", mock.Anything, courseID).Return(models.CourseBalance{LessonsPaid: 4, LessonsRemaining: 4}, nil)
+
+	balance, err := svc.GetBalance(context.Background(), courseID, tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, balance.LessonsRemaining)
+	assert.Empty(t, balance.Packages)
+	packageRepo.AssertNotCalled(t, "GetCharges", mock.Anything, mock.Anything)
+}
+
+func TestPaymentGetStudentBalances_PackageCredits(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	packageRepo := new(mockPackageRepo)
+	svc := service.NewPaymentService(payRepo, courseRepo, new(mockEnrollmentRepo), new(mockExchangeRateRepo), packageRepo)
+
+	ann, bob := "student-uuid-2", "student-uuid-3"
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(groupCourse, nil)
+	payRepo.On("GetStudentBalances", mock.Anything, courseID).Return([]models.StudentBalance{
+		{StudentID: ann, LessonsPaid: 4, LessonsCompleted: 1, LessonsRemaining: 3},
+		{StudentID: bob, LessonsPaid: 4, LessonsCompleted: 3, LessonsRemaining: 1},
+	}, nil)
+	packageRepo.On("GetCredits", mock.Anything, courseID).Return([]models.LessonCredits{
+		{PaymentID: "p1", StudentID: &ann, LessonsCount: 4, StartsAt: day(time.January, 1), ExpiresAt: ptr(day(time.February, 1))},
+		{PaymentID: "p2", StudentID: &bob, LessonsCount: 4, StartsAt: day(time.January, 1), ExpiresAt: ptr(day(time.February, 1))},
+	}, nil)
+	packageRepo.On("GetStudentCharges", mock.Anything, courseID).Return([]models.LessonCharge{
+		{At: day(time.January, 10), Share: 1, StudentID: &bob},
+		{At: day(time.January, 10), Share: 1, StudentID: &ann},
+		{At: day(time.January, 17), Share: 1, StudentID: &bob},
+		{At: day(time.January, 24), Share: 1, StudentID: &bob},
+	}, nil)
+
+	balances, err := svc.GetStudentBalances(context.Background(), courseID, tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, balances[0].LessonsExpired)
+	assert.Zero(t, balances[0].LessonsRemaining)
+	assert.Len(t, balances[0].Packages, 1)
+	assert.Equal(t, 1.0, balances[1].LessonsExpired)
+	assert.Equal(t, 3.0, balances[1].Packages[0].LessonsUsed)
+	assert.Zero(t, balances[1].LessonsRemaining)
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 	"tutorgo/models"
+	"tutorgo/money"
 	"tutorgo/repository"
 )
 
@@ -13,22 +16,41 @@ type PaymentService interface {
 	GetAllByTutor(ctx context.Context, tutorID string, limit int) ([]models.Payment, error)
 	GetAllByTutorPaged(ctx context.Context, tutorID string, p models.Pagination) ([]models.Payment, int, error)
 	GetBalance(ctx context.Context, courseID string, tutorID string) (models.CourseBalance, error)
-	GetMonthlyIncome(ctx context.Context, tutorID string) (float64, error)
+	GetStudentBalances(ctx context.Context, courseID string, tutorID string) ([]models.StudentBalance, error)
+	GetMonthlyIncome(ctx context.Context, tutorID string) (models.MonthlyIncome, error)
+
+	Update(ctx context.Context, id string, tutorID string, req models.UpdatePaymentRequest) (models.Payment, error)
+	Void(ctx context.Context, id string, tutorID string, req models.VoidPaymentRequest) (models.Payment, error)
+	Refund(ctx context.Context, id string, tutorID string, req models.RefundPaymentRequest) (models.Payment, error)
+	GetEntries(ctx context.Context, id string, tutorID string) ([]models.PaymentEntry, error)
 }
 
 type paymentService struct {
-	repo       repository.PaymentRepository
-	courseRepo repository.CourseRepository
+	repo           repository.PaymentRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+	rateRepo       repository.ExchangeRateRepository
+	packageRepo    repository.PackageRepository
 }
 
-func NewPaymentService(repo repository.PaymentRepository, courseRepo repository.CourseRepository) PaymentService {
-	return &paymentService{repo: repo, courseRepo: courseRepo}
+func NewPaymentService(repo repository.PaymentRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, rateRepo repository.ExchangeRateRepository, packageRepo repository.PackageRepository) PaymentService {
+	return &paymentService{repo: repo, courseRepo: courseRepo, enrollmentRepo: enrollmentRepo, rateRepo: rateRepo, packageRepo: packageRepo}
 }
 
+// Create records a payment. In a group course it may name the enrolled student who
+// paid; an individual course's payments always belong to its student.
 func (s *paymentService) Create(ctx context.Context, req models.CreatePaymentRequest, tutorID string) (models.Payment, error) {
-	if _, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID); err != nil {
+	course, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID)
+	if err != nil {
 		return models.Payment{}, fmt.Errorf("course: %w", ErrNotFound)
 	}
+	if _, err := courseStudent(ctx, s.enrollmentRepo, course, req.StudentID, false); err != nil {
+		return models.Payment{}, err
+	}
+	if course.StudentID != nil {
+		// The course already says whose payment it is.
+		req.StudentID = nil
+	}
 	return s.repo.Create(ctx, req)
 }
 
@@ -47,13 +69,219 @@ func (s *paymentService) GetAllByTutorPaged(ctx context.Context, tutorID string,
 	return s.repo.GetAllByTutorPaged(ctx, tutorID, p)
 }
 
+// GetBalance also details the course's packages and subscription months; their
+// expired lessons no longer remain.
 func (s *paymentService) GetBalance(ctx context.Context, courseID string, tutorID string) (models.CourseBalance, error) {
-	if _, err := s.courseRepo.GetByID(ctx, courseID, tutorID); err != nil {
+	course, err := s.courseRepo.GetByID(ctx, courseID, tutorID)
+	if err != nil {
 		return models.CourseBalance{}, fmt.Errorf("course: %w", ErrNotFound)
 	}
-	return s.repo.GetBalance(ctx, courseID)
+	balance, err := s.repo.GetBalance(ctx, courseID)
+	if err != nil {
+		return models.CourseBalance{}, err
+	}
+	credits, err := allocatedCredits(ctx, s.packageRepo, course)
+	if err != nil {
+		return models.CourseBalance{}, err
+	}
+	balance.Packages = credits
+	for _, c := range credits {
+		balance.LessonsExpired += c.LessonsExpired
+	}
+	balance.LessonsRemaining -= balance.LessonsExpired
+	return balance, nil
+}
+
+// GetStudentBalances lists the members of a group course with their own balances.
+func (s *paymentService) GetStudentBalances(ctx context.Context, courseID string, tutorID string) ([]models.StudentBalance, error) {
+	course, err := s.courseRepo.GetByID(ctx, courseID, tutorID)
+	if err != nil {
+		return nil, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	if course.StudentID != nil {
+		return nil, fmt.Errorf("individual course: %w", ErrForbidden)
+	}
+	balances, err := s.repo.GetStudentBalances(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := allocatedCredits(ctx, s.packageRepo, course)
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		b := &balances[i]
+		b.Packages = []models.LessonCredits{}
+		for _, c := range credits {
+			if c.StudentID != nil && *c.StudentID == b.StudentID {
+				b.Packages = append(b.Packages, c)
+				b.LessonsExpired += c.LessonsExpired
+			}
+		}
+		b.LessonsRemaining -= b.LessonsExpired
+	}
+	return balances, nil
+}
+
+// courseStudent works out whose a payment or bill on the course is. On an
+// individual course it is the course's student, whom studentID may only repeat;
+// on a group course it is the member studentID names, or no one ("") when
+// studentID is nil and required is false.
+func courseStudent(ctx context.Context, enrollmentRepo repository.EnrollmentRepository, course models.Course, studentID *string, required bool) (string, error) {
+	switch {
+	case course.StudentID != nil:
+		if studentID != nil && *studentID != *course.StudentID {
+			return "", fmt.Errorf("student_id is not the course's student: %w", ErrBadRequest)
+		}
+		return *course.StudentID, nil
+	case studentID == nil:
+		if required {
+			return "", fmt.Errorf("student_id is required for a group course: %w", ErrBadRequest)
+		}
+		return "", nil
+	}
+	members, err := enrollmentRepo.GetByCourse(ctx, course.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range members {
+		if m.StudentID == *studentID {
+			return *studentID, nil
+		}
+	}
+	return "", fmt.Errorf("student is not enrolled in the course: %w", ErrBadRequest)
+}
+
+// allocatedCredits lists the course's credits as charged lessons have used them:
+// a group course's by each member's attendance.
+func allocatedCredits(ctx context.Context, packageRepo repository.PackageRepository, course models.Course) ([]models.LessonCredits, error) {
+	credits, err := packageRepo.GetCredits(ctx, course.ID)
+	if err != nil || len(credits) == 0 {
+		return credits, err
+	}
+	var charges []models.LessonCharge
+	if course.StudentID != nil {
+		charges, err = packageRepo.GetCharges(ctx, course.ID)
+	} else {
+		charges, err = packageRepo.GetStudentCharges(ctx, course.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return allocateCredits(credits, charges, time.Now()), nil
+}
+
+// GetMonthlyIncome totals the month's income in the tutor's home currency,
+// converting other currencies at the tutor's exchange rates.
+func (s *paymentService) GetMonthlyIncome(ctx context.Context, tutorID string) (models.MonthlyIncome, error) {
+	income, err := s.repo.GetMonthlyIncome(ctx, tutorID)
+	if err != nil {
+		return models.MonthlyIncome{}, err
+	}
+	rates, err := s.rateRepo.GetAll(ctx, tutorID)
+	if err != nil {
+		return models.MonthlyIncome{}, err
+	}
+	return convertIncome(income, rates), nil
+}
+
+// convertIncome fills Total and Earned from ByCurrency. A currency without a rate
+// is listed in Unconverted rather than guessed at.
+func convertIncome(income models.MonthlyIncome, rates []models.ExchangeRate) models.MonthlyIncome {
+	byCurrency := make(map[string]money.Rate, len(rates))
+	for _, r := range rates {
+		byCurrency[r.Currency] = r.Rate
+	}
+	income.Total, income.Earned = 0, 0
+	income.Unconverted = []string{}
+	for _, ci := range income.ByCurrency {
+		if ci.Currency == income.Currency {
+			income.Total += ci.Total
+			income.Earned += ci.Earned
+			continue
+		}
+		rate, ok := byCurrency[ci.Currency]
+		if !ok {
+			income.Unconverted = append(income.Unconverted, ci.Currency)
+			continue
+		}
+		income.Total += ci.Total.Convert(rate)
+		income.Earned += ci.Earned.Convert(rate)
+	}
+	return income
+}
+
+// paymentError maps a lost race with another change to the same payment.
+func paymentError(err error) error {
+	if errors.Is(err, repository.ErrPaymentChanged) {
+		return fmt.Errorf("payment was changed meanwhile: %w", ErrConflict)
+	}
+	return err
 }
 
-func (s *paymentService) GetMonthlyIncome(ctx context.Context, tutorID string) (float64, error) {
-	return s.repo.GetMonthlyIncome(ctx, tutorID)
+// Update corrects a mistyped payment. Refunds already made stand, so the
+// corrected payment must still cover them.
+func (s *paymentService) Update(ctx context.Context, id string, tutorID string, req models.UpdatePaymentRequest) (models.Payment, error) {
+	current, err := s.repo.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Payment{}, fmt.Errorf("payment: %w", ErrNotFound)
+	}
+	if current.Voided {
+		return models.Payment{}, fmt.Errorf("payment is voided: %w", ErrConflict)
+	}
+	if req.Amount < current.Refunded || req.LessonsCount < current.LessonsRefunded {
+		return models.Payment{}, fmt.Errorf("payment can't be less than what was refunded: %w", ErrBadRequest)
+	}
+	payment, err := s.repo.Correct(ctx, current, req)
+	return payment, paymentError(err)
+}
+
+// Void cancels a payment recorded by mistake. A payment with refunds was real, so
+// it can only be refunded in full.
+func (s *paymentService) Void(ctx context.Context, id string, tutorID string, req models.VoidPaymentRequest) (models.Payment, error) {
+	current, err := s.repo.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Payment{}, fmt.Errorf("payment: %w", ErrNotFound)
+	}
+	if current.Voided {
+		return models.Payment{}, fmt.Errorf("payment is already voided: %w", ErrConflict)
+	}
+	if current.Refunded > 0 || current.LessonsRefunded > 0 {
+		return models.Payment{}, fmt.Errorf("payment has refunds: %w", ErrConflict)
+	}
+	payment, err := s.repo.Void(ctx, current, req.Reason)
+	return payment, paymentError(err)
+}
+
+// Refund gives back up to what is left of the payment, in money and in lessons.
+func (s *paymentService) Refund(ctx context.Context, id string, tutorID string, req models.RefundPaymentRequest) (models.Payment, error) {
+	current, err := s.repo.GetByID(ctx, id, tutorID)
+	if err != nil {
+		return models.Payment{}, fmt.Errorf("payment: %w", ErrNotFound)
+	}
+	if current.Voided {
+		return models.Payment{}, fmt.Errorf("payment is voided: %w", ErrConflict)
+	}
+	if req.Amount > current.Amount-current.Refunded {
+		return models.Payment{}, fmt.Errorf("refund exceeds the %s left of the payment: %w", current.Amount-current.Refunded, ErrBadRequest)
+	}
+	if req.LessonsCount > current.LessonsCount-current.LessonsRefunded {
+		return models.Payment{}, fmt.Errorf("refund exceeds the %d lessons left of the payment: %w", current.LessonsCount-current.LessonsRefunded, ErrBadRequest)
+	}
+	if req.RefundedAt == nil {
+		now := time.Now()
+		req.RefundedAt = &now
+	}
+	if req.RefundedAt.Before(current.PaidAt) {
+		return models.Payment{}, fmt.Errorf("refunded_at is before the payment: %w", ErrBadRequest)
+	}
+	payment, err := s.repo.Refund(ctx, current, req)
+	return payment, paymentError(err)
+}
+
+func (s *paymentService) GetEntries(ctx context.Context, id string, tutorID string) ([]models.PaymentEntry, error) {
+	if _, err := s.repo.GetByID(ctx, id, tutorID); err != nil {
+		return nil, fmt.Errorf("payment: %w", ErrNotFound)
+	}
+	return s.repo.GetEntries(ctx, id)
 }
@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func paymentRepoWith(current models.Payment) *mockPaymentRepo {
+	m := new(mockPaymentRepo)
+	m.On("GetByID", mock.Anything, current.ID, tutorID).Return(current, nil)
+	return m
+}
+
+func refundedPayment() models.Payment {
+	p := expectedPayment
+	p.Refunded = money.FromUnits(1000)
+	p.LessonsRefunded = 2
+	return p
+}
+
+// Update
+
+func TestPaymentUpdate_Success(t *testing.T) {
+	payRepo := paymentRepoWith(expectedPayment)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	req := models.UpdatePaymentRequest{Amount: money.FromUnits(4500), LessonsCount: 9, PaidAt: expectedPayment.PaidAt, Reason: "typo"}
+	corrected := expectedPayment
+	corrected.Amount, corrected.LessonsCount = req.Amount, req.LessonsCount
+	payRepo.On("Correct", mock.Anything, expectedPayment, req).Return(corrected, nil)
+
+	got, err := svc.Update(context.Background(), expectedPayment.ID, tutorID, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, corrected, got)
+	payRepo.AssertExpectations(t)
+}
+
+func TestPaymentUpdate_BelowRefunded(t *testing.T) {
+	current := refundedPayment()
+	payRepo := paymentRepoWith(current)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	req := models.UpdatePaymentRequest{Amount: money.FromUnits(500), LessonsCount: 9, PaidAt: current.PaidAt}
+
+	_, err := svc.Update(context.Background(), current.ID, tutorID, req)
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	payRepo.AssertNotCalled(t, "Correct", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentUpdate_ChangedMeanwhile(t *testing.T) {
+	payRepo := paymentRepoWith(expectedPayment)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	req := models.UpdatePaymentRequest{Amount: money.FromUnits(4500), LessonsCount: 9, PaidAt: expectedPayment.PaidAt}
+	payRepo.On("Correct", mock.Anything, expectedPayment, req).Return(models.Payment{}, repository.ErrPaymentChanged)
+
+	_, err := svc.Update(context.Background(), expectedPayment.ID, tutorID, req)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestPaymentUpdate_NotFound(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	payRepo.On("GetByID", mock.Anything, "payment-uuid-9", tutorID).Return(models.Payment{}, errors.New("no rows"))
+
+	_, err := svc.Update(context.Background(), "payment-uuid-9", tutorID, models.UpdatePaymentRequest{})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// Void
+
+func TestPaymentVoid_Success(t *testing.T) {
+	payRepo := paymentRepoWith(expectedPayment)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	voided := expectedPayment
+	voided.Voided = true
+	payRepo.On("Void", mock.Anything, expectedPayment, "duplicate").Return(voided, nil)
+
+	got, err := svc.Void(context.Background(), expectedPayment.ID, tutorID, models.VoidPaymentRequest{Reason: "duplicate"})
+
+	assert.NoError(t, err)
+	assert.True(t, got.Voided)
+}
+
+func TestPaymentVoid_Refunded(t *testing.T) {
+	current := refundedPayment()
+	payRepo := paymentRepoWith(current)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	_, err := svc.Void(context.Background(), current.ID, tutorID, models.VoidPaymentRequest{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	payRepo.AssertNotCalled(t, "Void", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentVoid_AlreadyVoided(t *testing.T) {
+	current := expectedPayment
+	current.Voided = true
+	payRepo := paymentRepoWith(current)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	_, err := svc.Void(context.Background(), current.ID, tutorID, models.VoidPaymentRequest{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+// Refund
+
+func TestPaymentRefund_DefaultsToNow(t *testing.T) {
+	payRepo := paymentRepoWith(expectedPayment)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	before := time.Now()
+	payRepo.On("Refund", mock.Anything, expectedPayment, mock.MatchedBy(func(req models.RefundPaymentRequest) bool {
+		return req.RefundedAt != nil && !req.RefundedAt.Before(before) && req.Amount == money.FromUnits(1000)
+	})).Return(refundedPayment(), nil)
+
+	got, err := svc.Refund(context.Background(), expectedPayment.ID, tutorID, models.RefundPaymentRequest{Amount: money.FromUnits(1000), LessonsCount: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.FromUnits(1000), got.Refunded)
+	payRepo.AssertExpectations(t)
+}
+
+func TestPaymentRefund_ExceedsRemainder(t *testing.T) {
+	current := refundedPayment()
+	payRepo := paymentRepoWith(current)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	// 5000 paid, 1000 refunded: 4000 left.
+	_, err := svc.Refund(context.Background(), current.ID, tutorID, models.RefundPaymentRequest{Amount: money.FromUnits(4001)})
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+
+	// 12 lessons paid, 2 refunded: 10 left.
+	_, err = svc.Refund(context.Background(), current.ID, tutorID, models.RefundPaymentRequest{Amount: money.FromUnits(100), LessonsCount: 11})
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+
+	payRepo.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentRefund_BeforePayment(t *testing.T) {
+	payRepo := paymentRepoWith(expectedPayment)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	at := expectedPayment.PaidAt.Add(-time.Hour)
+	_, err := svc.Refund(context.Background(), expectedPayment.ID, tutorID, models.RefundPaymentRequest{Amount: money.FromUnits(100), RefundedAt: &at})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestPaymentRefund_Voided(t *testing.T) {
+	current := expectedPayment
+	current.Voided = true
+	payRepo := paymentRepoWith(current)
+	svc := newPaymentSvc(payRepo, new(mockCourseRepo))
+
+	_, err := svc.Refund(context.Background(), current.ID, tutorID, models.RefundPaymentRequest{Amount: money.FromUnits(100)})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
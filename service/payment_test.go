@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 	"tutorgo/models"
+	"tutorgo/money"
 	"tutorgo/service"
 
 	"github.com/stretchr/testify/assert"
@@ -41,9 +42,57 @@ func (m *mockPaymentRepo) GetBalance(ctx context.Context, courseID string) (mode
 	return args.Get(0).(models.CourseBalance), args.Error(1)
 }
 
-func (m *mockPaymentRepo) GetMonthlyIncome(ctx context.Context, tutorID string) (float64, error) {
+func (m *mockPaymentRepo) GetMonthlyIncome(ctx context.Context, tutorID string) (models.MonthlyIncome, error) {
 	args := m.Called(ctx, tutorID)
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(models.MonthlyIncome), args.Error(1)
+}
+
+func (m *mockPaymentRepo) GetByID(ctx context.Context, id string, tutorID string) (models.Payment, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+
+func (m *mockPaymentRepo) Correct(ctx context.Context, current models.Payment, req models.UpdatePaymentRequest) (models.Payment, error) {
+	args := m.Called(ctx, current, req)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+
+func (m *mockPaymentRepo) Void(ctx context.Context, current models.Payment, reason string) (models.Payment, error) {
+	args := m.Called(ctx, current, reason)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+
+func (m *mockPaymentRepo) Refund(ctx context.Context, current models.Payment, req models.RefundPaymentRequest) (models.Payment, error) {
+	args := m.Called(ctx, current, req)
+	return args.Get(0).(models.Payment), args.Error(1)
+}
+
+func (m *mockPaymentRepo) GetEntries(ctx context.Context, paymentID string) ([]models.PaymentEntry, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Get(0).([]models.PaymentEntry), args.Error(1)
+}
+
+func (m *mockPaymentRepo) GetStudentBalances(ctx context.Context, courseID string) ([]models.StudentBalance, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).([]models.StudentBalance), args.Error(1)
+}
+
+type mockEnrollmentRepo struct {
+	mock.Mock
+}
+
+func (m *mockEnrollmentRepo) Add(ctx context.Context, courseID string, studentID string) (models.CourseEnrollment, error) {
+	args := m.Called(ctx, courseID, studentID)
+	return args.Get(0).(models.CourseEnrollment), args.Error(1)
+}
+
+func (m *mockEnrollmentRepo) Remove(ctx context.Context, courseID string, studentID string) error {
+	return m.Called(ctx, courseID, studentID).Error(0)
+}
+
+func (m *mockEnrollmentRepo) GetByCourse(ctx context.Context, courseID string) ([]models.CourseEnrollment, error) {
+	args := m.Called(ctx, courseID)
+	return args.Get(0).([]models.CourseEnrollment), args.Error(1)
 }
 
 var (
@@ -52,15 +101,15 @@ var (
 
 	paymentReq = models.CreatePaymentRequest{
 		CourseID:     courseID,
-		Amount:       5000,
+		Amount:       money.FromUnits(5000),
 		LessonsCount: 12,
 		PaidAt:       time.Date(2001, time.September, 11, 0, 0, 0, 0, time.UTC),
 	}
 
 	expectedPayment = models.Payment{
 		ID:           "payment-uuid-1",
-		CourseID:     courseID,
-		Amount:       5000,
+		CourseID:     &courseID,
+		Amount:       money.FromUnits(5000),
 		LessonsCount: 12,
 		PaidAt:       time.Date(2001, time.September, 11, 0, 0, 0, 0, time.UTC),
 	}
@@ -72,7 +121,11 @@ var (
 )
 
 func newPaymentSvc(payRepo *mockPaymentRepo, courseRepo *mockCourseRepo) service.PaymentService {
-	return service.NewPaymentService(payRepo, courseRepo)
+	return newGroupPaymentSvc(payRepo, courseRepo, new(mockEnrollmentRepo))
+}
+
+func newGroupPaymentSvc(payRepo *mockPaymentRepo, courseRepo *mockCourseRepo, enrollmentRepo *mockEnrollmentRepo) service.PaymentService {
+	return service.NewPaymentService(payRepo, courseRepo, enrollmentRepo, new(mockExchangeRateRepo), noPackages())
 }
 
 // Create
@@ -173,6 +226,7 @@ func TestPaymentGetBalance_Success(t *testing.T) {
 		LessonsPaid:      10,
 		LessonsCompleted: 3,
 		LessonsRemaining: 7,
+		Packages:         []models.LessonCredits{},
 	}
 	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
 	payRepo.On("GetBalance", mock.Anything, courseID).Return(expected, nil)
@@ -199,3 +253,144 @@ func TestPaymentGetBalance_CourseNotFound(t *testing.T) {
 	payRepo.AssertNotCalled(t, "GetBalance")
 	courseRepo.AssertExpectations(t)
 }
+
+// Group courses
+
+var groupCourse = models.Course{ID: courseID, TutorID: tutorID}
+
+func TestPaymentCreate_GroupMember(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	enrollmentRepo := new(mockEnrollmentRepo)
+	svc := newGroupPaymentSvc(payRepo, courseRepo, enrollmentRepo)
+
+	studentID := "student-uuid-2"
+	req := paymentReq
+	req.StudentID = &studentID
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(groupCourse, nil)
+	enrollmentRepo.On("GetByCourse", mock.Anything, courseID).Return([]models.CourseEnrollment{{CourseID: courseID, StudentID: studentID}}, nil)
+	payRepo.On("Create", mock.Anything, req).Return(expectedPayment, nil)
+
+	_, err := svc.Create(context.Background(), req, tutorID)
+
+	assert.NoError(t, err)
+	payRepo.AssertExpectations(t)
+}
+
+func TestPaymentCreate_NotAGroupMember(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	enrollmentRepo := new(mockEnrollmentRepo)
+	svc := newGroupPaymentSvc(payRepo, courseRepo, enrollmentRepo)
+
+	stranger := "student-uuid-9"
+	req := paymentReq
+	req.StudentID = &stranger
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(groupCourse, nil)
+	enrollmentRepo.On("GetByCourse", mock.Anything, courseID).Return([]models.CourseEnrollment{{CourseID: courseID, StudentID: "student-uuid-2"}}, nil)
+
+	_, err := svc.Create(context.Background(), req, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	payRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPaymentCreate_IndividualCourseOtherStudent(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newPaymentSvc(payRepo, courseRepo)
+
+	own, other := "student-uuid-1", "student-uuid-2"
+	course := groupCourse
+	course.StudentID = &own
+	req := paymentReq
+	req.StudentID = &other
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(course, nil)
+
+	_, err := svc.Create(context.Background(), req, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestPaymentGetStudentBalances(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newPaymentSvc(payRepo, courseRepo)
+
+	balances := []models.StudentBalance{{StudentID: "student-uuid-2", LessonsPaid: 8, LessonsCompleted: 2.5, LessonsRemaining: 5.5}}
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(groupCourse, nil)
+	payRepo.On("GetStudentBalances", mock.Anything, courseID).Return(balances, nil)
+
+	got, err := svc.GetStudentBalances(context.Background(), courseID, tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, balances, got)
+}
+
+func TestPaymentGetStudentBalances_IndividualCourse(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newPaymentSvc(payRepo, courseRepo)
+
+	own := "student-uuid-1"
+	course := groupCourse
+	course.StudentID = &own
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(course, nil)
+
+	_, err := svc.GetStudentBalances(context.Background(), courseID, tutorID)
+
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	payRepo.AssertNotCalled(t, "GetStudentBalances", mock.Anything, mock.Anything)
+}
+
+// GetMonthlyIncome
+
+func mustRate(t *testing.T, s string) money.Rate {
+	t.Helper()
+	r, err := money.ParseRate(s)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestPaymentGetMonthlyIncome_ConvertsIntoHomeCurrency(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	rateRepo := new(mockExchangeRateRepo)
+	svc := service.NewPaymentService(payRepo, new(mockCourseRepo), new(mockEnrollmentRepo), rateRepo, noPackages())
+
+	payRepo.On("GetMonthlyIncome", mock.Anything, tutorID).Return(models.MonthlyIncome{
+		Currency: "KZT",
+		ByCurrency: []models.CurrencyIncome{
+			{Currency: "EUR", Total: money.FromUnits(100), Earned: money.FromUnits(40)},
+			{Currency: "GBP", Total: money.FromUnits(10), Earned: 0},
+			{Currency: "KZT", Total: money.FromUnits(15000), Earned: money.FromUnits(9000)},
+		},
+	}, nil)
+	rateRepo.On("GetAll", mock.Anything, tutorID).Return([]models.ExchangeRate{
+		{Currency: "EUR", Rate: mustRate(t, "520.25")},
+	}, nil)
+
+	income, err := svc.GetMonthlyIncome(context.Background(), tutorID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "KZT", income.Currency)
+	assert.Equal(t, money.FromUnits(15000+52025), income.Total)
+	assert.Equal(t, money.FromUnits(9000+20810), income.Earned)
+	assert.Equal(t, []string{"GBP"}, income.Unconverted)
+	assert.Len(t, income.ByCurrency, 3)
+}
+
+func TestPaymentGetMonthlyIncome_NoIncome(t *testing.T) {
+	payRepo := new(mockPaymentRepo)
+	rateRepo := new(mockExchangeRateRepo)
+	svc := service.NewPaymentService(payRepo, new(mockCourseRepo), new(mockEnrollmentRepo), rateRepo, noPackages())
+
+	payRepo.On("GetMonthlyIncome", mock.Anything, tutorID).Return(models.MonthlyIncome{Currency: "KZT", ByCurrency: []models.CurrencyIncome{}}, nil)
+	rateRepo.On("GetAll", mock.Anything, tutorID).Return([]models.ExchangeRate{}, nil)
+
+	income, err := svc.GetMonthlyIncome(context.Background(), tutorID)
+
+	assert.NoError(t, err)
+	assert.Zero(t, income.Total)
+	assert.Zero(t, income.Earned)
+	assert.Empty(t, income.Unconverted)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"tutorgo/mail"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type PortalService interface {
+	Invite(ctx context.Context, tutorID string, studentID string, req models.InvitePortalRequest) (models.PortalAccount, error)
+	GetAccounts(ctx context.Context, tutorID string, studentID string) ([]models.PortalAccount, error)
+	DeleteAccount(ctx context.Context, tutorID string, studentID string, id string) error
+	AcceptInvite(ctx context.Context, token string, passwordHash string) error
+	GetLogins(ctx context.Context, email string) ([]models.PortalLogin, error)
+	IsActive(ctx context.Context, id string) (bool, error)
+	GetProfile(ctx context.Context, id string) (models.PortalProfile, error)
+	GetLessons(ctx context.Context, studentID string, from string, to string) ([]models.PortalLesson, error)
+	GetBalances(ctx context.Context, studentID string) ([]models.PortalBalance, error)
+	GetHomework(ctx context.Context, studentID string) ([]models.PortalLesson, error)
+}
+
+type portalService struct {
+	repo        repository.PortalRepository
+	studentRepo repository.StudentRepository
+	paymentRepo repository.PaymentRepository
+	packageRepo repository.PackageRepository
+	mailer      mail.Sender
+	signer      linkSigner
+	appURL      string
+}
+
+// NewPortalService signs invitations with secret and links to pages of the app
+// at appURL.
+func NewPortalService(repo repository.PortalRepository, studentRepo repository.StudentRepository, paymentRepo repository.PaymentRepository, packageRepo repository.PackageRepository, mailer mail.Sender, secret string, appURL string) PortalService {
+	return &portalService{
+		repo:        repo,
+		studentRepo: studentRepo,
+		paymentRepo: paymentRepo,
+		packageRepo: packageRepo,
+		mailer:      mailer,
+		signer:      linkSigner{secret: []byte(secret)},
+		appURL:      strings.TrimSuffix(appURL, "/"),
+	}
+}
+
+const (
+	portalInvitePurpose = "portal_invite"
+	portalInviteTTL     = 7 * 24 * time.Hour
+	// maxPortalRange bounds the lessons listed at once.
+	maxPortalRange = 92 * 24 * time.Hour
+	// portalHomeworkLimit is how many lessons with homework are listed.
+	portalHomeworkLimit = 50
+)
+
+// Invite creates a student's or parent's account and mails them a link to set
+// their password. Inviting the same email to the same student again, before it
+// is accepted, sends a fresh link; an email may be invited for several students.
+// If the mail can't be sent the invitation still stands, marked MailFailed, so
+// the tutor can simply invite again.
+func (s *portalService) Invite(ctx context.Context, tutorID string, studentID string, req models.InvitePortalRequest) (models.PortalAccount, error) {
+	student, err := s.studentRepo.GetByID(ctx, studentID, tutorID)
+	if err != nil {
+		return models.PortalAccount{}, fmt.Errorf("student: %w", ErrNotFound)
+	}
+	account, err := s.repo.CreateAccount(ctx, studentID, req)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return models.PortalAccount{}, fmt.Errorf("this email already has a portal account for the student: %w", ErrConflict)
+	}
+	if err != nil {
+		return models.PortalAccount{}, err
+	}
+
+	token := s.signer.sign(portalInvitePurpose, account.ID, time.Now().Add(portalInviteTTL))
+	greeting := "Hello,"
+	if account.Name != "" {
+		greeting = "Hello " + account.Name + ","
+	}
+	whose := "your"
+	if account.Role == models.RoleParent {
+		whose = student.FirstName + "'s"
+	}
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      account.Email,
+		Subject: "You're invited to TutorGo",
+		Body: greeting + "\n\n" +
+			"You've been invited to see " + whose + " lessons, homework and balance in TutorGo. " +
+			"To choose a password and sign in, follow the link below:\n\n" +
+			s.appURL + "/portal/invite?token=" + url.QueryEscape(token) + "\n\n" +
+			"The link works for 7 days.\n",
+	}); err != nil {
+		account.MailFailed = true
+	}
+	return account, nil
+}
+
+func (s *portalService) GetAccounts(ctx context.Context, tutorID string, studentID string) ([]models.PortalAccount, error) {
+	if _, err := s.studentRepo.GetByID(ctx, studentID, tutorID); err != nil {
+		return nil, fmt.Errorf("student: %w", ErrNotFound)
+	}
+	return s.repo.GetAccounts(ctx, studentID)
+}
+
+// DeleteAccount revokes an account, or an invitation not yet accepted; the
+// account's tokens stop working at once.
+func (s *portalService) DeleteAccount(ctx context.Context, tutorID string, studentID string, id string) error {
+	if _, err := s.studentRepo.GetByID(ctx, studentID, tutorID); err != nil {
+		return fmt.Errorf("student: %w", ErrNotFound)
+	}
+	if err := s.repo.DeleteAccount(ctx, id, studentID); err != nil {
+		return fmt.Errorf("portal account: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// AcceptInvite sets the password the account signs in with.
+func (s *portalService) AcceptInvite(ctx context.Context, token string, passwordHash string) error {
+	id, ok := s.signer.parse(portalInvitePurpose, token)
+	if !ok {
+		return errInvalidLink
+	}
+	if err := s.repo.Accept(ctx, id, passwordHash); err != nil {
+		return errInvalidLink
+	}
+	return nil
+}
+
+// GetLogins lists the accounts the email signs in with, one per student.
+func (s *portalService) GetLogins(ctx context.Context, email string) ([]models.PortalLogin, error) {
+	return s.repo.GetLogins(ctx, email)
+}
+
+func (s *portalService) IsActive(ctx context.Context, id string) (bool, error) {
+	return s.repo.IsActive(ctx, id)
+}
+
+func (s *portalService) GetProfile(ctx context.Context, id string) (models.PortalProfile, error) {
+	profile, err := s.repo.GetProfile(ctx, id)
+	if err != nil {
+		return models.PortalProfile{}, fmt.Errorf("portal account: %w", ErrNotFound)
+	}
+	return profile, nil
+}
+
+// GetLessons lists the student's lessons in [from, to). Bare dates are midnights
+// in the student's timezone.
+func (s *portalService) GetLessons(ctx context.Context, studentID string, from string, to string) ([]models.PortalLesson, error) {
+	tz, err := s.repo.GetTimezone(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("student: %w", ErrNotFound)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := parseRange(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	if end.Sub(start) > maxPortalRange {
+		return nil, fmt.Errorf("range is longer than 92 days: %w", ErrBadRequest)
+	}
+	lessons, err := s.repo.GetLessons(ctx, studentID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	s.addCallURLs(lessons)
+	return lessons, nil
+}
+
+func (s *portalService) GetHomework(ctx context.Context, studentID string) ([]models.PortalLesson, error) {
+	lessons, err := s.repo.GetHomework(ctx, studentID, portalHomeworkLimit)
+	if err != nil {
+		return nil, err
+	}
+	s.addCallURLs(lessons)
+	return lessons, nil
+}
+
+// addCallURLs links scheduled lessons to the page that joins their call.
+func (s *portalService) addCallURLs(lessons []models.PortalLesson) {
+	for i := range lessons {
+		if lessons[i].Status == "scheduled" {
+			link := s.appURL + "/join/" + lessons[i].ID
+			lessons[i].CallURL = &link
+		}
+	}
+}
+
+// GetBalances gives the student's balance on each of their courses, as the
+// tutor sees it: on a group course, their own share.
+func (s *portalService) GetBalances(ctx context.Context, studentID string) ([]models.PortalBalance, error) {
+	courses, err := s.repo.GetCourses(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]models.PortalBalance, 0, len(courses))
+	for _, course := range courses {
+		b := models.PortalBalance{CourseID: course.ID, Subject: course.Subject, IsGroup: course.StudentID == nil}
+		if b.IsGroup {
+			members, err := s.paymentRepo.GetStudentBalances(ctx, course.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range members {
+				if m.StudentID == studentID {
+					b.LessonsPaid, b.LessonsCompleted, b.LessonsRemaining = m.LessonsPaid, m.LessonsCompleted, m.LessonsRemaining
+				}
+			}
+		} else {
+			cb, err := s.paymentRepo.GetBalance(ctx, course.ID)
+			if err != nil {
+				return nil, err
+			}
+			b.LessonsPaid, b.LessonsCompleted, b.LessonsRemaining = cb.LessonsPaid, cb.LessonsCompleted, cb.LessonsRemaining
+		}
+
+		credits, err := allocatedCredits(ctx, s.packageRepo, course)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range credits {
+			if !b.IsGroup || (c.StudentID != nil && *c.StudentID == studentID) {
+				b.LessonsExpired += c.LessonsExpired
+			}
+		}
+		b.LessonsRemaining -= b.LessonsExpired
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
@@ -0,0 +1,238 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/mail"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPortalRepo struct {
+	mock.Mock
+}
+
+func (m *mockPortalRepo) CreateAccount(ctx context.Context, studentID string, req models.InvitePortalRequest) (models.PortalAccount, error) {
+	args := m.Called(ctx, studentID, req)
+	return args.Get(0).(models.PortalAccount), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetAccounts(ctx context.Context, studentID string) ([]models.PortalAccount, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]models.PortalAccount), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetAccount(ctx context.Context, id string) (models.PortalAccount, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.PortalAccount), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetLogins(ctx context.Context, email string) ([]models.PortalLogin, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).([]models.PortalLogin), args.Error(1)
+}
+
+func (m *mockPortalRepo) DeleteAccount(ctx context.Context, id string, studentID string) error {
+	return m.Called(ctx, id, studentID).Error(0)
+}
+
+func (m *mockPortalRepo) Accept(ctx context.Context, id string, passwordHash string) error {
+	return m.Called(ctx, id, passwordHash).Error(0)
+}
+
+func (m *mockPortalRepo) IsActive(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetProfile(ctx context.Context, id string) (models.PortalProfile, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.PortalProfile), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetTimezone(ctx context.Context, studentID string) (string, error) {
+	args := m.Called(ctx, studentID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetCourses(ctx context.Context, studentID string) ([]models.Course, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]models.Course), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetLessons(ctx context.Context, studentID string, from time.Time, to time.Time) ([]models.PortalLesson, error) {
+	args := m.Called(ctx, studentID, from, to)
+	return args.Get(0).([]models.PortalLesson), args.Error(1)
+}
+
+func (m *mockPortalRepo) GetHomework(ctx context.Context, studentID string, limit int) ([]models.PortalLesson, error) {
+	args := m.Called(ctx, studentID, limit)
+	return args.Get(0).([]models.PortalLesson), args.Error(1)
+}
+
+const (
+	portalStudentID = "student-uuid-1"
+	portalAccountID = "portal-account-uuid-1"
+)
+
+type portalMocks struct {
+	repo        *mockPortalRepo
+	studentRepo *mockStudentRepo
+	paymentRepo *mockPaymentRepo
+	packageRepo *mockPackageRepo
+	sent        *outbox
+}
+
+func newPortalSvc() (service.PortalService, portalMocks) {
+	m := portalMocks{
+		repo:        new(mockPortalRepo),
+		studentRepo: new(mockStudentRepo),
+		paymentRepo: new(mockPaymentRepo),
+		packageRepo: new(mockPackageRepo),
+		sent:        new(outbox),
+	}
+	svc := service.NewPortalService(m.repo, m.studentRepo, m.paymentRepo, m.packageRepo, m.sent, "test-secret", "https://app.example.com/")
+	return svc, m
+}
+
+func TestPortalInvite_MailsWorkingLink(t *testing.T) {
+	svc, m := newPortalSvc()
+	req := models.InvitePortalRequest{Email: "parent@example.com", Role: models.RoleParent, Name: "Maria"}
+	account := models.PortalAccount{ID: portalAccountID, StudentID: portalStudentID, Role: models.RoleParent, Name: "Maria", Email: req.Email}
+
+	m.studentRepo.On("GetByID", mock.Anything, portalStudentID, tutorID).Return(models.Student{ID: portalStudentID, FirstName: "Anna"}, nil)
+	m.repo.On("CreateAccount", mock.Anything, portalStudentID, req).Return(account, nil)
+	m.repo.On("Accept", mock.Anything, portalAccountID, "new-hash").Return(nil)
+
+	got, err := svc.Invite(context.Background(), tutorID, portalStudentID, req)
+	require.NoError(t, err)
+	assert.Equal(t, account, got)
+
+	require.Len(t, *m.sent, 1)
+	msg := (*m.sent)[0]
+	assert.Equal(t, "parent@example.com", msg.To)
+	assert.Contains(t, msg.Body, "Hello Maria,")
+	assert.Contains(t, msg.Body, "Anna's lessons")
+	assert.Contains(t, msg.Body, "https://app.example.com/portal/invite?token=")
+
+	require.NoError(t, svc.AcceptInvite(context.Background(), mailedToken(t, msg), "new-hash"))
+	m.repo.AssertExpectations(t)
+}
+
+type brokenMailer struct{}
+
+func (brokenMailer) Send(context.Context, mail.Message) error {
+	return errors.New("smtp: connection refused")
+}
+
+func TestPortalInvite_MailFailureKeepsInvitation(t *testing.T) {
+	repo, studentRepo := new(mockPortalRepo), new(mockStudentRepo)
+	svc := service.NewPortalService(repo, studentRepo, new(mockPaymentRepo), new(mockPackageRepo), brokenMailer{}, "test-secret", "https://app.example.com/")
+	req := models.InvitePortalRequest{Email: "parent@example.com", Role: models.RoleParent}
+	account := models.PortalAccount{ID: portalAccountID, StudentID: portalStudentID, Role: models.RoleParent, Email: req.Email}
+
+	studentRepo.On("GetByID", mock.Anything, portalStudentID, tutorID).Return(models.Student{ID: portalStudentID}, nil)
+	repo.On("CreateAccount", mock.Anything, portalStudentID, req).Return(account, nil)
+
+	got, err := svc.Invite(context.Background(), tutorID, portalStudentID, req)
+
+	require.NoError(t, err)
+	assert.True(t, got.MailFailed)
+	assert.Equal(t, portalAccountID, got.ID)
+}
+
+func TestPortalInvite_EmailTaken(t *testing.T) {
+	svc, m := newPortalSvc()
+	req := models.InvitePortalRequest{Email: "taken@example.com", Role: models.RoleStudent}
+
+	m.studentRepo.On("GetByID", mock.Anything, portalStudentID, tutorID).Return(models.Student{ID: portalStudentID}, nil)
+	m.repo.On("CreateAccount", mock.Anything, portalStudentID, req).Return(models.PortalAccount{}, repository.ErrEmailTaken)
+
+	_, err := svc.Invite(context.Background(), tutorID, portalStudentID, req)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	assert.Empty(t, *m.sent)
+}
+
+func TestPortalInvite_OtherTutorsStudent(t *testing.T) {
+	svc, m := newPortalSvc()
+
+	m.studentRepo.On("GetByID", mock.Anything, portalStudentID, tutorID).Return(models.Student{}, errors.New("no rows"))
+
+	_, err := svc.Invite(context.Background(), tutorID, portalStudentID, models.InvitePortalRequest{Email: "a@example.com", Role: models.RoleStudent})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	m.repo.AssertNotCalled(t, "CreateAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortalAcceptInvite_RejectsForeignToken(t *testing.T) {
+	svc, m := newPortalSvc()
+
+	for _, token := range []string{"", "garbage", "portal-account-uuid-1.4102444800.c2lnbmF0dXJl"} {
+		err := svc.AcceptInvite(context.Background(), token, "hash")
+		assert.ErrorIs(t, err, service.ErrBadRequest, token)
+	}
+	m.repo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortalGetLessons_CallURLOnScheduledOnly(t *testing.T) {
+	svc, m := newPortalSvc()
+	from := time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	m.repo.On("GetTimezone", mock.Anything, portalStudentID).Return("UTC", nil)
+	m.repo.On("GetLessons", mock.Anything, portalStudentID, from, to).Return([]models.PortalLesson{
+		{ID: "l1", Status: "completed"},
+		{ID: "l2", Status: "scheduled"},
+	}, nil)
+
+	lessons, err := svc.GetLessons(context.Background(), portalStudentID, "2026-05-01", "2026-06-01")
+
+	require.NoError(t, err)
+	require.Len(t, lessons, 2)
+	assert.Nil(t, lessons[0].CallURL)
+	require.NotNil(t, lessons[1].CallURL)
+	assert.Equal(t, "https://app.example.com/join/l2", *lessons[1].CallURL)
+}
+
+func TestPortalGetLessons_RangeTooLong(t *testing.T) {
+	svc, m := newPortalSvc()
+
+	m.repo.On("GetTimezone", mock.Anything, portalStudentID).Return("UTC", nil)
+
+	_, err := svc.GetLessons(context.Background(), portalStudentID, "2026-01-01", "2026-06-01")
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	m.repo.AssertNotCalled(t, "GetLessons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortalGetBalances_GroupShare(t *testing.T) {
+	svc, m := newPortalSvc()
+	student := portalStudentID
+
+	m.repo.On("GetCourses", mock.Anything, portalStudentID).Return([]models.Course{
+		{ID: "individual", Subject: "Maths", StudentID: &student},
+		{ID: "group", Subject: "English"},
+	}, nil)
+	m.paymentRepo.On("GetBalance", mock.Anything, "individual").Return(models.CourseBalance{LessonsPaid: 10, LessonsCompleted: 4, LessonsRemaining: 6}, nil)
+	m.paymentRepo.On("GetStudentBalances", mock.Anything, "group").Return([]models.StudentBalance{
+		{StudentID: "someone-else", LessonsPaid: 20, LessonsCompleted: 1, LessonsRemaining: 19},
+		{StudentID: portalStudentID, LessonsPaid: 8, LessonsCompleted: 3, LessonsRemaining: 5},
+	}, nil)
+	m.packageRepo.On("GetCredits", mock.Anything, mock.Anything).Return([]models.LessonCredits{}, nil)
+
+	balances, err := svc.GetBalances(context.Background(), portalStudentID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.PortalBalance{
+		{CourseID: "individual", Subject: "Maths", LessonsPaid: 10, LessonsCompleted: 4, LessonsRemaining: 6},
+		{CourseID: "group", Subject: "English", IsGroup: true, LessonsPaid: 8, LessonsCompleted: 3, LessonsRemaining: 5},
+	}, balances)
+}
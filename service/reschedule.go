@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type RescheduleService interface {
+	Reschedule(ctx context.Context, lessonID string, tutorID string, req models.RescheduleLessonRequest, opts models.ScheduleOptions) (models.Reschedule, error)
+	GetByLesson(ctx context.Context, lessonID string, tutorID string) ([]models.Reschedule, error)
+	Withdraw(ctx context.Context, lessonID string, tutorID string) error
+
+	GetPublic(ctx context.Context, token string) (models.PublicReschedule, error)
+	Accept(ctx context.Context, token string) (models.Reschedule, error)
+	Decline(ctx context.Context, token string) error
+}
+
+type rescheduleService struct {
+	repo             repository.RescheduleRepository
+	lessonRepo       repository.LessonRepository
+	scheduleRepo     repository.ScheduleRepository
+	availabilityRepo repository.AvailabilityRepository
+	tutorRepo        repository.TutorRepository
+}
+
+func NewRescheduleService(repo repository.RescheduleRepository, lessonRepo repository.LessonRepository, scheduleRepo repository.ScheduleRepository, availabilityRepo repository.AvailabilityRepository, tutorRepo repository.TutorRepository) RescheduleService {
+	return &rescheduleService{repo: repo, lessonRepo: lessonRepo, scheduleRepo: scheduleRepo, availabilityRepo: availabilityRepo, tutorRepo: tutorRepo}
+}
+
+// Reschedule moves a scheduled lesson to req.ScheduledAt, or with req.Propose
+// sends the new time to the student and leaves the lesson where it is until they
+// accept. Either way the new slot is checked against the schedule first.
+func (s *rescheduleService) Reschedule(ctx context.Context, lessonID string, tutorID string, req models.RescheduleLessonRequest, opts models.ScheduleOptions) (models.Reschedule, error) {
+	lesson, err := s.lessonRepo.GetByIDForTutor(ctx, lessonID, tutorID)
+	if err != nil {
+		return models.Reschedule{}, fmt.Errorf("lesson: %w", ErrNotFound)
+	}
+	if lesson.Status != "scheduled" {
+		return models.Reschedule{}, fmt.Errorf("only scheduled lessons can be rescheduled: %w", ErrConflict)
+	}
+	if req.ScheduledAt.Equal(lesson.ScheduledAt) {
+		return models.Reschedule{}, fmt.Errorf("scheduled_at is the lesson's current time: %w", ErrBadRequest)
+	}
+	if req.Propose && !req.ScheduledAt.After(time.Now()) {
+		return models.Reschedule{}, fmt.Errorf("a proposed time must be in the future: %w", ErrBadRequest)
+	}
+
+	slots := slotsAt([]time.Time{req.ScheduledAt}, lesson.DurationMinutes)
+	warnings, err := slotWarnings(ctx, s.availabilityRepo, s.tutorRepo, tutorID, slots)
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{LessonID: lessonID}, opts); !ok {
+		if err != nil {
+			return models.Reschedule{}, err
+		}
+		return models.Reschedule{Warnings: warnings[0]}, nil
+	}
+
+	var rs models.Reschedule
+	if req.Propose {
+		token, err := newToken()
+		if err != nil {
+			return models.Reschedule{}, err
+		}
+		rs, err = s.repo.Propose(ctx, lessonID, token, req.ScheduledAt, req.Reason, opts.Force)
+		if err == nil {
+			rs.Path = reschedulePath(token)
+		}
+	} else {
+		rs, err = s.repo.Move(ctx, lessonID, req.ScheduledAt, req.Reason, models.TutorActor(tutorID))
+	}
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return models.Reschedule{}, fmt.Errorf("lesson status was changed concurrently: %w", ErrConflict)
+	}
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	rs.Warnings = warnings[0]
+	return rs, nil
+}
+
+func (s *rescheduleService) GetByLesson(ctx context.Context, lessonID string, tutorID string) ([]models.Reschedule, error) {
+	if _, err := s.lessonRepo.GetByIDForTutor(ctx, lessonID, tutorID); err != nil {
+		return nil, fmt.Errorf("lesson: %w", ErrNotFound)
+	}
+	reschedules, err := s.repo.GetByLesson(ctx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	for i, rs := range reschedules {
+		if rs.Status == models.RescheduleProposed && rs.Token != nil {
+			reschedules[i].Path = reschedulePath(*rs.Token)
+		}
+	}
+	return reschedules, nil
+}
+
+func (s *rescheduleService) Withdraw(ctx context.Context, lessonID string, tutorID string) error {
+	if _, err := s.lessonRepo.GetByIDForTutor(ctx, lessonID, tutorID); err != nil {
+		return fmt.Errorf("lesson: %w", ErrNotFound)
+	}
+	if err := s.repo.Withdraw(ctx, lessonID); err != nil {
+		return fmt.Errorf("open reschedule proposal: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *rescheduleService) GetPublic(ctx context.Context, token string) (models.PublicReschedule, error) {
+	rs, err := s.repo.GetPublic(ctx, token)
+	if err != nil {
+		return models.PublicReschedule{}, fmt.Errorf("reschedule: %w", ErrNotFound)
+	}
+	return rs, nil
+}
+
+// Accept moves the lesson to the proposed time. It fails with ErrConflict once the
+// proposal is closed, its time has passed, the lesson has changed since it was
+// proposed, or the slot has been taken in the meantime (unless the tutor forced
+// the proposal into a busy slot).
+func (s *rescheduleService) Accept(ctx context.Context, token string) (models.Reschedule, error) {
+	proposal, err := s.openProposal(ctx, token)
+	if err != nil {
+		return models.Reschedule{}, err
+	}
+	if !proposal.ProposedAt.After(time.Now()) {
+		return models.Reschedule{}, fmt.Errorf("the proposed time has passed: %w", ErrConflict)
+	}
+	rs, err := s.repo.Accept(ctx, token)
+	switch {
+	case errors.Is(err, repository.ErrSlotTaken):
+		return models.Reschedule{}, fmt.Errorf("the proposed time is no longer available: %w", ErrConflict)
+	case errors.Is(err, repository.ErrStatusChanged):
+		return models.Reschedule{}, fmt.Errorf("the lesson has changed since this time was proposed: %w", ErrConflict)
+	}
+	return rs, err
+}
+
+func (s *rescheduleService) Decline(ctx context.Context, token string) error {
+	if _, err := s.openProposal(ctx, token); err != nil {
+		return err
+	}
+	if err := s.repo.Decline(ctx, token); err != nil {
+		return fmt.Errorf("reschedule is no longer open: %w", ErrConflict)
+	}
+	return nil
+}
+
+func (s *rescheduleService) openProposal(ctx context.Context, token string) (models.PublicReschedule, error) {
+	proposal, err := s.GetPublic(ctx, token)
+	if err != nil {
+		return models.PublicReschedule{}, err
+	}
+	if proposal.Status != models.RescheduleProposed {
+		return models.PublicReschedule{}, fmt.Errorf("reschedule is already %s: %w", proposal.Status, ErrConflict)
+	}
+	return proposal, nil
+}
+
+func reschedulePath(token string) string {
+	return "/public/reschedules/" + token
+}
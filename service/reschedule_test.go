@@ -0,0 +1,269 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRescheduleRepo struct {
+	mock.Mock
+}
+
+func (m *mockRescheduleRepo) Move(ctx context.Context, lessonID string, to time.Time, reason string, actor models.Actor) (models.Reschedule, error) {
+	args := m.Called(ctx, lessonID, to, reason, actor)
+	return args.Get(0).(models.Reschedule), args.Error(1)
+}
+
+func (m *mockRescheduleRepo) Propose(ctx context.Context, lessonID string, token string, to time.Time, reason string, force bool) (models.Reschedule, error) {
+	args := m.Called(ctx, lessonID, token, to, reason, force)
+	return args.Get(0).(models.Reschedule), args.Error(1)
+}
+
+func (m *mockRescheduleRepo) Withdraw(ctx context.Context, lessonID string) error {
+	return m.Called(ctx, lessonID).Error(0)
+}
+
+func (m *mockRescheduleRepo) GetByLesson(ctx context.Context, lessonID string) ([]models.Reschedule, error) {
+	args := m.Called(ctx, lessonID)
+	return args.Get(0).([]models.Reschedule), args.Error(1)
+}
+
+func (m *mockRescheduleRepo) GetPublic(ctx context.Context, token string) (models.PublicReschedule, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(models.PublicReschedule), args.Error(1)
+}
+
+func (m *mockRescheduleRepo) Accept(ctx context.Context, token string) (models.Reschedule, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(models.Reschedule), args.Error(1)
+}
+
+func (m *mockRescheduleRepo) Decline(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+const rescheduleToken = "reschedule-token"
+
+func newRescheduleSvc(repo *mockRescheduleRepo, lessonRepo *mockLessonRepo, scheduleRepo *mockScheduleRepo) service.RescheduleService {
+	return service.NewRescheduleService(repo, lessonRepo, scheduleRepo, noWorkingHours(), utcTutor())
+}
+
+func futureLesson() models.Lesson {
+	l := expectedLesson
+	l.ScheduledAt = time.Now().Add(48 * time.Hour).Truncate(time.Minute)
+	return l
+}
+
+// Reschedule
+
+func TestReschedule_MovesRightAway(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	lessonRepo := new(mockLessonRepo)
+	svc := newRescheduleSvc(repo, lessonRepo, freeSchedule())
+
+	lesson := futureLesson()
+	to := lesson.ScheduledAt.Add(24 * time.Hour)
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lesson, nil)
+	repo.On("Move", mock.Anything, lessonID, to, "student is ill", models.TutorActor(tutorID)).
+		Return(models.Reschedule{ID: "rs-1", LessonID: lessonID, OriginalAt: lesson.ScheduledAt, ProposedAt: to, Status: models.RescheduleApplied}, nil)
+
+	rs, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: to, Reason: "student is ill"}, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.RescheduleApplied, rs.Status)
+	assert.Empty(t, rs.Path)
+	repo.AssertExpectations(t)
+}
+
+func TestReschedule_ProposalGetsALink(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	lessonRepo := new(mockLessonRepo)
+	svc := newRescheduleSvc(repo, lessonRepo, freeSchedule())
+
+	lesson := futureLesson()
+	to := lesson.ScheduledAt.Add(24 * time.Hour)
+	var token string
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lesson, nil)
+	repo.On("Propose", mock.Anything, lessonID, mock.AnythingOfType("string"), to, "", false).
+		Run(func(args mock.Arguments) { token = args.String(2) }).
+		Return(models.Reschedule{ID: "rs-1", Status: models.RescheduleProposed}, nil)
+
+	rs, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: to, Propose: true}, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "/public/reschedules/"+token, rs.Path)
+	repo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReschedule_ForcedProposalIsRemembered(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	lessonRepo := new(mockLessonRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newRescheduleSvc(repo, lessonRepo, scheduleRepo)
+
+	lesson := futureLesson()
+	to := lesson.ScheduledAt.Add(2 * time.Hour)
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lesson, nil)
+	repo.On("Propose", mock.Anything, lessonID, mock.AnythingOfType("string"), to, "", true).
+		Return(models.Reschedule{ID: "rs-1", Status: models.RescheduleProposed, Force: true}, nil)
+
+	rs, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: to, Propose: true}, models.ScheduleOptions{Force: true})
+
+	assert.NoError(t, err)
+	assert.True(t, rs.Force)
+	repo.AssertExpectations(t)
+	scheduleRepo.AssertNotCalled(t, "FindConflicts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReschedule_OnlyScheduledLessons(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	lessonRepo := new(mockLessonRepo)
+	svc := newRescheduleSvc(repo, lessonRepo, freeSchedule())
+
+	lesson := futureLesson()
+	lesson.Status = "cancelled"
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lesson, nil)
+
+	_, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: lesson.ScheduledAt.Add(time.Hour)}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReschedule_SameTimeIsRejected(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newRescheduleSvc(new(mockRescheduleRepo), lessonRepo, freeSchedule())
+
+	lesson := futureLesson()
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lesson, nil)
+
+	_, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: lesson.ScheduledAt}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+}
+
+func TestReschedule_Conflict(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	lessonRepo := new(mockLessonRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	svc := newRescheduleSvc(repo, lessonRepo, scheduleRepo)
+
+	lesson := futureLesson()
+	to := lesson.ScheduledAt.Add(2 * time.Hour)
+	conflicts := []models.OccurrenceConflicts{{
+		ScheduledAt:     to,
+		DurationMinutes: lesson.DurationMinutes,
+		Conflicts:       []models.ScheduleConflict{{Kind: "lesson", ID: "lesson-uuid-2", Title: "Physics"}},
+	}}
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(lesson, nil)
+	scheduleRepo.On("FindConflicts", mock.Anything, tutorID, mock.Anything, models.ConflictExclusion{LessonID: lessonID}).Return(conflicts, nil)
+
+	_, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: to}, models.ScheduleOptions{})
+
+	var conflictErr *service.ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	repo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReschedule_NotFound(t *testing.T) {
+	lessonRepo := new(mockLessonRepo)
+	svc := newRescheduleSvc(new(mockRescheduleRepo), lessonRepo, freeSchedule())
+
+	lessonRepo.On("GetByIDForTutor", mock.Anything, lessonID, tutorID).Return(models.Lesson{}, errors.New("no rows"))
+
+	_, err := svc.Reschedule(context.Background(), lessonID, tutorID, models.RescheduleLessonRequest{ScheduledAt: time.Now().Add(time.Hour)}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// Accept / Decline
+
+func openProposal(at time.Time) models.PublicReschedule {
+	return models.PublicReschedule{ID: "rs-1", LessonID: lessonID, TutorID: tutorID, ProposedAt: at, Status: models.RescheduleProposed}
+}
+
+func TestRescheduleAccept_Success(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	svc := newRescheduleSvc(repo, new(mockLessonRepo), freeSchedule())
+
+	to := time.Now().Add(24 * time.Hour)
+	repo.On("GetPublic", mock.Anything, rescheduleToken).Return(openProposal(to), nil)
+	repo.On("Accept", mock.Anything, rescheduleToken).Return(models.Reschedule{ID: "rs-1", ProposedAt: to, Status: models.RescheduleAccepted}, nil)
+
+	rs, err := svc.Accept(context.Background(), rescheduleToken)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.RescheduleAccepted, rs.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestRescheduleAccept_Closed(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	svc := newRescheduleSvc(repo, new(mockLessonRepo), freeSchedule())
+
+	proposal := openProposal(time.Now().Add(24 * time.Hour))
+	proposal.Status = models.RescheduleWithdrawn
+	repo.On("GetPublic", mock.Anything, rescheduleToken).Return(proposal, nil)
+
+	_, err := svc.Accept(context.Background(), rescheduleToken)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything)
+}
+
+func TestRescheduleAccept_TimeHasPassed(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	svc := newRescheduleSvc(repo, new(mockLessonRepo), freeSchedule())
+
+	repo.On("GetPublic", mock.Anything, rescheduleToken).Return(openProposal(time.Now().Add(-time.Hour)), nil)
+
+	_, err := svc.Accept(context.Background(), rescheduleToken)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything)
+}
+
+func TestRescheduleAccept_SlotTaken(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	svc := newRescheduleSvc(repo, new(mockLessonRepo), freeSchedule())
+
+	repo.On("GetPublic", mock.Anything, rescheduleToken).Return(openProposal(time.Now().Add(24*time.Hour)), nil)
+	repo.On("Accept", mock.Anything, rescheduleToken).Return(models.Reschedule{}, repository.ErrSlotTaken)
+
+	_, err := svc.Accept(context.Background(), rescheduleToken)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+}
+
+func TestRescheduleAccept_UnknownToken(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	svc := newRescheduleSvc(repo, new(mockLessonRepo), freeSchedule())
+
+	repo.On("GetPublic", mock.Anything, "nope").Return(models.PublicReschedule{}, errors.New("no rows"))
+
+	_, err := svc.Accept(context.Background(), "nope")
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestRescheduleDecline_Success(t *testing.T) {
+	repo := new(mockRescheduleRepo)
+	svc := newRescheduleSvc(repo, new(mockLessonRepo), freeSchedule())
+
+	repo.On("GetPublic", mock.Anything, rescheduleToken).Return(openProposal(time.Now().Add(24*time.Hour)), nil)
+	repo.On("Decline", mock.Anything, rescheduleToken).Return(nil)
+
+	err := svc.Decline(context.Background(), rescheduleToken)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+// checkSchedule decides whether a write that occupies slots may go ahead. Conflicts
+// come back as a *ConflictError unless opts.Force is set; a dry run never proceeds
+// and reports conflicts the same way. Slots that overlap each other are reported
+// before the tutor's calendar is looked at.
+func checkSchedule(ctx context.Context, repo repository.ScheduleRepository, tutorID string, slots []models.TimeSlot, exclude models.ConflictExclusion, opts models.ScheduleOptions) (bool, error) {
+	if opts.Force && !opts.DryRun {
+		return true, nil
+	}
+	if overlaps := overlappingSlots(slots); len(overlaps) > 0 {
+		return false, &ConflictError{Occurrences: overlaps}
+	}
+	conflicts, err := repo.FindConflicts(ctx, tutorID, slots, exclude)
+	if err != nil {
+		return false, err
+	}
+	if len(conflicts) > 0 {
+		return false, &ConflictError{Occurrences: conflicts}
+	}
+	return !opts.DryRun, nil
+}
+
+// overlappingSlots reports the requested slots that overlap an earlier slot of the
+// same request. After sorting by start it is enough to compare each slot with the
+// one reaching furthest so far; intervals are half-open like in FindConflicts.
+func overlappingSlots(slots []models.TimeSlot) []models.OccurrenceConflicts {
+	sorted := slices.Clone(slots)
+	slices.SortStableFunc(sorted, func(a, b models.TimeSlot) int {
+		return a.ScheduledAt.Compare(b.ScheduledAt)
+	})
+	var out []models.OccurrenceConflicts
+	for i := 1; i < len(sorted); i++ {
+		prev, cur := sorted[i-1], sorted[i]
+		if !cur.ScheduledAt.Before(slotEnd(prev)) {
+			continue
+		}
+		out = append(out, models.OccurrenceConflicts{
+			ScheduledAt:     cur.ScheduledAt,
+			DurationMinutes: cur.DurationMinutes,
+			Conflicts: []models.ScheduleConflict{{
+				Kind:            "slot",
+				ScheduledAt:     prev.ScheduledAt,
+				DurationMinutes: prev.DurationMinutes,
+			}},
+		})
+		if slotEnd(cur).Before(slotEnd(prev)) {
+			// Keep the longer slot as the neighbour of the next one.
+			sorted[i] = prev
+		}
+	}
+	return out
+}
+
+func slotEnd(s models.TimeSlot) time.Time {
+	return s.ScheduledAt.Add(time.Duration(s.DurationMinutes) * time.Minute)
+}
+
+func slotsAt(times []time.Time, durationMinutes int) []models.TimeSlot {
+	slots := make([]models.TimeSlot, len(times))
+	for i, t := range times {
+		slots[i] = models.TimeSlot{ScheduledAt: t, DurationMinutes: durationMinutes}
+	}
+	return slots
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tutorgo/models"
+	"tutorgo/recurrence"
+	"tutorgo/repository"
+)
+
+const (
+	// seriesHorizon is how far ahead an open-ended series is materialized into lessons.
+	seriesHorizon = 12 * 7 * 24 * time.Hour
+	// maxSeriesHorizon caps a single expansion, for bounded rules and explicit extensions alike.
+	maxSeriesHorizon = 2 * 365 * 24 * time.Hour
+)
+
+type SeriesService interface {
+	Create(ctx context.Context, req models.CreateSeriesRequest, tutorID string, opts models.ScheduleOptions) (models.SeriesWithLessons, error)
+	GetByID(ctx context.Context, id string, tutorID string) (models.SeriesWithLessons, error)
+	Extend(ctx context.Context, id string, tutorID string, req models.ExtendSeriesRequest, opts models.ScheduleOptions) ([]models.Lesson, error)
+	Update(ctx context.Context, id string, tutorID string, req models.UpdateSeriesRequest, opts models.ScheduleOptions) error
+	Delete(ctx context.Context, id string, tutorID string, fromDate *string) error
+}
+
+type seriesService struct {
+	repo         repository.SeriesRepository
+	courseRepo   repository.CourseRepository
+	tutorRepo    repository.TutorRepository
+	scheduleRepo repository.ScheduleRepository
+}
+
+func NewSeriesService(repo repository.SeriesRepository, courseRepo repository.CourseRepository, tutorRepo repository.TutorRepository, scheduleRepo repository.ScheduleRepository) SeriesService {
+	return &seriesService{repo: repo, courseRepo: courseRepo, tutorRepo: tutorRepo, scheduleRepo: scheduleRepo}
+}
+
+func (s *seriesService) Create(ctx context.Context, req models.CreateSeriesRequest, tutorID string, opts models.ScheduleOptions) (models.SeriesWithLessons, error) {
+	_, err := s.courseRepo.GetByID(ctx, req.CourseID, tutorID)
+	if err != nil {
+		return models.SeriesWithLessons{}, fmt.Errorf("course: %w", ErrNotFound)
+	}
+	tz := req.Timezone
+	if tz == "" {
+		if tz, err = s.tutorRepo.GetTimezone(ctx, tutorID); err != nil {
+			return models.SeriesWithLessons{}, err
+		}
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return models.SeriesWithLessons{}, fmt.Errorf("timezone: %w", ErrBadRequest)
+	}
+	rule, err := recurrence.Parse(req.RRule)
+	if err != nil {
+		return models.SeriesWithLessons{}, fmt.Errorf("%s: %w", err.Error(), ErrBadRequest)
+	}
+	dtstart, err := parseLocalTime(req.DTStart, loc)
+	if err != nil {
+		return models.SeriesWithLessons{}, fmt.Errorf("dtstart: %w", ErrBadRequest)
+	}
+	exdates := make([]time.Time, 0, len(req.ExDates))
+	for _, v := range req.ExDates {
+		t, err := parseLocalTime(v, loc)
+		if err != nil {
+			return models.SeriesWithLessons{}, fmt.Errorf("exdates: %w", ErrBadRequest)
+		}
+		exdates = append(exdates, t)
+	}
+
+	ruleStr := rule.String()
+	series := models.LessonSeries{
+		CourseID:        req.CourseID,
+		RRule:           &ruleStr,
+		DTStart:         dtstart,
+		Timezone:        loc.String(),
+		DurationMinutes: req.DurationMinutes,
+		Notes:           req.Notes,
+		ExDates:         exdates,
+		GeneratedUntil:  horizonFor(rule, dtstart),
+	}
+	occurrences, err := expandSeries(rule, series, dtstart, series.GeneratedUntil)
+	if err != nil {
+		return models.SeriesWithLessons{}, err
+	}
+	if len(occurrences) == 0 {
+		return models.SeriesWithLessons{}, fmt.Errorf("rule produces no lessons: %w", ErrBadRequest)
+	}
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slotsAt(occurrences, series.DurationMinutes), models.ConflictExclusion{}, opts); !ok {
+		return models.SeriesWithLessons{}, err
+	}
+
+	created, lessons, err := s.repo.Create(ctx, series, occurrences)
+	if err != nil {
+		return models.SeriesWithLessons{}, err
+	}
+	return models.SeriesWithLessons{Series: created, Lessons: lessons}, nil
+}
+
+func (s *seriesService) GetByID(ctx context.Context, id string, tutorID string) (models.SeriesWithLessons, error) {
+	series, err := s.repo.GetByIDForTutor(ctx, id, tutorID)
+	if err != nil {
+		return models.SeriesWithLessons{}, fmt.Errorf("series: %w", ErrNotFound)
+	}
+	lessons, err := s.repo.GetLessons(ctx, id)
+	if err != nil {
+		return models.SeriesWithLessons{}, err
+	}
+	return models.SeriesWithLessons{Series: series, Lessons: lessons}, nil
+}
+
+func (s *seriesService) Extend(ctx context.Context, id string, tutorID string, req models.ExtendSeriesRequest, opts models.ScheduleOptions) ([]models.Lesson, error) {
+	series, err := s.repo.GetByIDForTutor(ctx, id, tutorID)
+	if err != nil {
+		return nil, fmt.Errorf("series: %w", ErrNotFound)
+	}
+	if series.RRule == nil {
+		return nil, fmt.Errorf("series has no recurrence rule: %w", ErrBadRequest)
+	}
+	if req.Until.After(time.Now().Add(maxSeriesHorizon)) {
+		return nil, fmt.Errorf("until is too far ahead: %w", ErrBadRequest)
+	}
+	if !req.Until.After(series.GeneratedUntil) {
+		return []models.Lesson{}, nil
+	}
+	rule, err := recurrence.Parse(*series.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := expandSeries(rule, series, series.GeneratedUntil, req.Until)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slotsAt(occurrences, series.DurationMinutes), models.ConflictExclusion{SeriesID: id}, opts); !ok {
+		return nil, err
+	}
+	series.GeneratedUntil = req.Until
+	return s.repo.Append(ctx, series, occurrences)
+}
+
+func (s *seriesService) Update(ctx context.Context, id string, tutorID string, req models.UpdateSeriesRequest, opts models.ScheduleOptions) error {
+	if req.NewTime == nil && req.DurationMinutes == nil && req.Notes == nil && req.RRule == nil {
+		return fmt.Errorf("update requires at least one field: %w", ErrBadRequest)
+	}
+	series, err := s.repo.GetByIDForTutor(ctx, id, tutorID)
+	if err != nil {
+		return fmt.Errorf("series: %w", ErrNotFound)
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return err
+	}
+	var from time.Time
+	if req.FromDate != nil {
+		if from, err = time.Parse(time.RFC3339, *req.FromDate); err != nil {
+			return fmt.Errorf("from_date: %w", ErrBadRequest)
+		}
+	}
+
+	if req.NewTime != nil {
+		clock, err := time.Parse("15:04", *req.NewTime)
+		if err != nil {
+			return fmt.Errorf("new_time must be HH:MM: %w", ErrBadRequest)
+		}
+		y, m, d := series.DTStart.In(loc).Date()
+		series.DTStart = time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+		// EXDATEs name occurrences by their instant, so they move with the time of
+		// day to keep excluding the same days.
+		exdates := make([]time.Time, len(series.ExDates))
+		for i, ex := range series.ExDates {
+			y, m, d := ex.In(loc).Date()
+			exdates[i] = time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+		}
+		series.ExDates = exdates
+	}
+	if req.DurationMinutes != nil {
+		series.DurationMinutes = *req.DurationMinutes
+	}
+	if req.Notes != nil {
+		series.Notes = *req.Notes
+	}
+
+	exclude := models.ConflictExclusion{SeriesID: id}
+	if req.RRule == nil {
+		slots, err := s.movedSlots(ctx, series, from, req, loc)
+		if err != nil {
+			return err
+		}
+		if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, exclude, opts); !ok {
+			return err
+		}
+		return s.repo.UpdateInPlace(ctx, series, from, req)
+	}
+
+	rule, err := recurrence.Parse(*req.RRule)
+	if err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), ErrBadRequest)
+	}
+	ruleStr := rule.String()
+	series.RRule = &ruleStr
+	if horizon := horizonFor(rule, series.DTStart); horizon.After(series.GeneratedUntil) {
+		series.GeneratedUntil = horizon
+	}
+	occurrences, err := expandSeries(rule, series, from, series.GeneratedUntil)
+	if err != nil {
+		return err
+	}
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slotsAt(occurrences, series.DurationMinutes), exclude, opts); !ok {
+		return err
+	}
+	return s.repo.Regenerate(ctx, series, from, occurrences)
+}
+
+// movedSlots predicts where UpdateInPlace puts the upcoming series lessons, mirroring
+// its SQL: the new time of day on the same calendar day in the series timezone.
+func (s *seriesService) movedSlots(ctx context.Context, series models.LessonSeries, from time.Time, req models.UpdateSeriesRequest, loc *time.Location) ([]models.TimeSlot, error) {
+	lessons, err := s.repo.GetLessons(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	var slots []models.TimeSlot
+	for _, l := range lessons {
+		if l.ScheduledAt.Before(from) || l.Status == "cancelled" {
+			continue
+		}
+		at := l.ScheduledAt
+		if req.NewTime != nil {
+			y, m, d := at.In(loc).Date()
+			local := series.DTStart.In(loc)
+			at = time.Date(y, m, d, local.Hour(), local.Minute(), 0, 0, loc)
+		}
+		duration := l.DurationMinutes
+		if req.DurationMinutes != nil {
+			duration = *req.DurationMinutes
+		}
+		slots = append(slots, models.TimeSlot{ScheduledAt: at, DurationMinutes: duration})
+	}
+	return slots, nil
+}
+
+func (s *seriesService) Delete(ctx context.Context, id string, tutorID string, fromDate *string) error {
+	series, err := s.repo.GetByIDForTutor(ctx, id, tutorID)
+	if err != nil {
+		return fmt.Errorf("series: %w", ErrNotFound)
+	}
+	if fromDate == nil {
+		return s.repo.Delete(ctx, id)
+	}
+	from, err := time.Parse(time.RFC3339, *fromDate)
+	if err != nil {
+		return fmt.Errorf("from: %w", ErrBadRequest)
+	}
+	if !from.After(series.DTStart) {
+		return s.repo.Delete(ctx, id)
+	}
+
+	if series.RRule != nil {
+		rule, err := recurrence.Parse(*series.RRule)
+		if err != nil {
+			return err
+		}
+		// Bound the rule at its last occurrence before from. Converting COUNT into UNTIL
+		// this way never lets the rule run past its original end.
+		loc, err := time.LoadLocation(series.Timezone)
+		if err != nil {
+			return err
+		}
+		last, ok := rule.Last(series.DTStart.In(loc), from)
+		if !ok {
+			return s.repo.Delete(ctx, id)
+		}
+		last = last.UTC()
+		rule.Count = 0
+		rule.Until = &last
+		ruleStr := rule.String()
+		series.RRule = &ruleStr
+	}
+	if series.GeneratedUntil.After(from) {
+		series.GeneratedUntil = from
+	}
+	return s.repo.Truncate(ctx, series, from)
+}
+
+// horizonFor picks how far a series is materialized: a rule that ends on its own is
+// expanded completely (within maxSeriesHorizon), an open-ended one seriesHorizon ahead.
+func horizonFor(rule recurrence.Rule, dtstart time.Time) time.Time {
+	if rule.Bounded() {
+		return dtstart.Add(maxSeriesHorizon)
+	}
+	start := time.Now()
+	if dtstart.After(start) {
+		start = dtstart
+	}
+	return start.Add(seriesHorizon)
+}
+
+// expandSeries returns the rule occurrences in [from, to) minus the series EXDATEs,
+// evaluated in the series timezone.
+func expandSeries(rule recurrence.Rule, series models.LessonSeries, from, to time.Time) ([]time.Time, error) {
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	occurrences, err := rule.Between(series.DTStart.In(loc), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrBadRequest)
+	}
+	var out []time.Time
+	for _, t := range occurrences {
+		if !containsInstant(series.ExDates, t) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func containsInstant(list []time.Time, t time.Time) bool {
+	for _, v := range list {
+		if v.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLocalTime reads a wall-clock date-time in loc; RFC3339 values with an
+// explicit offset are accepted as well.
+func parseLocalTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
@@ -0,0 +1,342 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSeriesRepo struct {
+	mock.Mock
+}
+
+func (m *mockSeriesRepo) Create(ctx context.Context, series models.LessonSeries, occurrences []time.Time) (models.LessonSeries, []models.Lesson, error) {
+	args := m.Called(ctx, series, occurrences)
+	return args.Get(0).(models.LessonSeries), args.Get(1).([]models.Lesson), args.Error(2)
+}
+
+func (m *mockSeriesRepo) GetByIDForTutor(ctx context.Context, id string, tutorID string) (models.LessonSeries, error) {
+	args := m.Called(ctx, id, tutorID)
+	return args.Get(0).(models.LessonSeries), args.Error(1)
+}
+
+func (m *mockSeriesRepo) GetLessons(ctx context.Context, id string) ([]models.Lesson, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]models.Lesson), args.Error(1)
+}
+
+func (m *mockSeriesRepo) Append(ctx context.Context, series models.LessonSeries, occurrences []time.Time) ([]models.Lesson, error) {
+	args := m.Called(ctx, series, occurrences)
+	return args.Get(0).([]models.Lesson), args.Error(1)
+}
+
+func (m *mockSeriesRepo) UpdateInPlace(ctx context.Context, series models.LessonSeries, from time.Time, req models.UpdateSeriesRequest) error {
+	return m.Called(ctx, series, from, req).Error(0)
+}
+
+func (m *mockSeriesRepo) Regenerate(ctx context.Context, series models.LessonSeries, from time.Time, occurrences []time.Time) error {
+	return m.Called(ctx, series, from, occurrences).Error(0)
+}
+
+func (m *mockSeriesRepo) Truncate(ctx context.Context, series models.LessonSeries, from time.Time) error {
+	return m.Called(ctx, series, from).Error(0)
+}
+
+func (m *mockSeriesRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+// fixtures
+
+var (
+	seriesID = "series-uuid-1"
+
+	weeklyRule = "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4"
+
+	storedSeries = models.LessonSeries{
+		ID:              seriesID,
+		CourseID:        courseID,
+		RRule:           &weeklyRule,
+		DTStart:         time.Date(2026, time.May, 4, 17, 0, 0, 0, time.UTC),
+		Timezone:        "UTC",
+		DurationMinutes: 60,
+		GeneratedUntil:  time.Date(2028, time.May, 3, 17, 0, 0, 0, time.UTC),
+	}
+)
+
+func newSeriesSvc(repo *mockSeriesRepo, courseRepo *mockCourseRepo) service.SeriesService {
+	tutorRepo := new(mockTutorRepo)
+	tutorRepo.On("GetTimezone", mock.Anything, tutorID).Return("Europe/Berlin", nil).Maybe()
+	return service.NewSeriesService(repo, courseRepo, tutorRepo, freeSchedule())
+}
+
+// Create
+
+func TestSeriesCreate_ExpandsRuleInTimezone(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newSeriesSvc(repo, courseRepo)
+
+	req := models.CreateSeriesRequest{
+		CourseID:        courseID,
+		RRule:           "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3",
+		DTStart:         "2026-05-04T17:00",
+		Timezone:        "Asia/Almaty",
+		DurationMinutes: 60,
+		ExDates:         []string{"2026-05-07T17:00"},
+	}
+	almaty, _ := time.LoadLocation("Asia/Almaty")
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		return *s.RRule == "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3" && s.Timezone == "Asia/Almaty" && len(s.ExDates) == 1
+	}), mock.MatchedBy(func(occ []time.Time) bool {
+		// COUNT=3 yields May 4, 7, 11; the EXDATE removes May 7.
+		return len(occ) == 2 &&
+			occ[0].Equal(time.Date(2026, time.May, 4, 17, 0, 0, 0, almaty)) &&
+			occ[1].Equal(time.Date(2026, time.May, 11, 17, 0, 0, 0, almaty))
+	})).Return(storedSeries, []models.Lesson{expectedLesson}, nil)
+
+	result, err := svc.Create(context.Background(), req, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, storedSeries, result.Series)
+	repo.AssertExpectations(t)
+}
+
+func TestSeriesCreate_DefaultsToTutorTimezone(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newSeriesSvc(repo, courseRepo)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		return s.Timezone == "Europe/Berlin" && s.DTStart.Equal(time.Date(2026, time.May, 4, 17, 0, 0, 0, berlin))
+	}), mock.Anything).Return(storedSeries, []models.Lesson{expectedLesson}, nil)
+
+	_, err := svc.Create(context.Background(), models.CreateSeriesRequest{
+		CourseID:        courseID,
+		RRule:           "FREQ=WEEKLY;COUNT=2",
+		DTStart:         "2026-05-04T17:00",
+		DurationMinutes: 60,
+	}, tutorID, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestSeriesCreate_InvalidRule(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newSeriesSvc(repo, courseRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(expectedCourse, nil)
+
+	_, err := svc.Create(context.Background(), models.CreateSeriesRequest{
+		CourseID:        courseID,
+		RRule:           "FREQ=HOURLY",
+		DTStart:         "2026-05-04T17:00",
+		DurationMinutes: 60,
+	}, tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "Create")
+}
+
+func TestSeriesCreate_CourseNotFound(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	courseRepo := new(mockCourseRepo)
+	svc := newSeriesSvc(repo, courseRepo)
+
+	courseRepo.On("GetByID", mock.Anything, courseID, tutorID).Return(models.Course{}, errors.New("not found"))
+
+	_, err := svc.Create(context.Background(), models.CreateSeriesRequest{CourseID: courseID}, tutorID, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "Create")
+}
+
+// Extend
+
+func TestSeriesExtend_RulelessSeries(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	legacy := storedSeries
+	legacy.RRule = nil
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(legacy, nil)
+
+	_, err := svc.Extend(context.Background(), seriesID, tutorID, models.ExtendSeriesRequest{Until: time.Now().Add(time.Hour)}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "Append")
+}
+
+func TestSeriesExtend_AppendsPastHorizon(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	daily := "FREQ=DAILY"
+	series := storedSeries
+	series.RRule = &daily
+	series.DTStart = time.Now().Truncate(time.Hour)
+	series.GeneratedUntil = series.DTStart.AddDate(0, 0, 7)
+	until := series.DTStart.AddDate(0, 0, 10)
+
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(series, nil)
+	repo.On("Append", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		return s.GeneratedUntil.Equal(until)
+	}), mock.MatchedBy(func(occ []time.Time) bool {
+		return len(occ) == 3 && occ[0].Equal(series.DTStart.AddDate(0, 0, 7))
+	})).Return([]models.Lesson{expectedLesson}, nil)
+
+	lessons, err := svc.Extend(context.Background(), seriesID, tutorID, models.ExtendSeriesRequest{Until: until}, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, lessons, 1)
+	repo.AssertExpectations(t)
+}
+
+// Update
+
+func TestSeriesUpdate_NoFields(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	err := svc.Update(context.Background(), seriesID, tutorID, models.UpdateSeriesRequest{}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "GetByIDForTutor")
+}
+
+func TestSeriesUpdate_NewTimeMovesDTStart(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	newTime := "18:30"
+	req := models.UpdateSeriesRequest{NewTime: &newTime}
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(storedSeries, nil)
+	repo.On("GetLessons", mock.Anything, seriesID).Return([]models.Lesson{expectedLesson}, nil)
+	repo.On("UpdateInPlace", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		return s.DTStart.Equal(time.Date(2026, time.May, 4, 18, 30, 0, 0, time.UTC))
+	}), time.Time{}, req).Return(nil)
+
+	err := svc.Update(context.Background(), seriesID, tutorID, req, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestSeriesUpdate_NewTimeMovesExDates(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	newTime := "18:30"
+	req := models.UpdateSeriesRequest{NewTime: &newTime}
+	series := storedSeries
+	series.ExDates = []time.Time{time.Date(2026, time.May, 11, 17, 0, 0, 0, time.UTC)}
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(series, nil)
+	repo.On("GetLessons", mock.Anything, seriesID).Return([]models.Lesson{}, nil)
+	repo.On("UpdateInPlace", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		return len(s.ExDates) == 1 && s.ExDates[0].Equal(time.Date(2026, time.May, 11, 18, 30, 0, 0, time.UTC))
+	}), time.Time{}, req).Return(nil)
+
+	err := svc.Update(context.Background(), seriesID, tutorID, req, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestSeriesUpdate_MovedLessonsConflict(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	scheduleRepo := new(mockScheduleRepo)
+	tutorRepo := new(mockTutorRepo)
+	svc := service.NewSeriesService(repo, new(mockCourseRepo), tutorRepo, scheduleRepo)
+
+	newTime := "18:30"
+	lesson := expectedLesson
+	lesson.ScheduledAt = time.Date(2026, time.May, 7, 17, 0, 0, 0, time.UTC)
+	moved := []models.TimeSlot{{ScheduledAt: time.Date(2026, time.May, 7, 18, 30, 0, 0, time.UTC), DurationMinutes: 60}}
+	conflicts := []models.OccurrenceConflicts{{ScheduledAt: moved[0].ScheduledAt, DurationMinutes: 60}}
+
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(storedSeries, nil)
+	repo.On("GetLessons", mock.Anything, seriesID).Return([]models.Lesson{lesson}, nil)
+	scheduleRepo.On("FindConflicts", mock.Anything, tutorID, moved, models.ConflictExclusion{SeriesID: seriesID}).Return(conflicts, nil)
+
+	err := svc.Update(context.Background(), seriesID, tutorID, models.UpdateSeriesRequest{NewTime: &newTime}, models.ScheduleOptions{})
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "UpdateInPlace")
+}
+
+func TestSeriesUpdate_RuleChangeRegenerates(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	rule := "FREQ=WEEKLY;BYDAY=TU;COUNT=2"
+	from := "2026-05-05T00:00:00Z"
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(storedSeries, nil)
+	repo.On("Regenerate", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		return *s.RRule == rule
+	}), time.Date(2026, time.May, 5, 0, 0, 0, 0, time.UTC), []time.Time{
+		time.Date(2026, time.May, 5, 17, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 12, 17, 0, 0, 0, time.UTC),
+	}).Return(nil)
+
+	err := svc.Update(context.Background(), seriesID, tutorID, models.UpdateSeriesRequest{RRule: &rule, FromDate: &from}, models.ScheduleOptions{})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// Delete
+
+func TestSeriesDelete_Whole(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(storedSeries, nil)
+	repo.On("Delete", mock.Anything, seriesID).Return(nil)
+
+	err := svc.Delete(context.Background(), seriesID, tutorID, nil)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestSeriesDelete_FromBoundsRule(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	from := "2026-05-10T00:00:00Z"
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(storedSeries, nil)
+	repo.On("Truncate", mock.Anything, mock.MatchedBy(func(s models.LessonSeries) bool {
+		// COUNT=4 becomes UNTIL at the last kept occurrence (Thursday May 7).
+		return *s.RRule == "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20260507T170000Z" &&
+			s.GeneratedUntil.Equal(time.Date(2026, time.May, 10, 0, 0, 0, 0, time.UTC))
+	}), time.Date(2026, time.May, 10, 0, 0, 0, 0, time.UTC)).Return(nil)
+
+	err := svc.Delete(context.Background(), seriesID, tutorID, &from)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestSeriesDelete_NotFound(t *testing.T) {
+	repo := new(mockSeriesRepo)
+	svc := newSeriesSvc(repo, new(mockCourseRepo))
+
+	repo.On("GetByIDForTutor", mock.Anything, seriesID, tutorID).Return(models.LessonSeries{}, errors.New("no rows"))
+
+	err := svc.Delete(context.Background(), seriesID, tutorID, nil)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "Delete")
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+)
+
+type SessionService interface {
+	Create(ctx context.Context, tutorID string, meta models.SessionMeta) (models.Session, string, error)
+	Refresh(ctx context.Context, refreshToken string, meta models.SessionMeta) (models.Session, string, error)
+	GetAll(ctx context.Context, tutorID string, currentID string) ([]models.Session, error)
+	Revoke(ctx context.Context, id string, tutorID string) error
+	RevokeOthers(ctx context.Context, tutorID string, currentID string) error
+	IsActive(ctx context.Context, id string) (bool, error)
+}
+
+type sessionService struct {
+	repo repository.SessionRepository
+}
+
+func NewSessionService(repo repository.SessionRepository) SessionService {
+	return &sessionService{repo: repo}
+}
+
+// RefreshTTL is how long a session lasts without being refreshed.
+const RefreshTTL = 30 * 24 * time.Hour
+
+// maxUserAgent keeps what is stored of a device's description short.
+const maxUserAgent = 255
+
+// hashToken is what is stored of a refresh token or a recovery code: enough to
+// find it by, useless to whoever reads the table.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func trimMeta(meta models.SessionMeta) models.SessionMeta {
+	if r := []rune(meta.UserAgent); len(r) > maxUserAgent {
+		meta.UserAgent = string(r[:maxUserAgent])
+	}
+	return meta
+}
+
+// Create signs a device in and returns its first refresh token.
+func (s *sessionService) Create(ctx context.Context, tutorID string, meta models.SessionMeta) (models.Session, string, error) {
+	token, err := newToken()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	session, err := s.repo.Create(ctx, tutorID, hashToken(token), trimMeta(meta), time.Now().Add(RefreshTTL))
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	return session, token, nil
+}
+
+// Refresh trades a refresh token for the next one, keeping the session alive for
+// another RefreshTTL. A token that has already been traded means two parties hold
+// it, so its session is revoked.
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string, meta models.SessionMeta) (models.Session, string, error) {
+	next, err := newToken()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	oldHash := hashToken(refreshToken)
+	session, err := s.repo.Rotate(ctx, oldHash, hashToken(next), trimMeta(meta), time.Now().Add(RefreshTTL))
+	if err == nil {
+		return session, next, nil
+	}
+	reused, revokeErr := s.repo.RevokeReused(ctx, oldHash)
+	if revokeErr != nil {
+		return models.Session{}, "", revokeErr
+	}
+	if reused {
+		return models.Session{}, "", fmt.Errorf("refresh token reused, session revoked: %w", ErrUnauthorized)
+	}
+	return models.Session{}, "", fmt.Errorf("refresh token: %w", ErrUnauthorized)
+}
+
+// GetAll lists the tutor's signed-in devices, marking the current one.
+func (s *sessionService) GetAll(ctx context.Context, tutorID string, currentID string) ([]models.Session, error) {
+	sessions, err := s.repo.GetActive(ctx, tutorID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke signs a device out; its access token stops working at once.
+func (s *sessionService) Revoke(ctx context.Context, id string, tutorID string) error {
+	if err := s.repo.Revoke(ctx, id, tutorID); err != nil {
+		return fmt.Errorf("session: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// RevokeOthers signs out every device but the current one.
+func (s *sessionService) RevokeOthers(ctx context.Context, tutorID string, currentID string) error {
+	return s.repo.RevokeOthers(ctx, tutorID, currentID)
+}
+
+func (s *sessionService) IsActive(ctx context.Context, id string) (bool, error) {
+	return s.repo.IsActive(ctx, id)
+}
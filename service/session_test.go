@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSessionRepo struct {
+	mock.Mock
+}
+
+func (m *mockSessionRepo) Create(ctx context.Context, tutorID string, refreshHash string, meta models.SessionMeta, expiresAt time.Time) (models.Session, error) {
+	args := m.Called(ctx, tutorID, refreshHash, meta, expiresAt)
+	return args.Get(0).(models.Session), args.Error(1)
+}
+
+func (m *mockSessionRepo) Rotate(ctx context.Context, oldHash string, newHash string, meta models.SessionMeta, expiresAt time.Time) (models.Session, error) {
+	args := m.Called(ctx, oldHash, newHash, meta, expiresAt)
+	return args.Get(0).(models.Session), args.Error(1)
+}
+
+func (m *mockSessionRepo) RevokeReused(ctx context.Context, oldHash string) (bool, error) {
+	args := m.Called(ctx, oldHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockSessionRepo) GetActive(ctx context.Context, tutorID string) ([]models.Session, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *mockSessionRepo) Revoke(ctx context.Context, id string, tutorID string) error {
+	return m.Called(ctx, id, tutorID).Error(0)
+}
+
+func (m *mockSessionRepo) RevokeOthers(ctx context.Context, tutorID string, keepID string) error {
+	return m.Called(ctx, tutorID, keepID).Error(0)
+}
+
+func (m *mockSessionRepo) IsActive(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestSessionCreate_StoresOnlyTheHash(t *testing.T) {
+	repo := new(mockSessionRepo)
+	svc := service.NewSessionService(repo)
+
+	var stored string
+	repo.On("Create", mock.Anything, tutorID, mock.Anything, models.SessionMeta{IP: "1.2.3.4"}, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.String(2) }).
+		Return(models.Session{ID: "s1"}, nil)
+
+	session, token, err := svc.Create(context.Background(), tutorID, models.SessionMeta{IP: "1.2.3.4"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "s1", session.ID)
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, stored)
+	assert.Len(t, stored, 64)
+}
+
+func TestSessionRefresh_Rotates(t *testing.T) {
+	repo := new(mockSessionRepo)
+	svc := service.NewSessionService(repo)
+
+	repo.On("Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Session{ID: "s1", TutorID: tutorID}, nil)
+
+	session, next, err := svc.Refresh(context.Background(), "old-token", models.SessionMeta{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "s1", session.ID)
+	assert.NotEqual(t, "old-token", next)
+	repo.AssertNotCalled(t, "RevokeReused", mock.Anything, mock.Anything)
+}
+
+func TestSessionRefresh_ReusedTokenRevokesSession(t *testing.T) {
+	repo := new(mockSessionRepo)
+	svc := service.NewSessionService(repo)
+
+	repo.On("Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Session{}, errors.New("no rows"))
+	repo.On("RevokeReused", mock.Anything, mock.Anything).Return(true, nil)
+
+	_, _, err := svc.Refresh(context.Background(), "old-token", models.SessionMeta{})
+
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+	repo.AssertExpectations(t)
+}
+
+func TestSessionRefresh_UnknownToken(t *testing.T) {
+	repo := new(mockSessionRepo)
+	svc := service.NewSessionService(repo)
+
+	repo.On("Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Session{}, errors.New("no rows"))
+	repo.On("RevokeReused", mock.Anything, mock.Anything).Return(false, nil)
+
+	_, _, err := svc.Refresh(context.Background(), "made-up", models.SessionMeta{})
+
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+}
+
+func TestSessionGetAll_MarksCurrent(t *testing.T) {
+	repo := new(mockSessionRepo)
+	svc := service.NewSessionService(repo)
+
+	repo.On("GetActive", mock.Anything, tutorID).Return([]models.Session{{ID: "s1"}, {ID: "s2"}}, nil)
+
+	sessions, err := svc.GetAll(context.Background(), tutorID, "s2")
+
+	require.NoError(t, err)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestSessionRevoke_NotFound(t *testing.T) {
+	repo := new(mockSessionRepo)
+	svc := service.NewSessionService(repo)
+
+	repo.On("Revoke", mock.Anything, "s9", tutorID).Return(errors.New("no rows"))
+
+	err := svc.Revoke(context.Background(), "s9", tutorID)
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/repository"
+	"tutorgo/validator"
+)
+
+type StudentImportService interface {
+	Import(ctx context.Context, tutorID string, file io.Reader, dryRun bool) (models.StudentImport, error)
+}
+
+type studentImportService struct {
+	repo      repository.StudentImportRepository
+	tutorRepo repository.TutorRepository
+}
+
+func NewStudentImportService(repo repository.StudentImportRepository, tutorRepo repository.TutorRepository) StudentImportService {
+	return &studentImportService{repo: repo, tutorRepo: tutorRepo}
+}
+
+// Limits of an imported file. The router caps request bodies at 1 MB, which
+// keeps a larger upload from getting this far.
+const (
+	maxImportBytes = 1 << 20
+	maxImportRows  = 1000
+)
+
+// studentImportColumns and courseImportColumns are the columns an import
+// understands, named like the fields of the API. A row with any course column
+// filled in also gets an individual course.
+var (
+	studentImportColumns = []string{"first_name", "last_name", "phone", "email", "notes", "timezone"}
+	courseImportColumns  = []string{"subject", "price_per_lesson", "currency", "started_at", "ended_at"}
+)
+
+// Import reads a CSV of students, one per row under a header naming the columns,
+// and checks each row as POST /students would. Unless it is a dry run and as
+// long as every row is valid, it then creates all the students and their
+// courses at once. A course starts today, in the tutor's timezone, unless the
+// row says when.
+func (s *studentImportService) Import(ctx context.Context, tutorID string, file io.Reader, dryRun bool) (models.StudentImport, error) {
+	today, err := s.today(ctx, tutorID)
+	if err != nil {
+		return models.StudentImport{}, err
+	}
+	rows, err := parseStudentImport(file, today)
+	if err != nil {
+		return models.StudentImport{}, err
+	}
+	if err := s.checkEmails(ctx, tutorID, rows); err != nil {
+		return models.StudentImport{}, err
+	}
+
+	imp := models.StudentImport{DryRun: dryRun, Rows: rows}
+	for _, row := range rows {
+		if row.Errors != nil {
+			imp.Invalid++
+		} else {
+			imp.Valid++
+		}
+	}
+	if dryRun || imp.Invalid > 0 {
+		return imp, nil
+	}
+	if err := s.repo.Import(ctx, tutorID, rows); err != nil {
+		return models.StudentImport{}, err
+	}
+	for _, row := range rows {
+		imp.StudentsCreated++
+		if row.Course != nil {
+			imp.CoursesCreated++
+		}
+	}
+	return imp, nil
+}
+
+// today is the tutor's current calendar date, at midnight UTC like the dates
+// read from the file.
+func (s *studentImportService) today(ctx context.Context, tutorID string) (time.Time, error) {
+	tz, err := s.tutorRepo.GetTimezone(ctx, tutorID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+	y, m, d := time.Now().In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+}
+
+// checkEmails flags emails used twice in the file or by a student the tutor
+// already has, so that importing a file again does not double everyone.
+func (s *studentImportService) checkEmails(ctx context.Context, tutorID string, rows []models.StudentImportRow) error {
+	firstLine := map[string]int{}
+	emails := []string{}
+	for i, row := range rows {
+		email := strings.ToLower(row.Student.Email)
+		if email == "" || row.Errors["email"] != "" {
+			continue
+		}
+		if line, ok := firstLine[email]; ok {
+			addImportError(&rows[i], "email", fmt.Sprintf("same as on line %d", line))
+			continue
+		}
+		firstLine[email] = row.Line
+		emails = append(emails, email)
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+	existing, err := s.repo.GetEmails(ctx, tutorID, emails)
+	if err != nil {
+		return err
+	}
+	taken := map[string]bool{}
+	for _, email := range existing {
+		taken[email] = true
+	}
+	for i, row := range rows {
+		email := strings.ToLower(row.Student.Email)
+		if taken[email] && firstLine[email] == row.Line {
+			addImportError(&rows[i], "email", "a student with this email already exists")
+		}
+	}
+	return nil
+}
+
+func addImportError(row *models.StudentImportRow, column string, msg string) {
+	if row.Errors == nil {
+		row.Errors = map[string]string{}
+	}
+	if _, ok := row.Errors[column]; !ok {
+		row.Errors[column] = msg
+	}
+}
+
+// parseStudentImport reads the rows of the file, skipping blank ones. The file
+// may be comma- or semicolon-separated, as spreadsheet apps save it depending on
+// the locale, and may start with a byte order mark.
+func parseStudentImport(file io.Reader, today time.Time) ([]models.StudentImportRow, error) {
+	data, err := io.ReadAll(io.LimitReader(file, maxImportBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportBytes {
+		return nil, fmt.Errorf("the file is larger than %d MB: %w", maxImportBytes>>20, ErrBadRequest)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	columns, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the file is empty: %w", ErrBadRequest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrBadRequest)
+	}
+	index, err := importColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []models.StudentImportRow{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", err.Error(), ErrBadRequest)
+		}
+		get := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("the file has more than %d students: %w", maxImportRows, ErrBadRequest)
+		}
+		line, _ := r.FieldPos(0)
+		rows = append(rows, parseImportRow(line, get, today))
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the file has no students: %w", ErrBadRequest)
+	}
+	return rows, nil
+}
+
+// importColumns maps column names to their position. Names are matched loosely,
+// so "First name" is first_name; unknown ones are an error rather than data
+// silently left out.
+func importColumns(header []string) (map[string]int, error) {
+	known := map[string]bool{}
+	for _, c := range append(append([]string{}, studentImportColumns...), courseImportColumns...) {
+		known[c] = true
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		column := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if column == "" {
+			continue
+		}
+		if !known[column] {
+			return nil, fmt.Errorf("unknown column %q: %w", name, ErrBadRequest)
+		}
+		if _, ok := index[column]; ok {
+			return nil, fmt.Errorf("column %q appears twice: %w", name, ErrBadRequest)
+		}
+		index[column] = i
+	}
+	if _, ok := index["first_name"]; !ok {
+		return nil, fmt.Errorf("the first_name column is required: %w", ErrBadRequest)
+	}
+	return index, nil
+}
+
+func parseImportRow(line int, get func(string) string, today time.Time) models.StudentImportRow {
+	row := models.StudentImportRow{
+		Line: line,
+		Student: models.CreateStudentRequest{
+			FirstName: get("first_name"),
+			LastName:  get("last_name"),
+			Phone:     get("phone"),
+			Email:     get("email"),
+			Notes:     get("notes"),
+		},
+	}
+	if tz := get("timezone"); tz != "" {
+		row.Student.Timezone = &tz
+	}
+	for column, msg := range validator.Validate(row.Student) {
+		addImportError(&row, column, msg)
+	}
+
+	hasCourse := false
+	for _, column := range courseImportColumns {
+		if get(column) != "" {
+			hasCourse = true
+		}
+	}
+	if !hasCourse {
+		return row
+	}
+	course := models.CreateCourseRequest{Subject: get("subject"), Currency: strings.ToUpper(get("currency")), StartedAt: today}
+	if price := get("price_per_lesson"); price != "" {
+		amount, err := money.ParseAmount(strings.Replace(price, ",", ".", 1))
+		if err != nil {
+			addImportError(&row, "price_per_lesson", "must be an amount such as 1500.00")
+		}
+		course.PricePerLesson = amount
+	}
+	if started := get("started_at"); started != "" {
+		t, err := time.Parse(time.DateOnly, started)
+		if err != nil {
+			addImportError(&row, "started_at", "must be a date (YYYY-MM-DD)")
+		}
+		course.StartedAt = t
+	}
+	if ended := get("ended_at"); ended != "" {
+		t, err := time.Parse(time.DateOnly, ended)
+		if err != nil {
+			addImportError(&row, "ended_at", "must be a date (YYYY-MM-DD)")
+		}
+		course.EndedAt = &t
+	}
+	for column, msg := range validator.Validate(course) {
+		addImportError(&row, column, msg)
+	}
+	row.Course = &course
+	return row
+}
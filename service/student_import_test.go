@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/money"
+	"tutorgo/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStudentImportRepo struct {
+	mock.Mock
+}
+
+func (m *mockStudentImportRepo) GetEmails(ctx context.Context, tutorID string, emails []string) ([]string, error) {
+	args := m.Called(ctx, tutorID, emails)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockStudentImportRepo) Import(ctx context.Context, tutorID string, rows []models.StudentImportRow) error {
+	return m.Called(ctx, tutorID, rows).Error(0)
+}
+
+func csvFile(lines ...string) *strings.Reader {
+	return strings.NewReader(strings.Join(lines, "\n"))
+}
+
+func TestStudentImport_DryRunReportsEachRow(t *testing.T) {
+	repo := new(mockStudentImportRepo)
+	svc := service.NewStudentImportService(repo, utcTutor())
+
+	repo.On("GetEmails", mock.Anything, tutorID, []string{"anna@example.com", "ivan@example.com"}).
+		Return([]string{"ivan@example.com"}, nil)
+
+	imp, err := svc.Import(context.Background(), tutorID, csvFile(
+		"First name,Last name,Email",
+		"Anna,Smirnova,anna@example.com",
+		"I,Petrov,not-an-email",
+		",,",
+		"Ivan,Petrov,Ivan@Example.com",
+		"Anya,Smirnova,ANNA@example.com",
+	), true)
+
+	require.NoError(t, err)
+	assert.True(t, imp.DryRun)
+	assert.Equal(t, 1, imp.Valid)
+	assert.Equal(t, 3, imp.Invalid)
+	require.Len(t, imp.Rows, 4)
+	assert.Nil(t, imp.Rows[0].Errors)
+	assert.Equal(t, 2, imp.Rows[0].Line)
+	assert.Equal(t, map[string]string{"first_name": "minimum length is 2", "email": "invalid email format"}, imp.Rows[1].Errors)
+	assert.Equal(t, map[string]string{"email": "a student with this email already exists"}, imp.Rows[2].Errors)
+	assert.Equal(t, 5, imp.Rows[2].Line)
+	assert.Equal(t, map[string]string{"email": "same as on line 2"}, imp.Rows[3].Errors)
+	repo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStudentImport_CommitsStudentsAndCourses(t *testing.T) {
+	repo := new(mockStudentImportRepo)
+	svc := service.NewStudentImportService(repo, utcTutor())
+
+	tz := "Europe/Moscow"
+	rows := []models.StudentImportRow{
+		{
+			Line:    2,
+			Student: models.CreateStudentRequest{FirstName: "Anna", Timezone: &tz},
+			Course: &models.CreateCourseRequest{
+				Subject: "Math", PricePerLesson: money.FromUnits(1500), Currency: "RUB",
+				StartedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{Line: 3, Student: models.CreateStudentRequest{FirstName: "Ivan"}},
+	}
+	repo.On("Import", mock.Anything, tutorID, rows).Return(nil)
+
+	imp, err := svc.Import(context.Background(), tutorID, csvFile(
+		"first_name;timezone;subject;price_per_lesson;currency;started_at",
+		"Anna;Europe/Moscow;Math;1500,00;rub;2026-09-01",
+		"Ivan;;;;;",
+	), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, imp.StudentsCreated)
+	assert.Equal(t, 1, imp.CoursesCreated)
+	repo.AssertExpectations(t)
+}
+
+func TestStudentImport_CourseStartsTodayForTheTutor(t *testing.T) {
+	repo := new(mockStudentImportRepo)
+	// UTC+14: the tutor's date is a day ahead of UTC for most of the day.
+	svc := service.NewStudentImportService(repo, tutorIn("Pacific/Kiritimati"))
+
+	imp, err := svc.Import(context.Background(), tutorID, csvFile(
+		"first_name,subject",
+		"Anna,Math",
+	), true)
+
+	loc, _ := time.LoadLocation("Pacific/Kiritimati")
+	y, m, d := time.Now().In(loc).Date()
+	require.NoError(t, err)
+	require.NotNil(t, imp.Rows[0].Course)
+	assert.Equal(t, time.Date(y, m, d, 0, 0, 0, 0, time.UTC), imp.Rows[0].Course.StartedAt)
+}
+
+func TestStudentImport_NothingCommittedWithErrors(t *testing.T) {
+	repo := new(mockStudentImportRepo)
+	svc := service.NewStudentImportService(repo, utcTutor())
+
+	imp, err := svc.Import(context.Background(), tutorID, csvFile(
+		"first_name,subject,price_per_lesson",
+		"Anna,Math,1500",
+		"Ivan,Physics,lots",
+	), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, imp.Invalid)
+	assert.Equal(t, map[string]string{"price_per_lesson": "must be an amount such as 1500.00"}, imp.Rows[1].Errors)
+	assert.Zero(t, imp.StudentsCreated)
+	repo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStudentImport_BadFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"empty", ""},
+		{"no first_name column", "last_name,email\nPetrov,ivan@example.com"},
+		{"unknown column", "first_name,birthday\nIvan,2010-01-01"},
+		{"header only", "first_name,last_name\n"},
+		{"too many rows", "first_name\n" + strings.Repeat("Ivan\n", 1001)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockStudentImportRepo)
+			svc := service.NewStudentImportService(repo, utcTutor())
+
+			_, err := svc.Import(context.Background(), tutorID, strings.NewReader(tt.file), true)
+
+			assert.ErrorIs(t, err, service.ErrBadRequest)
+		})
+	}
+}
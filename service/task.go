@@ -2,27 +2,33 @@ package service
 
 import (
 	"context"
+	"time"
 	"tutorgo/models"
 	"tutorgo/repository"
 )
 
 type TaskService interface {
-	Create(ctx context.Context, tutorID string, req models.CreateTaskRequest) (models.Task, error)
+	Create(ctx context.Context, tutorID string, req models.CreateTaskRequest, opts models.ScheduleOptions) (models.Task, error)
 	GetByRange(ctx context.Context, tutorID, from, to string) ([]models.Task, error)
-	Update(ctx context.Context, id, tutorID string, req models.UpdateTaskRequest) (models.Task, error)
+	Update(ctx context.Context, id, tutorID string, req models.UpdateTaskRequest, opts models.ScheduleOptions) (models.Task, error)
 	Delete(ctx context.Context, id, tutorID string) error
 	ToggleDone(ctx context.Context, id, tutorID string) (models.Task, error)
 }
 
 type taskService struct {
-	repo repository.TaskRepository
+	repo         repository.TaskRepository
+	scheduleRepo repository.ScheduleRepository
 }
 
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+func NewTaskService(repo repository.TaskRepository, scheduleRepo repository.ScheduleRepository) TaskService {
+	return &taskService{repo: repo, scheduleRepo: scheduleRepo}
 }
 
-func (s *taskService) Create(ctx context.Context, tutorID string, req models.CreateTaskRequest) (models.Task, error) {
+func (s *taskService) Create(ctx context.Context, tutorID string, req models.CreateTaskRequest, opts models.ScheduleOptions) (models.Task, error) {
+	slots := slotsAt([]time.Time{req.ScheduledAt}, req.DurationMinutes)
+	if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{}, opts); !ok {
+		return models.Task{}, err
+	}
 	return s.repo.Create(ctx, tutorID, req)
 }
 
@@ -30,7 +36,16 @@ func (s *taskService) GetByRange(ctx context.Context, tutorID, from, to string)
 	return s.repo.GetByRange(ctx, tutorID, from, to)
 }
 
-func (s *taskService) Update(ctx context.Context, id, tutorID string, req models.UpdateTaskRequest) (models.Task, error) {
+func (s *taskService) Update(ctx context.Context, id, tutorID string, req models.UpdateTaskRequest, opts models.ScheduleOptions) (models.Task, error) {
+	// A finished task no longer occupies its slot.
+	if !req.Done {
+		slots := slotsAt([]time.Time{req.ScheduledAt}, req.DurationMinutes)
+		if ok, err := checkSchedule(ctx, s.scheduleRepo, tutorID, slots, models.ConflictExclusion{TaskID: id}, opts); !ok {
+			return models.Task{}, err
+		}
+	} else if opts.DryRun {
+		return models.Task{}, nil
+	}
 	return s.repo.Update(ctx, id, tutorID, req)
 }
 
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newToken returns a URL-safe random token for links handed out to third parties.
+func newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// linkSigner makes the tokens of mailed links. A token carries the id of a
+// stored row and an expiry, signed together with its purpose: tampered, expired
+// or mistyped tokens are turned away before the database is asked, and a token
+// of one purpose can't be passed off as another. The row is what makes a token
+// work only once.
+type linkSigner struct {
+	secret []byte
+}
+
+func (s linkSigner) sign(purpose string, id string, expiresAt time.Time) string {
+	payload := id + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.signature(purpose, payload)
+}
+
+func (s linkSigner) signature(purpose string, payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(purpose + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parse returns the id in the token if it is signed for purpose and not
+// expired.
+func (s linkSigner) parse(purpose string, token string) (string, bool) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", false
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.signature(purpose, payload))) {
+		return "", false
+	}
+	id, exp, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() >= unix {
+		return "", false
+	}
+	return id, true
+}
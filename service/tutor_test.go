@@ -56,6 +56,11 @@ func (m *mockTutorRepo) UpdatePassword(ctx context.Context, id string, hash stri
 	return m.Called(ctx, id, hash).Error(0)
 }
 
+func (m *mockTutorRepo) GetTimezone(ctx context.Context, id string) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
 func TestCreateTutor_Success(t *testing.T) {
 	repo := new(mockTutorRepo)
 	svc := service.NewTutorService(repo)
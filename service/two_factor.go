@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/totp"
+)
+
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, tutorID string) (models.TwoFactorStatus, error)
+	Setup(ctx context.Context, tutorID string) (models.TwoFactorSetup, error)
+	Enable(ctx context.Context, tutorID string, code string) (models.RecoveryCodes, error)
+	Disable(ctx context.Context, tutorID string, code string) error
+	IsEnabled(ctx context.Context, tutorID string) (bool, error)
+	Verify(ctx context.Context, tutorID string, code string) error
+}
+
+type twoFactorService struct {
+	repo repository.TwoFactorRepository
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository) TwoFactorService {
+	return &twoFactorService{repo: repo}
+}
+
+// totpIssuer names the account in authenticator apps.
+const totpIssuer = "TutorGo"
+
+// Recovery codes: ten of them, each eight base32 characters (40 bits) shown as
+// two groups of four.
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 5
+)
+
+func (s *twoFactorService) state(ctx context.Context, tutorID string) (models.TwoFactorState, error) {
+	state, err := s.repo.GetState(ctx, tutorID)
+	if errors.Is(err, repository.ErrNoTutor) {
+		return models.TwoFactorState{}, fmt.Errorf("tutor: %w", ErrNotFound)
+	}
+	return state, err
+}
+
+func (s *twoFactorService) GetStatus(ctx context.Context, tutorID string) (models.TwoFactorStatus, error) {
+	state, err := s.state(ctx, tutorID)
+	if err != nil || !state.Enabled {
+		return models.TwoFactorStatus{}, err
+	}
+	left, err := s.repo.CountRecoveryCodes(ctx, tutorID)
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	return models.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// Setup starts enrolling with a new secret, replacing any from an enrollment
+// never confirmed. Sign-in does not ask for codes until Enable.
+func (s *twoFactorService) Setup(ctx context.Context, tutorID string) (models.TwoFactorSetup, error) {
+	state, err := s.state(ctx, tutorID)
+	if err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+	if state.Enabled {
+		return models.TwoFactorSetup{}, fmt.Errorf("two-factor authentication is already on: %w", ErrConflict)
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+	err = s.repo.SetSecret(ctx, tutorID, secret)
+	if errors.Is(err, repository.ErrTwoFactorOn) {
+		return models.TwoFactorSetup{}, fmt.Errorf("two-factor authentication is already on: %w", ErrConflict)
+	}
+	if err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+	return models.TwoFactorSetup{Secret: secret, URI: totp.URI(totpIssuer, state.Email, secret)}, nil
+}
+
+// Enable turns two-factor authentication on once the authenticator app shows a
+// code that matches, proving it holds the secret. It returns recovery codes,
+// which are only stored hashed and so can't be shown again.
+func (s *twoFactorService) Enable(ctx context.Context, tutorID string, code string) (models.RecoveryCodes, error) {
+	state, err := s.state(ctx, tutorID)
+	if err != nil {
+		return models.RecoveryCodes{}, err
+	}
+	if state.Enabled {
+		return models.RecoveryCodes{}, fmt.Errorf("two-factor authentication is already on: %w", ErrConflict)
+	}
+	if state.Secret == "" {
+		return models.RecoveryCodes{}, fmt.Errorf("two-factor setup has not been started: %w", ErrBadRequest)
+	}
+	step, ok := totp.Validate(state.Secret, normalizeCode(code), time.Now())
+	if !ok {
+		return models.RecoveryCodes{}, fmt.Errorf("the code is wrong or has expired: %w", ErrBadRequest)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return models.RecoveryCodes{}, err
+		}
+		c := base32.StdEncoding.EncodeToString(b)
+		codes[i] = c[:4] + "-" + c[4:]
+		hashes[i] = hashToken(c)
+	}
+	err = s.repo.Enable(ctx, tutorID, step, hashes)
+	if errors.Is(err, repository.ErrNoEnrollment) {
+		return models.RecoveryCodes{}, fmt.Errorf("two-factor setup has not been started: %w", ErrBadRequest)
+	}
+	if err != nil {
+		return models.RecoveryCodes{}, err
+	}
+	return models.RecoveryCodes{Codes: codes}, nil
+}
+
+// Disable turns two-factor authentication off. It takes a current code or a
+// recovery code; the handler checks the password.
+func (s *twoFactorService) Disable(ctx context.Context, tutorID string, code string) error {
+	if err := s.Verify(ctx, tutorID, code); err != nil {
+		return err
+	}
+	return s.repo.Disable(ctx, tutorID)
+}
+
+func (s *twoFactorService) IsEnabled(ctx context.Context, tutorID string) (bool, error) {
+	state, err := s.state(ctx, tutorID)
+	return state.Enabled, err
+}
+
+// Verify checks the second factor: a code from the authenticator app, each
+// accepted once, or an unused recovery code, which is then spent.
+func (s *twoFactorService) Verify(ctx context.Context, tutorID string, code string) error {
+	state, err := s.state(ctx, tutorID)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled {
+		return fmt.Errorf("two-factor authentication is off: %w", ErrConflict)
+	}
+	code = normalizeCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(state.Secret, code, time.Now())
+		if !ok {
+			return fmt.Errorf("wrong code: %w", ErrUnauthorized)
+		}
+		fresh, err := s.repo.UseStep(ctx, tutorID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return fmt.Errorf("code already used: %w", ErrUnauthorized)
+		}
+		return nil
+	}
+	used, err := s.repo.UseRecoveryCode(ctx, tutorID, hashToken(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("wrong recovery code: %w", ErrUnauthorized)
+	}
+	return nil
+}
+
+// normalizeCode forgives the spaces and dashes people type or paste along with
+// codes, and the case of recovery codes.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tutorgo/models"
+	"tutorgo/repository"
+	"tutorgo/service"
+	"tutorgo/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTwoFactorRepo struct {
+	mock.Mock
+}
+
+func (m *mockTwoFactorRepo) GetState(ctx context.Context, tutorID string) (models.TwoFactorState, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Get(0).(models.TwoFactorState), args.Error(1)
+}
+
+func (m *mockTwoFactorRepo) CountRecoveryCodes(ctx context.Context, tutorID string) (int, error) {
+	args := m.Called(ctx, tutorID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockTwoFactorRepo) SetSecret(ctx context.Context, tutorID string, secret string) error {
+	return m.Called(ctx, tutorID, secret).Error(0)
+}
+
+func (m *mockTwoFactorRepo) Enable(ctx context.Context, tutorID string, step int64, codeHashes []string) error {
+	return m.Called(ctx, tutorID, step, codeHashes).Error(0)
+}
+
+func (m *mockTwoFactorRepo) UseStep(ctx context.Context, tutorID string, step int64) (bool, error) {
+	args := m.Called(ctx, tutorID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockTwoFactorRepo) UseRecoveryCode(ctx context.Context, tutorID string, codeHash string) (bool, error) {
+	args := m.Called(ctx, tutorID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockTwoFactorRepo) Disable(ctx context.Context, tutorID string) error {
+	return m.Called(ctx, tutorID).Error(0)
+}
+
+const totpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func currentCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.Code(totpSecret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorSetup_ProvisioningURI(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Email: "anna@example.com"}, nil)
+	repo.On("SetSecret", mock.Anything, tutorID, mock.Anything).Return(nil)
+
+	setup, err := svc.Setup(context.Background(), tutorID)
+
+	require.NoError(t, err)
+	assert.Len(t, setup.Secret, 32)
+	assert.Contains(t, setup.URI, "otpauth://totp/TutorGo:anna@example.com?")
+	assert.Contains(t, setup.URI, "secret="+setup.Secret)
+}
+
+func TestTwoFactorSetup_AlreadyOn(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret, Enabled: true}, nil)
+
+	_, err := svc.Setup(context.Background(), tutorID)
+
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "SetSecret", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTwoFactorSetup_RepositoryErrors(t *testing.T) {
+	outage := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		stateErr error
+		setErr   error
+		want     error
+	}{
+		{"no tutor", repository.ErrNoTutor, nil, service.ErrNotFound},
+		{"state outage", outage, nil, outage},
+		{"enabled meanwhile", nil, repository.ErrTwoFactorOn, service.ErrConflict},
+		{"write outage", nil, outage, outage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockTwoFactorRepo)
+			svc := service.NewTwoFactorService(repo)
+
+			repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Email: "anna@example.com"}, tt.stateErr)
+			repo.On("SetSecret", mock.Anything, tutorID, mock.Anything).Return(tt.setErr).Maybe()
+
+			_, err := svc.Setup(context.Background(), tutorID)
+
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestTwoFactorEnable_StoresHashedRecoveryCodes(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	var hashes []string
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret}, nil)
+	repo.On("Enable", mock.Anything, tutorID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { hashes = args.Get(3).([]string) }).
+		Return(nil)
+
+	codes, err := svc.Enable(context.Background(), tutorID, currentCode(t))
+
+	require.NoError(t, err)
+	require.Len(t, codes.Codes, 10)
+	assert.Regexp(t, `^[A-Z2-7]{4}-[A-Z2-7]{4}$`, codes.Codes[0])
+	require.Len(t, hashes, 10)
+	assert.NotContains(t, hashes, codes.Codes[0])
+}
+
+func TestTwoFactorEnable_WrongCode(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret}, nil)
+
+	_, err := svc.Enable(context.Background(), tutorID, "000000")
+
+	assert.ErrorIs(t, err, service.ErrBadRequest)
+	repo.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTwoFactorEnable_RepositoryErrors(t *testing.T) {
+	outage := errors.New("connection refused")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"enrollment gone", repository.ErrNoEnrollment, service.ErrBadRequest},
+		{"outage", outage, outage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockTwoFactorRepo)
+			svc := service.NewTwoFactorService(repo)
+
+			repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret}, nil)
+			repo.On("Enable", mock.Anything, tutorID, mock.Anything, mock.Anything).Return(tt.err)
+
+			_, err := svc.Enable(context.Background(), tutorID, currentCode(t))
+
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestTwoFactorVerify_CodeWorksOnce(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret, Enabled: true}, nil)
+	repo.On("UseStep", mock.Anything, tutorID, mock.Anything).Return(true, nil).Once()
+	repo.On("UseStep", mock.Anything, tutorID, mock.Anything).Return(false, nil).Once()
+
+	code := currentCode(t)
+	assert.NoError(t, svc.Verify(context.Background(), tutorID, code[:3]+" "+code[3:]))
+	assert.ErrorIs(t, svc.Verify(context.Background(), tutorID, code), service.ErrUnauthorized)
+}
+
+func TestTwoFactorVerify_RecoveryCode(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	var stored []string
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret}, nil).Once()
+	repo.On("Enable", mock.Anything, tutorID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(3).([]string) }).
+		Return(nil)
+	codes, err := svc.Enable(context.Background(), tutorID, currentCode(t))
+	require.NoError(t, err)
+
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret, Enabled: true}, nil)
+	repo.On("UseRecoveryCode", mock.Anything, tutorID, stored[0]).Return(true, nil)
+	repo.On("UseRecoveryCode", mock.Anything, tutorID, mock.Anything).Return(false, nil)
+
+	assert.NoError(t, svc.Verify(context.Background(), tutorID, codes.Codes[0]))
+	assert.ErrorIs(t, svc.Verify(context.Background(), tutorID, "AAAA-AAAA"), service.ErrUnauthorized)
+}
+
+func TestTwoFactorDisable_NeedsCode(t *testing.T) {
+	repo := new(mockTwoFactorRepo)
+	svc := service.NewTwoFactorService(repo)
+
+	repo.On("GetState", mock.Anything, tutorID).Return(models.TwoFactorState{Secret: totpSecret, Enabled: true}, nil)
+
+	err := svc.Disable(context.Background(), tutorID, "000000")
+
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+	repo.AssertNotCalled(t, "Disable", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,99 @@
+package sheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type csvWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+// NewCSV writes comma-separated UTF-8 with a byte order mark, which spreadsheet
+// apps need to tell the encoding. Times are written as "2006-01-02 15:04", and
+// text that a spreadsheet would take for a formula gets a leading quote.
+func NewCSV(w io.Writer) Writer {
+	return &csvWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	_, err := io.WriteString(c.w, "\ufeff")
+	return err
+}
+
+func (c *csvWriter) WriteRow(cells ...any) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		v, err := csvValue(cell)
+		if err != nil {
+			return err
+		}
+		record[i] = v
+	}
+	if err := c.csv.Write(record); err != nil {
+		return err
+	}
+	// Flush per row so the rows stream instead of piling up in the buffer.
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+func csvValue(cell any) (string, error) {
+	switch v := cell.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return escapeFormula(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case Decimal:
+		return string(v), nil
+	case time.Time:
+		return v.Format(dateTimeLayout), nil
+	case Date:
+		return time.Time(v).Format(dateLayout), nil
+	default:
+		return "", fmt.Errorf("sheet: unsupported cell type %T", cell)
+	}
+}
+
+// signedNumber matches numbers and phone numbers such as "-12.50" or
+// "+7 (999) 123-45-67": a sign followed by digits and punctuation only, which
+// can't call a function however a spreadsheet reads it.
+var signedNumber = regexp.MustCompile(`^[+-][0-9 ().,-]*[0-9][0-9 ().,-]*$`)
+
+// escapeFormula prefixes text starting like a formula with a quote, so that a
+// name such as "=HYPERLINK(...)" is shown as typed instead of evaluated. Signed
+// numbers and phone numbers are left alone.
+func escapeFormula(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) || signedNumber.MatchString(s) {
+		return s
+	}
+	return "'" + s
+}
@@ -0,0 +1,27 @@
+// Package sheet streams tables as spreadsheets for exports: CSV, and XLSX
+// (Office Open XML) written as a single worksheet. Rows go out as they are
+// written, so a writer holds no more than the row at hand whatever the size of
+// the table. Nothing is written before the first row or Close.
+package sheet
+
+import "time"
+
+// Writer writes rows of cells. A cell is nil (left empty), a string, an int, a
+// float64, a bool, a Decimal, a time.Time (a date and time) or a Date.
+type Writer interface {
+	WriteRow(cells ...any) error
+	// Close finishes the file; it does not close the underlying writer.
+	Close() error
+}
+
+// Decimal is a number given as its decimal text, e.g. "1234.50", so amounts
+// keep their exact value.
+type Decimal string
+
+// Date is a calendar day; its time of day is ignored.
+type Date time.Time
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
@@ -0,0 +1,141 @@
+package sheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+	"tutorgo/sheet"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w := sheet.NewCSV(&buf)
+
+	require.NoError(t, w.WriteRow("Name", "Paid at", "Day", "Amount", "Lessons", "Share", "Voided", "Notes"))
+	require.NoError(t, w.WriteRow("Иван", time.Date(2026, 3, 2, 17, 5, 0, 0, time.UTC),
+		sheet.Date(time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)), sheet.Decimal("1500.00"), 4, 0.5, false, nil))
+	require.NoError(t, w.WriteRow(`say "hi", then`))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "\ufeff"+
+		"Name,Paid at,Day,Amount,Lessons,Share,Voided,Notes\n"+
+		"Иван,2026-03-02 17:05,2026-03-02,1500.00,4,0.5,false,\n"+
+		"\"say \"\"hi\"\", then\"\n", buf.String())
+}
+
+func TestCSV_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w := sheet.NewCSV(&buf)
+
+	require.NoError(t, w.WriteRow("=HYPERLINK(\"http://x\")", "+A1", "-1+cmd|' /C calc'!A0", "@SUM(A1)", "\tx", "a=b", sheet.Decimal("-12.50"), -3))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "\ufeff"+
+		"\"'=HYPERLINK(\"\"http://x\"\")\",'+A1,'-1+cmd|' /C calc'!A0,'@SUM(A1),'\tx,a=b,-12.50,-3\n", buf.String())
+}
+
+func TestCSV_KeepsSignedNumbersAndPhones(t *testing.T) {
+	var buf bytes.Buffer
+	w := sheet.NewCSV(&buf)
+
+	require.NoError(t, w.WriteRow("+7 (999) 123-45-67", "+79991234567", "-1500.00", "+1", "-"))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "\ufeff"+
+		"+7 (999) 123-45-67,+79991234567,-1500.00,+1,'-\n", buf.String())
+}
+
+func TestCSV_NothingBeforeFirstRow(t *testing.T) {
+	var buf bytes.Buffer
+	w := sheet.NewCSV(&buf)
+	assert.Zero(t, buf.Len())
+
+	assert.Error(t, w.WriteRow(struct{}{}))
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Style  string `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readPart(t *testing.T, data []byte, name string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	f, err := zr.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	body, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return body
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w := sheet.NewXLSX(&buf, "Payments")
+	assert.Zero(t, buf.Len())
+
+	require.NoError(t, w.WriteRow("Name", "Paid at", "Day", "Amount", "Lessons", "Voided"))
+	require.NoError(t, w.WriteRow("<Ivan> & co", time.Date(2026, 3, 2, 18, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+		sheet.Date(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)), sheet.Decimal("1500.50"), 4, true))
+	cells := make([]any, 28)
+	cells[27] = "last"
+	require.NoError(t, w.WriteRow(cells...))
+	require.NoError(t, w.Close())
+
+	var s xlsxSheet
+	require.NoError(t, xml.Unmarshal(readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"), &s))
+	require.Len(t, s.Rows, 3)
+
+	assert.Equal(t, xlsxCell{Ref: "A1", Type: "inlineStr", Inline: "Name"}, s.Rows[0].Cells[0])
+	row := s.Rows[1].Cells
+	assert.Equal(t, xlsxCell{Ref: "A2", Type: "inlineStr", Inline: "<Ivan> & co"}, row[0])
+	// 2026-03-02 is day 46083; the wall clock 18:00 is three quarters of it.
+	assert.Equal(t, xlsxCell{Ref: "B2", Style: "1", Value: "46083.75"}, row[1])
+	assert.Equal(t, xlsxCell{Ref: "C2", Style: "2", Value: "46083"}, row[2])
+	assert.Equal(t, xlsxCell{Ref: "D2", Value: "1500.50"}, row[3])
+	assert.Equal(t, xlsxCell{Ref: "E2", Value: "4"}, row[4])
+	assert.Equal(t, xlsxCell{Ref: "F2", Type: "b", Value: "1"}, row[5])
+	assert.Equal(t, []xlsxCell{{Ref: "AB3", Type: "inlineStr", Inline: "last"}}, s.Rows[2].Cells)
+
+	assert.Contains(t, string(readPart(t, buf.Bytes(), "xl/workbook.xml")), `<sheet name="Payments"`)
+	assert.Contains(t, string(readPart(t, buf.Bytes(), "[Content_Types].xml")), "/xl/worksheets/sheet1.xml")
+}
+
+func TestXLSX_FormulaTextStaysText(t *testing.T) {
+	var buf bytes.Buffer
+	w := sheet.NewXLSX(&buf, "Students")
+	require.NoError(t, w.WriteRow("=HYPERLINK(\"http://x\")", "@SUM(A1)"))
+	require.NoError(t, w.Close())
+
+	body := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	var s xlsxSheet
+	require.NoError(t, xml.Unmarshal(body, &s))
+	require.Len(t, s.Rows, 1)
+	for i, want := range []string{"=HYPERLINK(\"http://x\")", "@SUM(A1)"} {
+		cell := s.Rows[0].Cells[i]
+		assert.Equal(t, "inlineStr", cell.Type)
+		assert.Equal(t, want, cell.Inline)
+	}
+	assert.NotContains(t, string(body), "<f>")
+}
+
+func TestXLSX_InvalidDecimal(t *testing.T) {
+	w := sheet.NewXLSX(io.Discard, "Sheet")
+	assert.Error(t, w.WriteRow(sheet.Decimal("12,5")))
+}
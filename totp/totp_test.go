@@ -0,0 +1,74 @@
+package totp_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"tutorgo/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is "12345678901234567890", the SHA-1 key of RFC 6238 appendix B.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists eight digits; six-digit codes are their last six.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := totp.Code(rfcSecret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "at %d", tt.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := totp.Validate(rfcSecret, "050471", now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	previous, _ := totp.Code(rfcSecret, now.Add(-totp.Period))
+	step, ok = totp.Validate(rfcSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	stale, _ := totp.Code(rfcSecret, now.Add(-2*totp.Period))
+	_, ok = totp.Validate(rfcSecret, stale, now)
+	assert.False(t, ok)
+
+	_, ok = totp.Validate(rfcSecret, "50471", now)
+	assert.False(t, ok)
+	_, ok = totp.Validate("not base32!", "050471", now)
+	assert.False(t, ok)
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := totp.NewSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = totp.Code(secret, time.Now())
+	assert.NoError(t, err)
+}
+
+func TestURI(t *testing.T) {
+	uri := totp.URI("TutorGo", "anna@example.com", rfcSecret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/TutorGo:anna@example.com?"), uri)
+	assert.Contains(t, uri, "secret="+rfcSecret)
+	assert.Contains(t, uri, "issuer=TutorGo")
+	assert.Contains(t, uri, "period=30")
+}
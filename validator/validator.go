@@ -48,6 +48,8 @@ func formatError(err validator.FieldError) string {
 		return fmt.Sprintf("must be greater than %s", err.Param())
 	case "oneof":
 		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(err.Param(), " ", ", "))
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
 	default:
 		return fmt.Sprintf("%s is invalid", strings.ToLower(err.Field()))
 	}